@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ApplyRequest is a porter.yaml spec to plan or apply to a cluster
+type ApplyRequest struct {
+	Spec  string `json:"spec"`
+	Prune bool   `json:"prune"`
+}
+
+// ApplyPlanResponse is the ordered set of actions that applying a spec
+// performs. Once the spec is applied, the status of each action is set.
+type ApplyPlanResponse struct {
+	Actions []*ApplyAction `json:"actions"`
+}
+
+// ApplyAction is the operation that a plan performs on a single release or
+// env group
+type ApplyAction struct {
+	Kind      string   `json:"kind"`
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	Operation string   `json:"operation"`
+	Changes   []string `json:"changes,omitempty"`
+	Status    string   `json:"status,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// PlanApply computes the actions that applying a spec to a cluster would
+// perform, without performing them
+func (c *Client) PlanApply(
+	ctx context.Context,
+	projectID, clusterID uint,
+	applyRequest *ApplyRequest,
+) (*ApplyPlanResponse, error) {
+	return c.apply(ctx, projectID, clusterID, "apply/plan", applyRequest)
+}
+
+// Apply brings a cluster in line with a spec, and returns the result of each
+// action
+func (c *Client) Apply(
+	ctx context.Context,
+	projectID, clusterID uint,
+	applyRequest *ApplyRequest,
+) (*ApplyPlanResponse, error) {
+	return c.apply(ctx, projectID, clusterID, "apply", applyRequest)
+}
+
+func (c *Client) apply(
+	ctx context.Context,
+	projectID, clusterID uint,
+	path string,
+	applyRequest *ApplyRequest,
+) (*ApplyPlanResponse, error) {
+	data, err := json.Marshal(applyRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/projects/%d/%s?cluster_id=%d", c.BaseURL, projectID, path, clusterID),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &ApplyPlanResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// ListAuditEventsRequest represents the filters and pagination options for
+// listing audit events
+type ListAuditEventsRequest struct {
+	Page     int
+	PageSize int
+	UserID   uint
+	Resource string
+}
+
+// ListAuditEventsResponse is a page of audit events for a project
+type ListAuditEventsResponse struct {
+	Events   []*models.AuditEventExternal `json:"events"`
+	Page     int                          `json:"page"`
+	PageSize int                          `json:"page_size"`
+	Total    int64                        `json:"total"`
+}
+
+// ListAuditEvents lists a page of audit events for a project
+func (c *Client) ListAuditEvents(
+	ctx context.Context,
+	projectID uint,
+	listAuditEventsRequest *ListAuditEventsRequest,
+) (*ListAuditEventsResponse, error) {
+	vals := make(url.Values)
+
+	if listAuditEventsRequest.Page != 0 {
+		vals.Set("page", strconv.Itoa(listAuditEventsRequest.Page))
+	}
+
+	if listAuditEventsRequest.PageSize != 0 {
+		vals.Set("page_size", strconv.Itoa(listAuditEventsRequest.PageSize))
+	}
+
+	if listAuditEventsRequest.UserID != 0 {
+		vals.Set("user_id", fmt.Sprintf("%d", listAuditEventsRequest.UserID))
+	}
+
+	if listAuditEventsRequest.Resource != "" {
+		vals.Set("resource", listAuditEventsRequest.Resource)
+	}
+
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s/projects/%d/events?%s", c.BaseURL, projectID, vals.Encode()),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &ListAuditEventsResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
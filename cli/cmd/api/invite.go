@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// InviteResponse is an invite to a project
+type InviteResponse models.InviteExternal
+
+// CreateInviteRequest represents the accepted fields for inviting one or more
+// emails to a project
+type CreateInviteRequest struct {
+	Emails    []string `json:"emails"`
+	Kind      string   `json:"kind,omitempty"`
+	ClusterID uint     `json:"cluster_id,omitempty"`
+	Namespace string   `json:"namespace,omitempty"`
+}
+
+// CreateInvites invites each email to a project with the same role
+func (c *Client) CreateInvites(
+	ctx context.Context,
+	projectID uint,
+	createInviteRequest *CreateInviteRequest,
+) ([]*InviteResponse, error) {
+	data, err := json.Marshal(createInviteRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/projects/%d/invites/bulk", c.BaseURL, projectID),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := make([]*InviteResponse, 0)
+
+	if httpErr, err := c.sendRequest(req, &bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// ListInvites lists the invites for a project, optionally filtered to the
+// pending, expired or accepted invites
+func (c *Client) ListInvites(
+	ctx context.Context,
+	projectID uint,
+	status string,
+) ([]*InviteResponse, error) {
+	vals := url.Values{}
+
+	if status != "" {
+		vals.Set("status", status)
+	}
+
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s/projects/%d/invites?%s", c.BaseURL, projectID, vals.Encode()),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := make([]*InviteResponse, 0)
+
+	if httpErr, err := c.sendRequest(req, &bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// ResendInvite generates a new link for an invite and sends it again
+func (c *Client) ResendInvite(
+	ctx context.Context,
+	projectID, inviteID uint,
+) (*InviteResponse, error) {
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/projects/%d/invites/%d/resend", c.BaseURL, projectID, inviteID),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &InviteResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// DeleteInvite deletes an invite given a project id and invite id
+func (c *Client) DeleteInvite(
+	ctx context.Context,
+	projectID, inviteID uint,
+) error {
+	req, err := http.NewRequest(
+		"DELETE",
+		fmt.Sprintf("%s/projects/%d/invites/%d", c.BaseURL, projectID, inviteID),
+		nil,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+
+	if httpErr, err := c.sendRequest(req, nil, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,443 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// ReleaseDiffResponse is the set of changes that an upgrade or rollback would
+// make to the deployed revision of a release
+type ReleaseDiffResponse struct {
+	Name            string                  `json:"name"`
+	CurrentRevision int                     `json:"current_revision"`
+	TargetRevision  int                     `json:"target_revision,omitempty"`
+	ValuesDiff      string                  `json:"values_diff"`
+	Resources       []*ResourceDiffResponse `json:"resources"`
+}
+
+// ResourceDiffResponse is the change to a single rendered resource
+type ResourceDiffResponse struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Change    string `json:"change"`
+	Diff      string `json:"diff"`
+}
+
+// DiffUpgradeReleaseRequest is the values.yaml, and optionally the chart version,
+// to render an upgrade with
+type DiffUpgradeReleaseRequest struct {
+	Values       string `json:"values"`
+	ChartVersion string `json:"version,omitempty"`
+}
+
+// DiffUpgradeRelease renders an upgrade of a release without applying it
+func (c *Client) DiffUpgradeRelease(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace, name string,
+	diffUpgradeReleaseRequest *DiffUpgradeReleaseRequest,
+) (*ReleaseDiffResponse, error) {
+	data, err := json.Marshal(diffUpgradeReleaseRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.diffRelease(ctx, projectID, clusterID, namespace, name, "diff", data)
+}
+
+// DiffRollbackReleaseRequest is the revision to compare the deployed revision to
+type DiffRollbackReleaseRequest struct {
+	Revision int `json:"revision"`
+}
+
+// DiffRollbackRelease compares the deployed revision of a release to the revision
+// that it would be rolled back to
+func (c *Client) DiffRollbackRelease(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace, name string,
+	diffRollbackReleaseRequest *DiffRollbackReleaseRequest,
+) (*ReleaseDiffResponse, error) {
+	data, err := json.Marshal(diffRollbackReleaseRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.diffRelease(ctx, projectID, clusterID, namespace, name, "rollback/diff", data)
+}
+
+func (c *Client) diffRelease(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace, name, path string,
+	data []byte,
+) (*ReleaseDiffResponse, error) {
+	vals := make(url.Values)
+	vals.Set("cluster_id", fmt.Sprintf("%d", clusterID))
+	vals.Set("namespace", namespace)
+	vals.Set("storage", "secret")
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf(
+			"%s/projects/%d/releases/%s/%s?%s",
+			c.BaseURL,
+			projectID,
+			url.PathEscape(name),
+			path,
+			vals.Encode(),
+		),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &ReleaseDiffResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// PromoteReleaseRequest is the target release to promote a release to, along
+// with the paths of the values that are copied to the target
+type PromoteReleaseRequest struct {
+	TargetClusterID uint     `json:"target_cluster_id"`
+	TargetNamespace string   `json:"target_namespace"`
+	TargetName      string   `json:"target_name,omitempty"`
+	Values          []string `json:"values,omitempty"`
+}
+
+// PromoteReleaseResponse is the result of promoting a release, or of rendering
+// the promotion without applying it
+type PromoteReleaseResponse struct {
+	TargetClusterID uint                 `json:"target_cluster_id"`
+	TargetNamespace string               `json:"target_namespace"`
+	TargetName      string               `json:"target_name"`
+	Created         bool                 `json:"created"`
+	Diff            *ReleaseDiffResponse `json:"diff,omitempty"`
+	Revision        int                  `json:"revision,omitempty"`
+}
+
+// DiffPromoteRelease renders the promotion of a release to a target release
+// without applying it
+func (c *Client) DiffPromoteRelease(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace, name string,
+	promoteReleaseRequest *PromoteReleaseRequest,
+) (*PromoteReleaseResponse, error) {
+	return c.promoteRelease(ctx, projectID, clusterID, namespace, name, "promote/diff", "", promoteReleaseRequest)
+}
+
+// PromoteRelease applies the chart version, image tag and selected values of a
+// release to a target release, creating the target if it doesn't exist. The
+// override reason is only needed if a deploy policy blocks the promotion.
+func (c *Client) PromoteRelease(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace, name, overrideReason string,
+	promoteReleaseRequest *PromoteReleaseRequest,
+) (*PromoteReleaseResponse, error) {
+	return c.promoteRelease(ctx, projectID, clusterID, namespace, name, "promote", overrideReason, promoteReleaseRequest)
+}
+
+func (c *Client) promoteRelease(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace, name, path, overrideReason string,
+	promoteReleaseRequest *PromoteReleaseRequest,
+) (*PromoteReleaseResponse, error) {
+	data, err := json.Marshal(promoteReleaseRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make(url.Values)
+	vals.Set("cluster_id", fmt.Sprintf("%d", clusterID))
+	vals.Set("namespace", namespace)
+	vals.Set("storage", "secret")
+
+	if overrideReason != "" {
+		vals.Set("override_reason", overrideReason)
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf(
+			"%s/projects/%d/releases/%s/%s?%s",
+			c.BaseURL,
+			projectID,
+			url.PathEscape(name),
+			path,
+			vals.Encode(),
+		),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &PromoteReleaseResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// UpgradeReleaseChartRequest is the chart version to upgrade a release to, and
+// the repo to load the chart version from
+type UpgradeReleaseChartRequest struct {
+	Version    string `json:"version"`
+	RepoURL    string `json:"repo_url,omitempty"`
+	HelmRepoID uint   `json:"helm_repo_id,omitempty"`
+	DryRun     bool   `json:"dry_run"`
+}
+
+// RenamedValueResponse is a value that the new chart version moved to a new
+// path
+type RenamedValueResponse struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ValuesMigrationResponse is the result of migrating the values of a release
+// to a new chart version
+type ValuesMigrationResponse struct {
+	Values          map[string]interface{}  `json:"values"`
+	Removed         []string                `json:"removed"`
+	Renamed         []*RenamedValueResponse `json:"renamed"`
+	UpdatedDefaults []string                `json:"updated_defaults"`
+}
+
+// UpgradeReleaseChartResponse is the result of upgrading a release to another
+// chart version, or of rendering the upgrade without applying it
+type UpgradeReleaseChartResponse struct {
+	Name           string                   `json:"name"`
+	Chart          string                   `json:"chart"`
+	CurrentVersion string                   `json:"current_version"`
+	TargetVersion  string                   `json:"target_version"`
+	Migration      *ValuesMigrationResponse `json:"migration"`
+	Form           *models.FormYAML         `json:"form"`
+	Diff           *ReleaseDiffResponse     `json:"diff,omitempty"`
+	Revision       int                      `json:"revision,omitempty"`
+}
+
+// UpgradeReleaseChart upgrades a release to another version of its chart,
+// migrating the values of the release to the new version. The override reason
+// is only needed if a deploy policy blocks the upgrade.
+func (c *Client) UpgradeReleaseChart(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace, name, overrideReason string,
+	upgradeReleaseChartRequest *UpgradeReleaseChartRequest,
+) (*UpgradeReleaseChartResponse, error) {
+	data, err := json.Marshal(upgradeReleaseChartRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make(url.Values)
+	vals.Set("cluster_id", fmt.Sprintf("%d", clusterID))
+	vals.Set("namespace", namespace)
+	vals.Set("storage", "secret")
+
+	if overrideReason != "" {
+		vals.Set("override_reason", overrideReason)
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf(
+			"%s/projects/%d/releases/%s/upgrade_chart?%s",
+			c.BaseURL,
+			projectID,
+			url.PathEscape(name),
+			vals.Encode(),
+		),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &UpgradeReleaseChartResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// AdoptableRelease is a Helm release in a cluster that Porter doesn't manage
+type AdoptableRelease struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	Chart        string `json:"chart"`
+	ChartVersion string `json:"chart_version"`
+	Revision     int    `json:"revision"`
+	Status       string `json:"status"`
+}
+
+// ListAdoptableReleases lists the Helm releases in a cluster that Porter
+// doesn't manage. Releases in every namespace are listed if the namespace is
+// empty.
+func (c *Client) ListAdoptableReleases(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace string,
+) ([]*AdoptableRelease, error) {
+	vals := make(url.Values)
+	vals.Set("cluster_id", fmt.Sprintf("%d", clusterID))
+	vals.Set("storage", "secret")
+
+	if namespace != "" {
+		vals.Set("namespace", namespace)
+	}
+
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s/projects/%d/releases/adoptable?%s", c.BaseURL, projectID, vals.Encode()),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := make([]*AdoptableRelease, 0)
+
+	if httpErr, err := c.sendRequest(req, &bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// AdoptReleaseGitAction links an adopted release to the GitHub repository
+// that it is built and deployed from
+type AdoptReleaseGitAction struct {
+	GitRepo        string `json:"git_repo"`
+	GitBranch      string `json:"git_branch,omitempty"`
+	GitRepoID      uint   `json:"git_repo_id"`
+	ImageRepoURI   string `json:"image_repo_uri"`
+	DockerfilePath string `json:"dockerfile_path,omitempty"`
+	FolderPath     string `json:"folder_path,omitempty"`
+	RegistryID     uint   `json:"registry_id,omitempty"`
+}
+
+// AdoptReleaseRequest is a single Helm release to adopt
+type AdoptReleaseRequest struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	GitAction *AdoptReleaseGitAction `json:"git_action,omitempty"`
+}
+
+// AdoptReleasesRequest is the list of Helm releases to adopt, or every release
+// that Porter doesn't manage if All is set
+type AdoptReleasesRequest struct {
+	All      bool                   `json:"all"`
+	Releases []*AdoptReleaseRequest `json:"releases,omitempty"`
+}
+
+// StoredReleaseResponse is a release that Porter manages, along with the
+// token that its deploy webhook is called with
+type StoredReleaseResponse struct {
+	ID           uint   `json:"id"`
+	WebhookToken string `json:"webhook_token"`
+}
+
+// AdoptReleaseResult is the result of adopting a single Helm release
+type AdoptReleaseResult struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	Release   *StoredReleaseResponse `json:"release,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// AdoptReleases brings Helm releases that were not deployed through Porter
+// under Porter's management. If All is set and the namespace is not empty,
+// only the releases in the namespace are adopted.
+func (c *Client) AdoptReleases(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace string,
+	adoptReleasesRequest *AdoptReleasesRequest,
+) ([]*AdoptReleaseResult, error) {
+	data, err := json.Marshal(adoptReleasesRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make(url.Values)
+	vals.Set("cluster_id", fmt.Sprintf("%d", clusterID))
+	vals.Set("storage", "secret")
+
+	if namespace != "" {
+		vals.Set("namespace", namespace)
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/projects/%d/releases/adopt?%s", c.BaseURL, projectID, vals.Encode()),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := make([]*AdoptReleaseResult, 0)
+
+	if httpErr, err := c.sendRequest(req, &bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
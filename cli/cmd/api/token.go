@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// CreateAPITokenRequest represents the accepted fields for creating a
+// stored API token
+type CreateAPITokenRequest struct {
+	Name   string     `json:"name"`
+	Scopes []string   `json:"scopes"`
+	Expiry *time.Time `json:"expiry"`
+}
+
+// CreateAPITokenResponse is the stored API token after creation, along with
+// its encoded value
+type CreateAPITokenResponse struct {
+	*models.APITokenExternal
+	Token string `json:"token"`
+}
+
+// CreateAPIToken creates a stored API token for a project
+func (c *Client) CreateAPIToken(
+	ctx context.Context,
+	projectID uint,
+	createAPITokenRequest *CreateAPITokenRequest,
+) (*CreateAPITokenResponse, error) {
+	data, err := json.Marshal(createAPITokenRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/projects/%d/tokens", c.BaseURL, projectID),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &CreateAPITokenResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// ListAPITokensResponse is the list of stored API tokens for a project
+type ListAPITokensResponse []models.APITokenExternal
+
+// ListAPITokens lists the stored API tokens for a project
+func (c *Client) ListAPITokens(
+	ctx context.Context,
+	projectID uint,
+) (ListAPITokensResponse, error) {
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s/projects/%d/tokens", c.BaseURL, projectID),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := ListAPITokensResponse{}
+
+	if httpErr, err := c.sendRequest(req, &bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// RevokeAPITokenResponse is the stored API token after it has been revoked
+type RevokeAPITokenResponse models.APITokenExternal
+
+// RevokeAPIToken revokes a stored API token given a project id and token id
+func (c *Client) RevokeAPIToken(
+	ctx context.Context,
+	projectID uint,
+	tokenID uint,
+) (*RevokeAPITokenResponse, error) {
+	req, err := http.NewRequest(
+		"DELETE",
+		fmt.Sprintf("%s/projects/%d/tokens/%d", c.BaseURL, projectID, tokenID),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &RevokeAPITokenResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
@@ -48,8 +48,13 @@ type LoginRequest struct {
 }
 
 // LoginResponse is the user model response that is returned after successfully
-// logging in
-type LoginResponse models.UserExternal
+// logging in. If the user has two-factor authentication enabled, only
+// TwoFactorRequired is set, and the login must be completed with LoginTwoFactor.
+type LoginResponse struct {
+	models.UserExternal
+
+	TwoFactorRequired bool `json:"two_factor_required"`
+}
 
 // Login authorizes the user and grants them a cookie-based session
 func (c *Client) Login(ctx context.Context, loginRequest *LoginRequest) (*LoginResponse, error) {
@@ -83,6 +88,49 @@ func (c *Client) Login(ctx context.Context, loginRequest *LoginRequest) (*LoginR
 	return bodyResp, nil
 }
 
+// LoginTwoFactorRequest is the code or recovery code for the second step of
+// a login
+type LoginTwoFactorRequest struct {
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+// LoginTwoFactor completes the login of a user with two-factor authentication
+// enabled. It must be called with the same cookie jar as Login.
+func (c *Client) LoginTwoFactor(
+	ctx context.Context,
+	loginRequest *LoginTwoFactorRequest,
+) (*LoginResponse, error) {
+	data, err := json.Marshal(loginRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/login/2fa", c.BaseURL),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &LoginResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, false); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
 // Logout logs the user out and deauthorizes the cookie-based session
 func (c *Client) Logout(ctx context.Context) error {
 	req, err := http.NewRequest(
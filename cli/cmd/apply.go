@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/porter-dev/porter/cli/cmd/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyFile   string
+	applyPrune  bool
+	applyDryRun bool
+	applyYes    bool
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Applies a porter.yaml file to the current cluster",
+	Long: `Applies a porter.yaml file to the current cluster.
+
+The file describes the releases of a project and the env groups that they read.
+Porter compares the file to the cluster and shows the releases and env groups
+that will be created, upgraded or deleted before applying it. Resources that
+already match the file are left alone, so applying the same file twice does
+nothing.
+
+Releases and env groups that are not in the file are only deleted if --prune
+is set, and only in the namespaces that the file uses.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, applySpec)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.PersistentFlags().StringVar(
+		&host,
+		"host",
+		getHost(),
+		"host url of Porter instance",
+	)
+
+	applyCmd.Flags().StringVarP(
+		&applyFile,
+		"file",
+		"f",
+		"porter.yaml",
+		"path to the porter.yaml file",
+	)
+
+	applyCmd.Flags().BoolVar(
+		&applyPrune,
+		"prune",
+		false,
+		"delete releases and env groups that are not in the file",
+	)
+
+	applyCmd.Flags().BoolVar(
+		&applyDryRun,
+		"dry-run",
+		false,
+		"only show the plan, without applying it",
+	)
+
+	applyCmd.Flags().BoolVarP(
+		&applyYes,
+		"yes",
+		"y",
+		false,
+		"apply the plan without asking for confirmation",
+	)
+}
+
+func applySpec(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	spec, err := ioutil.ReadFile(applyFile)
+
+	if err != nil {
+		return err
+	}
+
+	req := &api.ApplyRequest{
+		Spec:  string(spec),
+		Prune: applyPrune,
+	}
+
+	plan, err := client.PlanApply(context.Background(), getProjectID(), getClusterID(), req)
+
+	if err != nil {
+		return err
+	}
+
+	if !printApplyPlan(plan) {
+		fmt.Println("No changes, the cluster already matches", applyFile)
+		return nil
+	}
+
+	if applyDryRun {
+		return nil
+	}
+
+	if !applyYes {
+		userResp, err := utils.PromptPlaintext(
+			fmt.Sprintf("\nApply these changes? %s ", color.New(color.FgCyan).Sprintf("[y/n]")),
+		)
+
+		if err != nil {
+			return err
+		}
+
+		if userResp := strings.ToLower(userResp); userResp != "y" && userResp != "yes" {
+			return nil
+		}
+	}
+
+	result, err := client.Apply(context.Background(), getProjectID(), getClusterID(), req)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+
+	failed := 0
+
+	for _, action := range result.Actions {
+		switch action.Status {
+		case "applied":
+			color.New(color.FgGreen).Printf("%s %s %s/%s\n", pastTense(action.Operation), action.Kind, action.Namespace, action.Name)
+		case "failed":
+			failed++
+			color.New(color.FgRed).Printf("Failed to %s %s %s/%s: %s\n", action.Operation, action.Kind, action.Namespace, action.Name, action.Error)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of the actions failed", failed)
+	}
+
+	return nil
+}
+
+// printApplyPlan prints the actions of a plan that change something, and
+// returns false if there are none
+func printApplyPlan(plan *api.ApplyPlanResponse) bool {
+	hasChanges := false
+
+	for _, action := range plan.Actions {
+		name := fmt.Sprintf("%s %s/%s", action.Kind, action.Namespace, action.Name)
+
+		switch action.Operation {
+		case "create":
+			color.New(color.FgGreen).Printf("+ create %s\n", name)
+		case "upgrade":
+			color.New(color.FgYellow).Printf("~ upgrade %s (%s)\n", name, strings.Join(action.Changes, ", "))
+		case "delete":
+			color.New(color.FgRed).Printf("- delete %s\n", name)
+		default:
+			continue
+		}
+
+		hasChanges = true
+	}
+
+	return hasChanges
+}
+
+func pastTense(operation string) string {
+	switch operation {
+	case "create":
+		return "Created"
+	case "upgrade":
+		return "Upgraded"
+	case "delete":
+		return "Deleted"
+	}
+
+	return operation
+}
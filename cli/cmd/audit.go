@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditPage     int
+	auditPageSize int
+	auditUserID   uint
+	auditResource string
+	auditJSON     bool
+)
+
+// auditCmd represents the "porter audit" command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Lists the audit log of mutating API calls for the current project",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, listAuditEvents)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	auditCmd.PersistentFlags().StringVar(
+		&host,
+		"host",
+		getHost(),
+		"host url of Porter instance",
+	)
+
+	auditCmd.PersistentFlags().IntVar(
+		&auditPage,
+		"page",
+		1,
+		"page of events to list, starting from the newest",
+	)
+
+	auditCmd.PersistentFlags().IntVar(
+		&auditPageSize,
+		"page-size",
+		50,
+		"number of events per page",
+	)
+
+	auditCmd.PersistentFlags().UintVar(
+		&auditUserID,
+		"user-id",
+		0,
+		"only list events for the user with this id",
+	)
+
+	auditCmd.PersistentFlags().StringVar(
+		&auditResource,
+		"resource",
+		"",
+		"only list events for this kind of resource (for example, releases)",
+	)
+
+	auditCmd.PersistentFlags().BoolVar(
+		&auditJSON,
+		"json",
+		false,
+		"print the events as JSON, including the values that each event changed",
+	)
+}
+
+func listAuditEvents(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	resp, err := client.ListAuditEvents(context.Background(), getProjectID(), &api.ListAuditEventsRequest{
+		Page:     auditPage,
+		PageSize: auditPageSize,
+		UserID:   auditUserID,
+		Resource: auditResource,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if auditJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(resp)
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 3, 8, 0, '\t', tabwriter.AlignRight)
+
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", "ID", "TIME", "USER", "RESOURCE", "NAME", "ACTION", "STATUS")
+
+	for _, event := range resp.Events {
+		user := fmt.Sprintf("%d", event.UserID)
+
+		if event.APITokenID != 0 {
+			user = fmt.Sprintf("%d (token %d)", event.UserID, event.APITokenID)
+		}
+
+		line := fmt.Sprintf(
+			"%d\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			event.ID,
+			event.CreatedAt.Format(time.RFC3339),
+			user,
+			event.Resource,
+			event.ResourceName,
+			event.Action,
+			event.Status,
+		)
+
+		if event.Success {
+			fmt.Fprint(w, line)
+		} else {
+			color.New(color.FgRed).Fprint(w, line)
+		}
+	}
+
+	w.Flush()
+
+	fmt.Printf("Page %d of events, %d total\n", resp.Page, resp.Total)
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/fatih/color"
 
@@ -201,6 +202,29 @@ func loginManual() error {
 		return err
 	}
 
+	if _user.TwoFactorRequired {
+		code, err := utils.PromptPlaintext("Authentication code (or recovery code): ")
+
+		if err != nil {
+			return err
+		}
+
+		twoFactorReq := &api.LoginTwoFactorRequest{}
+
+		// recovery codes contain a dash, while authenticator codes are digits
+		if strings.Contains(code, "-") {
+			twoFactorReq.RecoveryCode = code
+		} else {
+			twoFactorReq.Code = code
+		}
+
+		_user, err = client.LoginTwoFactor(context.Background(), twoFactorReq)
+
+		if err != nil {
+			return err
+		}
+	}
+
 	// set the token to empty since this is manual (cookie-based) login
 	setToken("")
 
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/porter-dev/porter/cli/cmd/api"
@@ -60,6 +61,74 @@ var listProjectCmd = &cobra.Command{
 	},
 }
 
+var (
+	inviteRole      string
+	inviteClusterID uint
+	inviteNamespace string
+	inviteStatus    string
+)
+
+var inviteCmd = &cobra.Command{
+	Use:     "invite",
+	Aliases: []string{"invites"},
+	Short:   "Commands that manage invites to the current project",
+}
+
+var createInviteCmd = &cobra.Command{
+	Use:   "create [email...]",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Invites one or more emails to the current project",
+	Long: `Invites one or more emails to the current project. Every invite grants the same role,
+and can optionally be scoped to a cluster, and a namespace within that cluster:
+
+  porter project invite create alice@example.com bob@example.com --role deployer --cluster 4 --namespace staging`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, createInvites)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var listInviteCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the invites for the current project",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, listInvites)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var resendInviteCmd = &cobra.Command{
+	Use:   "resend [id]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Generates a new link for the invite with the given id and sends it again",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, resendInvite)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var deleteInviteCmd = &cobra.Command{
+	Use:   "delete [id]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Deletes the invite with the given id",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, deleteInvite)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(projectCmd)
 
@@ -75,6 +144,44 @@ func init() {
 	projectCmd.AddCommand(deleteProjectCmd)
 
 	projectCmd.AddCommand(listProjectCmd)
+
+	createInviteCmd.PersistentFlags().StringVar(
+		&inviteRole,
+		"role",
+		"developer",
+		"role to grant when the invite is accepted",
+	)
+
+	createInviteCmd.PersistentFlags().UintVar(
+		&inviteClusterID,
+		"cluster",
+		0,
+		"id of the cluster to scope the invite to",
+	)
+
+	createInviteCmd.PersistentFlags().StringVar(
+		&inviteNamespace,
+		"namespace",
+		"",
+		"namespace in the cluster to scope the invite to",
+	)
+
+	listInviteCmd.PersistentFlags().StringVar(
+		&inviteStatus,
+		"status",
+		"",
+		"only list invites that are pending, expired or accepted",
+	)
+
+	inviteCmd.AddCommand(createInviteCmd)
+
+	inviteCmd.AddCommand(listInviteCmd)
+
+	inviteCmd.AddCommand(resendInviteCmd)
+
+	inviteCmd.AddCommand(deleteInviteCmd)
+
+	projectCmd.AddCommand(inviteCmd)
 }
 
 func createProject(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
@@ -149,3 +256,93 @@ func deleteProject(_ *api.AuthCheckResponse, client *api.Client, args []string)
 
 	return nil
 }
+
+func createInvites(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	invites, err := client.CreateInvites(context.Background(), getProjectID(), &api.CreateInviteRequest{
+		Emails:    args,
+		Kind:      inviteRole,
+		ClusterID: inviteClusterID,
+		Namespace: inviteNamespace,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, invite := range invites {
+		color.New(color.FgGreen).Printf("Invited %s as %s (invite id %d)\n", invite.Email, invite.Kind, invite.ID)
+	}
+
+	return nil
+}
+
+func listInvites(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	invites, err := client.ListInvites(context.Background(), getProjectID(), inviteStatus)
+
+	if err != nil {
+		return err
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 3, 8, 0, '\t', tabwriter.AlignRight)
+
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", "ID", "EMAIL", "ROLE", "SCOPE", "STATUS", "EXPIRY")
+
+	for _, invite := range invites {
+		scope := "project"
+
+		if invite.ClusterID != 0 {
+			scope = fmt.Sprintf("cluster %d", invite.ClusterID)
+
+			if invite.Namespace != "" {
+				scope = fmt.Sprintf("%s/%s", scope, invite.Namespace)
+			}
+		}
+
+		expiry := ""
+
+		if invite.Expiry != nil {
+			expiry = invite.Expiry.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", invite.ID, invite.Email, invite.Kind, scope, invite.Status, expiry)
+	}
+
+	w.Flush()
+
+	return nil
+}
+
+func resendInvite(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	id, err := strconv.ParseUint(args[0], 10, 64)
+
+	if err != nil {
+		return err
+	}
+
+	invite, err := client.ResendInvite(context.Background(), getProjectID(), uint(id))
+
+	if err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf("Resent invite to %s, which expires at %s\n", invite.Email, invite.Expiry.Format(time.RFC3339))
+
+	return nil
+}
+
+func deleteInvite(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	id, err := strconv.ParseUint(args[0], 10, 64)
+
+	if err != nil {
+		return err
+	}
+
+	if err := client.DeleteInvite(context.Background(), getProjectID(), uint(id)); err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf("Deleted invite with id %d\n", id)
+
+	return nil
+}
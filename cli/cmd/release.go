@@ -0,0 +1,617 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/porter-dev/porter/cli/cmd/utils"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	releaseNamespace    string
+	releaseValuesPath   string
+	releaseChartVersion string
+	releaseRollbackTo   int
+	releaseJSON         bool
+
+	promoteToCluster   uint
+	promoteToNamespace string
+	promoteToName      string
+	promoteValues      []string
+	promoteDryRun      bool
+	promoteYes         bool
+	promoteOverride    string
+
+	upgradeChartVersion string
+	upgradeRepoURL      string
+	upgradeHelmRepoID   uint
+	upgradeDryRun       bool
+	upgradeYes          bool
+	upgradeOverride     string
+)
+
+// releaseCmd represents the "porter release" base command
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Commands that operate on releases in the current cluster",
+}
+
+var releaseDiffCmd = &cobra.Command{
+	Use:   "diff [name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Shows what an upgrade or rollback of a release would change, without applying it",
+	Long: `Shows what an upgrade or rollback of a release would change, without applying it.
+
+Pass --values to see the changes an upgrade with a new values.yaml would make, or
+--rollback to see the changes a rollback to an earlier revision would make. Both
+the values and the rendered manifests are compared to the deployed revision.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, diffRelease)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var releasePromoteCmd = &cobra.Command{
+	Use:   "promote [name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Promotes a release in the current cluster to another cluster or namespace",
+	Long: `Promotes a release in the current cluster to another cluster or namespace.
+
+The chart version and image tag of the release are applied to the release with
+the same name in the target cluster and namespace, along with the values at the
+paths passed with --value, such as "container.env.normal". Every other value of
+the target is kept. If the target release doesn't exist, it is created with
+every value of the source release.
+
+The changes to the target release are shown before they are applied.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, promoteRelease)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var releaseUpgradeCmd = &cobra.Command{
+	Use:   "upgrade [name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Upgrades a release to another version of its chart",
+	Long: `Upgrades a release to another version of its chart.
+
+The values of the release are migrated to the new chart version: values that
+were left at the default of the current version take the default of the new
+version, values that were changed are kept, and values that the new version
+moved to another key are moved with it. Values that the new version no longer
+has are listed and dropped.
+
+The migrated values, the form of the new version and the changes to the release
+are shown before the upgrade is applied.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, upgradeReleaseChart)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+
+	releaseCmd.PersistentFlags().StringVar(
+		&host,
+		"host",
+		getHost(),
+		"host url of Porter instance",
+	)
+
+	releaseCmd.PersistentFlags().StringVar(
+		&releaseNamespace,
+		"namespace",
+		"default",
+		"namespace of the release",
+	)
+
+	releaseCmd.AddCommand(releaseDiffCmd)
+
+	releaseDiffCmd.Flags().StringVar(
+		&releaseValuesPath,
+		"values",
+		"",
+		"path to the values.yaml to upgrade the release with",
+	)
+
+	releaseDiffCmd.Flags().StringVar(
+		&releaseChartVersion,
+		"version",
+		"",
+		"chart version to upgrade the release to",
+	)
+
+	releaseDiffCmd.Flags().IntVar(
+		&releaseRollbackTo,
+		"rollback",
+		0,
+		"revision to roll the release back to",
+	)
+
+	releaseDiffCmd.Flags().BoolVar(
+		&releaseJSON,
+		"json",
+		false,
+		"print the diff as JSON",
+	)
+
+	releaseCmd.AddCommand(releasePromoteCmd)
+
+	releasePromoteCmd.Flags().UintVar(
+		&promoteToCluster,
+		"to-cluster",
+		0,
+		"id of the cluster to promote the release to, which is the current cluster if unset",
+	)
+
+	releasePromoteCmd.Flags().StringVar(
+		&promoteToNamespace,
+		"to-namespace",
+		"",
+		"namespace to promote the release to, which is the namespace of the release if unset",
+	)
+
+	releasePromoteCmd.Flags().StringVar(
+		&promoteToName,
+		"to-name",
+		"",
+		"name of the target release, which is the name of the release if unset",
+	)
+
+	releasePromoteCmd.Flags().StringArrayVar(
+		&promoteValues,
+		"value",
+		[]string{},
+		"path of a value to copy to the target release, such as container.env.normal",
+	)
+
+	releasePromoteCmd.Flags().BoolVar(
+		&promoteDryRun,
+		"dry-run",
+		false,
+		"only show the changes, without promoting the release",
+	)
+
+	releasePromoteCmd.Flags().BoolVarP(
+		&promoteYes,
+		"yes",
+		"y",
+		false,
+		"promote the release without asking for confirmation",
+	)
+
+	releasePromoteCmd.Flags().StringVar(
+		&promoteOverride,
+		"override-reason",
+		"",
+		"reason for promoting while a deploy policy blocks deploys to the target",
+	)
+
+	releaseCmd.AddCommand(releaseUpgradeCmd)
+
+	releaseUpgradeCmd.Flags().StringVar(
+		&upgradeChartVersion,
+		"version",
+		"",
+		"chart version to upgrade the release to",
+	)
+
+	releaseUpgradeCmd.MarkFlagRequired("version")
+
+	releaseUpgradeCmd.Flags().StringVar(
+		&upgradeRepoURL,
+		"repo-url",
+		"",
+		"url of the repo to load the chart from, which is the default Porter repo if unset",
+	)
+
+	releaseUpgradeCmd.Flags().UintVar(
+		&upgradeHelmRepoID,
+		"helm-repo-id",
+		0,
+		"id of a project Helm repo to load the chart from",
+	)
+
+	releaseUpgradeCmd.Flags().BoolVar(
+		&upgradeDryRun,
+		"dry-run",
+		false,
+		"only show the migration and changes, without upgrading the release",
+	)
+
+	releaseUpgradeCmd.Flags().BoolVarP(
+		&upgradeYes,
+		"yes",
+		"y",
+		false,
+		"upgrade the release without asking for confirmation",
+	)
+
+	releaseUpgradeCmd.Flags().StringVar(
+		&upgradeOverride,
+		"override-reason",
+		"",
+		"reason for upgrading while a deploy policy blocks deploys",
+	)
+
+	releaseUpgradeCmd.Flags().BoolVar(
+		&releaseJSON,
+		"json",
+		false,
+		"print the migration and diff as JSON, without upgrading the release",
+	)
+}
+
+func diffRelease(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	name := args[0]
+
+	var diff *api.ReleaseDiffResponse
+	var err error
+
+	switch {
+	case releaseRollbackTo != 0 && releaseValuesPath != "":
+		return fmt.Errorf("only one of --values and --rollback can be set")
+	case releaseRollbackTo != 0:
+		diff, err = client.DiffRollbackRelease(
+			context.Background(),
+			getProjectID(),
+			getClusterID(),
+			releaseNamespace,
+			name,
+			&api.DiffRollbackReleaseRequest{
+				Revision: releaseRollbackTo,
+			},
+		)
+	case releaseValuesPath != "":
+		values, readErr := ioutil.ReadFile(releaseValuesPath)
+
+		if readErr != nil {
+			return readErr
+		}
+
+		diff, err = client.DiffUpgradeRelease(
+			context.Background(),
+			getProjectID(),
+			getClusterID(),
+			releaseNamespace,
+			name,
+			&api.DiffUpgradeReleaseRequest{
+				Values:       string(values),
+				ChartVersion: releaseChartVersion,
+			},
+		)
+	default:
+		return fmt.Errorf("one of --values or --rollback must be set")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if releaseJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(diff)
+	}
+
+	printReleaseDiff(diff)
+
+	return nil
+}
+
+func promoteRelease(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	name := args[0]
+
+	req := &api.PromoteReleaseRequest{
+		TargetClusterID: promoteToCluster,
+		TargetNamespace: promoteToNamespace,
+		TargetName:      promoteToName,
+		Values:          promoteValues,
+	}
+
+	if req.TargetClusterID == 0 {
+		req.TargetClusterID = getClusterID()
+	}
+
+	if req.TargetNamespace == "" {
+		req.TargetNamespace = releaseNamespace
+	}
+
+	plan, err := client.DiffPromoteRelease(
+		context.Background(),
+		getProjectID(),
+		getClusterID(),
+		releaseNamespace,
+		name,
+		req,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if plan.Created {
+		fmt.Printf(
+			"Creating %s in namespace %s of cluster %d\n\n",
+			plan.TargetName,
+			plan.TargetNamespace,
+			plan.TargetClusterID,
+		)
+	} else {
+		fmt.Printf(
+			"Promoting %s to %s in namespace %s of cluster %d, at revision %d\n\n",
+			name,
+			plan.TargetName,
+			plan.TargetNamespace,
+			plan.TargetClusterID,
+			plan.Diff.CurrentRevision,
+		)
+	}
+
+	printReleaseDiffChanges(plan.Diff)
+
+	if promoteDryRun || (!plan.Created && plan.Diff.ValuesDiff == "" && len(plan.Diff.Resources) == 0) {
+		return nil
+	}
+
+	if !promoteYes {
+		userResp, err := utils.PromptPlaintext(
+			fmt.Sprintf("\nPromote %s? %s ", name, color.New(color.FgCyan).Sprintf("[y/n]")),
+		)
+
+		if err != nil {
+			return err
+		}
+
+		if userResp := strings.ToLower(userResp); userResp != "y" && userResp != "yes" {
+			return nil
+		}
+	}
+
+	result, err := client.PromoteRelease(
+		context.Background(),
+		getProjectID(),
+		getClusterID(),
+		releaseNamespace,
+		name,
+		promoteOverride,
+		req,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf(
+		"Promoted %s to %s/%s, revision %d\n",
+		name,
+		result.TargetNamespace,
+		result.TargetName,
+		result.Revision,
+	)
+
+	return nil
+}
+
+func upgradeReleaseChart(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	name := args[0]
+
+	if upgradeRepoURL != "" && upgradeHelmRepoID != 0 {
+		return fmt.Errorf("only one of --repo-url and --helm-repo-id can be set")
+	}
+
+	req := &api.UpgradeReleaseChartRequest{
+		Version:    upgradeChartVersion,
+		RepoURL:    upgradeRepoURL,
+		HelmRepoID: upgradeHelmRepoID,
+		DryRun:     true,
+	}
+
+	plan, err := client.UpgradeReleaseChart(
+		context.Background(),
+		getProjectID(),
+		getClusterID(),
+		releaseNamespace,
+		name,
+		"",
+		req,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if releaseJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(plan)
+	}
+
+	fmt.Printf(
+		"Upgrading %s from %s %s to %s %s\n\n",
+		name,
+		plan.Chart,
+		plan.CurrentVersion,
+		plan.Chart,
+		plan.TargetVersion,
+	)
+
+	printValuesMigration(plan.Migration)
+	printReleaseForm(plan.Form)
+	printReleaseDiffChanges(plan.Diff)
+
+	if upgradeDryRun {
+		return nil
+	}
+
+	if !upgradeYes {
+		userResp, err := utils.PromptPlaintext(
+			fmt.Sprintf("\nUpgrade %s to %s? %s ", name, plan.TargetVersion, color.New(color.FgCyan).Sprintf("[y/n]")),
+		)
+
+		if err != nil {
+			return err
+		}
+
+		if userResp := strings.ToLower(userResp); userResp != "y" && userResp != "yes" {
+			return nil
+		}
+	}
+
+	req.DryRun = false
+
+	result, err := client.UpgradeReleaseChart(
+		context.Background(),
+		getProjectID(),
+		getClusterID(),
+		releaseNamespace,
+		name,
+		upgradeOverride,
+		req,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf(
+		"Upgraded %s to %s %s, revision %d\n",
+		name,
+		result.Chart,
+		result.TargetVersion,
+		result.Revision,
+	)
+
+	return nil
+}
+
+func printValuesMigration(migration *api.ValuesMigrationResponse) {
+	if migration == nil {
+		return
+	}
+
+	if len(migration.Renamed) > 0 {
+		color.New(color.Bold).Println("Moved values:")
+
+		for _, renamed := range migration.Renamed {
+			color.New(color.FgYellow).Printf("  %s -> %s\n", renamed.From, renamed.To)
+		}
+
+		fmt.Println()
+	}
+
+	if len(migration.Removed) > 0 {
+		color.New(color.Bold).Println("Removed values:")
+
+		for _, path := range migration.Removed {
+			color.New(color.FgRed).Printf("  %s\n", path)
+		}
+
+		fmt.Println()
+	}
+
+	if len(migration.UpdatedDefaults) > 0 {
+		color.New(color.Bold).Println("Updated defaults:")
+
+		for _, path := range migration.UpdatedDefaults {
+			fmt.Printf("  %s\n", path)
+		}
+
+		fmt.Println()
+	}
+}
+
+func printReleaseForm(form *models.FormYAML) {
+	if form == nil || len(form.Tabs) == 0 {
+		return
+	}
+
+	color.New(color.Bold).Println("Form:")
+
+	for _, tab := range form.Tabs {
+		fmt.Printf("  %s\n", tab.Label)
+
+		for _, section := range tab.Sections {
+			for _, content := range section.Contents {
+				if content.Variable == "" {
+					continue
+				}
+
+				if content.Value != nil {
+					fmt.Printf("    %s (%s): %v\n", content.Label, content.Variable, content.Value)
+				} else {
+					fmt.Printf("    %s (%s)\n", content.Label, content.Variable)
+				}
+			}
+		}
+	}
+
+	fmt.Println()
+}
+
+func printReleaseDiff(diff *api.ReleaseDiffResponse) {
+	if diff.TargetRevision != 0 {
+		fmt.Printf("Rolling back %s from revision %d to revision %d\n\n", diff.Name, diff.CurrentRevision, diff.TargetRevision)
+	} else {
+		fmt.Printf("Upgrading %s from revision %d\n\n", diff.Name, diff.CurrentRevision)
+	}
+
+	printReleaseDiffChanges(diff)
+}
+
+func printReleaseDiffChanges(diff *api.ReleaseDiffResponse) {
+	if diff.ValuesDiff == "" && len(diff.Resources) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+
+	if diff.ValuesDiff != "" {
+		color.New(color.Bold).Println("Values:")
+		printUnifiedDiff(diff.ValuesDiff)
+		fmt.Println()
+	}
+
+	for _, res := range diff.Resources {
+		color.New(color.Bold).Printf("%s %s (%s):\n", res.Kind, res.Name, res.Change)
+		printUnifiedDiff(res.Diff)
+		fmt.Println()
+	}
+}
+
+func printUnifiedDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			color.New(color.Bold).Println(line)
+		case strings.HasPrefix(line, "+"):
+			color.New(color.FgGreen).Println(line)
+		case strings.HasPrefix(line, "-"):
+			color.New(color.FgRed).Println(line)
+		case strings.HasPrefix(line, "@@"):
+			color.New(color.FgCyan).Println(line)
+		default:
+			fmt.Println(line)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/porter-dev/porter/cli/cmd/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	adoptAll           bool
+	adoptAllNamespaces bool
+	adoptYes           bool
+	adoptGitAction     = &api.AdoptReleaseGitAction{}
+)
+
+var releaseAdoptCmd = &cobra.Command{
+	Use:   "adopt [name...]",
+	Short: "Brings Helm releases that were not deployed through Porter under Porter's management",
+	Long: `Brings Helm releases that were not deployed through Porter under Porter's management.
+
+Without arguments, the Helm releases in the namespace that Porter doesn't manage
+are listed. Pass the names of releases to adopt them, or --all to adopt every
+release that is listed. Adopted releases are given a deploy webhook, and a
+single release can be linked to the GitHub repository that it is built from
+with the --git-* flags.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, adoptReleases)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	releaseCmd.AddCommand(releaseAdoptCmd)
+
+	releaseAdoptCmd.Flags().BoolVar(
+		&adoptAll,
+		"all",
+		false,
+		"adopt every release that Porter doesn't manage",
+	)
+
+	releaseAdoptCmd.Flags().BoolVarP(
+		&adoptAllNamespaces,
+		"all-namespaces",
+		"A",
+		false,
+		"list and adopt releases in every namespace",
+	)
+
+	releaseAdoptCmd.Flags().BoolVarP(
+		&adoptYes,
+		"yes",
+		"y",
+		false,
+		"adopt every release without asking for confirmation",
+	)
+
+	releaseAdoptCmd.Flags().StringVar(
+		&adoptGitAction.GitRepo,
+		"git-repo",
+		"",
+		"GitHub repository that the release is built from, such as porter-dev/porter",
+	)
+
+	releaseAdoptCmd.Flags().UintVar(
+		&adoptGitAction.GitRepoID,
+		"git-repo-id",
+		0,
+		"id of the GitHub integration that can access the repository",
+	)
+
+	releaseAdoptCmd.Flags().StringVar(
+		&adoptGitAction.GitBranch,
+		"git-branch",
+		"",
+		"branch that the release is deployed from",
+	)
+
+	releaseAdoptCmd.Flags().StringVar(
+		&adoptGitAction.ImageRepoURI,
+		"image-repo-uri",
+		"",
+		"image repository that the release is built to",
+	)
+
+	releaseAdoptCmd.Flags().StringVar(
+		&adoptGitAction.DockerfilePath,
+		"dockerfile",
+		"",
+		"path to the Dockerfile in the repository",
+	)
+
+	releaseAdoptCmd.Flags().StringVar(
+		&adoptGitAction.FolderPath,
+		"folder",
+		"",
+		"folder in the repository that the release is built from",
+	)
+
+	releaseAdoptCmd.Flags().UintVar(
+		&adoptGitAction.RegistryID,
+		"registry-id",
+		0,
+		"id of the registry that the image is pushed to",
+	)
+}
+
+func adoptReleases(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	namespace := releaseNamespace
+
+	if adoptAllNamespaces {
+		namespace = ""
+	}
+
+	linkGitAction := adoptGitAction.GitRepo != ""
+
+	if linkGitAction && len(args) != 1 {
+		return fmt.Errorf("a github action can only be linked when a single release is adopted")
+	}
+
+	req := &api.AdoptReleasesRequest{}
+
+	switch {
+	case adoptAll && len(args) > 0:
+		return fmt.Errorf("release names cannot be passed with --all")
+	case len(args) > 0:
+		for _, name := range args {
+			relReq := &api.AdoptReleaseRequest{
+				Name:      name,
+				Namespace: releaseNamespace,
+			}
+
+			if linkGitAction {
+				relReq.GitAction = adoptGitAction
+			}
+
+			req.Releases = append(req.Releases, relReq)
+		}
+	default:
+		releases, err := client.ListAdoptableReleases(
+			context.Background(),
+			getProjectID(),
+			getClusterID(),
+			namespace,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		if len(releases) == 0 {
+			fmt.Println("No releases to adopt, Porter manages every release")
+			return nil
+		}
+
+		printAdoptableReleases(releases)
+
+		if !adoptAll {
+			fmt.Println("\nPass the names of releases or --all to adopt them")
+			return nil
+		}
+
+		if !adoptYes {
+			userResp, err := utils.PromptPlaintext(
+				fmt.Sprintf("\nAdopt %d releases? %s ", len(releases), color.New(color.FgCyan).Sprintf("[y/n]")),
+			)
+
+			if err != nil {
+				return err
+			}
+
+			if userResp := strings.ToLower(userResp); userResp != "y" && userResp != "yes" {
+				return nil
+			}
+		}
+
+		req.All = true
+	}
+
+	results, err := client.AdoptReleases(
+		context.Background(),
+		getProjectID(),
+		getClusterID(),
+		namespace,
+		req,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+
+	failed := 0
+
+	for _, result := range results {
+		switch {
+		case result.Error == "":
+			color.New(color.FgGreen).Printf("Adopted %s/%s\n", result.Namespace, result.Name)
+		case result.Release != nil:
+			color.New(color.FgYellow).Printf("Adopted %s/%s: %s\n", result.Namespace, result.Name, result.Error)
+			failed++
+		default:
+			color.New(color.FgRed).Printf("Could not adopt %s/%s: %s\n", result.Namespace, result.Name, result.Error)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d releases could not be adopted", failed)
+	}
+
+	return nil
+}
+
+func printAdoptableReleases(releases []*api.AdoptableRelease) {
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 3, 8, 0, '\t', tabwriter.AlignRight)
+
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", "NAMESPACE", "NAME", "CHART", "REVISION", "STATUS")
+
+	for _, rel := range releases {
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s-%s\t%d\t%s\n",
+			rel.Namespace,
+			rel.Name,
+			rel.Chart,
+			rel.ChartVersion,
+			rel.Revision,
+			rel.Status,
+		)
+	}
+
+	w.Flush()
+}
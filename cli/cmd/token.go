@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tokenScopes    []string
+	tokenExpiresIn time.Duration
+)
+
+// tokenCmd represents the "porter token" base command when called
+// without any subcommands
+var tokenCmd = &cobra.Command{
+	Use:     "token",
+	Aliases: []string{"tokens"},
+	Short:   "Commands that manage API tokens for a project",
+}
+
+var createTokenCmd = &cobra.Command{
+	Use:   "create [name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Creates an API token for the current project",
+	Long: `Creates an API token for the current project. The token is only printed once.
+
+Tokens can be limited to resource:verb scopes, for example:
+
+  porter token create ci --scope release:read --scope release:write --scope cluster:read --expires-in 720h`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, createToken)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var listTokenCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the API tokens for the current project",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, listTokens)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var revokeTokenCmd = &cobra.Command{
+	Use:   "revoke [id]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Revokes the API token with the given id",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, revokeToken)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+
+	tokenCmd.PersistentFlags().StringVar(
+		&host,
+		"host",
+		getHost(),
+		"host url of Porter instance",
+	)
+
+	createTokenCmd.PersistentFlags().StringArrayVar(
+		&tokenScopes,
+		"scope",
+		[]string{},
+		"resource:verb scope to limit the token to (can be repeated)",
+	)
+
+	createTokenCmd.PersistentFlags().DurationVar(
+		&tokenExpiresIn,
+		"expires-in",
+		0,
+		"duration after which the token expires (never expires if not set)",
+	)
+
+	tokenCmd.AddCommand(createTokenCmd)
+
+	tokenCmd.AddCommand(listTokenCmd)
+
+	tokenCmd.AddCommand(revokeTokenCmd)
+}
+
+func createToken(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	req := &api.CreateAPITokenRequest{
+		Name:   args[0],
+		Scopes: tokenScopes,
+	}
+
+	if tokenExpiresIn != 0 {
+		expiry := time.Now().Add(tokenExpiresIn)
+		req.Expiry = &expiry
+	}
+
+	resp, err := client.CreateAPIToken(context.Background(), getProjectID(), req)
+
+	if err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf("Created token with name %s and id %d\n", resp.Name, resp.ID)
+	fmt.Println("Copy the token now, it will not be shown again:")
+	fmt.Println(resp.Token)
+
+	return nil
+}
+
+func listTokens(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	tokens, err := client.ListAPITokens(context.Background(), getProjectID())
+
+	if err != nil {
+		return err
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 3, 8, 0, '\t', tabwriter.AlignRight)
+
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", "ID", "NAME", "SCOPES", "EXPIRY", "LAST USED", "STATUS")
+
+	for _, tok := range tokens {
+		scopes := strings.Join(tok.Scopes, ",")
+
+		if scopes == "" {
+			scopes = "all"
+		}
+
+		expiry := "never"
+
+		if tok.Expiry != nil {
+			expiry = tok.Expiry.Format(time.RFC3339)
+		}
+
+		lastUsed := "never"
+
+		if tok.LastUsedAt != nil {
+			lastUsed = tok.LastUsedAt.Format(time.RFC3339)
+		}
+
+		status := "active"
+
+		if tok.Revoked {
+			status = "revoked"
+		} else if tok.Expired {
+			status = "expired"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", tok.ID, tok.Name, scopes, expiry, lastUsed, status)
+	}
+
+	w.Flush()
+
+	return nil
+}
+
+func revokeToken(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	id, err := strconv.ParseUint(args[0], 10, 64)
+
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.RevokeAPIToken(context.Background(), getProjectID(), uint(id))
+
+	if err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf("Revoked token with name %s and id %d\n", resp.Name, resp.ID)
+
+	return nil
+}
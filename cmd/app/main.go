@@ -48,6 +48,22 @@ func main() {
 	err = db.AutoMigrate(
 		&models.Project{},
 		&models.Role{},
+		&models.RoleBinding{},
+		&models.APIToken{},
+		&models.AuditEvent{},
+		&models.SSOGroupMapping{},
+		&models.TwoFactor{},
+		&models.PreviewEnvironment{},
+		&models.Deployment{},
+		&models.NotificationChannel{},
+		&models.CanaryRollout{},
+		&models.EnvGroup{},
+		&models.EnvGroupVersion{},
+		&models.EnvGroupAttachment{},
+		&models.ProjectSecret{},
+		&models.DeployPolicy{},
+		&models.ReleaseSet{},
+		&models.ReleaseSetTarget{},
 		&models.User{},
 		&models.Session{},
 		&models.GitRepo{},
@@ -87,6 +103,20 @@ func main() {
 
 	repo := gorm.NewRepository(db, &key)
 
+	a, _ := api.New(&api.AppConfig{
+		Logger:     logger,
+		Repository: repo,
+		ServerConf: appConf.Server,
+		RedisConf:  &appConf.Redis,
+		CapConf: 	appConf.Capabilities,
+		DBConf:     appConf.Db,
+	})
+
+	// rollouts that were in progress when the server last stopped are no
+	// longer watched
+	go a.RecoverCanaryRollouts()
+	go a.RecoverRollouts()
+
 	if appConf.Redis.Enabled {
 		redis, err := adapter.NewRedisClient(&appConf.Redis)
 
@@ -99,18 +129,9 @@ func main() {
 
 		errorChan := make(chan error)
 
-		go prov.GlobalStreamListener(redis, *repo, errorChan)
+		go prov.GlobalStreamListener(redis, *repo, a.Notifier, logger, errorChan)
 	}
 
-	a, _ := api.New(&api.AppConfig{
-		Logger:     logger,
-		Repository: repo,
-		ServerConf: appConf.Server,
-		RedisConf:  &appConf.Redis,
-		CapConf: 	appConf.Capabilities,
-		DBConf:     appConf.Db,
-	})
-
 	appRouter := router.New(a)
 
 	address := fmt.Sprintf(":%d", appConf.Server.Port)
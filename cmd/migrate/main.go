@@ -32,6 +32,22 @@ func main() {
 	err = db.AutoMigrate(
 		&models.Project{},
 		&models.Role{},
+		&models.RoleBinding{},
+		&models.APIToken{},
+		&models.AuditEvent{},
+		&models.SSOGroupMapping{},
+		&models.TwoFactor{},
+		&models.PreviewEnvironment{},
+		&models.Deployment{},
+		&models.NotificationChannel{},
+		&models.CanaryRollout{},
+		&models.EnvGroup{},
+		&models.EnvGroupVersion{},
+		&models.EnvGroupAttachment{},
+		&models.ProjectSecret{},
+		&models.DeployPolicy{},
+		&models.ReleaseSet{},
+		&models.ReleaseSetTarget{},
 		&models.User{},
 		&models.Release{},
 		&models.Session{},
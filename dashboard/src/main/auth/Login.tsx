@@ -17,6 +17,8 @@ type StateType = {
   emailError: boolean;
   credentialError: boolean;
   hasGithub: boolean;
+  twoFactorRequired: boolean;
+  twoFactorCode: string;
 };
 
 export default class Login extends Component<PropsType, StateType> {
@@ -26,15 +28,29 @@ export default class Login extends Component<PropsType, StateType> {
     emailError: false,
     credentialError: false,
     hasGithub: true,
+    twoFactorRequired: false,
+    twoFactorCode: "",
   };
 
   handleKeyDown = (e: any) => {
-    e.key === "Enter" ? this.handleLogin() : null;
+    if (e.key !== "Enter") {
+      return;
+    }
+
+    this.state.twoFactorRequired
+      ? this.handleLoginTwoFactor()
+      : this.handleLogin();
   };
 
   componentDidMount() {
     let urlParams = new URLSearchParams(window.location.search);
     let emailFromCLI = urlParams.get("email");
+
+    // OAuth logins redirect here when the second step is required
+    if (urlParams.get("two_factor") === "true") {
+      this.setState({ twoFactorRequired: true });
+    }
+
     emailFromCLI
       ? this.setState({ email: emailFromCLI })
       : document.addEventListener("keydown", this.handleKeyDown);
@@ -73,7 +89,9 @@ export default class Login extends Component<PropsType, StateType> {
         )
         .then((res) => {
           // TODO: case and set credential error
-          if (res?.data?.redirect) {
+          if (res?.data?.two_factor_required) {
+            this.setState({ twoFactorRequired: true });
+          } else if (res?.data?.redirect) {
             window.location.href = res.data.redirect;
           } else {
             setUser(res?.data?.id, res?.data?.email);
@@ -86,6 +104,50 @@ export default class Login extends Component<PropsType, StateType> {
     }
   };
 
+  handleLoginTwoFactor = (): void => {
+    let { twoFactorCode } = this.state;
+    let { authenticate } = this.props;
+    let { setUser } = this.context;
+
+    // recovery codes contain a dash, while authenticator codes are digits
+    let body = twoFactorCode.includes("-")
+      ? { recovery_code: twoFactorCode }
+      : { code: twoFactorCode };
+
+    api
+      .logInTwoFactor("", body, {})
+      .then((res) => {
+        if (res?.data?.redirect) {
+          window.location.href = res.data.redirect;
+        } else {
+          setUser(res?.data?.id, res?.data?.email);
+          authenticate();
+        }
+      })
+      .catch((err) =>
+        this.context.setCurrentError(err.response.data.errors[0])
+      );
+  };
+
+  renderTwoFactor = () => {
+    return (
+      <>
+        <InputWrapper>
+          <Input
+            type="text"
+            placeholder="Authentication code or recovery code"
+            value={this.state.twoFactorCode}
+            onChange={(e: ChangeEvent<HTMLInputElement>) =>
+              this.setState({ twoFactorCode: e.target.value })
+            }
+            valid={true}
+          />
+        </InputWrapper>
+        <Button onClick={this.handleLoginTwoFactor}>Verify</Button>
+      </>
+    );
+  };
+
   renderEmailError = () => {
     let { emailError } = this.state;
     if (emailError) {
@@ -146,40 +208,46 @@ export default class Login extends Component<PropsType, StateType> {
           <FormWrapper>
             <Logo src={logo} />
             <Prompt>Log in to Porter</Prompt>
-            {this.renderGithubSection()}
-            <DarkMatter />
-            <InputWrapper>
-              <Input
-                type="email"
-                placeholder="Email"
-                value={email}
-                onChange={(e: ChangeEvent<HTMLInputElement>) =>
-                  this.setState({
-                    email: e.target.value,
-                    emailError: false,
-                    credentialError: false,
-                  })
-                }
-                valid={!credentialError && !emailError}
-              />
-              {this.renderEmailError()}
-            </InputWrapper>
-            <InputWrapper>
-              <Input
-                type="password"
-                placeholder="Password"
-                value={password}
-                onChange={(e: ChangeEvent<HTMLInputElement>) =>
-                  this.setState({
-                    password: e.target.value,
-                    credentialError: false,
-                  })
-                }
-                valid={!credentialError}
-              />
-              {this.renderCredentialError()}
-            </InputWrapper>
-            <Button onClick={this.handleLogin}>Continue</Button>
+            {this.state.twoFactorRequired ? (
+              this.renderTwoFactor()
+            ) : (
+              <>
+                {this.renderGithubSection()}
+                <DarkMatter />
+                <InputWrapper>
+                  <Input
+                    type="email"
+                    placeholder="Email"
+                    value={email}
+                    onChange={(e: ChangeEvent<HTMLInputElement>) =>
+                      this.setState({
+                        email: e.target.value,
+                        emailError: false,
+                        credentialError: false,
+                      })
+                    }
+                    valid={!credentialError && !emailError}
+                  />
+                  {this.renderEmailError()}
+                </InputWrapper>
+                <InputWrapper>
+                  <Input
+                    type="password"
+                    placeholder="Password"
+                    value={password}
+                    onChange={(e: ChangeEvent<HTMLInputElement>) =>
+                      this.setState({
+                        password: e.target.value,
+                        credentialError: false,
+                      })
+                    }
+                    valid={!credentialError}
+                  />
+                  {this.renderCredentialError()}
+                </InputWrapper>
+                <Button onClick={this.handleLogin}>Continue</Button>
+              </>
+            )}
 
             <Helper>
               <Link href="/register">Sign up</Link> |
@@ -17,6 +17,7 @@ import Selector from "components/Selector";
 import ImageSelector from "components/image-selector/ImageSelector";
 import TabRegion from "components/TabRegion";
 import InputRow from "components/values-form/InputRow";
+import CheckboxRow from "components/values-form/CheckboxRow";
 import SaveButton from "components/SaveButton";
 import ActionConfEditor from "components/repo-selector/ActionConfEditor";
 import FormWrapper from "components/values-form/FormWrapper";
@@ -57,6 +58,7 @@ type StateType = {
   folderPath: string | null;
   selectedRegistry: any | null;
   env: any;
+  previewsEnabled: boolean;
   valuesToOverride: any | null;
 };
 
@@ -93,6 +95,7 @@ class LaunchTemplate extends Component<PropsType, StateType> {
     folderPath: null as string | null,
     selectedRegistry: null as any | null,
     env: {},
+    previewsEnabled: false,
     valuesToOverride: null as any | null,
   };
 
@@ -118,6 +121,7 @@ class LaunchTemplate extends Component<PropsType, StateType> {
           image_repo_uri: imageRepoUri,
           git_repo_id: actionConfig.git_repo_id,
           env: this.state.env,
+          previews_enabled: this.state.previewsEnabled,
         },
         {
           project_id: currentProject.id,
@@ -666,6 +670,13 @@ class LaunchTemplate extends Component<PropsType, StateType> {
             }}
             selectedRegistry={this.state.selectedRegistry}
           />
+          <CheckboxRow
+            label="Deploy pull requests as preview environments"
+            checked={this.state.previewsEnabled}
+            toggle={() =>
+              this.setState({ previewsEnabled: !this.state.previewsEnabled })
+            }
+          />
           <br />
         </StyledSourceBox>
       );
@@ -94,20 +94,13 @@ export default class InviteList extends Component<PropsType, StateType> {
   replaceInvite = (index: number) => {
     let { currentProject } = this.context;
     api
-      .createInvite(
+      .resendInvite(
         "<token>",
-        { email: this.state.invites[index].email },
-        { id: currentProject.id }
-      )
-      .then((_) =>
-        api.deleteInvite(
-          "<token>",
-          {},
-          {
-            id: currentProject.id,
-            invId: this.state.invites[index].id,
-          }
-        )
+        {},
+        {
+          id: currentProject.id,
+          invId: this.state.invites[index].id,
+        }
       )
       .then(this.getInviteData)
       .catch((err) => console.log(err));
@@ -109,6 +109,7 @@ const createGHAction = baseApi<
     folder_path: string;
     git_repo_id: number;
     env: any;
+    previews_enabled?: boolean;
   },
   {
     project_id: number;
@@ -136,6 +137,9 @@ const createGKE = baseApi<
 const createInvite = baseApi<
   {
     email: string;
+    kind?: string;
+    cluster_id?: number;
+    namespace?: string;
   },
   {
     id: number;
@@ -221,6 +225,13 @@ const deleteInvite = baseApi<{}, { id: number; invId: number }>(
   }
 );
 
+const resendInvite = baseApi<{}, { id: number; invId: number }>(
+  "POST",
+  (pathParams) => {
+    return `/api/projects/${pathParams.id}/invites/${pathParams.invId}/resend`;
+  }
+);
+
 const deletePod = baseApi<
   {
     cluster_id: number;
@@ -645,6 +656,11 @@ const logInUser = baseApi<{
   password: string;
 }>("POST", "/api/login");
 
+const logInTwoFactor = baseApi<{
+  code?: string;
+  recovery_code?: string;
+}>("POST", "/api/login/2fa");
+
 const logOutUser = baseApi("POST", "/api/logout");
 
 const provisionECR = baseApi<
@@ -868,10 +884,12 @@ export default {
   linkGithubProject,
   listConfigMaps,
   logInUser,
+  logInTwoFactor,
   logOutUser,
   provisionECR,
   provisionEKS,
   registerUser,
+  resendInvite,
   rollbackChart,
   uninstallTemplate,
   updateUser,
@@ -161,8 +161,14 @@ export interface InfraType {
 export interface InviteType {
   token: string;
   expired: boolean;
+  expiry: string;
   email: string;
   accepted: boolean;
+  status: string;
+  kind: string;
+  cluster_id?: number;
+  namespace?: string;
+  inviter_id?: number;
   id: number;
 }
 
@@ -0,0 +1,359 @@
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// The kinds of resources that a spec manages
+const (
+	KindEnvGroup string = "env_group"
+	KindRelease  string = "release"
+)
+
+// The operations that a plan performs on a resource
+const (
+	OperationCreate  string = "create"
+	OperationUpgrade string = "upgrade"
+	OperationDelete  string = "delete"
+	OperationNone    string = "none"
+)
+
+// The statuses of an action once the plan has been executed
+const (
+	ActionStatusApplied string = "applied"
+	ActionStatusFailed  string = "failed"
+)
+
+// Action is the operation that a plan performs on a single resource
+type Action struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Operation string `json:"operation"`
+
+	// Changes are the parts of an existing resource that are upgraded
+	Changes []string `json:"changes,omitempty"`
+
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Plan is the ordered set of actions that bring a cluster in line with a
+// spec. Env groups are written before the releases that read them, and
+// resources are deleted last.
+type Plan struct {
+	Actions []*Action `json:"actions"`
+}
+
+// ReleaseState is a release that is currently deployed
+type ReleaseState struct {
+	Name      string
+	Namespace string
+	Chart     string
+	Version   string
+	Values    map[string]interface{}
+
+	// HasBuild is set if the release is built by a github action
+	HasBuild bool
+}
+
+// EnvGroupState is an env group that currently exists
+type EnvGroupState struct {
+	Name      string
+	Namespace string
+	Variables map[string]string
+	Secrets   map[string]string
+
+	// Prunable is set if the env group is a Porter env group that can be
+	// deleted when pruning. Configmaps of charts and of shared env groups are
+	// never pruned.
+	Prunable bool
+}
+
+// State is the current state of the resources that a spec can manage. When
+// pruning, every resource in the state that the spec does not define is
+// deleted.
+type State struct {
+	Releases  []*ReleaseState
+	EnvGroups []*EnvGroupState
+}
+
+// ComputePlan compares a spec to the current state of the cluster. Resources
+// that already match the spec are included with OperationNone, so that
+// applying the same spec twice does nothing.
+func ComputePlan(spec *Spec, state *State, prune bool) (*Plan, error) {
+	plan := &Plan{
+		Actions: make([]*Action, 0),
+	}
+
+	for _, group := range spec.EnvGroups {
+		action := &Action{
+			Kind:      KindEnvGroup,
+			Name:      group.Name,
+			Namespace: group.Namespace,
+			Operation: OperationCreate,
+		}
+
+		if curr := state.envGroup(group.Name, group.Namespace); curr != nil {
+			action.Changes = diffEnvGroup(group, curr)
+			action.Operation = operationFromChanges(action.Changes)
+		}
+
+		plan.Actions = append(plan.Actions, action)
+	}
+
+	for _, rel := range spec.Releases {
+		action := &Action{
+			Kind:      KindRelease,
+			Name:      rel.Name,
+			Namespace: rel.Namespace,
+			Operation: OperationCreate,
+		}
+
+		if curr := state.release(rel.Name, rel.Namespace); curr != nil {
+			values, err := spec.ReleaseValues(rel, curr)
+
+			if err != nil {
+				return nil, err
+			}
+
+			action.Changes, err = diffRelease(rel, values, curr)
+
+			if err != nil {
+				return nil, err
+			}
+
+			action.Operation = operationFromChanges(action.Changes)
+		}
+
+		plan.Actions = append(plan.Actions, action)
+	}
+
+	if !prune {
+		return plan, nil
+	}
+
+	for _, rel := range state.Releases {
+		if spec.Release(rel.Name, rel.Namespace) == nil {
+			plan.Actions = append(plan.Actions, &Action{
+				Kind:      KindRelease,
+				Name:      rel.Name,
+				Namespace: rel.Namespace,
+				Operation: OperationDelete,
+			})
+		}
+	}
+
+	for _, group := range state.EnvGroups {
+		if group.Prunable && spec.EnvGroup(group.Name, group.Namespace) == nil {
+			plan.Actions = append(plan.Actions, &Action{
+				Kind:      KindEnvGroup,
+				Name:      group.Name,
+				Namespace: group.Namespace,
+				Operation: OperationDelete,
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+// HasChanges returns true if executing the plan would change anything
+func (p *Plan) HasChanges() bool {
+	for _, action := range p.Actions {
+		if action.Operation != OperationNone {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReleaseValues returns the values that a release is deployed with: the values
+// in the spec, with the variables of the release's env groups added to the
+// container env and the domains added to the ingress. Env vars that are set in
+// the values take precedence over the env groups, and secret variables are
+// referenced from the env group's secret instead of being copied.
+//
+// The image of a release that is built from source is set by its github
+// action, so the image of the current release is kept unless the spec sets
+// one. The current release is nil if the release does not exist yet.
+func (s *Spec) ReleaseValues(rel *ReleaseSpec, curr *ReleaseState) (map[string]interface{}, error) {
+	// copy the values through json, so that they can be modified and compared
+	// to the values of deployed releases
+	values, err := normalizeValues(rel.Values)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid values for release %s: %v", rel.Name, err)
+	}
+
+	if len(rel.EnvGroups) > 0 {
+		env := make(map[string]interface{})
+
+		for _, name := range rel.EnvGroups {
+			group := s.EnvGroup(name, rel.Namespace)
+
+			for key, val := range group.Variables {
+				env[key] = val
+			}
+
+			for key := range group.Secrets {
+				env[key] = fmt.Sprintf("PORTERSECRET_%s", group.Name)
+			}
+		}
+
+		container := childMap(values, "container")
+		containerEnv := childMap(container, "env")
+		normal := childMap(containerEnv, "normal")
+
+		for key, val := range normal {
+			env[key] = val
+		}
+
+		containerEnv["normal"] = env
+	}
+
+	if len(rel.Domains) > 0 {
+		ingress := childMap(values, "ingress")
+
+		hosts := make([]interface{}, 0)
+
+		for _, domain := range rel.Domains {
+			hosts = append(hosts, domain)
+		}
+
+		ingress["enabled"] = true
+		ingress["custom_domain"] = true
+		ingress["hosts"] = hosts
+	}
+
+	if _, ok := values["image"]; !ok && rel.Build != nil && curr != nil {
+		if image, ok := curr.Values["image"]; ok {
+			values["image"] = image
+		}
+	}
+
+	return values, nil
+}
+
+func (s *State) release(name, namespace string) *ReleaseState {
+	for _, rel := range s.Releases {
+		if rel.Name == name && rel.Namespace == namespace {
+			return rel
+		}
+	}
+
+	return nil
+}
+
+func (s *State) envGroup(name, namespace string) *EnvGroupState {
+	for _, group := range s.EnvGroups {
+		if group.Name == name && group.Namespace == namespace {
+			return group
+		}
+	}
+
+	return nil
+}
+
+func diffEnvGroup(group *EnvGroupSpec, curr *EnvGroupState) []string {
+	changes := make([]string, 0)
+
+	if !stringMapsEqual(group.Variables, curr.Variables) {
+		changes = append(changes, "variables")
+	}
+
+	if !stringMapsEqual(group.Secrets, curr.Secrets) {
+		changes = append(changes, "secrets")
+	}
+
+	return changes
+}
+
+func diffRelease(
+	rel *ReleaseSpec,
+	values map[string]interface{},
+	curr *ReleaseState,
+) ([]string, error) {
+	changes := make([]string, 0)
+
+	if rel.Template != curr.Chart {
+		changes = append(changes, "template")
+	} else if rel.Version != "" && rel.Version != curr.Version {
+		changes = append(changes, "version")
+	}
+
+	currValues, err := normalizeValues(curr.Values)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !reflect.DeepEqual(values, currValues) {
+		changes = append(changes, "values")
+	}
+
+	if rel.Build != nil && !curr.HasBuild {
+		changes = append(changes, "build")
+	}
+
+	return changes, nil
+}
+
+func operationFromChanges(changes []string) string {
+	if len(changes) == 0 {
+		return OperationNone
+	}
+
+	return OperationUpgrade
+}
+
+// normalizeValues copies values through json, so that numbers and nested maps
+// have the same types as the values of releases read from Helm
+func normalizeValues(values map[string]interface{}) (map[string]interface{}, error) {
+	res := make(map[string]interface{})
+
+	if values == nil {
+		return res, nil
+	}
+
+	data, err := json.Marshal(values)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// childMap returns the map stored under key, replacing any other value
+func childMap(values map[string]interface{}, key string) map[string]interface{} {
+	if child, ok := values[key].(map[string]interface{}); ok {
+		return child
+	}
+
+	child := make(map[string]interface{})
+	values[key] = child
+
+	return child
+}
+
+func stringMapsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for key, val := range a {
+		if other, ok := b[key]; !ok || other != val {
+			return false
+		}
+	}
+
+	return true
+}
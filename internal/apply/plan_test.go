@@ -0,0 +1,176 @@
+package apply_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/apply"
+)
+
+func TestReleaseValues(t *testing.T) {
+	spec, err := apply.ParseSpec([]byte(testSpec))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	values, err := spec.ReleaseValues(spec.Releases[0], nil)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	expected := map[string]interface{}{
+		"replicaCount": float64(2),
+		"container": map[string]interface{}{
+			"env": map[string]interface{}{
+				"normal": map[string]interface{}{
+					"LOG_LEVEL":    "debug",
+					"DATABASE_URL": "PORTERSECRET_shared",
+				},
+			},
+		},
+		"ingress": map[string]interface{}{
+			"enabled":       true,
+			"custom_domain": true,
+			"hosts":         []interface{}{"app.example.com"},
+		},
+	}
+
+	if diff := deep.Equal(values, expected); diff != nil {
+		t.Errorf("incorrect values: %v\n", diff)
+	}
+}
+
+func TestComputePlan(t *testing.T) {
+	spec, err := apply.ParseSpec([]byte(testSpec))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	webValues, err := spec.ReleaseValues(spec.Releases[0], nil)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	state := &apply.State{
+		EnvGroups: []*apply.EnvGroupState{
+			{
+				Name:      "shared",
+				Namespace: "staging",
+				Variables: map[string]string{"LOG_LEVEL": "warn"},
+				Secrets:   map[string]string{"DATABASE_URL": "postgres://db"},
+			},
+			{
+				Name:      "old",
+				Namespace: "staging",
+				Prunable:  true,
+			},
+			{
+				Name:      "web-config",
+				Namespace: "staging",
+			},
+		},
+		Releases: []*apply.ReleaseState{
+			{
+				Name:      "web",
+				Namespace: "staging",
+				Chart:     "web",
+				Version:   "0.20.0",
+				Values:    webValues,
+			},
+			{
+				Name:      "api",
+				Namespace: "staging",
+				Chart:     "web",
+				Version:   "0.20.0",
+			},
+		},
+	}
+
+	plan, err := apply.ComputePlan(spec, state, false)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	expected := []*apply.Action{
+		{
+			Kind:      apply.KindEnvGroup,
+			Name:      "shared",
+			Namespace: "staging",
+			Operation: apply.OperationUpgrade,
+			Changes:   []string{"variables"},
+		},
+		{
+			Kind:      apply.KindRelease,
+			Name:      "web",
+			Namespace: "staging",
+			Operation: apply.OperationNone,
+			Changes:   []string{},
+		},
+		{
+			Kind:      apply.KindRelease,
+			Name:      "worker",
+			Namespace: "jobs",
+			Operation: apply.OperationCreate,
+		},
+	}
+
+	if diff := deep.Equal(plan.Actions, expected); diff != nil {
+		t.Errorf("incorrect plan: %v\n", diff)
+	}
+
+	plan, err = apply.ComputePlan(spec, state, true)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(plan.Actions) != 5 {
+		t.Fatalf("incorrect number of actions: expected 5, got %d\n", len(plan.Actions))
+	}
+
+	if a := plan.Actions[3]; a.Name != "api" || a.Operation != apply.OperationDelete {
+		t.Errorf("expected release api to be deleted, got %s %s\n", a.Operation, a.Name)
+	}
+
+	if a := plan.Actions[4]; a.Name != "old" || a.Operation != apply.OperationDelete {
+		t.Errorf("expected env group old to be deleted, got %s %s\n", a.Operation, a.Name)
+	}
+}
+
+func TestReleaseValuesKeepsBuiltImage(t *testing.T) {
+	spec, err := apply.ParseSpec([]byte(`version: v1
+releases:
+- name: web
+  template: web
+  build:
+    git_repo: porter-dev/porter
+    git_repo_id: 1
+    image_repo_uri: registry.example.com/web
+`))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	image := map[string]interface{}{
+		"repository": "registry.example.com/web",
+		"tag":        "3f2a1c",
+	}
+
+	values, err := spec.ReleaseValues(spec.Releases[0], &apply.ReleaseState{
+		Values: map[string]interface{}{"image": image},
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if diff := deep.Equal(values["image"], image); diff != nil {
+		t.Errorf("image of built release was not kept: %v\n", diff)
+	}
+}
@@ -0,0 +1,199 @@
+package apply
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// SpecVersion is the only version of the porter.yaml format that is accepted
+const SpecVersion = "v1"
+
+// Spec is a declarative description of the releases and env groups of a
+// project in a single cluster, read from a porter.yaml file
+type Spec struct {
+	Version string `json:"version"`
+
+	// Namespace is the namespace of releases and env groups that do not set
+	// one
+	Namespace string `json:"namespace"`
+
+	EnvGroups []*EnvGroupSpec `json:"env_groups"`
+	Releases  []*ReleaseSpec  `json:"releases"`
+}
+
+// EnvGroupSpec is an env group, which is stored as a configmap along with a
+// linked secret for the secret variables
+type EnvGroupSpec struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Variables map[string]string `json:"variables"`
+	Secrets   map[string]string `json:"secrets"`
+}
+
+// ReleaseSpec is a release that is deployed from a template
+type ReleaseSpec struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+
+	// Template is the name of the chart in the template repository, and
+	// Version is its version. The latest version is used if it is not set.
+	Template string `json:"template"`
+	Version  string `json:"version"`
+
+	// RepoURL overrides the default template repository
+	RepoURL string `json:"repo_url"`
+
+	Values map[string]interface{} `json:"values"`
+
+	// EnvGroups are the names of env groups in the spec whose variables are
+	// added to the container env of the release, in order
+	EnvGroups []string `json:"env_groups"`
+
+	// Domains are custom domains that the release's ingress is exposed on
+	Domains []string `json:"domains"`
+
+	Build *BuildSpec `json:"build"`
+}
+
+// BuildSpec is the github action that builds and deploys the release from
+// source
+type BuildSpec struct {
+	GitRepo        string            `json:"git_repo"`
+	GitRepoID      uint              `json:"git_repo_id"`
+	GitBranch      string            `json:"git_branch"`
+	ImageRepoURI   string            `json:"image_repo_uri"`
+	DockerfilePath string            `json:"dockerfile_path"`
+	FolderPath     string            `json:"folder_path"`
+	RegistryID     uint              `json:"registry_id"`
+	Env            map[string]string `json:"env"`
+}
+
+// ParseSpec reads a porter.yaml file, fills in the default namespaces, and
+// checks that the spec is consistent
+func ParseSpec(data []byte) (*Spec, error) {
+	spec := &Spec{}
+
+	if err := yaml.UnmarshalStrict(data, spec); err != nil {
+		return nil, fmt.Errorf("could not parse spec: %v", err)
+	}
+
+	if spec.Version != SpecVersion {
+		return nil, fmt.Errorf("unsupported spec version %q, expected %s", spec.Version, SpecVersion)
+	}
+
+	if spec.Namespace == "" {
+		spec.Namespace = "default"
+	}
+
+	envGroups := make(map[string]*EnvGroupSpec)
+
+	for _, group := range spec.EnvGroups {
+		if group.Name == "" {
+			return nil, fmt.Errorf("env groups must have a name")
+		}
+
+		if group.Namespace == "" {
+			group.Namespace = spec.Namespace
+		}
+
+		key := group.Namespace + "/" + group.Name
+
+		if _, exists := envGroups[key]; exists {
+			return nil, fmt.Errorf("env group %s is defined more than once", key)
+		}
+
+		for name := range group.Secrets {
+			if _, exists := group.Variables[name]; exists {
+				return nil, fmt.Errorf("env group %s sets %s as a variable and a secret", key, name)
+			}
+		}
+
+		envGroups[key] = group
+	}
+
+	releases := make(map[string]bool)
+
+	for _, rel := range spec.Releases {
+		if rel.Name == "" {
+			return nil, fmt.Errorf("releases must have a name")
+		}
+
+		if rel.Template == "" {
+			return nil, fmt.Errorf("release %s must have a template", rel.Name)
+		}
+
+		if rel.Namespace == "" {
+			rel.Namespace = spec.Namespace
+		}
+
+		key := rel.Namespace + "/" + rel.Name
+
+		if releases[key] {
+			return nil, fmt.Errorf("release %s is defined more than once", key)
+		}
+
+		releases[key] = true
+
+		// releases can only read env groups in their own namespace
+		for _, name := range rel.EnvGroups {
+			if _, exists := envGroups[rel.Namespace+"/"+name]; !exists {
+				return nil, fmt.Errorf("release %s uses env group %s, which is not defined in namespace %s", rel.Name, name, rel.Namespace)
+			}
+		}
+
+		if rel.Build != nil && (rel.Build.GitRepo == "" || rel.Build.GitRepoID == 0 || rel.Build.ImageRepoURI == "") {
+			return nil, fmt.Errorf("build of release %s must set git_repo, git_repo_id and image_repo_uri", rel.Name)
+		}
+	}
+
+	return spec, nil
+}
+
+// EnvGroup returns the env group with the given name and namespace, or nil
+// if the spec does not define it
+func (s *Spec) EnvGroup(name, namespace string) *EnvGroupSpec {
+	for _, group := range s.EnvGroups {
+		if group.Name == name && group.Namespace == namespace {
+			return group
+		}
+	}
+
+	return nil
+}
+
+// Release returns the release with the given name and namespace, or nil if
+// the spec does not define it
+func (s *Spec) Release(name, namespace string) *ReleaseSpec {
+	for _, rel := range s.Releases {
+		if rel.Name == name && rel.Namespace == namespace {
+			return rel
+		}
+	}
+
+	return nil
+}
+
+// Namespaces returns the namespaces of the releases and env groups in the
+// spec, without duplicates
+func (s *Spec) Namespaces() []string {
+	res := make([]string, 0)
+	seen := make(map[string]bool)
+
+	add := func(namespace string) {
+		if !seen[namespace] {
+			seen[namespace] = true
+			res = append(res, namespace)
+		}
+	}
+
+	for _, group := range s.EnvGroups {
+		add(group.Namespace)
+	}
+
+	for _, rel := range s.Releases {
+		add(rel.Namespace)
+	}
+
+	return res
+}
@@ -0,0 +1,99 @@
+package apply_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/apply"
+)
+
+const testSpec = `version: v1
+namespace: staging
+env_groups:
+- name: shared
+  variables:
+    LOG_LEVEL: info
+  secrets:
+    DATABASE_URL: postgres://db
+releases:
+- name: web
+  template: web
+  version: 0.20.0
+  env_groups:
+  - shared
+  domains:
+  - app.example.com
+  values:
+    replicaCount: 2
+    container:
+      env:
+        normal:
+          LOG_LEVEL: debug
+- name: worker
+  namespace: jobs
+  template: worker
+`
+
+func TestParseSpec(t *testing.T) {
+	spec, err := apply.ParseSpec([]byte(testSpec))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if spec.EnvGroups[0].Namespace != "staging" || spec.Releases[0].Namespace != "staging" {
+		t.Errorf("default namespace was not set\n")
+	}
+
+	if spec.Releases[1].Namespace != "jobs" {
+		t.Errorf("incorrect namespace: expected jobs, got %s\n", spec.Releases[1].Namespace)
+	}
+
+	namespaces := spec.Namespaces()
+
+	if len(namespaces) != 2 || namespaces[0] != "staging" || namespaces[1] != "jobs" {
+		t.Errorf("incorrect namespaces: got %v\n", namespaces)
+	}
+}
+
+func TestParseSpecErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected string
+	}{
+		{
+			name:     "version",
+			spec:     "version: v2\n",
+			expected: "unsupported spec version",
+		},
+		{
+			name:     "unknown field",
+			spec:     "version: v1\nreleses: []\n",
+			expected: "could not parse spec",
+		},
+		{
+			name:     "duplicate release",
+			spec:     "version: v1\nreleases:\n- name: web\n  template: web\n- name: web\n  template: web\n",
+			expected: "release default/web is defined more than once",
+		},
+		{
+			name:     "missing env group",
+			spec:     "version: v1\nreleases:\n- name: web\n  template: web\n  env_groups: [shared]\n",
+			expected: "uses env group shared",
+		},
+		{
+			name:     "incomplete build",
+			spec:     "version: v1\nreleases:\n- name: web\n  template: web\n  build:\n    git_repo: porter-dev/porter\n",
+			expected: "build of release web must set",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := apply.ParseSpec([]byte(test.spec))
+
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected error containing %q, got %v\n", test.name, test.expected, err)
+		}
+	}
+}
@@ -25,6 +25,14 @@ type Token struct {
 	ProjectID uint       `json:"project_id"`
 	IBy       uint       `json:"iby"`
 	IAt       *time.Time `json:"iat"`
+
+	// TokenID is the unique ID of the stored API token that this token was
+	// generated from. It is empty for tokens that are not stored.
+	TokenID string `json:"token_id"`
+
+	// TwoFactorVerified is true for user tokens that were issued to a session
+	// that passed the second step of a two-factor login
+	TwoFactorVerified bool `json:"two_factor_verified"`
 }
 
 func GetTokenForUser(userID uint) (*Token, error) {
@@ -58,13 +66,33 @@ func GetTokenForAPI(userID, projID uint) (*Token, error) {
 	}, nil
 }
 
+// GetStoredTokenForAPI generates a project token that is backed by a stored API
+// token with the given unique ID, so that it can be revoked
+func GetStoredTokenForAPI(userID, projID uint, tokenID string) (*Token, error) {
+	if tokenID == "" {
+		return nil, fmt.Errorf("token id cannot be empty")
+	}
+
+	tok, err := GetTokenForAPI(userID, projID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tok.TokenID = tokenID
+
+	return tok, nil
+}
+
 func (t *Token) EncodeToken(conf *TokenGeneratorConf) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub_kind":   t.SubKind,
-		"sub":        t.Sub,
-		"iby":        t.IBy,
-		"iat":        fmt.Sprintf("%d", t.IAt.Unix()),
-		"project_id": t.ProjectID,
+		"sub_kind":            t.SubKind,
+		"sub":                 t.Sub,
+		"iby":                 t.IBy,
+		"iat":                 fmt.Sprintf("%d", t.IAt.Unix()),
+		"project_id":          t.ProjectID,
+		"token_id":            t.TokenID,
+		"two_factor_verified": t.TwoFactorVerified,
 	})
 
 	// Sign and get the complete encoded token as a string using the secret
@@ -105,12 +133,20 @@ func GetTokenFromEncoded(tokenString string, conf *TokenGeneratorConf) (*Token,
 
 		iat := time.Unix(iatUnix, 0)
 
+		// tokens generated before stored API tokens do not have this claim
+		tokenID, _ := claims["token_id"].(string)
+
+		// tokens generated before two-factor authentication have not passed it
+		twoFactorVerified, _ := claims["two_factor_verified"].(bool)
+
 		return &Token{
-			SubKind:   Subject(fmt.Sprintf("%v", claims["sub_kind"])),
-			Sub:       fmt.Sprintf("%v", claims["sub"]),
-			IBy:       uint(iby),
-			IAt:       &iat,
-			ProjectID: uint(projID),
+			SubKind:           Subject(fmt.Sprintf("%v", claims["sub_kind"])),
+			Sub:               fmt.Sprintf("%v", claims["sub"]),
+			IBy:               uint(iby),
+			IAt:               &iat,
+			ProjectID:         uint(projID),
+			TokenID:           tokenID,
+			TwoFactorVerified: twoFactorVerified,
 		}, nil
 	}
 
@@ -50,3 +50,43 @@ func TestGetAndEncodeTokenForUser(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+func TestGetAndEncodeStoredTokenForAPI(t *testing.T) {
+	conf := &token.TokenGeneratorConf{
+		TokenSecret: "fakesecret",
+	}
+
+	tok, err := token.GetStoredTokenForAPI(1, 2, "abcd")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	tokString, err := tok.EncodeToken(conf)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// decode the token again and compare
+	expToken := &token.Token{
+		SubKind:   token.API,
+		Sub:       string(token.API),
+		ProjectID: 2,
+		IBy:       1,
+		TokenID:   "abcd",
+	}
+
+	gotToken, err := token.GetTokenFromEncoded(tokString, conf)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	gotToken.IAt = nil
+
+	if diff := deep.Equal(expToken, gotToken); diff != nil {
+		t.Errorf("tokens not equal:")
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,69 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/auth/totp"
+)
+
+// the SHA1 test vectors from RFC 6238, truncated to 6 digits
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+var rfcCodes = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+}
+
+func TestGenerateCode(t *testing.T) {
+	for _, c := range rfcCodes {
+		code, err := totp.GenerateCode(rfcSecret, totp.Step(time.Unix(c.unix, 0)))
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		if code != c.code {
+			t.Errorf("incorrect code at %d: expected %s, got %s\n", c.unix, c.code, code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	now := time.Now()
+	curr := totp.Step(now)
+
+	prevCode, _ := totp.GenerateCode(secret, curr-1)
+	oldCode, _ := totp.GenerateCode(secret, curr-3)
+
+	step, ok := totp.Validate(secret, prevCode, now, 0)
+
+	if !ok || step != curr-1 {
+		t.Errorf("expected code from the previous period to be valid\n")
+	}
+
+	if _, ok := totp.Validate(secret, prevCode, now, step); ok {
+		t.Errorf("expected used code to be rejected\n")
+	}
+
+	if _, ok := totp.Validate(secret, oldCode, now, 0); ok {
+		t.Errorf("expected code outside of the skew to be rejected\n")
+	}
+
+	if _, ok := totp.Validate(secret, "abc", now, 0); ok {
+		t.Errorf("expected malformed code to be rejected\n")
+	}
+}
@@ -38,17 +38,46 @@ type ServerConf struct {
 	GithubClientID     string `env:"GITHUB_CLIENT_ID"`
 	GithubClientSecret string `env:"GITHUB_CLIENT_SECRET"`
 
+	OIDCIssuerURL    string `env:"OIDC_ISSUER_URL"`
+	OIDCClientID     string `env:"OIDC_CLIENT_ID"`
+	OIDCClientSecret string `env:"OIDC_CLIENT_SECRET"`
+	OIDCGroupsClaim  string `env:"OIDC_GROUPS_CLAIM,default=groups"`
+
 	SendgridAPIKey                  string `env:"SENDGRID_API_KEY"`
 	SendgridPWResetTemplateID       string `env:"SENDGRID_PW_RESET_TEMPLATE_ID"`
 	SendgridPWGHTemplateID          string `env:"SENDGRID_PW_GH_TEMPLATE_ID"`
 	SendgridVerifyEmailTemplateID   string `env:"SENDGRID_VERIFY_EMAIL_TEMPLATE_ID"`
 	SendgridProjectInviteTemplateID string `env:"SENDGRID_INVITE_TEMPLATE_ID"`
+	SendgridNotificationTemplateID  string `env:"SENDGRID_NOTIFICATION_TEMPLATE_ID"`
 	SendgridSenderEmail             string `env:"SENDGRID_SENDER_EMAIL"`
 
 	DOClientID          string `env:"DO_CLIENT_ID"`
 	DOClientSecret      string `env:"DO_CLIENT_SECRET"`
 	ProvisionerImageTag string `env:"PROV_IMAGE_TAG,default=latest"`
 	SegmentClientKey    string `env:"SEGMENT_CLIENT_KEY"`
+
+	// SecretBackend is where the secret store keeps values: "database" or
+	// "vault"
+	SecretBackend   string `env:"SECRET_BACKEND,default=database"`
+	VaultAddress    string `env:"VAULT_ADDR"`
+	VaultToken      string `env:"VAULT_TOKEN"`
+	VaultMountPath  string `env:"VAULT_MOUNT_PATH,default=secret"`
+	VaultPathPrefix string `env:"VAULT_PATH_PREFIX,default=porter"`
+
+	// HelmRepoS3Endpoint and HelmRepoGCSEndpoint override the endpoints that S3
+	// and GCS backed Helm repos are read from, such as to use S3 and GCS
+	// compatible servers
+	HelmRepoS3Endpoint  string `env:"HELM_REPO_S3_ENDPOINT"`
+	HelmRepoGCSEndpoint string `env:"HELM_REPO_GCS_ENDPOINT"`
+
+	// HelmChartCacheSize is the number of chart archives of public Helm repos
+	// that are cached in memory, or 0 to read public repos without a cache.
+	// Cached index files are revalidated after HelmIndexRefreshInterval, and
+	// archives are also kept in HelmChartCacheDir or in redis if either is set.
+	HelmChartCacheSize       int           `env:"HELM_CHART_CACHE_SIZE,default=100"`
+	HelmIndexRefreshInterval time.Duration `env:"HELM_INDEX_REFRESH_INTERVAL,default=5m"`
+	HelmChartCacheDir        string        `env:"HELM_CHART_CACHE_DIR"`
+	HelmChartCacheRedis      bool          `env:"HELM_CHART_CACHE_REDIS,default=false"`
 }
 
 // DBConf is the database configuration: if generated from environment variables,
@@ -0,0 +1,42 @@
+package forms
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/oauth"
+)
+
+// CreateAPITokenForm represents the accepted values for creating a stored
+// API token for a project
+type CreateAPITokenForm struct {
+	ProjectID uint       `form:"required"`
+	UserID    uint       `form:"required"`
+	Name      string     `json:"name" form:"required"`
+	Scopes    []string   `json:"scopes"`
+	Expiry    *time.Time `json:"expiry"`
+}
+
+// ToAPIToken converts the form to a gorm API token model
+func (cat *CreateAPITokenForm) ToAPIToken() (*models.APIToken, error) {
+	for _, scope := range cat.Scopes {
+		if !models.IsValidScope(scope) {
+			return nil, fmt.Errorf("%s is not a valid scope", scope)
+		}
+	}
+
+	if cat.Expiry != nil && cat.Expiry.Before(time.Now()) {
+		return nil, fmt.Errorf("expiry must be in the future")
+	}
+
+	return &models.APIToken{
+		UniqueID:        oauth.CreateRandomState(),
+		ProjectID:       cat.ProjectID,
+		CreatedByUserID: cat.UserID,
+		Name:            cat.Name,
+		Scopes:          strings.Join(cat.Scopes, ","),
+		Expiry:          cat.Expiry,
+	}, nil
+}
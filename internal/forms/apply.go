@@ -0,0 +1,12 @@
+package forms
+
+// ApplyForm represents the accepted values for planning or applying a
+// porter.yaml spec to a cluster
+type ApplyForm struct {
+	// Spec is the contents of the porter.yaml file
+	Spec string `json:"spec" form:"required"`
+
+	// Prune deletes releases and env groups in the spec's namespaces that
+	// the spec does not define
+	Prune bool `json:"prune"`
+}
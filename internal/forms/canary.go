@@ -0,0 +1,87 @@
+package forms
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// The defaults for the options of a canary rollout that are not set
+var (
+	defaultCanarySteps          = []int{10, 25, 50}
+	defaultCanaryStepInterval   = uint(300)
+	defaultCanaryErrorThreshold = 5.0
+)
+
+// CanaryForm represents the accepted options for a canary rollout
+type CanaryForm struct {
+	// Steps are the percentages of traffic that the canary receives in order,
+	// before it is promoted
+	Steps []int `json:"steps"`
+
+	// StepIntervalSeconds is how long each step is watched for
+	StepIntervalSeconds uint `json:"step_interval_seconds"`
+
+	// ErrorThreshold is the percentage of 5xx responses above which the
+	// rollout is rolled back
+	ErrorThreshold float64 `json:"error_threshold"`
+}
+
+// ToCanaryRollout converts the form to a gorm canary rollout model for the
+// release, filling in the defaults of options that are not set
+func (cf *CanaryForm) ToCanaryRollout(
+	projectID, clusterID uint,
+	namespace, name string,
+) (*models.CanaryRollout, error) {
+	steps := cf.Steps
+
+	if len(steps) == 0 {
+		steps = defaultCanarySteps
+	}
+
+	strSteps := make([]string, 0)
+
+	for i, step := range steps {
+		if step <= 0 || step >= 100 {
+			return nil, fmt.Errorf("canary steps must be between 1 and 99")
+		}
+
+		if i > 0 && step <= steps[i-1] {
+			return nil, fmt.Errorf("canary steps must be increasing")
+		}
+
+		strSteps = append(strSteps, strconv.Itoa(step))
+	}
+
+	interval := cf.StepIntervalSeconds
+
+	if interval == 0 {
+		interval = defaultCanaryStepInterval
+	}
+
+	threshold := cf.ErrorThreshold
+
+	if threshold == 0 {
+		threshold = defaultCanaryErrorThreshold
+	}
+
+	if threshold < 0 || threshold > 100 {
+		return nil, fmt.Errorf("error threshold must be a percentage")
+	}
+
+	return &models.CanaryRollout{
+		ProjectID:           projectID,
+		ClusterID:           clusterID,
+		Namespace:           namespace,
+		ReleaseName:         name,
+		CanaryName:          helm.CanaryReleaseName(name),
+		Steps:               strings.Join(strSteps, ","),
+		StepIntervalSeconds: interval,
+		ErrorThreshold:      threshold,
+		CurrentWeight:       steps[0],
+		Status:              models.CanaryStatusProgressing,
+	}, nil
+}
@@ -0,0 +1,81 @@
+package forms
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// DeployPolicyForm represents the accepted values for creating or replacing a
+// deploy policy of a project
+type DeployPolicyForm struct {
+	// ClusterID is the cluster that the policy applies to, or 0 if the policy
+	// applies to every cluster of the project
+	ClusterID    uint                   `json:"cluster_id"`
+	Name         string                 `json:"name" form:"required,max=255"`
+	Timezone     string                 `json:"timezone"`
+	AllowedDays  []string               `json:"allowed_days"`
+	AllowedHours string                 `json:"allowed_hours"`
+	Freezes      []*models.DeployFreeze `json:"freezes"`
+}
+
+// ToDeployPolicy converts the form to a gorm deploy policy model
+func (dpf *DeployPolicyForm) ToDeployPolicy(projectID uint) (*models.DeployPolicy, error) {
+	timezone := dpf.Timezone
+
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("invalid timezone %s", timezone)
+	}
+
+	for _, day := range dpf.AllowedDays {
+		valid := false
+
+		for _, d := range models.DeployDays {
+			if day == d {
+				valid = true
+				break
+			}
+		}
+
+		if !valid {
+			return nil, fmt.Errorf("invalid day %s: must be one of %s", day, strings.Join(models.DeployDays, ", "))
+		}
+	}
+
+	if dpf.AllowedHours != "" {
+		if _, _, err := models.ParseDeployHours(dpf.AllowedHours); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, freeze := range dpf.Freezes {
+		if freeze == nil || freeze.StartsAt.IsZero() || freeze.EndsAt.IsZero() {
+			return nil, fmt.Errorf("freezes must have a start and an end")
+		}
+
+		if !freeze.EndsAt.After(freeze.StartsAt) {
+			return nil, fmt.Errorf("freeze must end after it starts")
+		}
+	}
+
+	policy := &models.DeployPolicy{
+		ProjectID:    projectID,
+		ClusterID:    dpf.ClusterID,
+		Name:         dpf.Name,
+		Timezone:     timezone,
+		AllowedDays:  strings.Join(dpf.AllowedDays, ","),
+		AllowedHours: dpf.AllowedHours,
+	}
+
+	if err := policy.SetFreezes(dpf.Freezes); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
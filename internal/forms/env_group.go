@@ -0,0 +1,137 @@
+package forms
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/models"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// CreateEnvGroupForm represents the accepted values for creating a shared env
+// group in a cluster
+type CreateEnvGroupForm struct {
+	ProjectID       uint              `form:"required"`
+	ClusterID       uint              `form:"required"`
+	Name            string            `json:"name" form:"required,max=253"`
+	Variables       map[string]string `json:"variables"`
+	SecretVariables map[string]string `json:"secret_variables"`
+}
+
+// ToEnvGroup converts the form to a gorm env group model, along with its first
+// version
+func (ceg *CreateEnvGroupForm) ToEnvGroup() (*models.EnvGroup, *models.EnvGroupVersion, error) {
+	// the name of the group is the name of its configmap and secret
+	if errs := validation.IsDNS1123Subdomain(ceg.Name); len(errs) > 0 {
+		return nil, nil, fmt.Errorf("invalid env group name: %s", strings.Join(errs, ", "))
+	}
+
+	if err := validateEnvGroupVariables(ceg.Variables, ceg.SecretVariables); err != nil {
+		return nil, nil, err
+	}
+
+	group := &models.EnvGroup{
+		ProjectID: ceg.ProjectID,
+		ClusterID: ceg.ClusterID,
+		Name:      ceg.Name,
+		Version:   1,
+	}
+
+	version := &models.EnvGroupVersion{
+		Version: 1,
+	}
+
+	if err := version.SetVariables(ceg.Variables, ceg.SecretVariables); err != nil {
+		return nil, nil, err
+	}
+
+	return group, version, nil
+}
+
+// UpdateEnvGroupForm represents the accepted values for updating the variables
+// of an env group. The variables replace the variables of the latest version.
+type UpdateEnvGroupForm struct {
+	Variables map[string]string `json:"variables"`
+
+	// SecretVariables with an empty value keep the value of the latest
+	// version, since the values of secret variables are never sent to clients
+	SecretVariables map[string]string `json:"secret_variables"`
+
+	// Redeploy upgrades every release that the group is attached to, so that
+	// the releases pick up the new version
+	Redeploy bool `json:"redeploy"`
+}
+
+// ToEnvGroupVersion converts the form to the next version of an env group,
+// given its latest version
+func (ueg *UpdateEnvGroupForm) ToEnvGroupVersion(
+	group *models.EnvGroup,
+	prev *models.EnvGroupVersion,
+) (*models.EnvGroupVersion, error) {
+	if err := validateEnvGroupVariables(ueg.Variables, ueg.SecretVariables); err != nil {
+		return nil, err
+	}
+
+	prevSecrets, err := prev.GetSecretVariables()
+
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[string]string)
+
+	for key, val := range ueg.SecretVariables {
+		if val == "" {
+			prevVal, ok := prevSecrets[key]
+
+			if !ok {
+				return nil, fmt.Errorf("secret variable %s must have a value", key)
+			}
+
+			val = prevVal
+		}
+
+		secrets[key] = val
+	}
+
+	version := &models.EnvGroupVersion{
+		EnvGroupID: group.ID,
+		Version:    group.Version + 1,
+	}
+
+	if err := version.SetVariables(ueg.Variables, secrets); err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// EnvGroupAttachmentForm represents the accepted values for attaching an env
+// group to a release, or detaching it
+type EnvGroupAttachmentForm struct {
+	ReleaseName string `json:"release_name" form:"required"`
+	Namespace   string `json:"namespace" form:"required"`
+
+	// Redeploy upgrades the release, so that the release picks up the change
+	Redeploy bool `json:"redeploy"`
+}
+
+func validateEnvGroupVariables(variables, secrets map[string]string) error {
+	for key := range variables {
+		if errs := validation.IsEnvVarName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid variable name %s: %s", key, strings.Join(errs, ", "))
+		}
+
+		if _, exists := secrets[key]; exists {
+			return fmt.Errorf("%s cannot be both a variable and a secret variable", key)
+		}
+	}
+
+	for key := range secrets {
+		if errs := validation.IsEnvVarName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid secret variable name %s: %s", key, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
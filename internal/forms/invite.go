@@ -1,28 +1,108 @@
 package forms
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/oauth"
 )
 
+// InviteScope is the role, and optionally the cluster and namespace, that an
+// invite grants when it is accepted
+type InviteScope struct {
+	// Kind is the role to grant, and defaults to admin
+	Kind      string `json:"kind"`
+	ClusterID uint   `json:"cluster_id"`
+	Namespace string `json:"namespace"`
+}
+
+// Validate returns an error if the role is not supported, or if a namespace
+// is set without a cluster
+func (is *InviteScope) Validate() error {
+	if is.Kind != "" && !models.IsValidRole(is.Kind) {
+		return fmt.Errorf("%s is not a valid role", is.Kind)
+	}
+
+	if is.Namespace != "" && is.ClusterID == 0 {
+		return fmt.Errorf("namespace can only be set with a cluster")
+	}
+
+	return nil
+}
+
+func (is *InviteScope) toInvite(projectID, inviterID uint, email string) *models.Invite {
+	// generate a token and an expiry time
+	expiry := time.Now().Add(models.InviteExpiry)
+
+	kind := is.Kind
+
+	if kind == "" {
+		kind = models.RoleAdmin
+	}
+
+	return &models.Invite{
+		Email:     email,
+		Expiry:    &expiry,
+		ProjectID: projectID,
+		Token:     oauth.CreateRandomState(),
+		Kind:      kind,
+		ClusterID: is.ClusterID,
+		Namespace: is.Namespace,
+		InviterID: inviterID,
+	}
+}
+
 // CreateInvite represents the accepted values for creating an
 // invite to a project
 type CreateInvite struct {
+	InviteScope
+
 	Email     string `json:"email" form:"required"`
 	ProjectID uint   `form:"required"`
+	InviterID uint
 }
 
 // ToInvite converts the project to a gorm project model
 func (ci *CreateInvite) ToInvite() (*models.Invite, error) {
-	// generate a token and an expiry time
-	expiry := time.Now().Add(24 * time.Hour)
+	if err := ci.Validate(); err != nil {
+		return nil, err
+	}
 
-	return &models.Invite{
-		Email:     ci.Email,
-		Expiry:    &expiry,
-		ProjectID: ci.ProjectID,
-		Token:     oauth.CreateRandomState(),
-	}, nil
+	return ci.toInvite(ci.ProjectID, ci.InviterID, ci.Email), nil
+}
+
+// CreateBulkInvite represents the accepted values for inviting several
+// emails to a project with the same role
+type CreateBulkInvite struct {
+	InviteScope
+
+	Emails    []string `json:"emails" form:"required,min=1,max=100,dive,required,email"`
+	ProjectID uint     `form:"required"`
+	InviterID uint
+}
+
+// ToInvites converts the form to a gorm invite model for each unique email
+func (cbi *CreateBulkInvite) ToInvites() ([]*models.Invite, error) {
+	if err := cbi.Validate(); err != nil {
+		return nil, err
+	}
+
+	invites := make([]*models.Invite, 0)
+	seen := make(map[string]bool)
+
+	for _, email := range cbi.Emails {
+		key := strings.ToLower(email)
+
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		invites = append(invites, cbi.toInvite(cbi.ProjectID, cbi.InviterID, email))
+	}
+
+	return invites, nil
 }
@@ -0,0 +1,97 @@
+package forms
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/notifier"
+	"github.com/porter-dev/porter/internal/oauth"
+)
+
+// CreateNotificationChannelForm represents the accepted values for creating a
+// notification channel for a project
+type CreateNotificationChannelForm struct {
+	ProjectID uint     `form:"required"`
+	Name      string   `json:"name" form:"required,max=255"`
+	Kind      string   `json:"kind" form:"required,oneof=slack webhook email"`
+	Target    string   `json:"target" form:"required"`
+	Events    []string `json:"events" form:"required,min=1"`
+}
+
+// ToNotificationChannel converts the form to a gorm notification channel
+// model. Generic webhooks are given a new signing secret.
+func (cnc *CreateNotificationChannelForm) ToNotificationChannel() (*models.NotificationChannel, error) {
+	if err := validateNotificationEvents(cnc.Events); err != nil {
+		return nil, err
+	}
+
+	if cnc.Kind == models.NotificationChannelEmail {
+		if _, err := mail.ParseAddress(cnc.Target); err != nil {
+			return nil, fmt.Errorf("target must be an email address")
+		}
+	} else if err := notifier.ValidateWebhookURL(cnc.Target); err != nil {
+		return nil, err
+	}
+
+	channel := &models.NotificationChannel{
+		ProjectID: cnc.ProjectID,
+		Name:      cnc.Name,
+		Kind:      cnc.Kind,
+		Events:    strings.Join(cnc.Events, ","),
+		Target:    []byte(cnc.Target),
+	}
+
+	if cnc.Kind == models.NotificationChannelWebhook {
+		channel.SigningSecret = []byte(oauth.CreateRandomState())
+	}
+
+	return channel, nil
+}
+
+// UpdateNotificationChannelForm represents the accepted values for updating
+// the name and subscriptions of a notification channel
+type UpdateNotificationChannelForm struct {
+	Name   string   `json:"name" form:"max=255"`
+	Events []string `json:"events"`
+}
+
+// UpdateNotificationChannel applies the form to an existing channel. Fields
+// that are not set are left unchanged.
+func (unc *UpdateNotificationChannelForm) UpdateNotificationChannel(
+	channel *models.NotificationChannel,
+) error {
+	if unc.Events != nil {
+		if err := validateNotificationEvents(unc.Events); err != nil {
+			return err
+		}
+
+		channel.Events = strings.Join(unc.Events, ",")
+	}
+
+	if unc.Name != "" {
+		channel.Name = unc.Name
+	}
+
+	return nil
+}
+
+func validateNotificationEvents(events []string) error {
+	for _, event := range events {
+		found := false
+
+		for _, valid := range models.NotificationEvents {
+			if event == valid {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("%s is not a valid event", event)
+		}
+	}
+
+	return nil
+}
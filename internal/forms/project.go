@@ -1,6 +1,8 @@
 package forms
 
 import (
+	"fmt"
+
 	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/repository"
 	"gorm.io/gorm"
@@ -41,3 +43,23 @@ func (cprf *CreateProjectRoleForm) ToProject(_ repository.ProjectRepository) (*m
 		Roles: cprf.Roles,
 	}, nil
 }
+
+// UpdateProjectRoleForm represents the accepted values for changing the role
+// of a project member
+type UpdateProjectRoleForm struct {
+	ProjectID uint   `form:"required"`
+	UserID    uint   `form:"required"`
+	Kind      string `json:"kind" form:"required"`
+}
+
+// ToRole applies the form to an existing role, returning an error if the role
+// kind is not supported
+func (uprf *UpdateProjectRoleForm) ToRole(role *models.Role) (*models.Role, error) {
+	if !models.IsValidRole(uprf.Kind) {
+		return nil, fmt.Errorf("%s is not a valid role", uprf.Kind)
+	}
+
+	role.Kind = uprf.Kind
+
+	return role, nil
+}
@@ -106,12 +106,76 @@ type RollbackReleaseForm struct {
 	Revision int    `json:"revision" form:"required"`
 }
 
+// PromoteReleaseForm represents the accepted values for promoting a release to
+// a release in another cluster or namespace. The release in the URL is the
+// source, and the target is created if it doesn't exist.
+type PromoteReleaseForm struct {
+	*ReleaseForm
+	Name            string `json:"name" form:"required"`
+	TargetClusterID uint   `json:"target_cluster_id" form:"required"`
+	TargetNamespace string `json:"target_namespace" form:"required"`
+
+	// TargetName is the name of the target release, which is the name of the
+	// source if it is unset
+	TargetName string `json:"target_name"`
+
+	// Values are the paths of the values that are copied from the source, such
+	// as "container.env.normal". The image tag is always copied.
+	Values []string `json:"values"`
+}
+
+// AdoptReleasesForm represents the accepted values for adopting Helm releases
+// that were not deployed through Porter
+type AdoptReleasesForm struct {
+	*ReleaseForm
+
+	// All adopts every release in the cluster that Porter doesn't manage, or
+	// every release in the namespace if one is passed
+	All bool `json:"all"`
+
+	Releases []*AdoptReleaseForm `json:"releases" form:"dive"`
+}
+
+// AdoptReleaseForm represents a single Helm release to adopt
+type AdoptReleaseForm struct {
+	Name      string `json:"name" form:"required"`
+	Namespace string `json:"namespace" form:"required"`
+
+	// GitAction optionally links the release to a GitHub repository that it is
+	// built and deployed from. It is validated once the release is adopted,
+	// since the release id of the form is only known then.
+	GitAction *CreateGitAction `json:"git_action,omitempty" form:"-"`
+}
+
 // UpgradeReleaseForm represents the accepted values for updating a Helm release
 type UpgradeReleaseForm struct {
 	*ReleaseForm
 	Name         string `json:"name" form:"required"`
 	Values       string `json:"values" form:"required"`
 	ChartVersion string `json:"version"`
+
+	// Canary deploys the upgrade as a weighted canary of the release instead
+	// of replacing it at once, if set
+	Canary *CanaryForm `json:"canary,omitempty"`
+}
+
+// UpgradeReleaseChartForm represents the accepted values for upgrading a
+// release to another version of its chart, with the values of the release
+// migrated to the new chart
+type UpgradeReleaseChartForm struct {
+	*ReleaseForm
+	Name    string `json:"name" form:"required"`
+	Version string `json:"version" form:"required"`
+
+	// RepoURL or HelmRepoID is the repo that the chart is loaded from. If
+	// neither is set, Porter applications are loaded from the application
+	// repo and other charts from the add-on repo.
+	RepoURL    string `json:"repo_url"`
+	HelmRepoID uint   `json:"helm_repo_id"`
+
+	// DryRun returns the migrated values, form and changes without upgrading
+	// the release
+	DryRun bool `json:"dry_run"`
 }
 
 // ChartTemplateForm represents the accepted values for installing a new chart from a template.
@@ -130,3 +194,10 @@ type InstallChartTemplateForm struct {
 	// optional git action config
 	GithubActionConfig *CreateGitActionOptional `json:"github_action,omitempty"`
 }
+
+// RolloutPolicyForm represents the accepted values for setting how the rollouts
+// of a release are watched after it is deployed
+type RolloutPolicyForm struct {
+	RolloutDeadlineSeconds uint `json:"rollout_deadline_seconds" form:"max=3600"`
+	AutoRollback           bool `json:"auto_rollback"`
+}
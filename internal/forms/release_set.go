@@ -0,0 +1,74 @@
+package forms
+
+import (
+	"fmt"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// ReleaseSetForm represents the accepted values for creating or replacing a
+// release set of a project
+type ReleaseSetForm struct {
+	Name string `json:"name" form:"required,max=255"`
+
+	// Strategy defaults to sequential, and FailurePolicy defaults to stop
+	Strategy      string `json:"strategy" form:"omitempty,oneof=sequential parallel"`
+	FailurePolicy string `json:"failure_policy" form:"omitempty,oneof=stop continue rollback"`
+
+	Targets []*ReleaseSetTargetForm `json:"targets" form:"required,min=1,max=50,dive,required"`
+}
+
+// ReleaseSetTargetForm represents a release that a release set deploys
+type ReleaseSetTargetForm struct {
+	ClusterID   uint   `json:"cluster_id" form:"required"`
+	Namespace   string `json:"namespace" form:"required"`
+	ReleaseName string `json:"release_name" form:"required"`
+}
+
+// ToReleaseSet converts the form to a gorm release set model. A release can
+// only be a target of the set once.
+func (rsf *ReleaseSetForm) ToReleaseSet(projectID uint) (*models.ReleaseSet, error) {
+	set := &models.ReleaseSet{
+		ProjectID:     projectID,
+		Name:          rsf.Name,
+		Strategy:      rsf.Strategy,
+		FailurePolicy: rsf.FailurePolicy,
+		Targets:       make([]models.ReleaseSetTarget, 0),
+	}
+
+	if set.Strategy == "" {
+		set.Strategy = models.ReleaseSetStrategySequential
+	}
+
+	if set.FailurePolicy == "" {
+		set.FailurePolicy = models.ReleaseSetFailureStop
+	}
+
+	for _, target := range rsf.Targets {
+		if set.Target(target.ClusterID, target.Namespace, target.ReleaseName) != nil {
+			return nil, fmt.Errorf(
+				"release %s in namespace %s of cluster %d is a target more than once",
+				target.ReleaseName,
+				target.Namespace,
+				target.ClusterID,
+			)
+		}
+
+		set.Targets = append(set.Targets, models.ReleaseSetTarget{
+			ClusterID:   target.ClusterID,
+			Namespace:   target.Namespace,
+			ReleaseName: target.ReleaseName,
+		})
+	}
+
+	return set, nil
+}
+
+// DeployReleaseSetForm represents the accepted values for deploying every
+// target of a release set. Targets keep their current values if Values is
+// empty, and ImageTag replaces the image tag of every target if it is set.
+type DeployReleaseSetForm struct {
+	Values       string `json:"values"`
+	ImageTag     string `json:"image_tag"`
+	ChartVersion string `json:"version"`
+}
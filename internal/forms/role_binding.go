@@ -0,0 +1,27 @@
+package forms
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// CreateRoleBindingForm represents the accepted values for scoping a project
+// member or an API token to a cluster, and optionally to a namespace within
+// that cluster. Exactly one of UserID and APITokenID must be set.
+type CreateRoleBindingForm struct {
+	ProjectID  uint   `form:"required"`
+	UserID     uint   `json:"user_id"`
+	APITokenID uint   `json:"api_token_id"`
+	ClusterID  uint   `json:"cluster_id" form:"required"`
+	Namespace  string `json:"namespace"`
+}
+
+// ToRoleBinding converts the form to a gorm role binding model
+func (crb *CreateRoleBindingForm) ToRoleBinding() (*models.RoleBinding, error) {
+	return &models.RoleBinding{
+		ProjectID:  crb.ProjectID,
+		UserID:     crb.UserID,
+		APITokenID: crb.APITokenID,
+		ClusterID:  crb.ClusterID,
+		Namespace:  crb.Namespace,
+	}, nil
+}
@@ -0,0 +1,8 @@
+package forms
+
+// WriteProjectSecretForm represents the accepted values for creating a secret
+// in a project's secret store, or replacing its value
+type WriteProjectSecretForm struct {
+	Name  string `json:"name" form:"required,max=253"`
+	Value string `json:"value" form:"required"`
+}
@@ -0,0 +1,30 @@
+package forms
+
+import (
+	"fmt"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// CreateSSOGroupMappingForm represents the accepted values for granting a
+// project role to the members of an SSO group
+type CreateSSOGroupMappingForm struct {
+	ProjectID uint   `form:"required"`
+	Issuer    string `form:"required"`
+	Group     string `json:"group" form:"required"`
+	Kind      string `json:"kind" form:"required"`
+}
+
+// ToSSOGroupMapping converts the form to a gorm SSO group mapping model
+func (csm *CreateSSOGroupMappingForm) ToSSOGroupMapping() (*models.SSOGroupMapping, error) {
+	if !models.IsValidRole(csm.Kind) {
+		return nil, fmt.Errorf("%s is not a valid role", csm.Kind)
+	}
+
+	return &models.SSOGroupMapping{
+		ProjectID: csm.ProjectID,
+		Issuer:    csm.Issuer,
+		Group:     csm.Group,
+		Kind:      csm.Kind,
+	}, nil
+}
@@ -0,0 +1,21 @@
+package forms
+
+// TwoFactorCodeForm represents the accepted values for confirming a request
+// with a code from the user's authenticator app
+type TwoFactorCodeForm struct {
+	Code string `json:"code" form:"required"`
+}
+
+// VerifyTwoFactorForm represents the accepted values for the second step of
+// a login, or for disabling two-factor authentication. Either a code from the
+// user's authenticator app or an unused recovery code must be set.
+type VerifyTwoFactorForm struct {
+	Code         string `json:"code" form:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code" form:"required_without=Code"`
+}
+
+// UpdateProjectTwoFactorForm represents the accepted values for requiring
+// all members of a project to have two-factor authentication enabled
+type UpdateProjectTwoFactorForm struct {
+	Required bool `json:"required"`
+}
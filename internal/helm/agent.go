@@ -7,9 +7,11 @@ import (
 	"github.com/porter-dev/porter/internal/kubernetes"
 	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/repository"
+	"github.com/porter-dev/porter/internal/secrets"
 	"golang.org/x/oauth2"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/release"
 	"k8s.io/helm/pkg/chartutil"
 )
@@ -63,6 +65,13 @@ type UpgradeReleaseConfig struct {
 
 	// Optional, if chart should be overriden
 	Chart *chart.Chart
+
+	// Optional, the env groups attached to the release. The env groups are
+	// only written to the values if this is not nil.
+	EnvGroups []*EnvGroupValues
+
+	// Optional, the secret store that references in the release are read from
+	SecretBackend secrets.Backend
 }
 
 // UpgradeRelease upgrades a specific release with new values.yaml
@@ -100,22 +109,26 @@ func (a *Agent) UpgradeReleaseByValues(
 		ch = conf.Chart
 	}
 
+	if conf.EnvGroups != nil {
+		conf.Values = InjectEnvGroups(conf.Values, conf.EnvGroups)
+	}
+
 	cmd := action.NewUpgrade(a.ActionConfig)
 	cmd.Namespace = rel.Namespace
 
-	if conf.Cluster != nil && a.K8sAgent != nil && conf.Registries != nil && len(conf.Registries) > 0 {
-		cmd.PostRenderer, err = NewDockerSecretsPostRenderer(
-			conf.Cluster,
-			conf.Repo,
-			a.K8sAgent,
-			rel.Namespace,
-			conf.Registries,
-			doAuth,
-		)
+	cmd.PostRenderer, err = a.getPostRenderer(
+		conf.Cluster,
+		conf.Repo,
+		rel.Namespace,
+		conf.Name,
+		conf.Registries,
+		conf.SecretBackend,
+		doAuth,
+		false,
+	)
 
-		if err != nil {
-			return nil, err
-		}
+	if err != nil {
+		return nil, err
 	}
 
 	res, err := cmd.Run(conf.Name, ch, conf.Values)
@@ -136,6 +149,9 @@ type InstallChartConfig struct {
 	Cluster    *models.Cluster
 	Repo       repository.Repository
 	Registries []*models.Registry
+
+	// Optional, the secret store that references in the release are read from
+	SecretBackend secrets.Backend
 }
 
 // InstallChartFromValuesBytes reads the raw values and calls Agent.InstallChart
@@ -175,20 +191,19 @@ func (a *Agent) InstallChart(
 
 	var err error
 
-	// only add the postrenderer if required fields exist
-	if conf.Cluster != nil && a.K8sAgent != nil && conf.Registries != nil && len(conf.Registries) > 0 {
-		cmd.PostRenderer, err = NewDockerSecretsPostRenderer(
-			conf.Cluster,
-			conf.Repo,
-			a.K8sAgent,
-			conf.Namespace,
-			conf.Registries,
-			doAuth,
-		)
+	cmd.PostRenderer, err = a.getPostRenderer(
+		conf.Cluster,
+		conf.Repo,
+		conf.Namespace,
+		conf.Name,
+		conf.Registries,
+		conf.SecretBackend,
+		doAuth,
+		false,
+	)
 
-		if err != nil {
-			return nil, err
-		}
+	if err != nil {
+		return nil, err
 	}
 
 	if req := conf.Chart.Metadata.Dependencies; req != nil {
@@ -221,6 +236,56 @@ func (a *Agent) RollbackRelease(
 
 // ------------------------ Helm agent helper functions ------------------------ //
 
+// getPostRenderer returns the post-renderers that a release is rendered with.
+// Post-renderers are only added if the fields they require exist. For dry
+// runs, the post-renderers don't write to the cluster.
+func (a *Agent) getPostRenderer(
+	cluster *models.Cluster,
+	repo repository.Repository,
+	namespace, name string,
+	registries []*models.Registry,
+	backend secrets.Backend,
+	doAuth *oauth2.Config,
+	dryRun bool,
+) (postrender.PostRenderer, error) {
+	renderers := make([]postrender.PostRenderer, 0)
+
+	if cluster == nil || a.K8sAgent == nil {
+		return nil, nil
+	}
+
+	if len(registries) > 0 {
+		renderer, err := NewDockerSecretsPostRenderer(
+			cluster,
+			repo,
+			a.K8sAgent,
+			namespace,
+			registries,
+			doAuth,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		renderer.DryRun = dryRun
+		renderers = append(renderers, renderer)
+	}
+
+	if backend != nil {
+		renderers = append(renderers, &SecretStorePostRenderer{
+			Agent:       a.K8sAgent,
+			Backend:     backend,
+			ProjectID:   cluster.ProjectID,
+			Namespace:   namespace,
+			ReleaseName: name,
+			DryRun:      dryRun,
+		})
+	}
+
+	return chainPostRenderers(renderers), nil
+}
+
 // checkIfInstallable validates if a chart can be installed
 // Application chart type is only installable
 func checkIfInstallable(ch *chart.Chart) error {
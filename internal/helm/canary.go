@@ -0,0 +1,93 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CanarySuffix is appended to the name of a release to get the name of the
+// release that its canary is deployed as
+const CanarySuffix = "-canary"
+
+// The nginx ingress annotations that mark an ingress as the canary of another
+// ingress with the same host
+const (
+	canaryAnnotation       = "nginx.ingress.kubernetes.io/canary"
+	canaryWeightAnnotation = "nginx.ingress.kubernetes.io/canary-weight"
+)
+
+// CanaryReleaseName returns the name of the canary release for a release
+func CanaryReleaseName(name string) string {
+	return name + CanarySuffix
+}
+
+// CanaryValues returns a copy of the values of a web release with the ingress
+// marked as a canary that receives the given percentage of traffic. The
+// values are not modified.
+func CanaryValues(values map[string]interface{}, weight int) map[string]interface{} {
+	res := make(map[string]interface{})
+
+	for key, val := range values {
+		res[key] = val
+	}
+
+	ingress := make(map[string]interface{})
+
+	if curr, ok := values["ingress"].(map[string]interface{}); ok {
+		for key, val := range curr {
+			ingress[key] = val
+		}
+	}
+
+	annotations := make(map[string]interface{})
+
+	if curr, ok := ingress["annotations"].(map[string]interface{}); ok {
+		for key, val := range curr {
+			annotations[key] = val
+		}
+	}
+
+	annotations[canaryAnnotation] = "true"
+	annotations[canaryWeightAnnotation] = fmt.Sprintf("%d", weight)
+
+	ingress["annotations"] = annotations
+	res["ingress"] = ingress
+
+	return res
+}
+
+// IsIngressEnabled returns true if the values of a web release expose it
+// through an ingress
+func IsIngressEnabled(values map[string]interface{}) bool {
+	ingress, ok := values["ingress"].(map[string]interface{})
+
+	if !ok {
+		return false
+	}
+
+	enabled, ok := ingress["enabled"].(bool)
+
+	return ok && enabled
+}
+
+// IngressNames returns the names of the ingresses in a rendered manifest, in
+// a stable order
+func IngressNames(manifest string) ([]string, error) {
+	resources, err := parseManifest(manifest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, 0)
+
+	for _, resource := range resources {
+		if resource.kind == "Ingress" {
+			res = append(res, resource.name)
+		}
+	}
+
+	sort.Strings(res)
+
+	return res, nil
+}
@@ -0,0 +1,77 @@
+package helm_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/helm"
+)
+
+func TestCanaryValues(t *testing.T) {
+	values := map[string]interface{}{
+		"replicaCount": 2,
+		"ingress": map[string]interface{}{
+			"enabled": true,
+			"annotations": map[string]interface{}{
+				"kubernetes.io/ingress.class": "nginx",
+			},
+		},
+	}
+
+	canary := helm.CanaryValues(values, 25)
+
+	expected := map[string]interface{}{
+		"replicaCount": 2,
+		"ingress": map[string]interface{}{
+			"enabled": true,
+			"annotations": map[string]interface{}{
+				"kubernetes.io/ingress.class":               "nginx",
+				"nginx.ingress.kubernetes.io/canary":        "true",
+				"nginx.ingress.kubernetes.io/canary-weight": "25",
+			},
+		},
+	}
+
+	if diff := deep.Equal(canary, expected); diff != nil {
+		t.Errorf("incorrect canary values:\n")
+		t.Error(diff)
+	}
+
+	// make sure the original values are not modified
+	annotations := values["ingress"].(map[string]interface{})["annotations"].(map[string]interface{})
+
+	if len(annotations) != 1 {
+		t.Errorf("original values were modified: %v\n", annotations)
+	}
+
+	if !helm.IsIngressEnabled(canary) {
+		t.Errorf("expected ingress to be enabled\n")
+	}
+}
+
+func TestIngressNames(t *testing.T) {
+	manifest := `---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: api-web
+---
+# Source: web/templates/ingress.yaml
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: api-web
+`
+
+	names, err := helm.IngressNames(manifest)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if diff := deep.Equal(names, []string{"api-web"}); diff != nil {
+		t.Errorf("incorrect ingress names:\n")
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,356 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"golang.org/x/oauth2"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// The ways that a resource can change between two revisions
+const (
+	ResourceAdded    string = "added"
+	ResourceRemoved  string = "removed"
+	ResourceModified string = "modified"
+)
+
+// ReleaseDiff is the set of changes that an upgrade or rollback would make to
+// the currently deployed revision of a release
+type ReleaseDiff struct {
+	Name            string `json:"name"`
+	CurrentRevision int    `json:"current_revision"`
+
+	// TargetRevision is the revision that is rolled back to, and is unset for
+	// upgrades
+	TargetRevision int `json:"target_revision,omitempty"`
+
+	// ValuesDiff is a unified diff of the values
+	ValuesDiff string `json:"values_diff"`
+
+	// Resources are the rendered resources that change, in a stable order
+	Resources []*ResourceDiff `json:"resources"`
+}
+
+// ResourceDiff is the change to a single rendered resource
+type ResourceDiff struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Change    string `json:"change"`
+
+	// Diff is a unified diff of the resource manifest
+	Diff string `json:"diff"`
+}
+
+// HasChanges returns true if the values or any resources differ
+func (d *ReleaseDiff) HasChanges() bool {
+	return d.ValuesDiff != "" || len(d.Resources) > 0
+}
+
+// DiffUpgradeRelease renders an upgrade of the release without applying it, and
+// compares it to the currently deployed revision. Image pull secrets and
+// secret store references are rendered as they would be for a real upgrade,
+// without writing any secrets to the cluster.
+func (a *Agent) DiffUpgradeRelease(
+	conf *UpgradeReleaseConfig,
+	doAuth *oauth2.Config,
+) (*ReleaseDiff, error) {
+	current, err := a.GetRelease(conf.Name, 0)
+
+	if err != nil {
+		return nil, fmt.Errorf("Could not get release to be upgraded: %v", err)
+	}
+
+	ch := current.Chart
+
+	if conf.Chart != nil {
+		ch = conf.Chart
+	}
+
+	cmd := action.NewUpgrade(a.ActionConfig)
+	cmd.Namespace = current.Namespace
+	cmd.DryRun = true
+
+	cmd.PostRenderer, err = a.getPostRenderer(
+		conf.Cluster,
+		conf.Repo,
+		current.Namespace,
+		conf.Name,
+		conf.Registries,
+		conf.SecretBackend,
+		doAuth,
+		true,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	proposed, err := cmd.Run(conf.Name, ch, conf.Values)
+
+	if err != nil {
+		return nil, fmt.Errorf("Upgrade dry run failed: %v", err)
+	}
+
+	return DiffReleases(current, proposed)
+}
+
+// DiffRollbackRelease compares the currently deployed revision of a release to
+// the revision that it would be rolled back to
+func (a *Agent) DiffRollbackRelease(
+	name string,
+	version int,
+) (*ReleaseDiff, error) {
+	current, err := a.GetRelease(name, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := a.GetRelease(name, version)
+
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := DiffReleases(current, target)
+
+	if err != nil {
+		return nil, err
+	}
+
+	diff.TargetRevision = target.Version
+
+	return diff, nil
+}
+
+// DiffInstallChart renders an install of a chart without applying it. Since
+// there is no deployed revision, every value and resource is added.
+func (a *Agent) DiffInstallChart(
+	conf *InstallChartConfig,
+	doAuth *oauth2.Config,
+) (*ReleaseDiff, error) {
+	if err := checkIfInstallable(conf.Chart); err != nil {
+		return nil, err
+	}
+
+	cmd := action.NewInstall(a.ActionConfig)
+	cmd.ReleaseName = conf.Name
+	cmd.Namespace = conf.Namespace
+	cmd.DryRun = true
+
+	var err error
+
+	cmd.PostRenderer, err = a.getPostRenderer(
+		conf.Cluster,
+		conf.Repo,
+		conf.Namespace,
+		conf.Name,
+		conf.Registries,
+		conf.SecretBackend,
+		doAuth,
+		true,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	proposed, err := cmd.Run(conf.Chart, conf.Values)
+
+	if err != nil {
+		return nil, fmt.Errorf("Install dry run failed: %v", err)
+	}
+
+	return DiffReleases(&release.Release{
+		Name:   conf.Name,
+		Config: map[string]interface{}{},
+	}, proposed)
+}
+
+// DiffReleases compares the values and rendered manifests of two releases
+func DiffReleases(current, proposed *release.Release) (*ReleaseDiff, error) {
+	currValues, err := valuesYAML(current.Config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	propValues, err := valuesYAML(proposed.Config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	valuesDiff, err := unifiedDiff("values.yaml", currValues, propValues)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := diffManifests(current.Manifest, proposed.Manifest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReleaseDiff{
+		Name:            current.Name,
+		CurrentRevision: current.Version,
+		ValuesDiff:      valuesDiff,
+		Resources:       resources,
+	}, nil
+}
+
+// valuesYAML marshals values, leaving releases without values empty so that
+// new releases are diffed against an empty file
+func valuesYAML(values map[string]interface{}) (string, error) {
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	bytes, err := yaml.Marshal(values)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(bytes), nil
+}
+
+type manifestResource struct {
+	kind      string
+	name      string
+	namespace string
+	content   string
+}
+
+func (r *manifestResource) key() string {
+	return fmt.Sprintf("%s/%s/%s", r.kind, r.namespace, r.name)
+}
+
+func diffManifests(current, proposed string) ([]*ResourceDiff, error) {
+	currResources, err := parseManifest(current)
+
+	if err != nil {
+		return nil, err
+	}
+
+	propResources, err := parseManifest(proposed)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0)
+
+	for key := range currResources {
+		keys = append(keys, key)
+	}
+
+	for key := range propResources {
+		if _, found := currResources[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	res := make([]*ResourceDiff, 0)
+
+	for _, key := range keys {
+		curr, inCurr := currResources[key]
+		prop, inProp := propResources[key]
+
+		var change, currContent, propContent string
+		var meta *manifestResource
+
+		switch {
+		case inCurr && inProp:
+			if curr.content == prop.content {
+				continue
+			}
+
+			change, meta = ResourceModified, prop
+			currContent, propContent = curr.content, prop.content
+		case inProp:
+			change, meta = ResourceAdded, prop
+			propContent = prop.content
+		default:
+			change, meta = ResourceRemoved, curr
+			currContent = curr.content
+		}
+
+		diff, err := unifiedDiff(key, currContent, propContent)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, &ResourceDiff{
+			Kind:      meta.kind,
+			Name:      meta.name,
+			Namespace: meta.namespace,
+			Change:    change,
+			Diff:      diff,
+		})
+	}
+
+	return res, nil
+}
+
+// parseManifest splits a rendered manifest into its resources, keyed by kind,
+// namespace and name
+func parseManifest(manifest string) (map[string]*manifestResource, error) {
+	res := make(map[string]*manifestResource)
+
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		var head struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name      string `yaml:"name"`
+				Namespace string `yaml:"namespace"`
+			} `yaml:"metadata"`
+		}
+
+		if err := yaml.Unmarshal([]byte(doc), &head); err != nil {
+			return nil, err
+		}
+
+		// skip documents that only contain comments
+		if head.Kind == "" {
+			continue
+		}
+
+		resource := &manifestResource{
+			kind:      head.Kind,
+			name:      head.Metadata.Name,
+			namespace: head.Metadata.Namespace,
+			content:   strings.TrimSpace(doc) + "\n",
+		}
+
+		res[resource.key()] = resource
+	}
+
+	return res, nil
+}
+
+func unifiedDiff(name, current, proposed string) (string, error) {
+	if current == proposed {
+		return "", nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(current),
+		B:        difflib.SplitLines(proposed),
+		FromFile: "current/" + name,
+		ToFile:   "proposed/" + name,
+		Context:  3,
+	})
+}
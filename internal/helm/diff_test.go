@@ -0,0 +1,110 @@
+package helm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/helm"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const currentManifest = `---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`
+
+const proposedManifest = `---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+---
+# Source: web/templates/ingress.yaml
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: web
+`
+
+func TestDiffReleases(t *testing.T) {
+	current := &release.Release{
+		Name:     "web",
+		Version:  4,
+		Config:   map[string]interface{}{"replicaCount": 1},
+		Manifest: currentManifest,
+	}
+
+	proposed := &release.Release{
+		Name:     "web",
+		Version:  5,
+		Config:   map[string]interface{}{"replicaCount": 3, "ingress": map[string]interface{}{"enabled": true}},
+		Manifest: proposedManifest,
+	}
+
+	diff, err := helm.DiffReleases(current, proposed)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if diff.CurrentRevision != 4 {
+		t.Errorf("incorrect current revision: expected %d, got %d\n", 4, diff.CurrentRevision)
+	}
+
+	if !strings.Contains(diff.ValuesDiff, "-replicaCount: 1") || !strings.Contains(diff.ValuesDiff, "+replicaCount: 3") {
+		t.Errorf("values diff missing replicaCount change:\n%s", diff.ValuesDiff)
+	}
+
+	expChanges := []struct {
+		kind   string
+		change string
+	}{
+		{"Deployment", helm.ResourceModified},
+		{"Ingress", helm.ResourceAdded},
+		{"Service", helm.ResourceRemoved},
+	}
+
+	if len(diff.Resources) != len(expChanges) {
+		t.Fatalf("incorrect number of resources: expected %d, got %d\n", len(expChanges), len(diff.Resources))
+	}
+
+	for i, exp := range expChanges {
+		res := diff.Resources[i]
+
+		if res.Kind != exp.kind || res.Change != exp.change {
+			t.Errorf("resource %d: expected %s %s, got %s %s\n", i, exp.kind, exp.change, res.Kind, res.Change)
+		}
+	}
+
+	if !strings.Contains(diff.Resources[0].Diff, "+  replicas: 3") {
+		t.Errorf("deployment diff missing replicas change:\n%s", diff.Resources[0].Diff)
+	}
+
+	// a release compared to itself has no changes
+	diff, err = helm.DiffReleases(current, current)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if diff.HasChanges() {
+		t.Errorf("expected no changes, got %v\n", diff)
+	}
+}
@@ -0,0 +1,119 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EnvGroupValues is a version of a shared env group that is attached to a
+// release
+type EnvGroupValues struct {
+	Name       string
+	Version    uint
+	Variables  map[string]string
+	SecretKeys []string
+}
+
+// InjectEnvGroups writes the variables of the env groups attached to a release
+// into container.env.normal, in the order of the groups. Secret variables are
+// referenced with PORTERSECRET_${group}, so they are read from the group's
+// linked secret.
+//
+// The keys that were injected are recorded in container.env.synced, so that
+// keys that were removed from a group, or that belong to a group that was
+// detached, are removed on the next upgrade. Variables set directly on the
+// release take precedence over the env groups.
+func InjectEnvGroups(values map[string]interface{}, groups []*EnvGroupValues) map[string]interface{} {
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+
+	container := childValues(values, "container")
+	env := childValues(container, "env")
+	normal := childValues(env, "normal")
+
+	// remove the keys injected by the last upgrade
+	if synced, ok := env["synced"].([]interface{}); ok {
+		for _, entry := range synced {
+			entryMap, ok := entry.(map[string]interface{})
+
+			if !ok {
+				continue
+			}
+
+			keys, _ := entryMap["keys"].([]interface{})
+
+			for _, key := range keys {
+				if keyStr, ok := key.(string); ok {
+					delete(normal, keyStr)
+				}
+			}
+		}
+	}
+
+	explicit := make(map[string]bool)
+
+	for key := range normal {
+		explicit[key] = true
+	}
+
+	synced := make([]interface{}, 0)
+
+	for _, group := range groups {
+		groupVals := make(map[string]string)
+
+		for key, val := range group.Variables {
+			groupVals[key] = val
+		}
+
+		for _, key := range group.SecretKeys {
+			groupVals[key] = fmt.Sprintf("PORTERSECRET_%s", group.Name)
+		}
+
+		keys := make([]string, 0)
+
+		for key, val := range groupVals {
+			if explicit[key] {
+				continue
+			}
+
+			normal[key] = val
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		keysVal := make([]interface{}, 0)
+
+		for _, key := range keys {
+			keysVal = append(keysVal, key)
+		}
+
+		synced = append(synced, map[string]interface{}{
+			"name":    group.Name,
+			"version": group.Version,
+			"keys":    keysVal,
+		})
+	}
+
+	if len(synced) == 0 {
+		delete(env, "synced")
+	} else {
+		env["synced"] = synced
+	}
+
+	return values
+}
+
+// childValues returns the map of values stored under key, replacing any other
+// value
+func childValues(values map[string]interface{}, key string) map[string]interface{} {
+	if child, ok := values[key].(map[string]interface{}); ok {
+		return child
+	}
+
+	child := make(map[string]interface{})
+	values[key] = child
+
+	return child
+}
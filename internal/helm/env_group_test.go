@@ -0,0 +1,90 @@
+package helm_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/helm"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const envGroupValues = `
+container:
+  port: 80
+  env:
+    normal:
+      PORT: "80"
+      LOG_LEVEL: warn
+`
+
+func TestInjectEnvGroups(t *testing.T) {
+	values, err := chartutil.ReadValues([]byte(envGroupValues))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	values = helm.InjectEnvGroups(values, []*helm.EnvGroupValues{
+		{
+			Name:    "shared",
+			Version: 1,
+			Variables: map[string]string{
+				"LOG_LEVEL": "info",
+				"REGION":    "us-east-1",
+			},
+			SecretKeys: []string{"DATABASE_URL"},
+		},
+	})
+
+	expNormal := map[string]interface{}{
+		"PORT":         "80",
+		"LOG_LEVEL":    "warn",
+		"REGION":       "us-east-1",
+		"DATABASE_URL": "PORTERSECRET_shared",
+	}
+
+	env := values["container"].(map[string]interface{})["env"].(map[string]interface{})
+
+	if diff := deep.Equal(env["normal"], expNormal); diff != nil {
+		t.Errorf("incorrect env after first upgrade: %v\n", diff)
+	}
+
+	// the next version of the group drops REGION, and the values of the
+	// release are read back with the synced keys
+	values = helm.InjectEnvGroups(values, []*helm.EnvGroupValues{
+		{
+			Name:    "shared",
+			Version: 2,
+			Variables: map[string]string{
+				"LOG_LEVEL": "info",
+			},
+			SecretKeys: []string{"DATABASE_URL"},
+		},
+	})
+
+	delete(expNormal, "REGION")
+
+	env = values["container"].(map[string]interface{})["env"].(map[string]interface{})
+
+	if diff := deep.Equal(env["normal"], expNormal); diff != nil {
+		t.Errorf("incorrect env after second upgrade: %v\n", diff)
+	}
+
+	// detaching the group removes its keys
+	values = helm.InjectEnvGroups(values, []*helm.EnvGroupValues{})
+
+	env = values["container"].(map[string]interface{})["env"].(map[string]interface{})
+
+	expNormal = map[string]interface{}{
+		"PORT":      "80",
+		"LOG_LEVEL": "warn",
+	}
+
+	if diff := deep.Equal(env["normal"], expNormal); diff != nil {
+		t.Errorf("incorrect env after detaching: %v\n", diff)
+	}
+
+	if _, ok := env["synced"]; ok {
+		t.Errorf("synced keys were not removed after detaching\n")
+	}
+}
@@ -0,0 +1,444 @@
+package loader
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	redis "github.com/go-redis/redis/v8"
+	"k8s.io/helm/pkg/repo"
+
+	"helm.sh/helm/v3/pkg/chart"
+	chartloader "helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// DefaultChartCache is the cache that public repos are read through. Public
+// repos are read without a cache if it is nil.
+var DefaultChartCache *ChartCache
+
+// ChartStore is a second level of a chart cache, which keeps chart archives
+// after they are evicted from memory, and can be shared between servers
+type ChartStore interface {
+	// GetArchive returns the archive with the key, and false if the store
+	// doesn't have the archive
+	GetArchive(key string) ([]byte, bool, error)
+	SetArchive(key string, data []byte) error
+}
+
+// maxCachedIndexes is the number of index files that a chart cache keeps in
+// memory. Index files of public repos can be large, and the repo of a template
+// can be any URL, so the least recently used index files are evicted.
+const maxCachedIndexes = 64
+
+// ChartCache caches the index files and chart archives of public Helm repos.
+// Index files are served from the cache for RefreshInterval, and are then
+// revalidated with a conditional request. Index files and chart archives are
+// kept in LRUs, and chart archives are keyed by the digest of the archive in
+// the index file, since the contents of a digest never change.
+type ChartCache struct {
+	// RefreshInterval is how long an index file is used before it is
+	// revalidated with the repo
+	RefreshInterval time.Duration
+
+	// Store is an optional second level for chart archives
+	Store ChartStore
+
+	size int
+
+	mu            sync.Mutex
+	indexes       *list.List
+	indexElements map[string]*list.Element
+	archives      *list.List
+	elements      map[string]*list.Element
+}
+
+type cachedIndex struct {
+	url          string
+	index        *repo.IndexFile
+	etag         string
+	lastModified string
+	fetchedAt    time.Time
+}
+
+type cachedArchive struct {
+	key  string
+	data []byte
+}
+
+// NewChartCache returns a chart cache that keeps up to size chart archives in
+// memory
+func NewChartCache(size int, refreshInterval time.Duration, store ChartStore) *ChartCache {
+	return &ChartCache{
+		RefreshInterval: refreshInterval,
+		Store:           store,
+		size:            size,
+		indexes:         list.New(),
+		indexElements:   make(map[string]*list.Element),
+		archives:        list.New(),
+		elements:        make(map[string]*list.Element),
+	}
+}
+
+// LoadRepoIndex returns the index file of a public repo. The index file is
+// fetched if it is not cached, and revalidated if it is older than the refresh
+// interval. If the repo can't be reached, the cached index file is returned.
+func (c *ChartCache) LoadRepoIndex(repoURL string) (*repo.IndexFile, error) {
+	indexURL := trimRepoURL(repoURL) + "/index.yaml"
+
+	cached, ok := c.getIndex(indexURL)
+
+	if ok && time.Since(cached.fetchedAt) < c.RefreshInterval {
+		return copyRepoIndex(cached.index), nil
+	}
+
+	fetched, err := fetchRepoIndex(indexURL, cached)
+
+	if err != nil {
+		if ok {
+			return copyRepoIndex(cached.index), nil
+		}
+
+		return nil, err
+	}
+
+	fetched.url = indexURL
+	c.addIndex(fetched)
+
+	return copyRepoIndex(fetched.index), nil
+}
+
+// Refresh drops the index file of a repo, so that it is fetched again. Chart
+// archives are kept, since they are keyed by their digest.
+func (c *ChartCache) Refresh(repoURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.indexElements[trimRepoURL(repoURL)+"/index.yaml"]; ok {
+		c.indexes.Remove(elem)
+		delete(c.indexElements, elem.Value.(*cachedIndex).url)
+	}
+}
+
+// getIndex returns a cached index file, and moves it to the front of the LRU
+func (c *ChartCache) getIndex(indexURL string) (*cachedIndex, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.indexElements[indexURL]
+
+	if !ok {
+		return nil, false
+	}
+
+	c.indexes.MoveToFront(elem)
+
+	return elem.Value.(*cachedIndex), true
+}
+
+// addIndex adds an index file to the front of the LRU, replacing the cached
+// index file of the same URL, and evicts the least recently used index files
+// above maxCachedIndexes
+func (c *ChartCache) addIndex(cached *cachedIndex) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.indexElements[cached.url]; ok {
+		c.indexes.Remove(elem)
+	}
+
+	c.indexElements[cached.url] = c.indexes.PushFront(cached)
+
+	for c.indexes.Len() > maxCachedIndexes {
+		oldest := c.indexes.Back()
+		c.indexes.Remove(oldest)
+		delete(c.indexElements, oldest.Value.(*cachedIndex).url)
+	}
+}
+
+// LoadChart returns a chart from a public repo, using the cached archive of
+// the chart if the digest of the chart version is cached. If chartVersion is
+// an empty string, the most stable latest version is found.
+func (c *ChartCache) LoadChart(repoURL, chartName, chartVersion string) (*chart.Chart, error) {
+	repoIndex, err := c.LoadRepoIndex(repoURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cv, err := repoIndex.Get(chartName, chartVersion)
+
+	if err != nil {
+		return nil, err
+	} else if len(cv.URLs) == 0 {
+		return nil, fmt.Errorf("%s:%s no valid download urls", chartName, chartVersion)
+	}
+
+	// charts without a digest can't be cached, since the archive at a url can
+	// change
+	if cv.Digest == "" {
+		data, err := downloadChart(&BasicAuthClient{}, chartDownloadURL(repoURL, cv.URLs[0]))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return chartloader.LoadArchive(bytes.NewReader(data))
+	}
+
+	data := c.getArchive(cv.Digest)
+
+	if data == nil {
+		data, err = downloadChart(&BasicAuthClient{}, chartDownloadURL(repoURL, cv.URLs[0]))
+
+		if err != nil {
+			return nil, err
+		}
+
+		// only archives that match their digest are cached
+		if archiveDigest(data) == cv.Digest {
+			c.setArchive(cv.Digest, data)
+		}
+	}
+
+	return chartloader.LoadArchive(bytes.NewReader(data))
+}
+
+// getArchive returns a cached chart archive, or nil if the archive is not
+// cached. The store is a best effort cache, so errors of the store are
+// treated as misses. Archives from the store are shared with other servers
+// and kept outside of the process, so they are only used if they match their
+// digest.
+func (c *ChartCache) getArchive(key string) []byte {
+	c.mu.Lock()
+
+	if elem, ok := c.elements[key]; ok {
+		c.archives.MoveToFront(elem)
+		c.mu.Unlock()
+
+		return elem.Value.(*cachedArchive).data
+	}
+
+	c.mu.Unlock()
+
+	if c.Store == nil {
+		return nil
+	}
+
+	data, ok, err := c.Store.GetArchive(key)
+
+	if err != nil || !ok || archiveDigest(data) != key {
+		return nil
+	}
+
+	c.addArchive(key, data)
+
+	return data
+}
+
+func (c *ChartCache) setArchive(key string, data []byte) {
+	c.addArchive(key, data)
+
+	if c.Store != nil {
+		c.Store.SetArchive(key, data)
+	}
+}
+
+// addArchive adds an archive to the front of the LRU, and evicts the least
+// recently used archives above the size of the cache
+func (c *ChartCache) addArchive(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.elements[key]; ok {
+		c.archives.MoveToFront(elem)
+		return
+	}
+
+	c.elements[key] = c.archives.PushFront(&cachedArchive{key, data})
+
+	for c.archives.Len() > c.size {
+		oldest := c.archives.Back()
+		c.archives.Remove(oldest)
+		delete(c.elements, oldest.Value.(*cachedArchive).key)
+	}
+}
+
+// fetchRepoIndex fetches an index file, sending the validators of the cached
+// index file so that an unchanged index file is not downloaded again
+func fetchRepoIndex(indexURL string, cached *cachedIndex) (*cachedIndex, error) {
+	req, err := http.NewRequest("GET", indexURL, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return &cachedIndex{
+			index:        cached.index,
+			etag:         cached.etag,
+			lastModified: cached.lastModified,
+			fetchedAt:    time.Now(),
+		}, nil
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request to %s failed with status %d", indexURL, resp.StatusCode)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := ParseRepoIndex(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &cachedIndex{
+		index:        index,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		fetchedAt:    time.Now(),
+	}, nil
+}
+
+// copyRepoIndex copies the entries of an index file, so that callers can sort
+// and modify the entries without changing the cached index file
+func copyRepoIndex(index *repo.IndexFile) *repo.IndexFile {
+	res := &repo.IndexFile{
+		APIVersion: index.APIVersion,
+		Generated:  index.Generated,
+		PublicKeys: index.PublicKeys,
+		Entries:    make(map[string]repo.ChartVersions, len(index.Entries)),
+	}
+
+	for name, versions := range index.Entries {
+		res.Entries[name] = append(repo.ChartVersions{}, versions...)
+	}
+
+	return res
+}
+
+// archiveDigest returns the digest of a chart archive, in the format of the
+// digests of index files
+func archiveDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func trimRepoURL(repoURL string) string {
+	return strings.TrimSuffix(strings.TrimSpace(repoURL), "/")
+}
+
+// DiskChartStore keeps chart archives in a directory
+type DiskChartStore struct {
+	Dir string
+}
+
+// GetArchive reads an archive from the directory
+func (s *DiskChartStore) GetArchive(key string) ([]byte, bool, error) {
+	data, err := ioutil.ReadFile(s.path(key))
+
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+// SetArchive writes an archive to the directory. The archive is written to a
+// temporary file first, so that a partially written archive is never read.
+func (s *DiskChartStore) SetArchive(key string, data []byte) error {
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(s.Dir, "chart-*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+// path returns the file of a key. Keys are hashed, since they are not
+// guaranteed to be valid file names.
+func (s *DiskChartStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.Dir, hex.EncodeToString(sum[:])+".tgz")
+}
+
+// RedisChartStore keeps chart archives in redis, so that the servers of an
+// installation share chart archives
+type RedisChartStore struct {
+	Client *redis.Client
+
+	// TTL is how long archives are kept, or 0 to keep archives until they
+	// are evicted by redis
+	TTL time.Duration
+}
+
+// GetArchive reads an archive from redis
+func (s *RedisChartStore) GetArchive(key string) ([]byte, bool, error) {
+	data, err := s.Client.Get(context.Background(), s.key(key)).Bytes()
+
+	if err == redis.Nil {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+// SetArchive writes an archive to redis
+func (s *RedisChartStore) SetArchive(key string, data []byte) error {
+	return s.Client.Set(context.Background(), s.key(key), data, s.TTL).Err()
+}
+
+func (s *RedisChartStore) key(key string) string {
+	return "porter:charts:" + key
+}
@@ -0,0 +1,253 @@
+package loader_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/helm/loader"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestChartCache(t *testing.T) {
+	archive := chartArchive(t, "0.1.0")
+	sum := sha256.Sum256(archive)
+
+	index := fmt.Sprintf(`apiVersion: v1
+entries:
+  web:
+  - apiVersion: v2
+    name: web
+    version: 0.1.0
+    digest: %s
+    urls:
+    - web-0.1.0.tgz
+`, hex.EncodeToString(sum[:]))
+
+	requests := make(map[string]int)
+
+	// repo that answers conditional requests for the index file
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				requests["index-not-modified"]++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			requests["index"]++
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(index))
+		case "/web-0.1.0.tgz":
+			requests["chart"]++
+			w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	defer server.Close()
+
+	cache := loader.NewChartCache(1, time.Hour, nil)
+
+	for i := 0; i < 3; i++ {
+		ch, err := cache.LoadChart(server.URL, "web", "")
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		if ch.Metadata.Version != "0.1.0" {
+			t.Errorf("incorrect chart version: %s\n", ch.Metadata.Version)
+		}
+	}
+
+	if requests["index"] != 1 || requests["chart"] != 1 {
+		t.Errorf("expected a single download of the index and chart, got %v\n", requests)
+	}
+
+	// stale index files are revalidated instead of downloaded again
+	cache.RefreshInterval = 0
+
+	if _, err := cache.LoadRepoIndex(server.URL); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if requests["index"] != 1 || requests["index-not-modified"] != 1 {
+		t.Errorf("expected the index to be revalidated, got %v\n", requests)
+	}
+
+	// refreshing a repo downloads the index file again, but keeps the chart
+	cache.RefreshInterval = time.Hour
+	cache.Refresh(server.URL + "/")
+
+	if _, err := cache.LoadChart(server.URL, "web", "0.1.0"); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if requests["index"] != 2 || requests["chart"] != 1 {
+		t.Errorf("expected the index to be downloaded again, got %v\n", requests)
+	}
+}
+
+// mapChartStore is a chart store that keeps archives in a map
+type mapChartStore map[string][]byte
+
+func (s mapChartStore) GetArchive(key string) ([]byte, bool, error) {
+	data, ok := s[key]
+	return data, ok, nil
+}
+
+func (s mapChartStore) SetArchive(key string, data []byte) error {
+	s[key] = data
+	return nil
+}
+
+func TestChartCacheStoreDigest(t *testing.T) {
+	archive := chartArchive(t, "0.1.0")
+	sum := sha256.Sum256(archive)
+	digest := hex.EncodeToString(sum[:])
+
+	index := fmt.Sprintf(`apiVersion: v1
+entries:
+  web:
+  - apiVersion: v2
+    name: web
+    version: 0.1.0
+    digest: %s
+    urls:
+    - web-0.1.0.tgz
+`, digest)
+
+	chartRequests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			w.Write([]byte(index))
+		case "/web-0.1.0.tgz":
+			chartRequests++
+			w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	defer server.Close()
+
+	// the store has a different archive under the digest of the chart
+	store := mapChartStore{digest: chartArchive(t, "0.2.0")}
+	cache := loader.NewChartCache(1, time.Hour, store)
+
+	ch, err := cache.LoadChart(server.URL, "web", "0.1.0")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if ch.Metadata.Version != "0.1.0" || chartRequests != 1 {
+		t.Errorf("expected the archive to be downloaded, got version %s and %d requests\n",
+			ch.Metadata.Version, chartRequests)
+	}
+
+	if string(store[digest]) != string(archive) {
+		t.Errorf("expected the archive in the store to be replaced\n")
+	}
+}
+
+func TestChartCacheEvictsIndexes(t *testing.T) {
+	requests := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Write([]byte("apiVersion: v1\nentries: {}\n"))
+	}))
+
+	defer server.Close()
+
+	cache := loader.NewChartCache(1, time.Hour, nil)
+
+	// the cache keeps 64 index files, so the first repo is evicted
+	for i := 0; i <= 64; i++ {
+		if _, err := cache.LoadRepoIndex(fmt.Sprintf("%s/repo-%d", server.URL, i)); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	for _, i := range []int{0, 64} {
+		if _, err := cache.LoadRepoIndex(fmt.Sprintf("%s/repo-%d", server.URL, i)); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	if requests["/repo-0/index.yaml"] != 2 || requests["/repo-64/index.yaml"] != 1 {
+		t.Errorf("expected only the least recently used index to be evicted, got %v\n", requests)
+	}
+}
+
+func TestDiskChartStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "porter-chart-cache")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	store := &loader.DiskChartStore{Dir: filepath.Join(dir, "charts")}
+
+	if _, ok, err := store.GetArchive("digest"); ok || err != nil {
+		t.Fatalf("expected missing archive, got %t %v\n", ok, err)
+	}
+
+	if err := store.SetArchive("digest", []byte("archive")); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	data, ok, err := store.GetArchive("digest")
+
+	if !ok || err != nil || string(data) != "archive" {
+		t.Errorf("incorrect archive: got %s %t %v\n", data, ok, err)
+	}
+}
+
+func chartArchive(t *testing.T, version string) []byte {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "porter-chart")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path, err := chartutil.Save(&chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "web",
+			Version:    version,
+		},
+	}, dir)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	return data
+}
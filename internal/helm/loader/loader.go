@@ -105,9 +105,13 @@ func LoadRepoIndex(client *BasicAuthClient, repoURL string) (*repo.IndexFile, er
 		return nil, err
 	}
 
-	// index not found in the cache, parse it
+	return ParseRepoIndex(data)
+}
+
+// ParseRepoIndex parses the contents of an index file, and sorts its entries
+func ParseRepoIndex(data []byte) (*repo.IndexFile, error) {
 	index := &repo.IndexFile{}
-	err = yaml.Unmarshal(data, index)
+	err := yaml.Unmarshal(data, index)
 
 	if err != nil {
 		return index, err
@@ -118,8 +122,13 @@ func LoadRepoIndex(client *BasicAuthClient, repoURL string) (*repo.IndexFile, er
 	return index, nil
 }
 
-// LoadRepoIndexPublic loads an index file from a remote public Helm repo
+// LoadRepoIndexPublic loads an index file from a remote public Helm repo. The
+// index file is read through DefaultChartCache if it is set.
 func LoadRepoIndexPublic(repoURL string) (*repo.IndexFile, error) {
+	if DefaultChartCache != nil {
+		return DefaultChartCache.LoadRepoIndex(repoURL)
+	}
+
 	return LoadRepoIndex(&BasicAuthClient{}, repoURL)
 }
 
@@ -139,10 +148,23 @@ func LoadChart(client *BasicAuthClient, repoURL, chartName, chartVersion string)
 		return nil, fmt.Errorf("%s:%s no valid download urls", chartName, chartVersion)
 	}
 
-	trimmedRepoURL := strings.TrimSuffix(strings.TrimSpace(repoURL), "/")
-	chartURL := trimmedRepoURL + "/" + strings.TrimPrefix(cv.URLs[0], "/")
+	data, err := downloadChart(client, chartDownloadURL(repoURL, cv.URLs[0]))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return chartloader.LoadArchive(bytes.NewReader(data))
+}
 
-	// download tgz
+// chartDownloadURL returns the url of a chart archive, from its url in the
+// index file of a repo
+func chartDownloadURL(repoURL, chartURL string) string {
+	return trimRepoURL(repoURL) + "/" + strings.TrimPrefix(chartURL, "/")
+}
+
+// downloadChart downloads a chart archive
+func downloadChart(client *BasicAuthClient, chartURL string) ([]byte, error) {
 	req, err := http.NewRequest("GET", chartURL, nil)
 
 	if err != nil {
@@ -161,20 +183,16 @@ func LoadChart(client *BasicAuthClient, repoURL, chartName, chartVersion string)
 
 	defer resp.Body.Close()
 
-	data, err := ioutil.ReadAll(resp.Body)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return chartloader.LoadArchive(bytes.NewReader(data))
+	return ioutil.ReadAll(resp.Body)
 }
 
 // LoadChartPublic returns a Helm3 (v2) chart from a remote public repo.
 // If chartVersion is an empty string, the most stable latest version is found.
-//
-// TODO: this is an expensive operation, so after retrieving the digest from the
-// repo index, this should check the digest in the cache
+// The chart is read through DefaultChartCache if it is set.
 func LoadChartPublic(repoURL, chartName, chartVersion string) (*chart.Chart, error) {
+	if DefaultChartCache != nil {
+		return DefaultChartCache.LoadChart(repoURL, chartName, chartVersion)
+	}
+
 	return LoadChart(&BasicAuthClient{}, repoURL, chartName, chartVersion)
 }
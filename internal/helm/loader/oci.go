@@ -0,0 +1,525 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/porter-dev/porter/internal/models"
+	"helm.sh/helm/v3/pkg/chart"
+	chartloader "helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// The media types of the manifests and layers of charts in OCI registries
+const (
+	ociManifestMediaType     = "application/vnd.oci.image.manifest.v1+json"
+	helmChartConfigMediaType = "application/vnd.cncf.helm.config.v1+json"
+	helmChartLayerMediaType  = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+
+	// legacyHelmChartLayerMediaType is the layer media type written by Helm
+	// versions before 3.7
+	legacyHelmChartLayerMediaType = "application/tar+gzip"
+)
+
+// maxOCIResponseSize is the largest response that is read from a registry,
+// which is well above the size of any chart
+const maxOCIResponseSize = 32 * 1024 * 1024
+
+// ociTokenServices maps registry hosts to the hosts of the token services that
+// they use, for registries that don't serve tokens from their own host.
+// Credentials are only sent to a token realm on the registry host or on the
+// registry's token service.
+var ociTokenServices = map[string]string{
+	"docker.io":            "auth.docker.io",
+	"index.docker.io":      "auth.docker.io",
+	"registry-1.docker.io": "auth.docker.io",
+}
+
+// OCIReference is a repository in an OCI registry, parsed from a URL such as
+// oci://registry.example.com/charts
+type OCIReference struct {
+	Host       string
+	Repository string
+}
+
+// ParseOCIReference parses an oci:// URL into a registry host and repository
+func ParseOCIReference(ociURL string) (*OCIReference, error) {
+	parsed, err := url.Parse(strings.TrimSpace(ociURL))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.Scheme != "oci" || parsed.Host == "" {
+		return nil, fmt.Errorf("oci url must be of the form oci://registry/repository")
+	}
+
+	return &OCIReference{
+		Host:       parsed.Host,
+		Repository: strings.Trim(parsed.Path, "/"),
+	}, nil
+}
+
+// IsOCIURL returns true if a repo URL refers to an OCI registry
+func IsOCIURL(repoURL string) bool {
+	return strings.HasPrefix(strings.TrimSpace(repoURL), "oci://")
+}
+
+// Chart returns the reference of a chart in the repository
+func (r *OCIReference) Chart(chartName string) *OCIReference {
+	return &OCIReference{
+		Host:       r.Host,
+		Repository: strings.TrimPrefix(r.Repository+"/"+chartName, "/"),
+	}
+}
+
+// String returns the oci:// URL of the reference
+func (r *OCIReference) String() string {
+	return fmt.Sprintf("oci://%s/%s", r.Host, r.Repository)
+}
+
+// OCIClient reads Helm charts from an OCI registry using the registry HTTP
+// API. Requests are sent without credentials if Username is empty.
+type OCIClient struct {
+	Username string
+	Password string
+
+	// HTTPClient is http.DefaultClient if it is nil
+	HTTPClient *http.Client
+}
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+}
+
+type ociManifest struct {
+	Config ociDescriptor   `json:"config"`
+	Layers []ociDescriptor `json:"layers"`
+}
+
+type ociTagsResp struct {
+	Tags []string `json:"tags"`
+}
+
+type ociCatalogResp struct {
+	Repositories []string `json:"repositories"`
+}
+
+// ListRepositories lists the repositories of the registry that are nested
+// under ref, using the catalog of the registry. Not every registry serves a
+// catalog.
+func (c *OCIClient) ListRepositories(ref *OCIReference) ([]string, error) {
+	data, err := c.get(&OCIReference{Host: ref.Host}, "_catalog", "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	catalogResp := &ociCatalogResp{}
+
+	if err := json.Unmarshal(data, catalogResp); err != nil {
+		return nil, fmt.Errorf("could not read catalog of %s: %v", ref.Host, err)
+	}
+
+	res := make([]string, 0)
+
+	for _, repository := range catalogResp.Repositories {
+		if ref.Repository == "" {
+			res = append(res, repository)
+		} else if strings.HasPrefix(repository, ref.Repository+"/") {
+			res = append(res, strings.TrimPrefix(repository, ref.Repository+"/"))
+		}
+	}
+
+	return res, nil
+}
+
+// ListChartVersions returns the tags of a chart repository that are chart
+// versions, from newest to oldest
+func (c *OCIClient) ListChartVersions(ref *OCIReference) ([]string, error) {
+	data, err := c.get(ref, "tags/list", "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	tagsResp := &ociTagsResp{}
+
+	if err := json.Unmarshal(data, tagsResp); err != nil {
+		return nil, fmt.Errorf("could not read tags of %s: %v", ref, err)
+	}
+
+	versions := make([]*semver.Version, 0)
+
+	for _, tag := range tagsResp.Tags {
+		// Helm replaces the + of build metadata with _ in tags, since + is
+		// not allowed in tags
+		if v, err := semver.NewVersion(strings.Replace(tag, "_", "+", 1)); err == nil {
+			versions = append(versions, v)
+		}
+	}
+
+	sort.Sort(sort.Reverse(semver.Collection(versions)))
+
+	res := make([]string, 0)
+
+	for _, v := range versions {
+		res = append(res, v.Original())
+	}
+
+	return res, nil
+}
+
+// LoadChart pulls a chart from a chart repository. If chartVersion is an
+// empty string, the latest stable version is pulled.
+func (c *OCIClient) LoadChart(ref *OCIReference, chartVersion string) (*chart.Chart, error) {
+	manifest, err := c.getChartManifest(ref, chartVersion)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == helmChartLayerMediaType || layer.MediaType == legacyHelmChartLayerMediaType {
+			data, err := c.get(ref, "blobs/"+layer.Digest, "")
+
+			if err != nil {
+				return nil, err
+			}
+
+			return chartloader.LoadArchive(bytes.NewReader(data))
+		}
+	}
+
+	return nil, fmt.Errorf("%s:%s is not a helm chart", ref, chartVersion)
+}
+
+// LoadPorterChart reads the metadata of the latest version of a chart
+// repository, without pulling the chart. It returns an error if the
+// repository stores images rather than charts.
+func (c *OCIClient) LoadPorterChart(ref *OCIReference, name string) (*models.PorterChartList, error) {
+	versions, err := c.ListChartVersions(ref)
+
+	if err != nil {
+		return nil, err
+	} else if len(versions) == 0 {
+		return nil, fmt.Errorf("%s has no chart versions", ref)
+	}
+
+	res := &models.PorterChartList{
+		Name:     name,
+		Versions: versions,
+	}
+
+	manifest, err := c.getManifest(ref, ociTag(versions[0]))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.Config.MediaType != helmChartConfigMediaType {
+		return nil, fmt.Errorf("%s is not a helm chart repository", ref)
+	}
+
+	data, err := c.get(ref, "blobs/"+manifest.Config.Digest, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &chart.Metadata{}
+
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, fmt.Errorf("could not read chart metadata of %s: %v", ref, err)
+	}
+
+	res.Description = metadata.Description
+	res.Icon = metadata.Icon
+
+	return res, nil
+}
+
+func (c *OCIClient) getChartManifest(ref *OCIReference, chartVersion string) (*ociManifest, error) {
+	if chartVersion == "" {
+		versions, err := c.ListChartVersions(ref)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range versions {
+			if parsed, err := semver.NewVersion(v); err == nil && parsed.Prerelease() == "" {
+				chartVersion = v
+				break
+			}
+		}
+
+		if chartVersion == "" {
+			return nil, fmt.Errorf("%s has no stable chart versions", ref)
+		}
+	}
+
+	return c.getManifest(ref, ociTag(chartVersion))
+}
+
+func (c *OCIClient) getManifest(ref *OCIReference, tag string) (*ociManifest, error) {
+	data, err := c.get(ref, "manifests/"+tag, ociManifestMediaType)
+
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &ociManifest{}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("could not read manifest of %s:%s: %v", ref, tag, err)
+	}
+
+	return manifest, nil
+}
+
+// get sends a request to the registry API of a repository. If the registry
+// requires a token, the token is requested with the client's credentials and
+// the request is retried.
+func (c *OCIClient) get(ref *OCIReference, path, accept string) ([]byte, error) {
+	reqURL := fmt.Sprintf("https://%s/v2/%s", ref.Host, path)
+
+	if ref.Repository != "" {
+		reqURL = fmt.Sprintf("https://%s/v2/%s/%s", ref.Host, ref.Repository, path)
+	}
+
+	resp, err := c.do(reqURL, accept, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		auth, err := c.authorize(ref.Host, challenge)
+
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err = c.do(reqURL, accept, auth)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request to %s failed with status %d", reqURL, resp.StatusCode)
+	}
+
+	// one byte more than the limit is read, so that larger responses are
+	// rejected rather than truncated
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxOCIResponseSize+1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxOCIResponseSize {
+		return nil, fmt.Errorf("response from %s is larger than %d bytes", reqURL, maxOCIResponseSize)
+	}
+
+	return data, nil
+}
+
+func (c *OCIClient) do(reqURL, accept, auth string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", reqURL, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+
+	return c.httpClient().Do(req)
+}
+
+// authorize returns the Authorization header that answers an authentication
+// challenge of the registry
+func (c *OCIClient) authorize(registryHost, challenge string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.Username == "" {
+			return "", fmt.Errorf("registry requires credentials")
+		}
+
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.SetBasicAuth(c.Username, c.Password)
+
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		tok, err := c.getToken(registryHost, params)
+
+		if err != nil {
+			return "", err
+		}
+
+		return "Bearer " + tok, nil
+	}
+
+	return "", fmt.Errorf("unsupported registry authentication %s", challenge)
+}
+
+type ociTokenResp struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+// getToken requests a token from the realm of a bearer challenge. The realm
+// must use https, and the client's credentials are only sent if the realm is
+// the registry itself or the registry's known token service, so that a
+// registry can't redirect them to another host.
+func (c *OCIClient) getToken(registryHost string, params map[string]string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid registry token realm %s", params["realm"])
+	}
+
+	if realm.Scheme != "https" {
+		return "", fmt.Errorf("registry token realm %s must use https", params["realm"])
+	}
+
+	query := realm.Query()
+
+	for _, key := range []string{"service", "scope"} {
+		if val, ok := params[key]; ok {
+			query.Set(key, val)
+		}
+	}
+
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", realm.String(), nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	if c.Username != "" && isOCITokenRealm(registryHost, realm.Hostname()) {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := c.httpClient().Do(req)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry token request failed with status %d", resp.StatusCode)
+	}
+
+	tokResp := &ociTokenResp{}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOCIResponseSize)).Decode(tokResp); err != nil {
+		return "", fmt.Errorf("could not read registry token: %v", err)
+	}
+
+	if tokResp.Token != "" {
+		return tokResp.Token, nil
+	}
+
+	return tokResp.AccessToken, nil
+}
+
+// isOCITokenRealm returns true if the host of a token realm may be sent the
+// credentials of a registry
+func isOCITokenRealm(registryHost, realmHost string) bool {
+	registryHost = strings.ToLower(registryHost)
+	realmHost = strings.ToLower(realmHost)
+
+	if h, _, err := net.SplitHostPort(registryHost); err == nil {
+		registryHost = h
+	}
+
+	return realmHost == registryHost || realmHost == ociTokenServices[registryHost]
+}
+
+func (c *OCIClient) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+
+	return http.DefaultClient
+}
+
+// ociTag returns the tag of a chart version, since tags can't contain the +
+// of build metadata
+func ociTag(chartVersion string) string {
+	return strings.ReplaceAll(chartVersion, "+", "_")
+}
+
+// parseChallenge parses a WWW-Authenticate header, such as
+// Bearer realm="https://auth.example.com/token",service="registry"
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := make(map[string]string)
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+
+	if len(parts) < 2 {
+		return parts[0], params
+	}
+
+	rest := parts[1]
+
+	for rest != "" {
+		eq := strings.Index(rest, "=")
+
+		if eq == -1 {
+			break
+		}
+
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = strings.TrimSpace(rest[eq+1:])
+
+		var val string
+
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+
+			if end == -1 {
+				val, rest = rest[1:], ""
+			} else {
+				val, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else if comma := strings.Index(rest, ","); comma != -1 {
+			val, rest = rest[:comma], rest[comma:]
+		} else {
+			val, rest = rest, ""
+		}
+
+		params[key] = val
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+	}
+
+	return parts[0], params
+}
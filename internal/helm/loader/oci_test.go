@@ -0,0 +1,115 @@
+package loader_test
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/helm/loader"
+)
+
+// newTestRegistry returns a registry that requires a bearer token from realm,
+// which is computed from the registry's own URL
+func newTestRegistry(realm func(registryURL string) string) *httptest.Server {
+	var registry *httptest.Server
+
+	registry = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s",service="registry"`, realm(registry.URL)))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Write([]byte(`{"name":"charts/web","tags":["0.1.0"]}`))
+	}))
+
+	return registry
+}
+
+func newTestTokenServer(authHeaders *[]string) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*authHeaders = append(*authHeaders, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"token":"test-token"}`))
+	}))
+}
+
+func newTestOCIClient() *loader.OCIClient {
+	return &loader.OCIClient{
+		Username: "user",
+		Password: "password",
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+	}
+}
+
+func TestOCIClientSendsCredentialsToRegistryRealm(t *testing.T) {
+	authHeaders := make([]string, 0)
+	tokenServer := newTestTokenServer(&authHeaders)
+	defer tokenServer.Close()
+
+	registry := newTestRegistry(func(registryURL string) string {
+		// the token server listens on the same host as the registry
+		return tokenServer.URL + "/token"
+	})
+	defer registry.Close()
+
+	ref := &loader.OCIReference{
+		Host:       strings.TrimPrefix(registry.URL, "https://"),
+		Repository: "charts/web",
+	}
+
+	if _, err := newTestOCIClient().ListChartVersions(ref); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(authHeaders) != 1 || !strings.HasPrefix(authHeaders[0], "Basic ") {
+		t.Errorf("expected credentials to be sent to the registry's realm, got %v\n", authHeaders)
+	}
+}
+
+func TestOCIClientWithholdsCredentialsFromOtherRealm(t *testing.T) {
+	authHeaders := make([]string, 0)
+	tokenServer := newTestTokenServer(&authHeaders)
+	defer tokenServer.Close()
+
+	registry := newTestRegistry(func(registryURL string) string {
+		// the token server is reached through a different host name
+		return strings.Replace(tokenServer.URL, "127.0.0.1", "localhost", 1) + "/token"
+	})
+	defer registry.Close()
+
+	ref := &loader.OCIReference{
+		Host:       strings.TrimPrefix(registry.URL, "https://"),
+		Repository: "charts/web",
+	}
+
+	if _, err := newTestOCIClient().ListChartVersions(ref); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(authHeaders) != 1 || authHeaders[0] != "" {
+		t.Errorf("expected no credentials to be sent to another host's realm, got %v\n", authHeaders)
+	}
+}
+
+func TestOCIClientRejectsInsecureRealm(t *testing.T) {
+	registry := newTestRegistry(func(registryURL string) string {
+		return strings.Replace(registryURL, "https://", "http://", 1) + "/token"
+	})
+	defer registry.Close()
+
+	ref := &loader.OCIReference{
+		Host:       strings.TrimPrefix(registry.URL, "https://"),
+		Repository: "charts/web",
+	}
+
+	if _, err := newTestOCIClient().ListChartVersions(ref); err == nil {
+		t.Errorf("expected a token realm without https to be rejected\n")
+	}
+}
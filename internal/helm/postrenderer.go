@@ -14,7 +14,6 @@ import (
 	"github.com/porter-dev/porter/internal/repository"
 	"golang.org/x/oauth2"
 	"gopkg.in/yaml.v2"
-	"helm.sh/helm/v3/pkg/postrender"
 
 	"github.com/docker/distribution/reference"
 )
@@ -33,6 +32,11 @@ type DockerSecretsPostRenderer struct {
 	Namespace string
 	DOAuth    *oauth2.Config
 
+	// DryRun adds the image pull secrets to pod specs without creating or
+	// refreshing the secrets, so that dry runs and diffs don't change the
+	// cluster
+	DryRun bool
+
 	registries map[string]*models.Registry
 
 	podSpecs  []resource
@@ -50,7 +54,7 @@ func NewDockerSecretsPostRenderer(
 	namespace string,
 	regs []*models.Registry,
 	doAuth *oauth2.Config,
-) (postrender.PostRenderer, error) {
+) (*DockerSecretsPostRenderer, error) {
 	// Registries is a map of registry URLs to registry ids
 	registries := make(map[string]*models.Registry)
 
@@ -149,6 +153,7 @@ func (d *DockerSecretsPostRenderer) Run(
 					Agent:      d.Agent,
 					Namespace:  d.Namespace,
 					DOAuth:     d.DOAuth,
+					DryRun:     d.DryRun,
 					registries: d.registries,
 					podSpecs:   make([]resource, 0),
 					resources:  make([]resource, 0),
@@ -167,16 +172,24 @@ func (d *DockerSecretsPostRenderer) Run(
 		}
 	}
 
-	// create the necessary secrets
-	secrets, err := d.Agent.CreateImagePullSecrets(
-		d.Repo,
-		d.Namespace,
-		linkedRegs,
-		d.DOAuth,
-	)
+	// create the necessary secrets, or only name them for dry runs
+	secrets := make(map[string]string)
 
-	if err != nil {
-		return renderedManifests, nil
+	if d.DryRun {
+		for key, reg := range linkedRegs {
+			secrets[key] = kubernetes.ImagePullSecretName(reg)
+		}
+	} else {
+		secrets, err = d.Agent.CreateImagePullSecrets(
+			d.Repo,
+			d.Namespace,
+			linkedRegs,
+			d.DOAuth,
+		)
+
+		if err != nil {
+			return renderedManifests, nil
+		}
 	}
 
 	d.updatePodSpecs(secrets)
@@ -0,0 +1,101 @@
+package helm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PromoteValues returns the values that a release is promoted to a target
+// release with. The image tag and the values at the selected paths, such as
+// "container.env.normal", are copied from the source, and every other value of
+// the target is kept. A selected path that the source doesn't set is removed
+// from the target. A target without values, which doesn't exist yet, is given
+// every value of the source.
+func PromoteValues(
+	source, target map[string]interface{},
+	paths []string,
+) (map[string]interface{}, error) {
+	if target == nil {
+		return copyValues(source), nil
+	}
+
+	res := copyValues(target)
+
+	if image, ok := source["image"].(map[string]interface{}); ok {
+		if tag, ok := image["tag"]; ok {
+			childValues(res, "image")["tag"] = copyValue(tag)
+		}
+	}
+
+	for _, path := range paths {
+		keys := strings.Split(path, ".")
+
+		for _, key := range keys {
+			if key == "" {
+				return nil, fmt.Errorf("invalid value path %s", path)
+			}
+		}
+
+		parent := res
+
+		for _, key := range keys[:len(keys)-1] {
+			parent = childValues(parent, key)
+		}
+
+		last := keys[len(keys)-1]
+
+		if val, ok := lookupValue(source, keys); ok {
+			parent[last] = copyValue(val)
+		} else {
+			delete(parent, last)
+		}
+	}
+
+	return res, nil
+}
+
+// lookupValue returns the value at a path of keys
+func lookupValue(values map[string]interface{}, keys []string) (interface{}, bool) {
+	var curr interface{} = values
+
+	for _, key := range keys {
+		currMap, ok := curr.(map[string]interface{})
+
+		if !ok {
+			return nil, false
+		}
+
+		if curr, ok = currMap[key]; !ok {
+			return nil, false
+		}
+	}
+
+	return curr, true
+}
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{})
+
+	for key, val := range values {
+		res[key] = copyValue(val)
+	}
+
+	return res
+}
+
+func copyValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return copyValues(v)
+	case []interface{}:
+		res := make([]interface{}, 0)
+
+		for _, item := range v {
+			res = append(res, copyValue(item))
+		}
+
+		return res
+	default:
+		return v
+	}
+}
@@ -0,0 +1,102 @@
+package helm_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/helm"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const promoteSourceValues = `
+image:
+  repository: gcr.io/staging/web
+  tag: abc123
+replicaCount: 1
+container:
+  env:
+    normal:
+      LOG_LEVEL: debug
+ingress:
+  hosts:
+  - staging.example.com
+`
+
+const promoteTargetValues = `
+image:
+  repository: gcr.io/production/web
+  tag: 0f0f0f
+replicaCount: 5
+container:
+  env:
+    normal:
+      LOG_LEVEL: warn
+resources:
+  limits:
+    memory: 1Gi
+ingress:
+  hosts:
+  - example.com
+`
+
+func TestPromoteValues(t *testing.T) {
+	source, err := chartutil.ReadValues([]byte(promoteSourceValues))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	target, err := chartutil.ReadValues([]byte(promoteTargetValues))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	values, err := helm.PromoteValues(source, target, []string{"container.env", "resources"})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	expected := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "gcr.io/production/web",
+			"tag":        "abc123",
+		},
+		"replicaCount": float64(5),
+		"container": map[string]interface{}{
+			"env": map[string]interface{}{
+				"normal": map[string]interface{}{
+					"LOG_LEVEL": "debug",
+				},
+			},
+		},
+		"ingress": map[string]interface{}{
+			"hosts": []interface{}{"example.com"},
+		},
+	}
+
+	if diff := deep.Equal(values, expected); diff != nil {
+		t.Errorf("incorrect promoted values: %v\n", diff)
+	}
+
+	// the target is not modified
+	if target["replicaCount"] != float64(5) || target["resources"] == nil {
+		t.Errorf("target values were modified\n")
+	}
+
+	// a missing target is created with the values of the source
+	values, err = helm.PromoteValues(source, nil, []string{"resources"})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if diff := deep.Equal(values, source.AsMap()); diff != nil {
+		t.Errorf("incorrect values for new target: %v\n", diff)
+	}
+
+	if _, err := helm.PromoteValues(source, target, []string{"container..env"}); err == nil {
+		t.Errorf("expected error for invalid path\n")
+	}
+}
@@ -0,0 +1,314 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/porter-dev/porter/internal/helm/loader"
+	"github.com/porter-dev/porter/internal/models"
+	ints "github.com/porter-dev/porter/internal/models/integrations"
+	"github.com/porter-dev/porter/internal/repository"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+	storage "google.golang.org/api/storage/v1"
+	"helm.sh/helm/v3/pkg/chart"
+	chartloader "helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// BucketEndpoints are the endpoints that S3 and GCS backed Helm repos are
+// read from. They are empty to use the endpoints of AWS and GCP, and are set
+// to read from S3 and GCS compatible servers instead.
+type BucketEndpoints struct {
+	S3  string
+	GCS string
+}
+
+// Endpoints are the bucket endpoints used by every Helm repo
+var Endpoints = &BucketEndpoints{}
+
+// gcsReadScope is the scope of the GCP tokens that GCS buckets are read with
+const gcsReadScope = "https://www.googleapis.com/auth/devstorage.read_only"
+
+// bucket reads objects from a bucket that stores a Helm repo. Keys are
+// relative to the prefix of the repo in the bucket.
+type bucket interface {
+	// scheme is the URL scheme of the bucket, such as s3
+	scheme() string
+	name() string
+	prefix() string
+	getObject(key string) ([]byte, error)
+}
+
+// bucketLocation is the bucket and prefix of a Helm repo URL, such as
+// s3://bucket/prefix
+type bucketLocation struct {
+	bucketName   string
+	bucketPrefix string
+}
+
+func parseBucketURL(repoURL, scheme string) (*bucketLocation, error) {
+	parsed, err := url.Parse(strings.TrimSpace(repoURL))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.Scheme != scheme || parsed.Host == "" {
+		return nil, fmt.Errorf("repo url must be of the form %s://bucket/path", scheme)
+	}
+
+	return &bucketLocation{
+		bucketName:   parsed.Host,
+		bucketPrefix: strings.Trim(parsed.Path, "/"),
+	}, nil
+}
+
+func (l *bucketLocation) name() string {
+	return l.bucketName
+}
+
+func (l *bucketLocation) prefix() string {
+	return l.bucketPrefix
+}
+
+func (l *bucketLocation) objectName(key string) string {
+	return path.Join(l.bucketPrefix, key)
+}
+
+type s3Bucket struct {
+	*bucketLocation
+	client *s3.S3
+}
+
+func (b *s3Bucket) scheme() string {
+	return "s3"
+}
+
+func (b *s3Bucket) getObject(key string) ([]byte, error) {
+	out, err := b.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(b.bucketName),
+		Key:    aws.String(b.objectName(key)),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer out.Body.Close()
+
+	return ioutil.ReadAll(out.Body)
+}
+
+type gcsBucket struct {
+	*bucketLocation
+	service *storage.Service
+}
+
+func (b *gcsBucket) scheme() string {
+	return "gs"
+}
+
+func (b *gcsBucket) getObject(key string) ([]byte, error) {
+	resp, err := b.service.Objects.Get(b.bucketName, b.objectName(key)).Download()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// getS3Bucket connects to the S3 bucket of the repo with the repo's AWS
+// integration
+func (hr *HelmRepo) getS3Bucket(repo repository.Repository) (bucket, error) {
+	location, err := parseBucketURL(hr.RepoURL, "s3")
+
+	if err != nil {
+		return nil, err
+	}
+
+	awsInt, err := repo.AWSIntegration.ReadAWSIntegration(hr.AWSIntegrationID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := awsInt.GetSession()
+
+	if err != nil {
+		return nil, err
+	}
+
+	conf := &aws.Config{}
+
+	if Endpoints.S3 != "" {
+		conf.Endpoint = aws.String(Endpoints.S3)
+		conf.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	return &s3Bucket{location, s3.New(sess, conf)}, nil
+}
+
+// getGCSBucket connects to the GCS bucket of the repo with a token of the
+// repo's GCP integration, which is kept in the repo's token cache
+func (hr *HelmRepo) getGCSBucket(repo repository.Repository) (bucket, error) {
+	location, err := parseBucketURL(hr.RepoURL, "gs")
+
+	if err != nil {
+		return nil, err
+	}
+
+	gcp, err := repo.GCPIntegration.ReadGCPIntegration(hr.GCPIntegrationID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tok, err := gcp.GetBearerToken(
+		hr.getTokenCache,
+		hr.setTokenCacheFunc(repo),
+		gcsReadScope,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []option.ClientOption{
+		option.WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: tok})),
+	}
+
+	if Endpoints.GCS != "" {
+		opts = append(opts, option.WithEndpoint(Endpoints.GCS))
+	}
+
+	service, err := storage.NewService(context.Background(), opts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &gcsBucket{location, service}, nil
+}
+
+// listChartsBucket lists the charts in the index file of a bucket
+func listChartsBucket(b bucket) ([]*models.PorterChartList, error) {
+	data, err := b.getObject("index.yaml")
+
+	if err != nil {
+		return nil, err
+	}
+
+	repoIndex, err := loader.ParseRepoIndex(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return loader.RepoIndexToPorterChartList(repoIndex), nil
+}
+
+// getChartBucket loads a chart archive from a bucket, using the url of the
+// chart version in the index file of the bucket. The url is either relative
+// to the repo, or an absolute url in the same bucket, as the helm-s3 and
+// helm-gcs plugins write them.
+func getChartBucket(b bucket, chartName, chartVersion string) (*chart.Chart, error) {
+	data, err := b.getObject("index.yaml")
+
+	if err != nil {
+		return nil, err
+	}
+
+	repoIndex, err := loader.ParseRepoIndex(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cv, err := repoIndex.Get(chartName, chartVersion)
+
+	if err != nil {
+		return nil, err
+	} else if len(cv.URLs) == 0 {
+		return nil, fmt.Errorf("%s:%s no valid download urls", chartName, chartVersion)
+	}
+
+	key, err := bucketChartKey(b, cv.URLs[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := b.getObject(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return chartloader.LoadArchive(bytes.NewReader(archive))
+}
+
+// bucketChartKey returns the key of a chart url in an index file, relative to
+// the prefix of the repo
+func bucketChartKey(b bucket, chartURL string) (string, error) {
+	parsed, err := url.Parse(chartURL)
+
+	if err != nil {
+		return "", err
+	}
+
+	if parsed.Scheme == "" {
+		return strings.TrimPrefix(parsed.Path, "/"), nil
+	}
+
+	if parsed.Scheme != b.scheme() || parsed.Host != b.name() {
+		return "", fmt.Errorf("chart url %s is not in bucket %s", chartURL, b.name())
+	}
+
+	key := strings.TrimPrefix(parsed.Path, "/")
+
+	if b.prefix() == "" {
+		return key, nil
+	}
+
+	if !strings.HasPrefix(key, b.prefix()+"/") {
+		return "", fmt.Errorf("chart url %s is not in repo %s://%s/%s", chartURL, b.scheme(), b.name(), b.prefix())
+	}
+
+	return strings.TrimPrefix(key, b.prefix()+"/"), nil
+}
+
+func (hr *HelmRepo) getTokenCache() (tok *ints.TokenCache, err error) {
+	return &ints.TokenCache{
+		Token:  hr.TokenCache.Token,
+		Expiry: hr.TokenCache.Expiry,
+	}, nil
+}
+
+func (hr *HelmRepo) setTokenCacheFunc(
+	repo repository.Repository,
+) ints.SetTokenCacheFunc {
+	return func(token string, expiry time.Time) error {
+		_, err := repo.HelmRepo.UpdateHelmRepoTokenCache(
+			&ints.HelmRepoTokenCache{
+				TokenCache: ints.TokenCache{
+					Token:  []byte(token),
+					Expiry: expiry,
+				},
+				HelmRepoID: hr.ID,
+			},
+		)
+
+		return err
+	}
+}
@@ -0,0 +1,181 @@
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/helm/loader"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/registry"
+	"github.com/porter-dev/porter/internal/repository"
+	"golang.org/x/oauth2"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// OCIHTTPClient is the http client that OCI registries are read with. It is
+// nil to use http.DefaultClient.
+var OCIHTTPClient *http.Client
+
+// listChartsOCI lists the chart repositories nested under the repo URL, with
+// the tags of each chart repository as its versions. Repositories that store
+// images rather than charts are left out.
+func (hr *HelmRepo) listChartsOCI(
+	repo repository.Repository,
+	doAuth *oauth2.Config,
+) ([]*models.PorterChartList, error) {
+	ref, err := loader.ParseOCIReference(hr.RepoURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	client, reg, err := hr.getOCIClient(repo, ref, doAuth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := listOCIChartNames(repo, client, reg, ref, doAuth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*models.PorterChartList, 0)
+
+	for _, name := range names {
+		chartList, err := client.LoadPorterChart(ref.Chart(name), name)
+
+		if err != nil {
+			continue
+		}
+
+		res = append(res, chartList)
+	}
+
+	return res, nil
+}
+
+func (hr *HelmRepo) getChartOCI(
+	repo repository.Repository,
+	doAuth *oauth2.Config,
+	chartName, chartVersion string,
+) (*chart.Chart, error) {
+	ref, err := loader.ParseOCIReference(hr.RepoURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	client, _, err := hr.getOCIClient(repo, ref, doAuth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.LoadChart(ref.Chart(chartName), chartVersion)
+}
+
+// getOCIClient returns a client for the registry of the repo URL, with the
+// credentials of the project's registry that the repo URL is in. The client
+// is anonymous if the project has no such registry.
+func (hr *HelmRepo) getOCIClient(
+	repo repository.Repository,
+	ref *loader.OCIReference,
+	doAuth *oauth2.Config,
+) (*loader.OCIClient, *registry.Registry, error) {
+	client := &loader.OCIClient{
+		HTTPClient: OCIHTTPClient,
+	}
+
+	regs, err := repo.Registry.ListRegistriesByProjectID(hr.ProjectID)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reg := matchOCIRegistry(regs, ref)
+
+	if reg == nil {
+		return client, nil, nil
+	}
+
+	client.Username, client.Password, err = reg.GetBasicAuth(repo, doAuth)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return client, reg, nil
+}
+
+// matchOCIRegistry returns the registry with the same host as ref whose path
+// is the longest prefix of the repository of ref, or nil if no registry
+// matches
+func matchOCIRegistry(regs []*models.Registry, ref *loader.OCIReference) *registry.Registry {
+	var res *registry.Registry
+	matchLen := -1
+
+	for _, reg := range regs {
+		regURL := strings.TrimPrefix(strings.TrimPrefix(reg.URL, "https://"), "http://")
+		parts := strings.SplitN(strings.Trim(regURL, "/"), "/", 2)
+
+		if parts[0] != ref.Host {
+			continue
+		}
+
+		regPath := ""
+
+		if len(parts) == 2 {
+			regPath = parts[1]
+		}
+
+		if regPath != "" && ref.Repository != regPath && !strings.HasPrefix(ref.Repository, regPath+"/") {
+			continue
+		}
+
+		if len(regPath) > matchLen {
+			r := registry.Registry(*reg)
+			res = &r
+			matchLen = len(regPath)
+		}
+	}
+
+	return res
+}
+
+// listOCIChartNames lists the names of the repositories nested under ref from
+// the catalog of the registry. Since not every registry serves a catalog, the
+// repositories of the linked registry are listed if reading the catalog fails.
+func listOCIChartNames(
+	repo repository.Repository,
+	client *loader.OCIClient,
+	reg *registry.Registry,
+	ref *loader.OCIReference,
+	doAuth *oauth2.Config,
+) ([]string, error) {
+	names, err := client.ListRepositories(ref)
+
+	if err == nil || reg == nil {
+		return names, err
+	}
+
+	repos, err := reg.ListRepositories(repo, doAuth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimSuffix(ref.Host+"/"+ref.Repository, "/") + "/"
+	res := make([]string, 0)
+
+	for _, r := range repos {
+		uri := strings.TrimPrefix(strings.TrimPrefix(r.URI, "https://"), "http://")
+
+		if strings.HasPrefix(uri, prefix) {
+			res = append(res, strings.TrimPrefix(uri, prefix))
+		}
+	}
+
+	return res, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/porter-dev/porter/internal/helm/loader"
 	"github.com/porter-dev/porter/internal/models"
+	"golang.org/x/oauth2"
 	"helm.sh/helm/v3/pkg/chart"
 
 	"github.com/porter-dev/porter/internal/repository"
@@ -14,23 +15,54 @@ import (
 type HelmRepo models.HelmRepo
 
 // ListCharts lists Porter charts for a given helm repo
-func (hr *HelmRepo) ListCharts(repo repository.Repository) ([]*models.PorterChartList, error) {
-	if hr.BasicAuthIntegrationID != 0 {
+func (hr *HelmRepo) ListCharts(
+	repo repository.Repository,
+	doAuth *oauth2.Config, // only required if using DOCR
+) ([]*models.PorterChartList, error) {
+	if loader.IsOCIURL(hr.RepoURL) {
+		return hr.listChartsOCI(repo, doAuth)
+	} else if hr.BasicAuthIntegrationID != 0 {
 		return hr.listChartsBasic(repo)
 	}
 
-	return nil, fmt.Errorf("error listing charts")
+	b, err := hr.getBucket(repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return listChartsBucket(b)
 }
 
 // GetChart retrieves a Porter chart for a given helm repo
 func (hr *HelmRepo) GetChart(
 	repo repository.Repository,
+	doAuth *oauth2.Config, // only required if using DOCR
 	chartName, chartVersion string,
 ) (*chart.Chart, error) {
-	if hr.BasicAuthIntegrationID != 0 {
+	if loader.IsOCIURL(hr.RepoURL) {
+		return hr.getChartOCI(repo, doAuth, chartName, chartVersion)
+	} else if hr.BasicAuthIntegrationID != 0 {
 		return hr.getChartBasic(repo, chartName, chartVersion)
 	}
 
+	b, err := hr.getBucket(repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return getChartBucket(b, chartName, chartVersion)
+}
+
+// getBucket connects to the bucket of an S3 or GCS backed repo
+func (hr *HelmRepo) getBucket(repo repository.Repository) (bucket, error) {
+	if hr.AWSIntegrationID != 0 {
+		return hr.getS3Bucket(repo)
+	} else if hr.GCPIntegrationID != 0 {
+		return hr.getGCSBucket(repo)
+	}
+
 	return nil, fmt.Errorf("error listing charts")
 }
 
@@ -0,0 +1,412 @@
+package repo_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/helm/repo"
+	"github.com/porter-dev/porter/internal/models"
+	ints "github.com/porter-dev/porter/internal/models/integrations"
+	"github.com/porter-dev/porter/internal/repository"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// bucketIndex is the index file of a bucket, with a chart url relative to
+// the repo and an absolute chart url in the bucket, as the helm-s3 and
+// helm-gcs plugins write them
+const bucketIndex = `apiVersion: v1
+entries:
+  web:
+  - apiVersion: v2
+    name: web
+    version: 0.2.0
+    description: Web service
+    urls:
+    - %s://charts/stable/web-0.2.0.tgz
+  - apiVersion: v2
+    name: web
+    version: 0.1.0
+    description: Web service
+    urls:
+    - web-0.1.0.tgz
+`
+
+// fakeBucket serves the objects of a bucket, keyed by their full object name
+type fakeBucket map[string][]byte
+
+func newFakeBucket(t *testing.T, scheme string) fakeBucket {
+	t.Helper()
+
+	return fakeBucket{
+		"stable/index.yaml":       []byte(strings.ReplaceAll(bucketIndex, "%s", scheme)),
+		"stable/web-0.1.0.tgz":    chartArchive(t, "0.1.0"),
+		"stable/web-0.2.0.tgz":    chartArchive(t, "0.2.0"),
+		"unrelated/index.yaml":    []byte("apiVersion: v1\nentries: {}\n"),
+		"unrelated/web-0.1.0.tgz": []byte("not a chart"),
+	}
+}
+
+func chartArchive(t *testing.T, version string) []byte {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "porter-chart")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path, err := chartutil.Save(&chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "web",
+			Version:    version,
+		},
+	}, dir)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	return data
+}
+
+func TestS3HelmRepo(t *testing.T) {
+	objects := newFakeBucket(t, "s3")
+
+	// S3 compatible server that serves path-style requests for the charts
+	// bucket
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access-key/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		data, ok := objects[strings.TrimPrefix(r.URL.Path, "/charts/")]
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Write(data)
+	}))
+
+	defer server.Close()
+
+	repo.Endpoints = &repo.BucketEndpoints{S3: server.URL}
+	defer func() { repo.Endpoints = &repo.BucketEndpoints{} }()
+
+	r := memory.NewRepository(true)
+
+	awsInt, err := r.AWSIntegration.CreateAWSIntegration(&ints.AWSIntegration{
+		AWSRegion:          "us-east-1",
+		AWSAccessKeyID:     []byte("access-key"),
+		AWSSecretAccessKey: []byte("secret-key"),
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	hr, err := r.HelmRepo.CreateHelmRepo(&models.HelmRepo{
+		Name:             "charts",
+		RepoURL:          "s3://charts/stable",
+		AWSIntegrationID: awsInt.ID,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	testBucketHelmRepo(t, r, hr)
+}
+
+func TestGCSHelmRepo(t *testing.T) {
+	objects := newFakeBucket(t, "gs")
+
+	// GCS compatible server that issues tokens for the service account, and
+	// serves media downloads from the charts bucket
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"fresh-token","token_type":"Bearer","expires_in":3600}`))
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer fresh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		object := strings.TrimPrefix(r.URL.Path, "/storage/v1/b/charts/o/")
+		data, ok := objects[object]
+
+		if r.URL.Query().Get("alt") != "media" || !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Write(data)
+	}))
+
+	defer server.Close()
+
+	repo.Endpoints = &repo.BucketEndpoints{GCS: server.URL + "/storage/v1/"}
+	defer func() { repo.Endpoints = &repo.BucketEndpoints{} }()
+
+	r := memory.NewRepository(true)
+
+	gcpInt, err := r.GCPIntegration.CreateGCPIntegration(&ints.GCPIntegration{
+		GCPKeyData: serviceAccountKey(t, server.URL+"/token"),
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	hr, err := r.HelmRepo.CreateHelmRepo(&models.HelmRepo{
+		Name:             "charts",
+		RepoURL:          "gs://charts/stable/",
+		GCPIntegrationID: gcpInt.ID,
+		TokenCache: ints.HelmRepoTokenCache{
+			TokenCache: ints.TokenCache{
+				Token:  []byte("expired-token"),
+				Expiry: time.Now().Add(-time.Minute),
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	testBucketHelmRepo(t, r, hr)
+
+	// the expired token is replaced in the token cache
+	hr, err = r.HelmRepo.ReadHelmRepo(hr.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if string(hr.TokenCache.Token) != "fresh-token" || hr.TokenCache.IsExpired() {
+		t.Errorf("token cache not refreshed: got %s expiring at %s\n", hr.TokenCache.Token, hr.TokenCache.Expiry)
+	}
+}
+
+func testBucketHelmRepo(t *testing.T, r *repository.Repository, hr *models.HelmRepo) {
+	t.Helper()
+
+	hrAPI := repo.HelmRepo(*hr)
+
+	charts, err := hrAPI.ListCharts(*r, nil)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(charts) != 1 || charts[0].Name != "web" || strings.Join(charts[0].Versions, ",") != "0.2.0,0.1.0" {
+		t.Fatalf("incorrect charts: %v\n", charts)
+	}
+
+	// the relative and the absolute chart urls are both read from the bucket
+	for _, version := range []string{"0.1.0", "0.2.0"} {
+		ch, err := hrAPI.GetChart(*r, nil, "web", version)
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		if ch.Metadata.Version != version {
+			t.Errorf("incorrect chart version: expected %s, got %s\n", version, ch.Metadata.Version)
+		}
+	}
+
+	if _, err := hrAPI.GetChart(*r, nil, "web", "0.3.0"); err == nil {
+		t.Errorf("expected error for missing chart version\n")
+	}
+}
+
+func serviceAccountKey(t *testing.T, tokenURL string) []byte {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "porter",
+		"private_key_id": "key-id",
+		"private_key":    string(keyPEM),
+		"client_email":   "charts@porter.iam.gserviceaccount.com",
+		"token_uri":      tokenURL,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	return data
+}
+
+func TestOCIHelmRepo(t *testing.T) {
+	// OCI registry that issues tokens for the registry's credentials, and
+	// serves a chart repository and an image repository under charts/
+	var server *httptest.Server
+
+	blobs := map[string][]byte{
+		"sha256:web-0.1.0":   chartArchive(t, "0.1.0"),
+		"sha256:web-0.2.0":   chartArchive(t, "0.2.0"),
+		"sha256:cfg-web":     []byte(`{"name":"web","version":"0.2.0","description":"Web service"}`),
+		"sha256:cfg-image":   []byte(`{"architecture":"amd64"}`),
+		"sha256:layer-image": []byte("not a chart"),
+	}
+
+	manifest := func(configType, configDigest, layerType, layerDigest string) []byte {
+		return []byte(`{"schemaVersion":2,"config":{"mediaType":"` + configType + `","digest":"` + configDigest +
+			`"},"layers":[{"mediaType":"` + layerType + `","digest":"` + layerDigest + `"}]}`)
+	}
+
+	routes := map[string][]byte{
+		"/v2/_catalog":                        []byte(`{"repositories":["charts/web","charts/image","other/web"]}`),
+		"/v2/charts/web/tags/list":            []byte(`{"name":"charts/web","tags":["0.1.0","latest","0.3.0-rc.1","0.2.0"]}`),
+		"/v2/charts/web/manifests/0.1.0":      manifest("application/vnd.cncf.helm.config.v1+json", "sha256:cfg-web", "application/tar+gzip", "sha256:web-0.1.0"),
+		"/v2/charts/web/manifests/0.2.0":      manifest("application/vnd.cncf.helm.config.v1+json", "sha256:cfg-web", "application/vnd.cncf.helm.chart.content.v1.tar+gzip", "sha256:web-0.2.0"),
+		"/v2/charts/web/manifests/0.3.0-rc.1": manifest("application/vnd.cncf.helm.config.v1+json", "sha256:cfg-web", "application/vnd.cncf.helm.chart.content.v1.tar+gzip", "sha256:web-0.2.0"),
+		"/v2/charts/image/tags/list":          []byte(`{"name":"charts/image","tags":["1.0.0"]}`),
+		"/v2/charts/image/manifests/1.0.0":    manifest("application/vnd.oci.image.config.v1+json", "sha256:cfg-image", "application/vnd.oci.image.layer.v1.tar+gzip", "sha256:layer-image"),
+	}
+
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.Write([]byte(`{"token":"registry-token"}`))
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer registry-token" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry",scope="repository:charts:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		data, ok := routes[r.URL.Path]
+
+		if strings.HasPrefix(r.URL.Path, "/v2/charts/") && strings.Contains(r.URL.Path, "/blobs/") {
+			data, ok = blobs[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+		}
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Write(data)
+	}))
+
+	defer server.Close()
+
+	repo.OCIHTTPClient = server.Client()
+	defer func() { repo.OCIHTTPClient = nil }()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	r := memory.NewRepository(true)
+
+	basic, err := r.BasicIntegration.CreateBasicIntegration(&ints.BasicIntegration{
+		ProjectID: 1,
+		Username:  []byte("user"),
+		Password:  []byte("pass"),
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	_, err = r.Registry.CreateRegistry(&models.Registry{
+		ProjectID:          1,
+		URL:                host,
+		BasicIntegrationID: basic.ID,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	hrAPI := &repo.HelmRepo{
+		ProjectID: 1,
+		RepoURL:   "oci://" + host + "/charts",
+	}
+
+	// the image repository is left out of the charts
+	charts, err := hrAPI.ListCharts(*r, nil)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(charts) != 1 || charts[0].Name != "web" || charts[0].Description != "Web service" ||
+		strings.Join(charts[0].Versions, ",") != "0.3.0-rc.1,0.2.0,0.1.0" {
+		t.Fatalf("incorrect charts: %v\n", charts)
+	}
+
+	// an empty version pulls the latest stable version, and charts pushed by
+	// older helm versions are read from their legacy layer
+	for version, expVersion := range map[string]string{"": "0.2.0", "0.1.0": "0.1.0"} {
+		ch, err := hrAPI.GetChart(*r, nil, "web", version)
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		if ch.Metadata.Version != expVersion {
+			t.Errorf("incorrect chart version: expected %s, got %s\n", expVersion, ch.Metadata.Version)
+		}
+	}
+
+	if _, err := hrAPI.GetChart(*r, nil, "image", "1.0.0"); err == nil {
+		t.Errorf("expected error for image repository\n")
+	}
+
+	// a project without a linked registry reads the registry anonymously
+	anonymous := &repo.HelmRepo{
+		ProjectID: 2,
+		RepoURL:   "oci://" + host + "/charts",
+	}
+
+	if _, err := anonymous.GetChart(*r, nil, "web", "0.2.0"); err == nil {
+		t.Errorf("expected error for anonymous access\n")
+	}
+}
@@ -0,0 +1,58 @@
+package helm
+
+import (
+	"sort"
+
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// ReleaseControllers returns the controllers in the rendered manifest of a
+// release whose rollout can be watched, in a stable order
+func ReleaseControllers(manifest string) ([]kubernetes.ControllerRef, error) {
+	resources, err := parseManifest(manifest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]kubernetes.ControllerRef, 0)
+
+	for _, resource := range resources {
+		switch resource.kind {
+		case "Deployment", "StatefulSet", "DaemonSet":
+			res = append(res, kubernetes.ControllerRef{
+				Kind: resource.kind,
+				Name: resource.name,
+			})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Kind != res[j].Kind {
+			return res[i].Kind < res[j].Kind
+		}
+
+		return res[i].Name < res[j].Name
+	})
+
+	return res, nil
+}
+
+// PreviousRevision returns the latest revision of a release before the given
+// revision that was deployed successfully, or 0 if there is none
+func PreviousRevision(history []*release.Release, revision int) int {
+	res := 0
+
+	for _, rel := range history {
+		if rel.Version >= revision || rel.Version <= res || rel.Info == nil {
+			continue
+		}
+
+		if rel.Info.Status == release.StatusSuperseded || rel.Info.Status == release.StatusDeployed {
+			res = rel.Version
+		}
+	}
+
+	return res
+}
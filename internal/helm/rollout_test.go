@@ -0,0 +1,67 @@
+package helm_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const rolloutManifest = `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+`
+
+func TestReleaseControllers(t *testing.T) {
+	controllers, err := helm.ReleaseControllers(rolloutManifest)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	expected := []kubernetes.ControllerRef{
+		{Kind: "Deployment", Name: "api"},
+		{Kind: "Deployment", Name: "web"},
+		{Kind: "StatefulSet", Name: "db"},
+	}
+
+	if diff := deep.Equal(controllers, expected); diff != nil {
+		t.Errorf("incorrect controllers: %v\n", diff)
+	}
+}
+
+func TestPreviousRevision(t *testing.T) {
+	history := []*release.Release{
+		{Version: 1, Info: &release.Info{Status: release.StatusSuperseded}},
+		{Version: 2, Info: &release.Info{Status: release.StatusSuperseded}},
+		{Version: 3, Info: &release.Info{Status: release.StatusFailed}},
+		{Version: 4, Info: &release.Info{Status: release.StatusDeployed}},
+	}
+
+	if rev := helm.PreviousRevision(history, 4); rev != 2 {
+		t.Errorf("incorrect revision: expected 2, got %d\n", rev)
+	}
+
+	if rev := helm.PreviousRevision(history, 1); rev != 0 {
+		t.Errorf("incorrect revision: expected 0, got %d\n", rev)
+	}
+}
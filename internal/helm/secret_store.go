@@ -0,0 +1,324 @@
+package helm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/secrets"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/postrender"
+)
+
+// SecretStoreChecksumAnnotation is added to the pod templates that read from
+// the secret store, so that pods are replaced when a secret value changes
+const SecretStoreChecksumAnnotation = "porter.run/secret-store-checksum"
+
+// SecretStorePostRenderer is a Helm post-renderer that materializes references
+// to a project's secret store. Container env vars with the value
+// PORTERSTORE_${name} are read from a Kubernetes secret that is written with
+// the current value of each referenced secret, so the values never appear in
+// the Helm values or in the manifest stored with the release.
+type SecretStorePostRenderer struct {
+	Agent       *kubernetes.Agent
+	Backend     secrets.Backend
+	ProjectID   uint
+	Namespace   string
+	ReleaseName string
+
+	// DryRun renders the references without writing the Kubernetes secret, so
+	// that dry runs and diffs don't change the cluster
+	DryRun bool
+}
+
+// NewSecretStorePostRenderer returns a post-renderer that reads the secrets
+// of a project from the backend
+func NewSecretStorePostRenderer(
+	agent *kubernetes.Agent,
+	backend secrets.Backend,
+	projectID uint,
+	namespace, releaseName string,
+) postrender.PostRenderer {
+	return &SecretStorePostRenderer{
+		Agent:       agent,
+		Backend:     backend,
+		ProjectID:   projectID,
+		Namespace:   namespace,
+		ReleaseName: releaseName,
+	}
+}
+
+// SecretStoreSecretName is the name of the Kubernetes secret that the secret
+// store references of a release are materialized in
+func SecretStoreSecretName(releaseName string) string {
+	return fmt.Sprintf("%s-porter-store", releaseName)
+}
+
+// Run replaces secret store references in the rendered manifests. Unlike the
+// docker secrets post-renderer, a reference that cannot be resolved fails the
+// deploy, since the release would otherwise run with the reference as its
+// value.
+func (s *SecretStorePostRenderer) Run(
+	renderedManifests *bytes.Buffer,
+) (modifiedManifests *bytes.Buffer, err error) {
+	resources, err := decodeManifests(bytes.NewBuffer(renderedManifests.Bytes()))
+
+	if err != nil {
+		return nil, fmt.Errorf("could not parse manifests for secret store references: %v", err)
+	}
+
+	secretName := SecretStoreSecretName(s.ReleaseName)
+	templates := make([]resource, 0)
+	names := make(map[string]bool)
+
+	for _, template := range getPodTemplates(resources) {
+		found := false
+
+		for _, env := range getContainerEnvs(getNestedResource(template, "spec")) {
+			value, ok := env["value"].(string)
+
+			if !ok {
+				continue
+			}
+
+			name, ok := secrets.RefName(value)
+
+			if !ok {
+				continue
+			}
+
+			if err := secrets.ValidateName(name); err != nil {
+				return nil, err
+			}
+
+			delete(env, "value")
+
+			env["valueFrom"] = resource{
+				"secretKeyRef": resource{
+					"name": secretName,
+					"key":  name,
+				},
+			}
+
+			names[name] = true
+			found = true
+		}
+
+		if found {
+			templates = append(templates, template)
+		}
+	}
+
+	// releases that don't use the secret store are left untouched
+	if len(names) == 0 {
+		return renderedManifests, nil
+	}
+
+	sortedNames := make([]string, 0)
+
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+
+	sort.Strings(sortedNames)
+
+	data := make(map[string][]byte)
+	checksum := sha256.New()
+
+	for _, name := range sortedNames {
+		value, err := s.Backend.Get(s.ProjectID, name)
+
+		if err == secrets.ErrNotFound {
+			return nil, fmt.Errorf("secret %s does not exist in the secret store", name)
+		} else if err != nil {
+			return nil, fmt.Errorf("could not read secret %s: %v", name, err)
+		}
+
+		data[name] = value
+		fmt.Fprintf(checksum, "%s=%x\n", name, sha256.Sum256(value))
+	}
+
+	if !s.DryRun {
+		_, err = s.Agent.ApplySecret(secretName, s.Namespace, map[string]string{
+			"porter":  "true",
+			"release": s.ReleaseName,
+		}, data)
+
+		if err != nil {
+			return nil, fmt.Errorf("could not write secret store values: %v", err)
+		}
+	}
+
+	sum := hex.EncodeToString(checksum.Sum(nil))
+
+	for _, template := range templates {
+		metadata, ok := template["metadata"].(resource)
+
+		if !ok {
+			metadata = make(resource)
+			template["metadata"] = metadata
+		}
+
+		annotations, ok := metadata["annotations"].(resource)
+
+		if !ok {
+			annotations = make(resource)
+			metadata["annotations"] = annotations
+		}
+
+		annotations[SecretStoreChecksumAnnotation] = sum
+	}
+
+	modifiedManifests = bytes.NewBuffer([]byte{})
+	encoder := yaml.NewEncoder(modifiedManifests)
+	defer encoder.Close()
+
+	for _, resource := range resources {
+		if err := encoder.Encode(resource); err != nil {
+			return nil, err
+		}
+	}
+
+	return modifiedManifests, nil
+}
+
+// chainedPostRenderer runs post-renderers in order, passing the output of
+// each to the next
+type chainedPostRenderer []postrender.PostRenderer
+
+func (c chainedPostRenderer) Run(
+	renderedManifests *bytes.Buffer,
+) (modifiedManifests *bytes.Buffer, err error) {
+	modifiedManifests = renderedManifests
+
+	for _, renderer := range c {
+		modifiedManifests, err = renderer.Run(modifiedManifests)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return modifiedManifests, nil
+}
+
+// chainPostRenderers combines post-renderers, returning nil if there are none
+func chainPostRenderers(renderers []postrender.PostRenderer) postrender.PostRenderer {
+	switch len(renderers) {
+	case 0:
+		return nil
+	case 1:
+		return renderers[0]
+	}
+
+	return chainedPostRenderer(renderers)
+}
+
+func decodeManifests(renderedManifests *bytes.Buffer) ([]resource, error) {
+	resources := make([]resource, 0)
+
+	// use the yaml decoder to parse the multi-document yaml.
+	decoder := yaml.NewDecoder(renderedManifests)
+
+	for {
+		res := make(resource)
+		err := decoder.Decode(&res)
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// empty documents are decoded as empty resources
+		if len(res) > 0 {
+			resources = append(resources, res)
+		}
+	}
+
+	return resources, nil
+}
+
+// getPodTemplates returns the pod templates of resources, which contain the
+// metadata and spec of the pods that the resources create
+func getPodTemplates(resources []resource) []resource {
+	res := make([]resource, 0)
+
+	for _, r := range resources {
+		if items, ok := r["items"].([]interface{}); ok {
+			itemResources := make([]resource, 0)
+
+			for _, item := range items {
+				if itemRes, ok := item.(resource); ok {
+					itemResources = append(itemResources, itemRes)
+				}
+			}
+
+			res = append(res, getPodTemplates(itemResources)...)
+			continue
+		}
+
+		kind, _ := r["kind"].(string)
+
+		var template resource
+
+		switch kind {
+		case "Pod":
+			template = r
+		case "DaemonSet", "Deployment", "Job", "ReplicaSet", "ReplicationController", "StatefulSet":
+			template = getNestedResource(r, "spec", "template")
+		case "PodTemplate":
+			template = getNestedResource(r, "template")
+		case "CronJob":
+			template = getNestedResource(r, "spec", "jobTemplate", "spec", "template")
+		}
+
+		if template != nil && getNestedResource(template, "spec") != nil {
+			res = append(res, template)
+		}
+	}
+
+	return res
+}
+
+// getContainerEnvs returns the env entries of every container and init
+// container in a pod spec
+func getContainerEnvs(podSpec resource) []resource {
+	res := make([]resource, 0)
+
+	for _, key := range []string{"initContainers", "containers"} {
+		containers, ok := podSpec[key].([]interface{})
+
+		if !ok {
+			continue
+		}
+
+		for _, container := range containers {
+			containerRes, ok := container.(resource)
+
+			if !ok {
+				continue
+			}
+
+			envs, ok := containerRes["env"].([]interface{})
+
+			if !ok {
+				continue
+			}
+
+			for _, env := range envs {
+				if envRes, ok := env.(resource); ok {
+					res = append(res, envRes)
+				}
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,195 @@
+package helm_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+	"github.com/porter-dev/porter/internal/secrets"
+	"gopkg.in/yaml.v2"
+)
+
+const secretStoreManifest = `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx
+        env:
+        - name: PORT
+          value: "80"
+        - name: DATABASE_URL
+          value: PORTERSTORE_db-url
+`
+
+func TestSecretStorePostRenderer(t *testing.T) {
+	k8sAgent := kubernetes.GetAgentTesting()
+	backend := secrets.NewDatabaseBackend(memory.NewProjectSecretRepository(true))
+
+	if err := backend.Put(1, "db-url", []byte("postgres://")); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	renderer := helm.NewSecretStorePostRenderer(k8sAgent, backend, 1, "default", "web")
+
+	res, err := renderer.Run(bytes.NewBufferString(secretStoreManifest))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if strings.Contains(res.String(), "PORTERSTORE_") {
+		t.Errorf("secret store reference was not replaced:\n%s", res.String())
+	}
+
+	decoder := yaml.NewDecoder(res)
+	deployment := struct {
+		Spec struct {
+			Template struct {
+				Metadata struct {
+					Annotations map[string]string `yaml:"annotations"`
+				} `yaml:"metadata"`
+				Spec struct {
+					Containers []struct {
+						Env []struct {
+							Name      string `yaml:"name"`
+							Value     string `yaml:"value"`
+							ValueFrom struct {
+								SecretKeyRef struct {
+									Name string `yaml:"name"`
+									Key  string `yaml:"key"`
+								} `yaml:"secretKeyRef"`
+							} `yaml:"valueFrom"`
+						} `yaml:"env"`
+					} `yaml:"containers"`
+				} `yaml:"spec"`
+			} `yaml:"template"`
+		} `yaml:"spec"`
+	}{}
+
+	// skip the service
+	decoder.Decode(&struct{}{})
+
+	if err := decoder.Decode(&deployment); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	env := deployment.Spec.Template.Spec.Containers[0].Env
+
+	if env[0].Value != "80" {
+		t.Errorf("plain env var was modified: %s\n", env[0].Value)
+	}
+
+	if ref := env[1].ValueFrom.SecretKeyRef; ref.Name != "web-porter-store" || ref.Key != "db-url" {
+		t.Errorf("incorrect secret key ref: %s/%s\n", ref.Name, ref.Key)
+	}
+
+	checksum := deployment.Spec.Template.Metadata.Annotations[helm.SecretStoreChecksumAnnotation]
+
+	if checksum == "" {
+		t.Errorf("checksum annotation was not added\n")
+	}
+
+	secret, err := k8sAgent.GetSecret("web-porter-store", "default")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if string(secret.Data["db-url"]) != "postgres://" {
+		t.Errorf("incorrect secret value: %s\n", secret.Data["db-url"])
+	}
+
+	// changing the value changes the checksum, so that the pods are replaced
+	if err := backend.Put(1, "db-url", []byte("postgres://new")); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	res, err = renderer.Run(bytes.NewBufferString(secretStoreManifest))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if strings.Contains(res.String(), checksum) {
+		t.Errorf("checksum did not change with the secret value\n")
+	}
+}
+
+func TestSecretStorePostRendererDryRun(t *testing.T) {
+	k8sAgent := kubernetes.GetAgentTesting()
+	backend := secrets.NewDatabaseBackend(memory.NewProjectSecretRepository(true))
+
+	if err := backend.Put(1, "db-url", []byte("postgres://")); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	renderer := &helm.SecretStorePostRenderer{
+		Agent:       k8sAgent,
+		Backend:     backend,
+		ProjectID:   1,
+		Namespace:   "default",
+		ReleaseName: "web",
+		DryRun:      true,
+	}
+
+	res, err := renderer.Run(bytes.NewBufferString(secretStoreManifest))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if !strings.Contains(res.String(), "web-porter-store") {
+		t.Errorf("secret store reference was not rendered:\n%s", res.String())
+	}
+
+	if _, err := k8sAgent.GetSecret("web-porter-store", "default"); err == nil {
+		t.Errorf("dry run wrote the secret store values\n")
+	}
+
+	// manifests that can't be parsed fail the render, rather than deploying
+	// the references as values
+	if _, err := renderer.Run(bytes.NewBufferString("kind: [")); err == nil {
+		t.Errorf("expected error for invalid manifests\n")
+	}
+}
+
+func TestSecretStorePostRendererMissingSecret(t *testing.T) {
+	backend := secrets.NewDatabaseBackend(memory.NewProjectSecretRepository(true))
+	renderer := helm.NewSecretStorePostRenderer(kubernetes.GetAgentTesting(), backend, 1, "default", "web")
+
+	_, err := renderer.Run(bytes.NewBufferString(secretStoreManifest))
+
+	if err == nil || !strings.Contains(err.Error(), "db-url does not exist") {
+		t.Errorf("expected missing secret error, got %v\n", err)
+	}
+
+	// manifests without references are returned as they were rendered
+	manifest := "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n"
+
+	res, err := renderer.Run(bytes.NewBufferString(manifest))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if res.String() != manifest {
+		t.Errorf("manifest without references was modified:\n%s", res.String())
+	}
+}
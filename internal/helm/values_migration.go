@@ -0,0 +1,220 @@
+package helm
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RenamedValue is a value that was moved to a new path in a newer version of
+// a chart
+type RenamedValue struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ValuesMigration is the result of migrating the values of a release to a
+// newer version of its chart
+type ValuesMigration struct {
+	// Values are the values that the release is upgraded with
+	Values map[string]interface{} `json:"values"`
+
+	// Removed are the paths that the user set, and that the new chart no
+	// longer has. They are left out of Values.
+	Removed []string `json:"removed"`
+
+	// Renamed are the paths that the user set, and that the new chart moved to
+	// a path with the same key and parent key
+	Renamed []*RenamedValue `json:"renamed"`
+
+	// UpdatedDefaults are the paths that the user left at the default of the
+	// current chart, and that the new chart changed the default of
+	UpdatedDefaults []string `json:"updated_defaults"`
+}
+
+// MigrateValues three-way merges the values of a release with the defaults of
+// its current chart and of the new chart:
+//
+// - values that are equal to the current default are dropped, so that the new
+// default is used
+// - values that the user changed are kept
+// - values whose key was removed from the defaults are moved to a path that
+// was added with the same key and parent key if there is exactly one, and are
+// removed otherwise. Top-level values are never moved, since a key alone
+// doesn't show that a value was moved rather than replaced.
+//
+// Values that are not in the current defaults at all, such as env variables,
+// are kept as they are.
+func MigrateValues(
+	values, currDefaults, newDefaults map[string]interface{},
+) *ValuesMigration {
+	res := &ValuesMigration{
+		Values:          make(map[string]interface{}),
+		Removed:         make([]string, 0),
+		Renamed:         make([]*RenamedValue, 0),
+		UpdatedDefaults: make([]string, 0),
+	}
+
+	removed := make([]*removedValue, 0)
+
+	migrateValues(res, &removed, res.Values, values, currDefaults, newDefaults, nil)
+
+	added := make(map[[2]string][][]string)
+	addedValuePaths(added, currDefaults, newDefaults, nil)
+
+	sort.Slice(removed, func(i, j int) bool {
+		return valuePath(removed[i].keys) < valuePath(removed[j].keys)
+	})
+
+	for _, rv := range removed {
+		renameKey, ok := valueRenameKey(rv.keys)
+		candidates := added[renameKey]
+
+		if !ok || len(candidates) != 1 {
+			res.Removed = append(res.Removed, valuePath(rv.keys))
+			continue
+		}
+
+		newKeys := candidates[0]
+		parent := res.Values
+
+		for _, key := range newKeys[:len(newKeys)-1] {
+			parent = childValues(parent, key)
+		}
+
+		parent[newKeys[len(newKeys)-1]] = rv.value
+
+		res.Renamed = append(res.Renamed, &RenamedValue{
+			From: valuePath(rv.keys),
+			To:   valuePath(newKeys),
+		})
+
+		delete(added, renameKey)
+	}
+
+	sort.Strings(res.UpdatedDefaults)
+
+	return res
+}
+
+// removedValue is a value that the user set, and whose key was removed from
+// the defaults. Paths are kept as keys, since keys such as annotations may
+// contain dots.
+type removedValue struct {
+	keys  []string
+	value interface{}
+}
+
+// migrateValues writes the values to keep into res, and the values whose key
+// was removed from the defaults into removed
+func migrateValues(
+	migration *ValuesMigration,
+	removed *[]*removedValue,
+	res, values, currDefaults, newDefaults map[string]interface{},
+	path []string,
+) {
+	for key, val := range values {
+		keyPath := append(append([]string{}, path...), key)
+		currDefault, inCurr := currDefaults[key]
+		newDefault, inNew := newDefaults[key]
+
+		if inCurr && !inNew {
+			if !reflect.DeepEqual(val, currDefault) {
+				*removed = append(*removed, &removedValue{
+					keys:  keyPath,
+					value: copyValue(val),
+				})
+			}
+
+			continue
+		}
+
+		valMap, isMap := val.(map[string]interface{})
+		newDefaultMap, newIsMap := newDefault.(map[string]interface{})
+
+		if isMap && newIsMap {
+			currDefaultMap, _ := currDefault.(map[string]interface{})
+			child := make(map[string]interface{})
+
+			migrateValues(migration, removed, child, valMap, currDefaultMap, newDefaultMap, keyPath)
+
+			if len(child) > 0 || len(valMap) == 0 {
+				res[key] = child
+			}
+
+			continue
+		}
+
+		if inCurr && reflect.DeepEqual(val, currDefault) {
+			if !reflect.DeepEqual(currDefault, newDefault) {
+				migration.UpdatedDefaults = append(migration.UpdatedDefaults, valuePath(keyPath))
+			}
+
+			continue
+		}
+
+		res[key] = copyValue(val)
+	}
+}
+
+// addedValuePaths finds the paths of the new defaults that are not in the
+// current defaults, keyed by their last key and parent key. The keys nested in
+// an added map are added as well.
+func addedValuePaths(
+	added map[[2]string][][]string,
+	currDefaults, newDefaults map[string]interface{},
+	path []string,
+) {
+	for key, newDefault := range newDefaults {
+		keyPath := append(append([]string{}, path...), key)
+		currDefault, inCurr := currDefaults[key]
+
+		newDefaultMap, newIsMap := newDefault.(map[string]interface{})
+
+		if !inCurr {
+			if renameKey, ok := valueRenameKey(keyPath); ok {
+				added[renameKey] = append(added[renameKey], keyPath)
+			}
+
+			if newIsMap {
+				addedValuePaths(added, nil, newDefaultMap, keyPath)
+			}
+
+			continue
+		}
+
+		currDefaultMap, currIsMap := currDefault.(map[string]interface{})
+
+		if newIsMap && currIsMap {
+			addedValuePaths(added, currDefaultMap, newDefaultMap, keyPath)
+		}
+	}
+}
+
+// valueRenameKey returns the parent key and key of a path, which must match
+// for a value to be moved from one path to another. Top-level paths have no
+// rename key.
+func valueRenameKey(keyPath []string) ([2]string, bool) {
+	if len(keyPath) < 2 {
+		return [2]string{}, false
+	}
+
+	return [2]string{keyPath[len(keyPath)-2], keyPath[len(keyPath)-1]}, true
+}
+
+// valuePath joins the keys of a path with dots to show it to the user. Keys
+// that contain a dot are quoted, so that the path can't be read as another.
+func valuePath(keyPath []string) string {
+	keys := make([]string, 0)
+
+	for _, key := range keyPath {
+		if strings.Contains(key, ".") {
+			key = strconv.Quote(key)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return strings.Join(keys, ".")
+}
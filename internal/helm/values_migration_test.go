@@ -0,0 +1,185 @@
+package helm_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/helm"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const migrateCurrDefaults = `
+replicaCount: 1
+image:
+  repository: nginx
+  tag: latest
+  pullPolicy: IfNotPresent
+container:
+  port: 80
+  command: ""
+  env:
+    normal: {}
+resources:
+  requests:
+    cpu: 100m
+ingress:
+  enabled: false
+`
+
+const migrateNewDefaults = `
+replicaCount: 1
+image:
+  repository: nginx
+  tag: stable
+deployment:
+  image:
+    pullPolicy: IfNotPresent
+container:
+  command: ""
+  env:
+    normal: {}
+service:
+  port: 80
+resources:
+  requests:
+    cpu: 200m
+`
+
+const migrateValues = `
+replicaCount: 3
+image:
+  repository: gcr.io/project/web
+  tag: latest
+  pullPolicy: Always
+container:
+  port: 8080
+  command: ./start.sh
+  env:
+    normal:
+      LOG_LEVEL: debug
+resources:
+  requests:
+    cpu: 100m
+ingress:
+  enabled: true
+`
+
+func TestMigrateValues(t *testing.T) {
+	readValues := func(data string) map[string]interface{} {
+		values, err := chartutil.ReadValues([]byte(data))
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		return values
+	}
+
+	values := readValues(migrateValues)
+
+	migration := helm.MigrateValues(values, readValues(migrateCurrDefaults), readValues(migrateNewDefaults))
+
+	expected := &helm.ValuesMigration{
+		Values: map[string]interface{}{
+			"replicaCount": float64(3),
+			"image": map[string]interface{}{
+				"repository": "gcr.io/project/web",
+			},
+			"container": map[string]interface{}{
+				"command": "./start.sh",
+				"env": map[string]interface{}{
+					"normal": map[string]interface{}{
+						"LOG_LEVEL": "debug",
+					},
+				},
+			},
+			"deployment": map[string]interface{}{
+				"image": map[string]interface{}{
+					"pullPolicy": "Always",
+				},
+			},
+		},
+		// container.port is not moved to service.port, since only the key
+		// matches
+		Removed: []string{"container.port", "ingress"},
+		Renamed: []*helm.RenamedValue{
+			&helm.RenamedValue{From: "image.pullPolicy", To: "deployment.image.pullPolicy"},
+		},
+		UpdatedDefaults: []string{"image.tag", "resources.requests.cpu"},
+	}
+
+	if diff := deep.Equal(migration, expected); diff != nil {
+		t.Errorf("incorrect values migration: %v\n", diff)
+	}
+
+	// the values of the release are not modified
+	if values["container"].(map[string]interface{})["port"] != float64(8080) {
+		t.Errorf("release values were modified\n")
+	}
+}
+
+const migrateAnnotationsCurrDefaults = `
+ingress:
+  annotations:
+    nginx.ingress.kubernetes.io/rewrite-target: /
+`
+
+const migrateAnnotationsNewDefaults = `
+ingress:
+  annotations: {}
+web:
+  annotations:
+    nginx.ingress.kubernetes.io/rewrite-target: /
+`
+
+const migrateAnnotationsValues = `
+ingress:
+  annotations:
+    nginx.ingress.kubernetes.io/rewrite-target: /api
+    cert-manager.io/cluster-issuer: letsencrypt
+`
+
+func TestMigrateValuesDottedKeys(t *testing.T) {
+	readValues := func(data string) map[string]interface{} {
+		values, err := chartutil.ReadValues([]byte(data))
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		return values
+	}
+
+	migration := helm.MigrateValues(
+		readValues(migrateAnnotationsValues),
+		readValues(migrateAnnotationsCurrDefaults),
+		readValues(migrateAnnotationsNewDefaults),
+	)
+
+	expected := &helm.ValuesMigration{
+		Values: map[string]interface{}{
+			"ingress": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					"cert-manager.io/cluster-issuer": "letsencrypt",
+				},
+			},
+			"web": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					"nginx.ingress.kubernetes.io/rewrite-target": "/api",
+				},
+			},
+		},
+		Removed: []string{},
+		Renamed: []*helm.RenamedValue{
+			&helm.RenamedValue{
+				From: `ingress.annotations."nginx.ingress.kubernetes.io/rewrite-target"`,
+				To:   `web.annotations."nginx.ingress.kubernetes.io/rewrite-target"`,
+			},
+		},
+		UpdatedDefaults: []string{},
+	}
+
+	if diff := deep.Equal(migration, expected); diff != nil {
+		t.Errorf("incorrect values migration: %v\n", diff)
+	}
+}
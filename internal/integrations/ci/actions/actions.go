@@ -35,6 +35,10 @@ type GithubActions struct {
 	FolderPath     string
 	ImageRepoURL   string
 
+	// Previews determines whether a workflow that deploys pull requests as
+	// preview environments is also committed
+	Previews bool
+
 	defaultBranch string
 }
 
@@ -79,6 +83,20 @@ func (g *GithubActions) Setup() (string, error) {
 		return "", err
 	}
 
+	if g.Previews {
+		previewBytes, err := g.GetPreviewActionYAML()
+
+		if err != nil {
+			return "", err
+		}
+
+		_, err = g.commitGithubFile(client, g.getPorterPreviewYMLFileName(), previewBytes)
+
+		if err != nil {
+			return "", err
+		}
+	}
+
 	fileBytes, err := g.GetGithubActionYAML()
 
 	if err != nil {
@@ -122,9 +140,38 @@ func (g *GithubActions) Cleanup() error {
 		return err
 	}
 
+	if g.Previews {
+		err = g.deleteGithubFile(client, g.getPorterPreviewYMLFileName())
+
+		if err != nil {
+			return err
+		}
+	}
+
 	return g.deleteGithubFile(client, g.getPorterYMLFileName())
 }
 
+// CommentOnPullRequest posts a comment on a pull request in the repository
+func (g *GithubActions) CommentOnPullRequest(number int, body string) error {
+	client, err := g.getClient()
+
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Issues.CreateComment(
+		context.TODO(),
+		g.GitRepoOwner,
+		g.GitRepoName,
+		number,
+		&github.IssueComment{
+			Body: github.String(body),
+		},
+	)
+
+	return err
+}
+
 type GithubActionYAMLStep struct {
 	Name string `yaml:"name,omitempty"`
 	ID   string `yaml:"id,omitempty"`
@@ -136,11 +183,17 @@ type GithubActionYAMLOnPushBranches struct {
 	Branches []string `yaml:"branches,omitempty"`
 }
 
+type GithubActionYAMLOnPullRequest struct {
+	Types []string `yaml:"types,omitempty"`
+}
+
 type GithubActionYAMLOnPush struct {
-	Push GithubActionYAMLOnPushBranches `yaml:"push,omitempty"`
+	Push        GithubActionYAMLOnPushBranches `yaml:"push,omitempty"`
+	PullRequest GithubActionYAMLOnPullRequest  `yaml:"pull_request,omitempty"`
 }
 
 type GithubActionYAMLJob struct {
+	If     string                 `yaml:"if,omitempty"`
 	RunsOn string                 `yaml:"runs-on,omitempty"`
 	Steps  []GithubActionYAMLStep `yaml:"steps,omitempty"`
 }
@@ -194,6 +247,55 @@ func (g *GithubActions) GetGithubActionYAML() ([]byte, error) {
 	return yaml.Marshal(actionYAML)
 }
 
+// GetPreviewActionYAML generates a workflow that builds and deploys every open
+// pull request as a preview environment, and removes the preview once the pull
+// request is closed
+func (g *GithubActions) GetPreviewActionYAML() ([]byte, error) {
+	gaSteps := []GithubActionYAMLStep{
+		getCheckoutCodeStep(),
+		getDownloadPorterStep(),
+		getConfigurePorterStep(g.getPorterTokenSecretName()),
+	}
+
+	if g.DockerFilePath == "" {
+		gaSteps = append(gaSteps, getBuildPackPushStep(g.getBuildEnvSecretName(), g.FolderPath, g.ImageRepoURL))
+	} else {
+		gaSteps = append(gaSteps, getDockerBuildPushStep(g.getBuildEnvSecretName(), g.DockerFilePath, g.ImageRepoURL))
+	}
+
+	gaSteps = append(gaSteps, deployPreviewWebhookStep(g.getWebhookSecretName(), g.ImageRepoURL))
+
+	actionYAML := &GithubActionYAML{
+		On: GithubActionYAMLOnPush{
+			PullRequest: GithubActionYAMLOnPullRequest{
+				Types: []string{
+					"opened",
+					"synchronize",
+					"reopened",
+					"closed",
+				},
+			},
+		},
+		Name: "Deploy preview to Porter",
+		Jobs: map[string]GithubActionYAMLJob{
+			"porter-preview-deploy": {
+				If:     "github.event.action != 'closed'",
+				RunsOn: "ubuntu-latest",
+				Steps:  gaSteps,
+			},
+			"porter-preview-delete": {
+				If:     "github.event.action == 'closed'",
+				RunsOn: "ubuntu-latest",
+				Steps: []GithubActionYAMLStep{
+					deletePreviewWebhookStep(g.getWebhookSecretName()),
+				},
+			},
+		},
+	}
+
+	return yaml.Marshal(actionYAML)
+}
+
 func (g *GithubActions) getClient() (*github.Client, error) {
 	// get the oauth integration
 	oauthInt, err := g.Repo.OAuthIntegration.ReadOAuthIntegration(g.GitIntegration.OAuthIntegrationID)
@@ -312,6 +414,12 @@ func (g *GithubActions) getPorterYMLFileName() string {
 	)
 }
 
+func (g *GithubActions) getPorterPreviewYMLFileName() string {
+	return fmt.Sprintf("porter_preview_%s.yml", strings.Replace(
+		strings.ToLower(g.ReleaseName), "-", "_", -1),
+	)
+}
+
 func (g *GithubActions) getPorterTokenSecretName() string {
 	return fmt.Sprintf("PORTER_TOKEN_%d", g.ProjectID)
 }
@@ -87,3 +87,27 @@ func deployPorterWebhookStep(webhookTokenSecretName, repoURL string) GithubActio
 		Run:  fmt.Sprintf(deployPorter, webhookTokenSecretName, repoURL),
 	}
 }
+
+const deployPreview string = `
+curl -f -X POST "https://dashboard.getporter.dev/api/webhooks/preview/${{secrets.%s}}?pr_number=${{github.event.number}}&commit=$(git rev-parse --short HEAD)&repository=%s"
+`
+
+func deployPreviewWebhookStep(webhookTokenSecretName, repoURL string) GithubActionYAMLStep {
+	return GithubActionYAMLStep{
+		Name: "Deploy preview on Porter",
+		ID:   "deploy_preview_porter",
+		Run:  fmt.Sprintf(deployPreview, webhookTokenSecretName, repoURL),
+	}
+}
+
+const deletePreview string = `
+curl -f -X DELETE "https://dashboard.getporter.dev/api/webhooks/preview/${{secrets.%s}}?pr_number=${{github.event.number}}"
+`
+
+func deletePreviewWebhookStep(webhookTokenSecretName string) GithubActionYAMLStep {
+	return GithubActionYAMLStep{
+		Name: "Delete preview on Porter",
+		ID:   "delete_preview_porter",
+		Run:  fmt.Sprintf(deletePreview, webhookTokenSecretName),
+	}
+}
@@ -13,6 +13,7 @@ type SendgridClient struct {
 	PWGHTemplateID          string
 	VerifyEmailTemplateID   string
 	ProjectInviteTemplateID string
+	NotificationTemplateID  string
 	SenderEmail             string
 }
 
@@ -144,3 +145,36 @@ func (client *SendgridClient) SendProjectInviteEmail(url, project, projectOwnerE
 
 	return err
 }
+
+func (client *SendgridClient) SendNotificationEmail(email, subject, message, url string) error {
+	request := sendgrid.GetRequest(os.Getenv("SENDGRID_API_KEY"), "/v3/mail/send", "https://api.sendgrid.com")
+	request.Method = "POST"
+
+	sgMail := &mail.SGMailV3{
+		Personalizations: []*mail.Personalization{
+			{
+				To: []*mail.Email{
+					{
+						Address: email,
+					},
+				},
+				DynamicTemplateData: map[string]interface{}{
+					"subject": subject,
+					"message": message,
+					"url":     url,
+				},
+			},
+		},
+		From: &mail.Email{
+			Address: client.SenderEmail,
+			Name:    "Porter",
+		},
+		TemplateID: client.NotificationTemplateID,
+	}
+
+	request.Body = mail.GetRequestBody(sgMail)
+
+	_, err := sendgrid.API(request)
+
+	return err
+}
@@ -205,6 +205,48 @@ func (a *Agent) GetConfigMap(name string, namespace string) (*v1.ConfigMap, erro
 	)
 }
 
+// GetSecret retrieves the secret given its name and namespace
+func (a *Agent) GetSecret(name string, namespace string) (*v1.Secret, error) {
+	return a.Clientset.CoreV1().Secrets(namespace).Get(
+		context.TODO(),
+		name,
+		metav1.GetOptions{},
+	)
+}
+
+// ApplySecret creates the secret given its name and namespace, or replaces the
+// labels and data of the secret if it already exists
+func (a *Agent) ApplySecret(
+	name, namespace string,
+	labels map[string]string,
+	data map[string][]byte,
+) (*v1.Secret, error) {
+	client := a.Clientset.CoreV1().Secrets(namespace)
+	secret, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+
+	if err != nil && errors.IsNotFound(err) {
+		return client.Create(
+			context.TODO(),
+			&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    labels,
+				},
+				Data: data,
+			},
+			metav1.CreateOptions{},
+		)
+	} else if err != nil {
+		return nil, err
+	}
+
+	secret.Labels = labels
+	secret.Data = data
+
+	return client.Update(context.TODO(), secret, metav1.UpdateOptions{})
+}
+
 // ListConfigMaps simply lists namespaces
 func (a *Agent) ListConfigMaps(namespace string) (*v1.ConfigMapList, error) {
 	return a.Clientset.CoreV1().ConfigMaps(namespace).List(
@@ -223,6 +265,28 @@ func (a *Agent) ListNamespaces() (*v1.NamespaceList, error) {
 	)
 }
 
+// CreateNamespace creates a namespace with the given name
+func (a *Agent) CreateNamespace(name string) (*v1.Namespace, error) {
+	return a.Clientset.CoreV1().Namespaces().Create(
+		context.TODO(),
+		&v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+		},
+		metav1.CreateOptions{},
+	)
+}
+
+// DeleteNamespace deletes the namespace given its name
+func (a *Agent) DeleteNamespace(name string) error {
+	return a.Clientset.CoreV1().Namespaces().Delete(
+		context.TODO(),
+		name,
+		metav1.DeleteOptions{},
+	)
+}
+
 // ListJobsByLabel lists jobs in a namespace matching a label
 type Label struct {
 	Key string
@@ -486,22 +550,10 @@ func (a *Agent) StreamControllerStatus(conn *websocket.Conn, kind string) error
 		0,
 	)
 
-	var informer cache.SharedInformer
+	informer := controllerInformer(factory, kind)
 
-	// Spins up an informer depending on kind. Convert to lowercase for robustness
-	switch strings.ToLower(kind) {
-	case "deployment":
-		informer = factory.Apps().V1().Deployments().Informer()
-	case "statefulset":
-		informer = factory.Apps().V1().StatefulSets().Informer()
-	case "replicaset":
-		informer = factory.Apps().V1().ReplicaSets().Informer()
-	case "daemonset":
-		informer = factory.Apps().V1().DaemonSets().Informer()
-	case "job":
-		informer = factory.Batch().V1().Jobs().Informer()
-	case "cronjob":
-		informer = factory.Batch().V1beta1().CronJobs().Informer()
+	if informer == nil {
+		return fmt.Errorf("unsupported controller kind %s", kind)
 	}
 
 	stopper := make(chan struct{})
@@ -568,6 +620,27 @@ func (a *Agent) StreamControllerStatus(conn *websocket.Conn, kind string) error
 	}
 }
 
+// controllerInformer spins up an informer depending on kind, or returns nil if
+// the kind is not supported. Kind is converted to lowercase for robustness.
+func controllerInformer(factory informers.SharedInformerFactory, kind string) cache.SharedInformer {
+	switch strings.ToLower(kind) {
+	case "deployment":
+		return factory.Apps().V1().Deployments().Informer()
+	case "statefulset":
+		return factory.Apps().V1().StatefulSets().Informer()
+	case "replicaset":
+		return factory.Apps().V1().ReplicaSets().Informer()
+	case "daemonset":
+		return factory.Apps().V1().DaemonSets().Informer()
+	case "job":
+		return factory.Batch().V1().Jobs().Informer()
+	case "cronjob":
+		return factory.Batch().V1beta1().CronJobs().Informer()
+	}
+
+	return nil
+}
+
 // ProvisionECR spawns a new provisioning pod that creates an ECR instance
 func (a *Agent) ProvisionECR(
 	projectID uint,
@@ -863,6 +936,12 @@ func (a *Agent) provision(
 	return job, nil
 }
 
+// ImagePullSecretName is the name of the image pull secret that is created
+// for a registry
+func ImagePullSecretName(reg *models.Registry) string {
+	return fmt.Sprintf("porter-%s-%d", reg.Externalize().Service, reg.ID)
+}
+
 // CreateImagePullSecrets will create the required image pull secrets and
 // return a map from the registry name to the name of the secret.
 func (a *Agent) CreateImagePullSecrets(
@@ -882,7 +961,7 @@ func (a *Agent) CreateImagePullSecrets(
 			return nil, err
 		}
 
-		secretName := fmt.Sprintf("porter-%s-%d", val.Externalize().Service, val.ID)
+		secretName := ImagePullSecretName(val)
 
 		secret, err := a.Clientset.CoreV1().Secrets(namespace).Get(
 			context.TODO(),
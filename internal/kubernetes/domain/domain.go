@@ -12,6 +12,7 @@ import (
 	"k8s.io/api/extensions/v1beta1"
 	"k8s.io/client-go/kubernetes"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -91,6 +92,43 @@ func (e *DNSRecord) CreateDomain(clientset kubernetes.Interface) error {
 	return e.createServiceWithEndpoint(clientset)
 }
 
+// DeleteDomain removes the ingress, service and endpoints that were created
+// for the record by CreateDomain
+func (e *DNSRecord) DeleteDomain(clientset kubernetes.Interface) error {
+	err := clientset.ExtensionsV1beta1().Ingresses("default").Delete(
+		context.TODO(),
+		e.SubdomainPrefix,
+		metav1.DeleteOptions{},
+	)
+
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	err = clientset.CoreV1().Services("default").Delete(
+		context.TODO(),
+		e.SubdomainPrefix,
+		metav1.DeleteOptions{},
+	)
+
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	// endpoints are only created for IP addresses
+	err = clientset.CoreV1().Endpoints("default").Delete(
+		context.TODO(),
+		e.SubdomainPrefix,
+		metav1.DeleteOptions{},
+	)
+
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
 func (e *DNSRecord) createIngress(clientset kubernetes.Interface) error {
 	_, err := clientset.ExtensionsV1beta1().Ingresses("default").Create(
 		context.TODO(),
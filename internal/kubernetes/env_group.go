@@ -0,0 +1,168 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The labels that mark the configmap and secret of a shared env group
+const (
+	EnvGroupLabel        string = "envgroup"
+	EnvGroupVersionLabel string = "envgroup-version"
+)
+
+// EnvGroupConflictError is returned when an env group would overwrite a
+// configmap or secret that does not belong to it
+type EnvGroupConflictError struct {
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+func (e *EnvGroupConflictError) Error() string {
+	return fmt.Sprintf(
+		"%s %s in namespace %s already exists and is not managed by an env group",
+		e.Kind,
+		e.Name,
+		e.Namespace,
+	)
+}
+
+// SyncEnvGroup writes the configmap and linked secret of a shared env group to
+// a namespace, replacing their data with the given version. Secret variables
+// are referenced in the configmap with PORTERSECRET_${name}, in the same way as
+// the env groups created from the dashboard.
+func (a *Agent) SyncEnvGroup(
+	name, namespace string,
+	version uint,
+	variables map[string]string,
+	secrets map[string][]byte,
+) error {
+	labels := map[string]string{
+		"porter":             "true",
+		EnvGroupLabel:        name,
+		EnvGroupVersionLabel: fmt.Sprintf("%d", version),
+	}
+
+	cmData := make(map[string]string)
+
+	for key, val := range variables {
+		cmData[key] = val
+	}
+
+	for key := range secrets {
+		cmData[key] = fmt.Sprintf("PORTERSECRET_%s", name)
+	}
+
+	secretLabels := map[string]string{"configmap": name}
+
+	for key, val := range labels {
+		secretLabels[key] = val
+	}
+
+	secretClient := a.Clientset.CoreV1().Secrets(namespace)
+	cmClient := a.Clientset.CoreV1().ConfigMaps(namespace)
+
+	// check for conflicts before writing anything, so that a conflict does not
+	// leave a partially written group behind
+	secret, err := secretClient.Get(context.TODO(), name, metav1.GetOptions{})
+
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	} else if err == nil && secret.Labels[EnvGroupLabel] != name {
+		return &EnvGroupConflictError{"secret", name, namespace}
+	} else if err != nil {
+		secret = nil
+	}
+
+	configMap, err := cmClient.Get(context.TODO(), name, metav1.GetOptions{})
+
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	} else if err == nil && configMap.Labels[EnvGroupLabel] != name {
+		return &EnvGroupConflictError{"configmap", name, namespace}
+	} else if err != nil {
+		configMap = nil
+	}
+
+	// the secret is written first, so that the configmap never references
+	// secret keys that do not exist
+	if secret == nil {
+		_, err = secretClient.Create(
+			context.TODO(),
+			&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    secretLabels,
+				},
+				Data: secrets,
+			},
+			metav1.CreateOptions{},
+		)
+	} else {
+		secret.Labels = secretLabels
+		secret.Data = secrets
+
+		_, err = secretClient.Update(context.TODO(), secret, metav1.UpdateOptions{})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if configMap == nil {
+		_, err = cmClient.Create(
+			context.TODO(),
+			&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    labels,
+				},
+				Data: cmData,
+			},
+			metav1.CreateOptions{},
+		)
+
+		return err
+	}
+
+	configMap.Labels = labels
+	configMap.Data = cmData
+
+	_, err = cmClient.Update(context.TODO(), configMap, metav1.UpdateOptions{})
+
+	return err
+}
+
+// DeleteEnvGroup deletes the configmap and linked secret of a shared env group
+// from a namespace. Configmaps and secrets that do not belong to the group are
+// left in place.
+func (a *Agent) DeleteEnvGroup(name, namespace string) error {
+	configMap, err := a.GetConfigMap(name, namespace)
+
+	if err == nil && configMap.Labels[EnvGroupLabel] == name {
+		err = a.DeleteConfigMap(name, namespace)
+	}
+
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	secret, err := a.GetSecret(name, namespace)
+
+	if err == nil && secret.Labels[EnvGroupLabel] == name {
+		err = a.DeleteLinkedSecret(name, namespace)
+	}
+
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
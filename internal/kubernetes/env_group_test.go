@@ -0,0 +1,115 @@
+package kubernetes_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncEnvGroup(t *testing.T) {
+	agent := newAgentFixture(t)
+
+	err := agent.SyncEnvGroup("shared", "default", 1, map[string]string{
+		"LOG_LEVEL": "info",
+		"REGION":    "us-east-1",
+	}, map[string][]byte{
+		"DATABASE_URL": []byte("postgres://"),
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// a new version replaces the data of the configmap and secret
+	err = agent.SyncEnvGroup("shared", "default", 2, map[string]string{
+		"LOG_LEVEL": "debug",
+	}, map[string][]byte{
+		"API_KEY": []byte("key"),
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	configMap, err := agent.GetConfigMap("shared", "default")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	expData := map[string]string{
+		"LOG_LEVEL": "debug",
+		"API_KEY":   "PORTERSECRET_shared",
+	}
+
+	if diff := deep.Equal(configMap.Data, expData); diff != nil {
+		t.Errorf("incorrect configmap data: %v\n", diff)
+	}
+
+	if version := configMap.Labels[kubernetes.EnvGroupVersionLabel]; version != "2" {
+		t.Errorf("incorrect version label: expected 2, got %s\n", version)
+	}
+
+	secret, err := agent.GetSecret("shared", "default")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if diff := deep.Equal(secret.Data, map[string][]byte{"API_KEY": []byte("key")}); diff != nil {
+		t.Errorf("incorrect secret data: %v\n", diff)
+	}
+
+	if err := agent.DeleteEnvGroup("shared", "default"); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if _, err := agent.GetConfigMap("shared", "default"); err == nil {
+		t.Errorf("configmap was not deleted\n")
+	}
+}
+
+func TestSyncEnvGroupConflict(t *testing.T) {
+	agent := newAgentFixture(t, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "shared",
+			Namespace: "default",
+			Labels: map[string]string{
+				"porter": "true",
+			},
+		},
+		Data: map[string]string{
+			"LOG_LEVEL": "info",
+		},
+	})
+
+	err := agent.SyncEnvGroup("shared", "default", 1, map[string]string{
+		"LOG_LEVEL": "debug",
+	}, map[string][]byte{})
+
+	if _, ok := err.(*kubernetes.EnvGroupConflictError); !ok {
+		t.Fatalf("expected conflict error, got %v\n", err)
+	}
+
+	if _, err := agent.GetSecret("shared", "default"); err == nil {
+		t.Errorf("secret was written for a conflicting env group\n")
+	}
+
+	// deleting the group does not delete the unmanaged configmap
+	if err := agent.DeleteEnvGroup("shared", "default"); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	configMap, err := agent.GetConfigMap("shared", "default")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if configMap.Data["LOG_LEVEL"] != "info" {
+		t.Errorf("unmanaged configmap was modified\n")
+	}
+}
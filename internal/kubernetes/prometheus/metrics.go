@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
@@ -122,6 +125,53 @@ func QueryPrometheus(
 	return parseQuery(rawQuery, opts.Metric)
 }
 
+// GetNGINXErrorRate returns the highest percentage of 5xx responses served
+// through the given ingresses over the window that ends now
+func GetNGINXErrorRate(
+	clientset kubernetes.Interface,
+	service *v1.Service,
+	namespace string,
+	ingresses []string,
+	window time.Duration,
+) (float64, error) {
+	now := time.Now()
+
+	rawQuery, err := QueryPrometheus(clientset, service, &QueryOpts{
+		Metric:     "nginx:errors",
+		PodList:    ingresses,
+		Namespace:  namespace,
+		StartRange: uint(now.Add(-window).Unix()),
+		EndRange:   uint(now.Unix()),
+		Resolution: "30s",
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	parsed := make([]*promParsedSingletonQuery, 0)
+
+	if err := json.Unmarshal(rawQuery, &parsed); err != nil {
+		return 0, err
+	}
+
+	res := 0.0
+
+	for _, singleton := range parsed {
+		for _, result := range singleton.Results {
+			pct, err := strconv.ParseFloat(fmt.Sprintf("%v", result.ErrorPct), 64)
+
+			if err != nil || math.IsNaN(pct) {
+				continue
+			}
+
+			res = math.Max(res, pct)
+		}
+	}
+
+	return res, nil
+}
+
 type promRawQuery struct {
 	Data struct {
 		Result []struct {
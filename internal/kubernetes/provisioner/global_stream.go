@@ -13,7 +13,9 @@ import (
 
 	redis "github.com/go-redis/redis/v8"
 
+	lr "github.com/porter-dev/porter/internal/logger"
 	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/notifier"
 )
 
 // GlobalStreamName is the name of the Redis stream for global operations
@@ -79,10 +81,13 @@ type ResourceCRUDHandler interface {
 }
 
 // GlobalStreamListener performs an XREADGROUP operation on a given stream and
-// updates models in the database as necessary
+// updates models in the database as necessary. Provisioning errors are sent to
+// the project's notification channels if notif is not nil.
 func GlobalStreamListener(
 	client *redis.Client,
 	repo repository.Repository,
+	notif *notifier.Notifier,
+	logger *lr.Logger,
 	errorChan chan error,
 ) {
 	for {
@@ -319,6 +324,10 @@ func GlobalStreamListener(
 				if err != nil {
 					continue
 				}
+
+				if notif != nil {
+					go notifyInfraError(notif, logger, infra, kind, projID)
+				}
 			} else if fmt.Sprintf("%v", msg.Values["status"]) == "destroyed" {
 				infra, err := repo.Infra.ReadInfra(infraID)
 
@@ -350,3 +359,26 @@ func GlobalStreamListener(
 		}
 	}
 }
+
+func notifyInfraError(
+	notif *notifier.Notifier,
+	logger *lr.Logger,
+	infra *models.Infra,
+	kind string,
+	projID uint,
+) {
+	err := notif.Notify(&notifier.Event{
+		Kind:      models.NotificationEventInfraError,
+		ProjectID: projID,
+		Title:     fmt.Sprintf("Provisioning %s failed", strings.ToUpper(kind)),
+		Message:   fmt.Sprintf("Infrastructure %d of kind %s could not be provisioned.", infra.ID, kind),
+		Fields: map[string]string{
+			"infra_id": fmt.Sprintf("%d", infra.ID),
+			"kind":     kind,
+		},
+	})
+
+	if err != nil {
+		logger.Warn().Err(err).Msgf("could not send infra error notification for infra %d", infra.ID)
+	}
+}
@@ -0,0 +1,344 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ControllerRef identifies a controller that a rollout waits for
+type ControllerRef struct {
+	Kind string
+	Name string
+}
+
+// RolloutError is returned when the controllers of a rollout do not become
+// healthy
+type RolloutError struct {
+	Reason string
+}
+
+func (e *RolloutError) Error() string {
+	return e.Reason
+}
+
+// container waiting reasons that will not resolve without a new deploy
+var failedWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+}
+
+// WatchRollout waits until every Deployment, StatefulSet and DaemonSet in the
+// list has rolled out, using the same informers as StreamControllerStatus. A
+// RolloutError is returned as soon as a pod created after the watch started
+// is crash looping or can't pull its image, or once the deadline passes with
+// controllers that are still rolling out.
+func (a *Agent) WatchRollout(
+	namespace string,
+	controllers []ControllerRef,
+	deadline time.Duration,
+) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		a.Clientset,
+		0,
+		informers.WithNamespace(namespace),
+	)
+
+	stopper := make(chan struct{})
+	defer close(stopper)
+
+	changed := make(chan struct{}, 1)
+
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { notifyChanged(changed) },
+		UpdateFunc: func(oldObj, newObj interface{}) { notifyChanged(changed) },
+		DeleteFunc: func(obj interface{}) { notifyChanged(changed) },
+	}
+
+	informersByKind := make(map[string]cache.SharedInformer)
+
+	for _, ref := range controllers {
+		kind := strings.ToLower(ref.Kind)
+
+		if _, ok := informersByKind[kind]; ok {
+			continue
+		}
+
+		informer := controllerInformer(factory, kind)
+
+		if informer == nil {
+			return fmt.Errorf("unsupported controller kind %s", ref.Kind)
+		}
+
+		informer.AddEventHandler(handler)
+		informersByKind[kind] = informer
+	}
+
+	podInformer := factory.Core().V1().Pods().Informer()
+	podInformer.AddEventHandler(handler)
+
+	factory.Start(stopper)
+
+	ctx, cancel := context.WithTimeout(context.Background(), deadline)
+	defer cancel()
+
+	watch := &rolloutWatch{
+		namespace:   namespace,
+		controllers: controllers,
+		informers:   informersByKind,
+		pods:        podInformer,
+		startedAt:   time.Now(),
+	}
+
+	for {
+		select {
+		case <-changed:
+			done, err := watch.check()
+
+			if err != nil || done {
+				return err
+			}
+		case <-ctx.Done():
+			_, err := watch.check()
+
+			if err != nil {
+				return err
+			}
+
+			return &RolloutError{
+				Reason: fmt.Sprintf(
+					"rollout did not finish within %s: %s",
+					deadline,
+					strings.Join(watch.pending, "; "),
+				),
+			}
+		}
+	}
+}
+
+func notifyChanged(changed chan struct{}) {
+	select {
+	case changed <- struct{}{}:
+	default:
+	}
+}
+
+// rolloutWatch is the state of a call to WatchRollout
+type rolloutWatch struct {
+	namespace   string
+	controllers []ControllerRef
+	informers   map[string]cache.SharedInformer
+	pods        cache.SharedInformer
+	startedAt   time.Time
+
+	// pending describes the controllers that are still rolling out
+	pending []string
+}
+
+// check returns true if every controller has rolled out, or an error if a pod
+// of a controller has failed. The controllers that are still rolling out are
+// stored in pending.
+func (w *rolloutWatch) check() (bool, error) {
+	w.pending = make([]string, 0)
+	selectors := make([]labels.Selector, 0)
+
+	for _, ref := range w.controllers {
+		informer := w.informers[strings.ToLower(ref.Kind)]
+		obj, exists, err := informer.GetStore().GetByKey(w.namespace + "/" + ref.Name)
+
+		if err != nil {
+			return false, err
+		}
+
+		if !exists {
+			w.pending = append(w.pending, fmt.Sprintf("%s %s does not exist", ref.Kind, ref.Name))
+			continue
+		}
+
+		done, reason, selector := ControllerRolloutStatus(obj)
+
+		if selector != nil {
+			selectors = append(selectors, selector)
+		}
+
+		if !done {
+			w.pending = append(w.pending, fmt.Sprintf("%s %s: %s", ref.Kind, ref.Name, reason))
+		}
+	}
+
+	if err := w.checkPods(selectors); err != nil {
+		return false, err
+	}
+
+	return len(w.pending) == 0, nil
+}
+
+// checkPods returns an error if a pod of the controllers that was created
+// after the watch started has failed, and adds pods that are not ready to
+// pending
+func (w *rolloutWatch) checkPods(selectors []labels.Selector) error {
+	notReady := make([]string, 0)
+
+	for _, obj := range w.pods.GetStore().List() {
+		pod, ok := obj.(*v1.Pod)
+
+		if !ok || pod.CreationTimestamp.Time.Before(w.startedAt.Add(-time.Second)) {
+			continue
+		}
+
+		matches := false
+
+		for _, selector := range selectors {
+			if selector.Matches(labels.Set(pod.Labels)) {
+				matches = true
+				break
+			}
+		}
+
+		if !matches {
+			continue
+		}
+
+		if reason := PodFailureReason(pod); reason != "" {
+			return &RolloutError{
+				Reason: fmt.Sprintf("pod %s failed: %s", pod.Name, reason),
+			}
+		}
+
+		if !isPodReady(pod) && pod.Status.Phase == v1.PodRunning {
+			notReady = append(notReady, pod.Name)
+		}
+	}
+
+	if len(notReady) > 0 {
+		sort.Strings(notReady)
+
+		w.pending = append(w.pending, fmt.Sprintf(
+			"pods failing readiness: %s",
+			strings.Join(notReady, ", "),
+		))
+	}
+
+	return nil
+}
+
+// ControllerRolloutStatus returns true if the controller has rolled out, and
+// otherwise the reason that it has not. The selector of the controller's pods
+// is also returned, if it can be parsed.
+func ControllerRolloutStatus(obj interface{}) (bool, string, labels.Selector) {
+	switch c := obj.(type) {
+	case *appsv1.Deployment:
+		return deploymentRolloutStatus(c), deploymentRolloutReason(c), parseSelector(c.Spec.Selector)
+	case *appsv1.StatefulSet:
+		return statefulSetRolloutStatus(c), "waiting for updated replicas to be ready", parseSelector(c.Spec.Selector)
+	case *appsv1.DaemonSet:
+		return daemonSetRolloutStatus(c), "waiting for updated pods to be available", parseSelector(c.Spec.Selector)
+	}
+
+	return true, "", nil
+}
+
+func parseSelector(selector *metav1.LabelSelector) labels.Selector {
+	if selector == nil {
+		return nil
+	}
+
+	res, err := metav1.LabelSelectorAsSelector(selector)
+
+	if err != nil {
+		return nil
+	}
+
+	return res
+}
+
+func deploymentRolloutStatus(d *appsv1.Deployment) bool {
+	if d.Generation > d.Status.ObservedGeneration {
+		return false
+	}
+
+	replicas := int32(1)
+
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+
+	return d.Status.UpdatedReplicas >= replicas &&
+		d.Status.Replicas <= d.Status.UpdatedReplicas &&
+		d.Status.AvailableReplicas >= d.Status.UpdatedReplicas
+}
+
+func deploymentRolloutReason(d *appsv1.Deployment) string {
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Reason == "ProgressDeadlineExceeded" {
+			return cond.Message
+		}
+	}
+
+	return fmt.Sprintf(
+		"%d of %d updated replicas are available",
+		d.Status.AvailableReplicas,
+		d.Status.UpdatedReplicas,
+	)
+}
+
+func statefulSetRolloutStatus(s *appsv1.StatefulSet) bool {
+	if s.Generation > s.Status.ObservedGeneration {
+		return false
+	}
+
+	replicas := int32(1)
+
+	if s.Spec.Replicas != nil {
+		replicas = *s.Spec.Replicas
+	}
+
+	return s.Status.UpdatedReplicas >= replicas && s.Status.ReadyReplicas >= replicas
+}
+
+func daemonSetRolloutStatus(d *appsv1.DaemonSet) bool {
+	if d.Generation > d.Status.ObservedGeneration {
+		return false
+	}
+
+	return d.Status.UpdatedNumberScheduled >= d.Status.DesiredNumberScheduled &&
+		d.Status.NumberAvailable >= d.Status.DesiredNumberScheduled
+}
+
+// PodFailureReason returns the reason that a container of the pod is stuck,
+// or an empty string if no container is stuck
+func PodFailureReason(pod *v1.Pod) string {
+	statuses := make([]v1.ContainerStatus, 0)
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil && failedWaitingReasons[waiting.Reason] {
+			return fmt.Sprintf("container %s is in %s", status.Name, waiting.Reason)
+		}
+	}
+
+	return ""
+}
+
+func isPodReady(pod *v1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == v1.PodReady {
+			return cond.Status == v1.ConditionTrue
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,113 @@
+package kubernetes_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newRolloutDeployment(updated, available int32) *appsv1.Deployment {
+	replicas := int32(2)
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "web",
+			Namespace:  "default",
+			Generation: 2,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "web"},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           updated,
+			UpdatedReplicas:    updated,
+			AvailableReplicas:  available,
+		},
+	}
+}
+
+func TestControllerRolloutStatus(t *testing.T) {
+	if done, _, _ := kubernetes.ControllerRolloutStatus(newRolloutDeployment(2, 1)); done {
+		t.Errorf("expected deployment with unavailable replicas to be rolling out\n")
+	}
+
+	done, _, selector := kubernetes.ControllerRolloutStatus(newRolloutDeployment(2, 2))
+
+	if !done {
+		t.Errorf("expected deployment with available replicas to be rolled out\n")
+	}
+
+	if selector == nil || selector.String() != "app=web" {
+		t.Errorf("incorrect selector: got %v\n", selector)
+	}
+}
+
+func TestWatchRolloutSucceeds(t *testing.T) {
+	agent := newAgentFixture(t, newRolloutDeployment(2, 2))
+
+	err := agent.WatchRollout("default", []kubernetes.ControllerRef{
+		{Kind: "Deployment", Name: "web"},
+	}, 5*time.Second)
+
+	if err != nil {
+		t.Errorf("expected rollout to succeed, got %v\n", err)
+	}
+}
+
+func TestWatchRolloutCrashLoop(t *testing.T) {
+	agent := newAgentFixture(
+		t,
+		newRolloutDeployment(2, 1),
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "web-abc",
+				Namespace:         "default",
+				Labels:            map[string]string{"app": "web"},
+				CreationTimestamp: metav1.Now(),
+			},
+			Status: v1.PodStatus{
+				Phase: v1.PodRunning,
+				ContainerStatuses: []v1.ContainerStatus{{
+					Name: "web",
+					State: v1.ContainerState{
+						Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+					},
+				}},
+			},
+		},
+	)
+
+	err := agent.WatchRollout("default", []kubernetes.ControllerRef{
+		{Kind: "Deployment", Name: "web"},
+	}, 5*time.Second)
+
+	if _, ok := err.(*kubernetes.RolloutError); !ok {
+		t.Fatalf("expected rollout error, got %v\n", err)
+	}
+
+	expected := "pod web-abc failed: container web is in CrashLoopBackOff"
+
+	if err.Error() != expected {
+		t.Errorf("incorrect reason: expected %s, got %s\n", expected, err.Error())
+	}
+}
+
+func TestWatchRolloutDeadline(t *testing.T) {
+	agent := newAgentFixture(t, newRolloutDeployment(2, 1))
+
+	err := agent.WatchRollout("default", []kubernetes.ControllerRef{
+		{Kind: "Deployment", Name: "web"},
+	}, time.Second)
+
+	if _, ok := err.(*kubernetes.RolloutError); !ok {
+		t.Fatalf("expected rollout error, got %v\n", err)
+	}
+}
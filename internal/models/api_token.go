@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIToken is a stored, revocable token that grants API access to a project
+type APIToken struct {
+	gorm.Model
+
+	// UniqueID is the identifier that is embedded in the encoded token
+	UniqueID string `gorm:"unique"`
+
+	ProjectID       uint
+	CreatedByUserID uint
+
+	Name string
+
+	// Scopes is a comma-separated list of resource:verb pairs that the token
+	// is limited to. If empty, the token is not limited to any scopes.
+	Scopes string
+
+	Expiry     *time.Time
+	LastUsedAt *time.Time
+	Revoked    bool
+}
+
+// APITokenExternal represents the APIToken type that is sent over REST
+type APITokenExternal struct {
+	ID              uint       `json:"id"`
+	ProjectID       uint       `json:"project_id"`
+	CreatedByUserID uint       `json:"created_by_user_id"`
+	Name            string     `json:"name"`
+	Scopes          []string   `json:"scopes"`
+	CreatedAt       time.Time  `json:"created_at"`
+	Expiry          *time.Time `json:"expiry"`
+	LastUsedAt      *time.Time `json:"last_used_at"`
+	Expired         bool       `json:"expired"`
+	Revoked         bool       `json:"revoked"`
+}
+
+// Externalize generates an external APIToken to be shared over REST
+func (t *APIToken) Externalize() *APITokenExternal {
+	return &APITokenExternal{
+		ID:              t.ID,
+		ProjectID:       t.ProjectID,
+		CreatedByUserID: t.CreatedByUserID,
+		Name:            t.Name,
+		Scopes:          t.ScopeList(),
+		CreatedAt:       t.CreatedAt,
+		Expiry:          t.Expiry,
+		LastUsedAt:      t.LastUsedAt,
+		Expired:         t.IsExpired(),
+		Revoked:         t.Revoked,
+	}
+}
+
+// IsExpired returns true if the token has an expiry that has passed
+func (t *APIToken) IsExpired() bool {
+	return t.Expiry != nil && t.Expiry.Before(time.Now())
+}
+
+// IsValid returns true if the token can still be used
+func (t *APIToken) IsValid() bool {
+	return !t.Revoked && !t.IsExpired()
+}
+
+// ScopeList returns the scopes of the token as a list
+func (t *APIToken) ScopeList() []string {
+	res := make([]string, 0)
+
+	for _, scope := range strings.Split(t.Scopes, ",") {
+		if scope != "" {
+			res = append(res, scope)
+		}
+	}
+
+	return res
+}
+
+// HasScope returns true if the token may perform the verb on the resource
+func (t *APIToken) HasScope(resource PermissionResource, verb PermissionVerb) bool {
+	scopes := t.ScopeList()
+
+	if len(scopes) == 0 {
+		return true
+	}
+
+	for _, scope := range scopes {
+		if scope == GetScope(resource, verb) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetScope returns the resource:verb scope for a resource and verb
+func GetScope(resource PermissionResource, verb PermissionVerb) string {
+	return fmt.Sprintf("%s:%s", resource, verb)
+}
+
+// IsValidScope returns true if the scope is a resource:verb pair that is
+// granted to the admin role
+func IsValidScope(scope string) bool {
+	for resource, verbs := range RolePolicies[RoleAdmin] {
+		for _, verb := range verbs {
+			if scope == GetScope(resource, verb) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,45 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestAPITokenHasScope(t *testing.T) {
+	unscoped := &models.APIToken{}
+
+	if !unscoped.HasScope(models.ClusterResource, models.DeleteVerb) {
+		t.Errorf("expected unscoped token to allow cluster:delete")
+	}
+
+	scoped := &models.APIToken{
+		Scopes: "release:read,release:write",
+	}
+
+	if !scoped.HasScope(models.ReleaseResource, models.WriteVerb) {
+		t.Errorf("expected scoped token to allow release:write")
+	}
+
+	if scoped.HasScope(models.ReleaseResource, models.DeleteVerb) {
+		t.Errorf("expected scoped token to deny release:delete")
+	}
+}
+
+func TestAPITokenIsValid(t *testing.T) {
+	past := time.Now().Add(-1 * time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	if tok := (&models.APIToken{Expiry: &future}); !tok.IsValid() {
+		t.Errorf("expected token with future expiry to be valid")
+	}
+
+	if tok := (&models.APIToken{Expiry: &past}); tok.IsValid() {
+		t.Errorf("expected expired token to be invalid")
+	}
+
+	if tok := (&models.APIToken{Revoked: true}); tok.IsValid() {
+		t.Errorf("expected revoked token to be invalid")
+	}
+}
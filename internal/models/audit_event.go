@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditEvent is a record of a mutating API call made against a project
+type AuditEvent struct {
+	gorm.Model
+
+	ProjectID uint
+
+	// UserID is the user that made the call, or that issued the token the
+	// call was made with
+	UserID uint
+
+	// APITokenID is the stored API token that the call was made with, if any
+	APITokenID uint
+
+	// Resource is the kind of resource that was acted on, such as "releases",
+	// and ResourceName identifies the resource, such as a release name
+	Resource     string
+	ResourceName string
+
+	// Action is the operation that was performed, such as "upgrade"
+	Action string
+
+	Method string
+	Path   string
+
+	// Changes is a JSON list of the values of the resource that the call
+	// changed, with their values before and after the call. The values of
+	// sensitive fields are redacted.
+	Changes string
+
+	Status int
+}
+
+// AuditEventExternal represents the AuditEvent type that is sent over REST
+type AuditEventExternal struct {
+	ID           uint      `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	ProjectID    uint      `json:"project_id"`
+	UserID       uint      `json:"user_id"`
+	APITokenID   uint      `json:"api_token_id"`
+	Resource     string    `json:"resource"`
+	ResourceName string    `json:"resource_name"`
+	Action       string    `json:"action"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Changes      string    `json:"changes,omitempty"`
+	Status       int       `json:"status"`
+	Success      bool      `json:"success"`
+}
+
+// Externalize generates an external AuditEvent to be shared over REST
+func (e *AuditEvent) Externalize() *AuditEventExternal {
+	return &AuditEventExternal{
+		ID:           e.ID,
+		CreatedAt:    e.CreatedAt,
+		ProjectID:    e.ProjectID,
+		UserID:       e.UserID,
+		APITokenID:   e.APITokenID,
+		Resource:     e.Resource,
+		ResourceName: e.ResourceName,
+		Action:       e.Action,
+		Method:       e.Method,
+		Path:         e.Path,
+		Changes:      e.Changes,
+		Status:       e.Status,
+		Success:      e.Status < 400,
+	}
+}
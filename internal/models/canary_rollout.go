@@ -0,0 +1,126 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The states of a canary rollout
+const (
+	CanaryStatusProgressing string = "progressing"
+	CanaryStatusPromoted    string = "promoted"
+	CanaryStatusRolledBack  string = "rolled_back"
+	CanaryStatusAborted     string = "aborted"
+	CanaryStatusFailed      string = "failed"
+)
+
+// CanaryRollout is an upgrade of a web release that is first deployed as a
+// second, weighted release, and promoted once every traffic step has passed
+// without the error rate crossing the threshold
+type CanaryRollout struct {
+	gorm.Model
+
+	ProjectID uint
+	ClusterID uint
+	Namespace string
+
+	// ReleaseName is the stable release, and CanaryName is the release that
+	// the upgrade is deployed as until it is promoted
+	ReleaseName string
+	CanaryName  string
+
+	// StableRevision is the revision of the stable release before promotion,
+	// which is rolled back to if the promoted release fails
+	StableRevision int
+	ChartVersion   string
+
+	// Steps is a comma-separated list of the traffic weights, in percent, that
+	// the canary is given in order
+	Steps               string
+	StepIntervalSeconds uint
+
+	// ErrorThreshold is the percentage of 5xx responses above which the
+	// rollout is rolled back
+	ErrorThreshold float64
+
+	CurrentWeight int
+
+	// ErrorRate is the last percentage of 5xx responses that was observed
+	ErrorRate float64
+
+	Status string
+
+	// Reason explains why the rollout was rolled back, aborted or failed
+	Reason string
+
+	UserID     uint
+	FinishedAt *time.Time
+}
+
+// CanaryRolloutExternal represents the CanaryRollout type that is sent over
+// REST
+type CanaryRolloutExternal struct {
+	ID                  uint       `json:"id"`
+	ReleaseName         string     `json:"release_name"`
+	CanaryName          string     `json:"canary_name"`
+	Namespace           string     `json:"namespace"`
+	StableRevision      int        `json:"stable_revision"`
+	ChartVersion        string     `json:"chart_version,omitempty"`
+	Steps               []int      `json:"steps"`
+	StepIntervalSeconds uint       `json:"step_interval_seconds"`
+	ErrorThreshold      float64    `json:"error_threshold"`
+	CurrentWeight       int        `json:"current_weight"`
+	ErrorRate           float64    `json:"error_rate"`
+	Status              string     `json:"status"`
+	Reason              string     `json:"reason,omitempty"`
+	UserID              uint       `json:"user_id"`
+	StartedAt           time.Time  `json:"started_at"`
+	FinishedAt          *time.Time `json:"finished_at,omitempty"`
+}
+
+// Externalize generates an external CanaryRollout to be shared over REST
+func (c *CanaryRollout) Externalize() *CanaryRolloutExternal {
+	return &CanaryRolloutExternal{
+		ID:                  c.ID,
+		ReleaseName:         c.ReleaseName,
+		CanaryName:          c.CanaryName,
+		Namespace:           c.Namespace,
+		StableRevision:      c.StableRevision,
+		ChartVersion:        c.ChartVersion,
+		Steps:               c.StepWeights(),
+		StepIntervalSeconds: c.StepIntervalSeconds,
+		ErrorThreshold:      c.ErrorThreshold,
+		CurrentWeight:       c.CurrentWeight,
+		ErrorRate:           c.ErrorRate,
+		Status:              c.Status,
+		Reason:              c.Reason,
+		UserID:              c.UserID,
+		StartedAt:           c.CreatedAt,
+		FinishedAt:          c.FinishedAt,
+	}
+}
+
+// StepWeights returns the traffic weights of the rollout's steps
+func (c *CanaryRollout) StepWeights() []int {
+	res := make([]int, 0)
+
+	if c.Steps == "" {
+		return res
+	}
+
+	for _, step := range strings.Split(c.Steps, ",") {
+		if weight, err := strconv.Atoi(step); err == nil {
+			res = append(res, weight)
+		}
+	}
+
+	return res
+}
+
+// IsFinished returns true if the rollout is no longer progressing
+func (c *CanaryRollout) IsFinished() bool {
+	return c.Status != CanaryStatusProgressing
+}
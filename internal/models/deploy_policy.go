@@ -0,0 +1,235 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeployDays are the names of the days of the week that deploys can be
+// allowed on, indexed by time.Weekday
+var DeployDays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// DeployFreeze is a period of time during which deploys are blocked
+type DeployFreeze struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+// DeployPolicy restricts when the releases of a project can be deployed.
+// Deploys that a policy blocks can only go ahead with an emergency override,
+// which is recorded on the deployment along with its reason.
+type DeployPolicy struct {
+	gorm.Model
+
+	ProjectID uint
+
+	// ClusterID is the cluster that the policy applies to, or 0 if the policy
+	// applies to every cluster of the project
+	ClusterID uint
+
+	Name string
+
+	// Timezone is the IANA time zone that the allowed days and hours are in
+	Timezone string
+
+	// AllowedDays is a comma-separated list of the days that deploys are
+	// allowed on, such as "mon,tue". Deploys are allowed on every day if it is
+	// empty.
+	AllowedDays string
+
+	// AllowedHours is the time of day that deploys are allowed in, such as
+	// "09:00-17:00". A range that ends before it starts spans midnight. Deploys
+	// are allowed at any time of day if it is empty.
+	AllowedHours string
+
+	// Freezes is a JSON-encoded list of freeze windows
+	Freezes []byte
+}
+
+// DeployPolicyExternal represents the DeployPolicy type that is sent over REST
+type DeployPolicyExternal struct {
+	ID           uint            `json:"id"`
+	ProjectID    uint            `json:"project_id"`
+	ClusterID    uint            `json:"cluster_id"`
+	Name         string          `json:"name"`
+	Timezone     string          `json:"timezone"`
+	AllowedDays  []string        `json:"allowed_days"`
+	AllowedHours string          `json:"allowed_hours"`
+	Freezes      []*DeployFreeze `json:"freezes"`
+}
+
+// Externalize generates an external DeployPolicy to be shared over REST
+func (p *DeployPolicy) Externalize() *DeployPolicyExternal {
+	freezes, err := p.GetFreezes()
+
+	if err != nil {
+		freezes = []*DeployFreeze{}
+	}
+
+	return &DeployPolicyExternal{
+		ID:           p.ID,
+		ProjectID:    p.ProjectID,
+		ClusterID:    p.ClusterID,
+		Name:         p.Name,
+		Timezone:     p.Timezone,
+		AllowedDays:  p.DayList(),
+		AllowedHours: p.AllowedHours,
+		Freezes:      freezes,
+	}
+}
+
+// DayList returns the days that deploys are allowed on
+func (p *DeployPolicy) DayList() []string {
+	if p.AllowedDays == "" {
+		return []string{}
+	}
+
+	return strings.Split(p.AllowedDays, ",")
+}
+
+// GetFreezes returns the freeze windows of the policy
+func (p *DeployPolicy) GetFreezes() ([]*DeployFreeze, error) {
+	freezes := make([]*DeployFreeze, 0)
+
+	if len(p.Freezes) == 0 {
+		return freezes, nil
+	}
+
+	if err := json.Unmarshal(p.Freezes, &freezes); err != nil {
+		return nil, err
+	}
+
+	return freezes, nil
+}
+
+// SetFreezes encodes the freeze windows of the policy
+func (p *DeployPolicy) SetFreezes(freezes []*DeployFreeze) error {
+	if freezes == nil {
+		freezes = []*DeployFreeze{}
+	}
+
+	bytes, err := json.Marshal(freezes)
+
+	if err != nil {
+		return err
+	}
+
+	p.Freezes = bytes
+
+	return nil
+}
+
+// AppliesTo returns true if the policy restricts deploys to the cluster
+func (p *DeployPolicy) AppliesTo(clusterID uint) bool {
+	return p.ClusterID == 0 || p.ClusterID == clusterID
+}
+
+// BlockedReason returns the reason that the policy blocks deploys at the given
+// time, or an empty string if deploys are allowed
+func (p *DeployPolicy) BlockedReason(now time.Time) (string, error) {
+	freezes, err := p.GetFreezes()
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, freeze := range freezes {
+		if !now.Before(freeze.StartsAt) && now.Before(freeze.EndsAt) {
+			reason := fmt.Sprintf("deploys are frozen until %s", freeze.EndsAt.Format(time.RFC3339))
+
+			if freeze.Reason != "" {
+				reason = fmt.Sprintf("%s: %s", reason, freeze.Reason)
+			}
+
+			return reason, nil
+		}
+	}
+
+	loc, err := time.LoadLocation(p.Timezone)
+
+	if err != nil {
+		return "", err
+	}
+
+	local := now.In(loc)
+
+	if days := p.DayList(); len(days) > 0 {
+		allowed := false
+
+		for _, day := range days {
+			if day == DeployDays[local.Weekday()] {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return fmt.Sprintf(
+				"deploys are only allowed on %s (%s)",
+				strings.Join(days, ", "),
+				loc.String(),
+			), nil
+		}
+	}
+
+	if p.AllowedHours != "" {
+		start, end, err := ParseDeployHours(p.AllowedHours)
+
+		if err != nil {
+			return "", err
+		}
+
+		minute := local.Hour()*60 + local.Minute()
+
+		var allowed bool
+
+		if start <= end {
+			allowed = minute >= start && minute < end
+		} else {
+			allowed = minute >= start || minute < end
+		}
+
+		if !allowed {
+			return fmt.Sprintf(
+				"deploys are only allowed between %s (%s)",
+				strings.Replace(p.AllowedHours, "-", " and ", 1),
+				loc.String(),
+			), nil
+		}
+	}
+
+	return "", nil
+}
+
+// ParseDeployHours parses a range of hours such as "09:00-17:00", returning
+// the start and the end of the range in minutes since midnight
+func ParseDeployHours(hours string) (int, int, error) {
+	bounds := strings.Split(hours, "-")
+
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("allowed hours must be a range such as 09:00-17:00")
+	}
+
+	minutes := make([]int, 0)
+
+	for _, bound := range bounds {
+		t, err := time.Parse("15:04", strings.TrimSpace(bound))
+
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid time %s: must be formatted as 15:04", bound)
+		}
+
+		minutes = append(minutes, t.Hour()*60+t.Minute())
+	}
+
+	if minutes[0] == minutes[1] {
+		return 0, 0, fmt.Errorf("allowed hours cannot start and end at the same time")
+	}
+
+	return minutes[0], minutes[1], nil
+}
@@ -0,0 +1,76 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestDeployPolicyBlockedReason(t *testing.T) {
+	policy := &models.DeployPolicy{
+		Timezone:     "America/New_York",
+		AllowedDays:  "mon,tue,wed,thu,fri",
+		AllowedHours: "09:00-17:00",
+	}
+
+	err := policy.SetFreezes([]*models.DeployFreeze{
+		{
+			StartsAt: time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC),
+			EndsAt:   time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC),
+			Reason:   "holidays",
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	tests := []struct {
+		description string
+		now         time.Time
+		expected    string
+	}{
+		// 14:00 in New York on a Monday
+		{"allowed", time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC), ""},
+		// 14:00 UTC is still allowed, but 22:00 UTC is 18:00 in New York
+		{"after hours", time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC), "only allowed between 09:00 and 17:00"},
+		{"weekend", time.Date(2026, 10, 18, 18, 0, 0, 0, time.UTC), "only allowed on mon"},
+		{"freeze", time.Date(2026, 12, 24, 18, 0, 0, 0, time.UTC), "frozen until 2026-12-27T00:00:00Z: holidays"},
+		{"freeze end", time.Date(2026, 12, 28, 18, 0, 0, 0, time.UTC), ""},
+	}
+
+	for _, test := range tests {
+		reason, err := policy.BlockedReason(test.now)
+
+		if err != nil {
+			t.Fatalf("%s: %v\n", test.description, err)
+		}
+
+		if test.expected == "" && reason != "" {
+			t.Errorf("%s: expected deploy to be allowed, got %s\n", test.description, reason)
+		} else if !strings.Contains(reason, test.expected) {
+			t.Errorf("%s: expected reason to contain %s, got %s\n", test.description, test.expected, reason)
+		}
+	}
+}
+
+func TestDeployPolicyOvernightHours(t *testing.T) {
+	policy := &models.DeployPolicy{
+		Timezone:     "UTC",
+		AllowedHours: "22:00-06:00",
+	}
+
+	for hour, allowed := range map[int]bool{23: true, 2: true, 6: false, 12: false} {
+		reason, err := policy.BlockedReason(time.Date(2026, 10, 19, hour, 0, 0, 0, time.UTC))
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		if allowed != (reason == "") {
+			t.Errorf("hour %d: expected allowed to be %t, got reason %q\n", hour, allowed, reason)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The sources that can trigger a deployment
+const (
+	DeploymentSourceUI      string = "ui"
+	DeploymentSourceCLI     string = "cli"
+	DeploymentSourceWebhook string = "webhook"
+)
+
+// The actions that a deployment can perform on a release
+const (
+	DeploymentActionInstall  string = "install"
+	DeploymentActionUpgrade  string = "upgrade"
+	DeploymentActionRollback string = "rollback"
+)
+
+// The statuses of a deployment. A deployment is progressing while the rollout
+// of the release is watched.
+const (
+	DeploymentStatusProgressing string = "progressing"
+	DeploymentStatusSucceeded   string = "succeeded"
+	DeploymentStatusFailed      string = "failed"
+)
+
+// Deployment is a record of a change made to a release, kept independently of
+// the Helm release history
+type Deployment struct {
+	gorm.Model
+
+	ProjectID uint
+	ClusterID uint
+
+	// ReleaseID is the id of the stored release, if the release has one
+	ReleaseID   uint
+	ReleaseName string
+	Namespace   string
+
+	// Revision is the Helm revision that the deployment created
+	Revision int
+
+	ImageRepository string
+	ImageTag        string
+	CommitSHA       string
+
+	Source string
+	Action string
+
+	// UserID is the user that triggered the deployment, which is unset for
+	// deployments triggered by a webhook
+	UserID uint
+
+	Status string
+	Error  string
+
+	// OverrideReason is the reason given for deploying while a deploy policy
+	// blocked deploys
+	OverrideReason string
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// DeploymentExternal represents the Deployment type that is sent over REST
+type DeploymentExternal struct {
+	ID              uint      `json:"id"`
+	ReleaseID       uint      `json:"release_id"`
+	ReleaseName     string    `json:"release_name"`
+	Namespace       string    `json:"namespace"`
+	Revision        int       `json:"revision"`
+	ImageRepository string    `json:"image_repository"`
+	ImageTag        string    `json:"image_tag"`
+	CommitSHA       string    `json:"commit_sha"`
+	Source          string    `json:"source"`
+	Action          string    `json:"action"`
+	UserID          uint      `json:"user_id"`
+	Status          string    `json:"status"`
+	Error           string    `json:"error,omitempty"`
+	OverrideReason  string    `json:"override_reason,omitempty"`
+	StartedAt       time.Time `json:"started_at"`
+	DurationMS      int64     `json:"duration_ms"`
+}
+
+// Externalize generates an external Deployment to be shared over REST
+func (d *Deployment) Externalize() *DeploymentExternal {
+	return &DeploymentExternal{
+		ID:              d.ID,
+		ReleaseID:       d.ReleaseID,
+		ReleaseName:     d.ReleaseName,
+		Namespace:       d.Namespace,
+		Revision:        d.Revision,
+		ImageRepository: d.ImageRepository,
+		ImageTag:        d.ImageTag,
+		CommitSHA:       d.CommitSHA,
+		Source:          d.Source,
+		Action:          d.Action,
+		UserID:          d.UserID,
+		Status:          d.Status,
+		Error:           d.Error,
+		OverrideReason:  d.OverrideReason,
+		StartedAt:       d.StartedAt,
+		DurationMS:      d.FinishedAt.Sub(d.StartedAt).Milliseconds(),
+	}
+}
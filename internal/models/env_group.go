@@ -0,0 +1,216 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EnvGroup is a named set of environment variables in a cluster that can be
+// attached to many releases. Every change to the variables creates a new
+// version of the group.
+type EnvGroup struct {
+	gorm.Model
+
+	ProjectID uint
+	ClusterID uint
+	Name      string
+
+	// Version is the latest version of the group
+	Version uint
+
+	Attachments []EnvGroupAttachment
+}
+
+// EnvGroupVersion is the set of variables of an env group at a version.
+// Versions are never modified.
+type EnvGroupVersion struct {
+	gorm.Model
+
+	EnvGroupID uint
+	Version    uint
+
+	// Variables is a JSON object of the plain variables
+	Variables []byte
+
+	// ------------------------------------------------------------------
+	// All fields encrypted before storage.
+	// ------------------------------------------------------------------
+
+	// SecretVariables is a JSON object of the secret variables
+	SecretVariables []byte
+}
+
+// EnvGroupAttachment attaches an env group to a release in the group's
+// cluster
+type EnvGroupAttachment struct {
+	gorm.Model
+
+	EnvGroupID  uint
+	ReleaseName string
+	Namespace   string
+}
+
+// EnvGroupExternal represents the EnvGroup type that is sent over REST, along
+// with the variables of its latest version. The values of secret variables are
+// not sent.
+type EnvGroupExternal struct {
+	ID          uint                          `json:"id"`
+	ProjectID   uint                          `json:"project_id"`
+	ClusterID   uint                          `json:"cluster_id"`
+	Name        string                        `json:"name"`
+	Version     uint                          `json:"version"`
+	Variables   map[string]string             `json:"variables"`
+	SecretKeys  []string                      `json:"secret_keys"`
+	Attachments []*EnvGroupAttachmentExternal `json:"attachments"`
+}
+
+// EnvGroupAttachmentExternal represents the EnvGroupAttachment type that is
+// sent over REST
+type EnvGroupAttachmentExternal struct {
+	ReleaseName string `json:"release_name"`
+	Namespace   string `json:"namespace"`
+}
+
+// EnvGroupVersionExternal represents the EnvGroupVersion type that is sent over
+// REST. The values of secret variables are not sent.
+type EnvGroupVersionExternal struct {
+	Version    uint              `json:"version"`
+	Variables  map[string]string `json:"variables"`
+	SecretKeys []string          `json:"secret_keys"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// Externalize generates an external EnvGroup to be shared over REST, with the
+// variables of the given version
+func (e *EnvGroup) Externalize(version *EnvGroupVersion) *EnvGroupExternal {
+	ext := &EnvGroupExternal{
+		ID:          e.ID,
+		ProjectID:   e.ProjectID,
+		ClusterID:   e.ClusterID,
+		Name:        e.Name,
+		Version:     e.Version,
+		Variables:   map[string]string{},
+		SecretKeys:  []string{},
+		Attachments: make([]*EnvGroupAttachmentExternal, 0),
+	}
+
+	if version != nil {
+		versionExt := version.Externalize()
+		ext.Variables = versionExt.Variables
+		ext.SecretKeys = versionExt.SecretKeys
+	}
+
+	for _, attachment := range e.Attachments {
+		ext.Attachments = append(ext.Attachments, &EnvGroupAttachmentExternal{
+			ReleaseName: attachment.ReleaseName,
+			Namespace:   attachment.Namespace,
+		})
+	}
+
+	return ext
+}
+
+// Namespaces returns the namespaces of the releases that the group is
+// attached to, without duplicates
+func (e *EnvGroup) Namespaces() []string {
+	res := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, attachment := range e.Attachments {
+		if !seen[attachment.Namespace] {
+			seen[attachment.Namespace] = true
+			res = append(res, attachment.Namespace)
+		}
+	}
+
+	return res
+}
+
+// Attachment returns the attachment of the group to a release, or nil if the
+// group is not attached to it
+func (e *EnvGroup) Attachment(namespace, releaseName string) *EnvGroupAttachment {
+	for i, attachment := range e.Attachments {
+		if attachment.Namespace == namespace && attachment.ReleaseName == releaseName {
+			return &e.Attachments[i]
+		}
+	}
+
+	return nil
+}
+
+// Externalize generates an external EnvGroupVersion to be shared over REST
+func (v *EnvGroupVersion) Externalize() *EnvGroupVersionExternal {
+	// the variables are always written with SetVariables, so they can be read
+	variables, _ := v.GetVariables()
+	secrets, _ := v.GetSecretVariables()
+
+	secretKeys := make([]string, 0)
+
+	for key := range secrets {
+		secretKeys = append(secretKeys, key)
+	}
+
+	sort.Strings(secretKeys)
+
+	return &EnvGroupVersionExternal{
+		Version:    v.Version,
+		Variables:  variables,
+		SecretKeys: secretKeys,
+		CreatedAt:  v.CreatedAt,
+	}
+}
+
+// SetVariables stores the plain and secret variables of the version
+func (v *EnvGroupVersion) SetVariables(variables, secrets map[string]string) error {
+	if variables == nil {
+		variables = map[string]string{}
+	}
+
+	if secrets == nil {
+		secrets = map[string]string{}
+	}
+
+	varBytes, err := json.Marshal(variables)
+
+	if err != nil {
+		return err
+	}
+
+	secretBytes, err := json.Marshal(secrets)
+
+	if err != nil {
+		return err
+	}
+
+	v.Variables = varBytes
+	v.SecretVariables = secretBytes
+
+	return nil
+}
+
+// GetVariables returns the plain variables of the version
+func (v *EnvGroupVersion) GetVariables() (map[string]string, error) {
+	return unmarshalEnvVariables(v.Variables)
+}
+
+// GetSecretVariables returns the secret variables of the version
+func (v *EnvGroupVersion) GetSecretVariables() (map[string]string, error) {
+	return unmarshalEnvVariables(v.SecretVariables)
+}
+
+func unmarshalEnvVariables(data []byte) (map[string]string, error) {
+	res := make(map[string]string)
+
+	if len(data) == 0 {
+		return res, nil
+	}
+
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
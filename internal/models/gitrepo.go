@@ -69,6 +69,9 @@ type GitActionConfig struct {
 
 	// The build context
 	FolderPath string `json:"folder_path"`
+
+	// Whether pull requests should be deployed as preview environments
+	PreviewsEnabled bool `json:"previews_enabled"`
 }
 
 // GitActionConfigExternal is an external GitActionConfig to be shared over REST
@@ -87,15 +90,19 @@ type GitActionConfigExternal struct {
 
 	// The build context
 	FolderPath string `json:"folder_path"`
+
+	// Whether pull requests should be deployed as preview environments
+	PreviewsEnabled bool `json:"previews_enabled"`
 }
 
 // Externalize generates an external GitActionConfig to be shared over REST
 func (r *GitActionConfig) Externalize() *GitActionConfigExternal {
 	return &GitActionConfigExternal{
-		GitRepo:        r.GitRepo,
-		ImageRepoURI:   r.ImageRepoURI,
-		GitRepoID:      r.GitRepoID,
-		DockerfilePath: r.DockerfilePath,
-		FolderPath:     r.FolderPath,
+		GitRepo:         r.GitRepo,
+		ImageRepoURI:    r.ImageRepoURI,
+		GitRepoID:       r.GitRepoID,
+		DockerfilePath:  r.DockerfilePath,
+		FolderPath:      r.FolderPath,
+		PreviewsEnabled: r.PreviewsEnabled,
 	}
 }
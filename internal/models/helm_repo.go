@@ -1,6 +1,8 @@
 package models
 
 import (
+	"strings"
+
 	"github.com/porter-dev/porter/internal/models/integrations"
 	"gorm.io/gorm"
 )
@@ -18,7 +20,8 @@ type HelmRepo struct {
 
 	// RepoURL is the URL to the helm repo. This varies based on the integration
 	// type. For example, for AWS S3 this may be prefixed with s3://, or for
-	// GCS it may be gs://
+	// GCS it may be gs://. Repos with an oci:// URL are read from an OCI
+	// registry, with the credentials of the project's linked registry.
 	RepoURL string `json:"repo_url"`
 
 	// ------------------------------------------------------------------
@@ -52,7 +55,9 @@ type HelmRepoExternal struct {
 func (hr *HelmRepo) Externalize() *HelmRepoExternal {
 	var serv integrations.IntegrationService
 
-	if hr.BasicAuthIntegrationID != 0 {
+	if strings.HasPrefix(hr.RepoURL, "oci://") {
+		serv = integrations.OCI
+	} else if hr.BasicAuthIntegrationID != 0 {
 		serv = integrations.HelmRepo
 	} else if hr.AWSIntegrationID != 0 {
 		serv = integrations.S3
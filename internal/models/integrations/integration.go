@@ -10,6 +10,7 @@ const (
 	GCS       IntegrationService = "gcs"
 	S3        IntegrationService = "s3"
 	HelmRepo  IntegrationService = "helm"
+	OCI       IntegrationService = "oci"
 	EKS       IntegrationService = "eks"
 	Kube      IntegrationService = "kube"
 	GCR       IntegrationService = "gcr"
@@ -97,6 +98,11 @@ var PorterHelmRepoIntegrations = []PorterIntegration{
 		Category:      "helm",
 		Service:       S3,
 	},
+	PorterIntegration{
+		AuthMechanism: "registry",
+		Category:      "helm",
+		Service:       OCI,
+	},
 }
 
 // PorterGitRepoIntegrations are the supported git repo integrations
@@ -6,6 +6,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// InviteExpiry is how long an invite can be accepted for after it is sent
+const InviteExpiry = 24 * time.Hour
+
+// The states that an invite can be in
+const (
+	InviteStatusPending  string = "pending"
+	InviteStatusExpired  string = "expired"
+	InviteStatusAccepted string = "accepted"
+)
+
 // Invite type that extends gorm.Model
 type Invite struct {
 	gorm.Model
@@ -16,25 +26,48 @@ type Invite struct {
 
 	ProjectID uint
 	UserID    uint
+
+	// Kind is the role that is granted when the invite is accepted
+	Kind string
+
+	// ClusterID and Namespace optionally scope the accepted invite to a single
+	// cluster, and a single namespace within that cluster
+	ClusterID uint
+	Namespace string
+
+	// InviterID is the user that created the invite
+	InviterID uint
 }
 
 // InviteExternal represents the Invite type that is sent over REST
 type InviteExternal struct {
-	ID       uint   `json:"id"`
-	Token    string `json:"token"`
-	Expired  bool   `json:"expired"`
-	Email    string `json:"email"`
-	Accepted bool   `json:"accepted"`
+	ID        uint       `json:"id"`
+	Token     string     `json:"token"`
+	Expired   bool       `json:"expired"`
+	Expiry    *time.Time `json:"expiry"`
+	Email     string     `json:"email"`
+	Accepted  bool       `json:"accepted"`
+	Status    string     `json:"status"`
+	Kind      string     `json:"kind"`
+	ClusterID uint       `json:"cluster_id,omitempty"`
+	Namespace string     `json:"namespace,omitempty"`
+	InviterID uint       `json:"inviter_id,omitempty"`
 }
 
 // Externalize generates an external Invite to be shared over REST
 func (i *Invite) Externalize() *InviteExternal {
 	return &InviteExternal{
-		ID:       i.Model.ID,
-		Token:    i.Token,
-		Email:    i.Email,
-		Expired:  i.IsExpired(),
-		Accepted: i.IsAccepted(),
+		ID:        i.Model.ID,
+		Token:     i.Token,
+		Email:     i.Email,
+		Expired:   i.IsExpired(),
+		Expiry:    i.Expiry,
+		Accepted:  i.IsAccepted(),
+		Status:    i.Status(),
+		Kind:      i.RoleKind(),
+		ClusterID: i.ClusterID,
+		Namespace: i.Namespace,
+		InviterID: i.InviterID,
 	}
 }
 
@@ -46,3 +79,39 @@ func (i *Invite) IsExpired() bool {
 func (i *Invite) IsAccepted() bool {
 	return i.UserID != 0
 }
+
+// Status returns whether the invite is pending, expired or accepted
+func (i *Invite) Status() string {
+	if i.IsAccepted() {
+		return InviteStatusAccepted
+	} else if i.IsExpired() {
+		return InviteStatusExpired
+	}
+
+	return InviteStatusPending
+}
+
+// RoleKind returns the role that is granted when the invite is accepted.
+// Invites created before roles could be chosen grant the admin role.
+func (i *Invite) RoleKind() string {
+	if i.Kind == "" {
+		return RoleAdmin
+	}
+
+	return i.Kind
+}
+
+// RoleBinding returns the binding that scopes the accepted invite, or nil if
+// the invite grants access to the whole project
+func (i *Invite) RoleBinding(userID uint) *RoleBinding {
+	if i.ClusterID == 0 {
+		return nil
+	}
+
+	return &RoleBinding{
+		ProjectID: i.ProjectID,
+		UserID:    userID,
+		ClusterID: i.ClusterID,
+		Namespace: i.Namespace,
+	}
+}
@@ -0,0 +1,54 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestInviteStatus(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		description string
+		invite      *models.Invite
+		expected    string
+	}{
+		{"pending", &models.Invite{Expiry: &future}, models.InviteStatusPending},
+		{"expired", &models.Invite{Expiry: &past}, models.InviteStatusExpired},
+		{"accepted after expiry", &models.Invite{Expiry: &past, UserID: 1}, models.InviteStatusAccepted},
+	}
+
+	for _, test := range tests {
+		if status := test.invite.Status(); status != test.expected {
+			t.Errorf("%s: expected %s, got %s\n", test.description, test.expected, status)
+		}
+	}
+}
+
+func TestInviteRoleBinding(t *testing.T) {
+	invite := &models.Invite{ProjectID: 1}
+
+	if invite.RoleKind() != models.RoleAdmin {
+		t.Errorf("incorrect default role: expected %s, got %s\n", models.RoleAdmin, invite.RoleKind())
+	}
+
+	if rb := invite.RoleBinding(2); rb != nil {
+		t.Errorf("expected no role binding for unscoped invite\n")
+	}
+
+	invite.ClusterID = 3
+	invite.Namespace = "staging"
+
+	rb := invite.RoleBinding(2)
+
+	if rb == nil {
+		t.Fatalf("expected role binding for scoped invite\n")
+	}
+
+	if rb.ProjectID != 1 || rb.UserID != 2 || rb.ClusterID != 3 || rb.Namespace != "staging" {
+		t.Errorf("incorrect role binding: got %v\n", rb.Externalize())
+	}
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// The kinds of channels that notifications can be sent to
+const (
+	NotificationChannelSlack   string = "slack"
+	NotificationChannelWebhook string = "webhook"
+	NotificationChannelEmail   string = "email"
+)
+
+// The events that a notification channel can subscribe to
+const (
+	NotificationEventDeployFailed   string = "deploy_failed"
+	NotificationEventRollback       string = "rollback"
+	NotificationEventInfraError     string = "infra_error"
+	NotificationEventDeployOverride string = "deploy_override"
+)
+
+// NotificationEvents are all of the events that can be subscribed to
+var NotificationEvents = []string{
+	NotificationEventDeployFailed,
+	NotificationEventRollback,
+	NotificationEventInfraError,
+	NotificationEventDeployOverride,
+}
+
+// NotificationChannel is a destination for a project's notifications, along
+// with the events that are sent to it
+type NotificationChannel struct {
+	gorm.Model
+
+	ProjectID uint
+	Name      string
+	Kind      string
+
+	// Events is a comma-separated list of the events that the channel is
+	// subscribed to
+	Events string
+
+	// ------------------------------------------------------------------
+	// All fields encrypted before storage.
+	// ------------------------------------------------------------------
+
+	// Target is the webhook URL or the email address of the channel. Slack
+	// webhook URLs grant access to post, so they are treated as secrets.
+	Target []byte
+
+	// SigningSecret is used to sign the payloads sent to generic webhooks
+	SigningSecret []byte
+}
+
+// NotificationChannelExternal represents the NotificationChannel type that is
+// sent over REST
+type NotificationChannelExternal struct {
+	ID        uint     `json:"id"`
+	ProjectID uint     `json:"project_id"`
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"`
+	Events    []string `json:"events"`
+
+	// Target is the email address of the channel, or only the host of a webhook
+	// URL
+	Target string `json:"target"`
+}
+
+// Externalize generates an external NotificationChannel to be shared over REST
+func (c *NotificationChannel) Externalize() *NotificationChannelExternal {
+	target := string(c.Target)
+
+	if c.Kind != NotificationChannelEmail {
+		if u, err := url.Parse(target); err == nil {
+			target = u.Scheme + "://" + u.Host
+		} else {
+			target = ""
+		}
+	}
+
+	return &NotificationChannelExternal{
+		ID:        c.ID,
+		ProjectID: c.ProjectID,
+		Name:      c.Name,
+		Kind:      c.Kind,
+		Events:    c.EventList(),
+		Target:    target,
+	}
+}
+
+// EventList returns the events that the channel is subscribed to
+func (c *NotificationChannel) EventList() []string {
+	if c.Events == "" {
+		return []string{}
+	}
+
+	return strings.Split(c.Events, ",")
+}
+
+// IsSubscribed returns true if the channel is subscribed to the event
+func (c *NotificationChannel) IsSubscribed(event string) bool {
+	for _, e := range c.EventList() {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
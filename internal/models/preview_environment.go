@@ -0,0 +1,129 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// MaxPreviewEnvironmentNameLength is the longest name of a preview. The name is
+// used as the Helm release name (at most 53 characters), in the namespace
+// preview-<name> and in the DNS label <name>-<16 hex characters> (both at most
+// 63 characters).
+const MaxPreviewEnvironmentNameLength = 46
+
+var invalidDNSLabelChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// PreviewEnvironment is an ephemeral copy of a release that is deployed for
+// an open pull request
+type PreviewEnvironment struct {
+	gorm.Model
+
+	ProjectID uint
+	ClusterID uint
+
+	// ReleaseID is the id of the release that the preview was cloned from
+	ReleaseID uint
+
+	PRNumber  uint
+	Name      string
+	Namespace string
+	Commit    string
+
+	// Hostname is the subdomain that the preview is exposed on, if any
+	Hostname    string
+	DNSRecordID uint
+}
+
+// PreviewEnvironmentExternal represents the PreviewEnvironment type that is
+// sent over REST
+type PreviewEnvironmentExternal struct {
+	ID        uint   `json:"id"`
+	ReleaseID uint   `json:"release_id"`
+	PRNumber  uint   `json:"pr_number"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Commit    string `json:"commit"`
+	URL       string `json:"url,omitempty"`
+}
+
+// Externalize generates an external PreviewEnvironment to be shared over REST
+func (p *PreviewEnvironment) Externalize() *PreviewEnvironmentExternal {
+	ext := &PreviewEnvironmentExternal{
+		ID:        p.ID,
+		ReleaseID: p.ReleaseID,
+		PRNumber:  p.PRNumber,
+		Name:      p.Name,
+		Namespace: p.Namespace,
+		Commit:    p.Commit,
+	}
+
+	if p.Hostname != "" {
+		ext.URL = "https://" + p.Hostname
+	}
+
+	return ext
+}
+
+// NewPreviewEnvironmentName returns the name of the preview of a release for a
+// pull request, which is <release>-pr-<number>. Release names that are too
+// long or that aren't valid DNS labels are shortened and suffixed with a hash
+// of the full name, so that previews of different releases don't collide.
+func NewPreviewEnvironmentName(releaseName string, prNumber uint) string {
+	suffix := fmt.Sprintf("-pr-%d", prNumber)
+
+	base := invalidDNSLabelChars.ReplaceAllString(strings.ToLower(releaseName), "-")
+	base = strings.Trim(base, "-")
+
+	if base == releaseName && len(base)+len(suffix) <= MaxPreviewEnvironmentNameLength {
+		return base + suffix
+	}
+
+	sum := sha256.Sum256([]byte(releaseName))
+	hash := hex.EncodeToString(sum[:])[:8]
+
+	maxBaseLength := MaxPreviewEnvironmentNameLength - len(suffix) - len(hash) - 1
+
+	if maxBaseLength < 0 {
+		maxBaseLength = 0
+	}
+
+	if len(base) > maxBaseLength {
+		base = strings.TrimRight(base[:maxBaseLength], "-")
+	}
+
+	if base == "" {
+		return hash + suffix
+	}
+
+	return base + "-" + hash + suffix
+}
+
+// PreviewEnvironmentNamespace returns the namespace that a preview is
+// installed in
+func PreviewEnvironmentNamespace(name string) string {
+	return "preview-" + name
+}
+
+// Validate returns an error if the name or namespace of the preview can't be
+// used for its Helm release, namespace or DNS record
+func (p *PreviewEnvironment) Validate() error {
+	if len(p.Name) > MaxPreviewEnvironmentNameLength {
+		return fmt.Errorf("preview name %s is longer than %d characters", p.Name, MaxPreviewEnvironmentNameLength)
+	}
+
+	if errs := validation.IsDNS1123Label(p.Name); len(errs) > 0 {
+		return fmt.Errorf("invalid preview name %s: %s", p.Name, strings.Join(errs, ", "))
+	}
+
+	if errs := validation.IsDNS1123Label(p.Namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid preview namespace %s: %s", p.Namespace, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
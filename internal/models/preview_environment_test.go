@@ -0,0 +1,59 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestNewPreviewEnvironmentName(t *testing.T) {
+	if name := models.NewPreviewEnvironmentName("web", 12); name != "web-pr-12" {
+		t.Errorf("expected short release names to be kept, got %s\n", name)
+	}
+
+	longName := strings.Repeat("a", 50)
+	otherLongName := strings.Repeat("a", 49) + "b"
+
+	names := []string{
+		models.NewPreviewEnvironmentName(longName, 12),
+		models.NewPreviewEnvironmentName(otherLongName, 12),
+		models.NewPreviewEnvironmentName("Web.API", 12),
+		models.NewPreviewEnvironmentName("web-api", 4294967295),
+		models.NewPreviewEnvironmentName("---", 1),
+	}
+
+	for _, name := range names {
+		env := &models.PreviewEnvironment{
+			Name:      name,
+			Namespace: models.PreviewEnvironmentNamespace(name),
+		}
+
+		if err := env.Validate(); err != nil {
+			t.Errorf("expected generated name %s to be valid, got %v\n", name, err)
+		}
+	}
+
+	if names[0] == names[1] {
+		t.Errorf("expected truncated names of different releases to differ, got %s\n", names[0])
+	}
+
+	if !strings.HasSuffix(names[0], "-pr-12") {
+		t.Errorf("expected truncated name to keep the pull request number, got %s\n", names[0])
+	}
+}
+
+func TestPreviewEnvironmentValidate(t *testing.T) {
+	invalid := []*models.PreviewEnvironment{
+		{Name: strings.Repeat("a", 47), Namespace: "preview-a"},
+		{Name: "web.api-pr-1", Namespace: "preview-web-api-pr-1"},
+		{Name: "Web-pr-1", Namespace: "preview-web-pr-1"},
+		{Name: "web-pr-1", Namespace: "preview-" + strings.Repeat("a", 60)},
+	}
+
+	for _, env := range invalid {
+		if err := env.Validate(); err == nil {
+			t.Errorf("expected preview %s in %s to be invalid\n", env.Name, env.Namespace)
+		}
+	}
+}
@@ -13,6 +13,10 @@ type Project struct {
 	Name  string `json:"name"`
 	Roles []Role `json:"roles"`
 
+	// RequireTwoFactor requires all members of the project to have two-factor
+	// authentication enabled
+	RequireTwoFactor bool `json:"require_two_factor"`
+
 	// linked repos
 	GitRepos []GitRepo `json:"git_repos,omitempty"`
 
@@ -43,10 +47,11 @@ type Project struct {
 
 // ProjectExternal represents the Project type that is sent over REST
 type ProjectExternal struct {
-	ID       uint              `json:"id"`
-	Name     string            `json:"name"`
-	Roles    []RoleExternal    `json:"roles"`
-	GitRepos []GitRepoExternal `json:"git_repos,omitempty"`
+	ID               uint              `json:"id"`
+	Name             string            `json:"name"`
+	Roles            []RoleExternal    `json:"roles"`
+	GitRepos         []GitRepoExternal `json:"git_repos,omitempty"`
+	RequireTwoFactor bool              `json:"require_two_factor"`
 }
 
 // Externalize generates an external Project to be shared over REST
@@ -64,9 +69,10 @@ func (p *Project) Externalize() *ProjectExternal {
 	}
 
 	return &ProjectExternal{
-		ID:       p.ID,
-		Name:     p.Name,
-		Roles:    roles,
-		GitRepos: repos,
+		ID:               p.ID,
+		Name:             p.Name,
+		Roles:            roles,
+		GitRepos:         repos,
+		RequireTwoFactor: p.RequireTwoFactor,
 	}
 }
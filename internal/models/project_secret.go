@@ -0,0 +1,33 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// ProjectSecret is a secret value in a project's secret store, which releases
+// reference by name instead of storing the value in their Helm values
+type ProjectSecret struct {
+	gorm.Model
+
+	ProjectID uint
+	Name      string
+
+	// ------------------------------------------------------------------
+	// All fields encrypted before storage.
+	// ------------------------------------------------------------------
+
+	Value []byte
+}
+
+// ProjectSecretExternal represents the ProjectSecret type that is sent over
+// REST. The value of the secret is never sent.
+type ProjectSecretExternal struct {
+	Name string `json:"name"`
+}
+
+// Externalize generates an external ProjectSecret to be shared over REST
+func (s *ProjectSecret) Externalize() *ProjectSecretExternal {
+	return &ProjectSecretExternal{
+		Name: s.Name,
+	}
+}
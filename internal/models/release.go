@@ -16,6 +16,15 @@ type Release struct {
 	Name            string          `json:"name"`
 	Namespace       string          `json:"namespace"`
 	GitActionConfig GitActionConfig `json:"git_action_config"`
+
+	// RolloutDeadlineSeconds is how long the controllers of the release are
+	// given to roll out after a deploy before the deploy is marked as failed.
+	// Rollouts are not watched if it is 0.
+	RolloutDeadlineSeconds uint `json:"rollout_deadline_seconds"`
+
+	// AutoRollback rolls the release back to its previous revision when a
+	// rollout fails
+	AutoRollback bool `json:"auto_rollback"`
 }
 
 // ReleaseExternal represents the Release type that is sent over REST
@@ -24,6 +33,9 @@ type ReleaseExternal struct {
 
 	WebhookToken    string                   `json:"webhook_token"`
 	GitActionConfig *GitActionConfigExternal `json:"git_action_config,omitempty"`
+
+	RolloutDeadlineSeconds uint `json:"rollout_deadline_seconds"`
+	AutoRollback           bool `json:"auto_rollback"`
 }
 
 // Externalize generates an external User to be shared over REST
@@ -32,5 +44,8 @@ func (r *Release) Externalize() *ReleaseExternal {
 		ID:              r.ID,
 		WebhookToken:    r.WebhookToken,
 		GitActionConfig: r.GitActionConfig.Externalize(),
+
+		RolloutDeadlineSeconds: r.RolloutDeadlineSeconds,
+		AutoRollback:           r.AutoRollback,
 	}
 }
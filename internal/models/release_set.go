@@ -0,0 +1,150 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The strategies that a release set can deploy its targets with
+const (
+	// ReleaseSetStrategySequential deploys one target at a time, in the order
+	// that the targets were added
+	ReleaseSetStrategySequential string = "sequential"
+
+	// ReleaseSetStrategyParallel deploys every target at the same time
+	ReleaseSetStrategyParallel string = "parallel"
+)
+
+// The policies that decide what a release set does when a target fails
+const (
+	// ReleaseSetFailureStop skips the targets that have not been deployed yet.
+	// Every target has already been started when a parallel deploy fails, so
+	// it behaves like ReleaseSetFailureContinue for parallel release sets.
+	ReleaseSetFailureStop string = "stop"
+
+	// ReleaseSetFailureContinue deploys the remaining targets
+	ReleaseSetFailureContinue string = "continue"
+
+	// ReleaseSetFailureRollback skips the remaining targets, and rolls the
+	// targets that were deployed back to their previous revision
+	ReleaseSetFailureRollback string = "rollback"
+)
+
+// The statuses of a target after a release set was deployed
+const (
+	ReleaseSetTargetStatusDeployed   string = "deployed"
+	ReleaseSetTargetStatusFailed     string = "failed"
+	ReleaseSetTargetStatusSkipped    string = "skipped"
+	ReleaseSetTargetStatusRolledBack string = "rolled_back"
+)
+
+// ReleaseSet is a logical release that runs identically on several clusters or
+// namespaces of a project. Deploying a release set deploys each of its target
+// releases.
+type ReleaseSet struct {
+	gorm.Model
+
+	ProjectID uint
+	Name      string
+
+	Strategy      string
+	FailurePolicy string
+
+	// WebhookToken deploys every target with a new image tag when it is called
+	WebhookToken string `gorm:"unique"`
+
+	Targets []ReleaseSetTarget
+}
+
+// ReleaseSetTarget is a release that a release set deploys, along with the
+// status of the last deploy of the set
+type ReleaseSetTarget struct {
+	gorm.Model
+
+	ReleaseSetID uint
+	ClusterID    uint
+	Namespace    string
+	ReleaseName  string
+
+	// Status is empty if the release set has not been deployed
+	Status     string
+	Revision   int
+	Error      string
+	DeployedAt time.Time
+}
+
+// ReleaseSetExternal represents the ReleaseSet type that is sent over REST
+type ReleaseSetExternal struct {
+	ID            uint                        `json:"id"`
+	ProjectID     uint                        `json:"project_id"`
+	Name          string                      `json:"name"`
+	Strategy      string                      `json:"strategy"`
+	FailurePolicy string                      `json:"failure_policy"`
+	WebhookToken  string                      `json:"webhook_token"`
+	Targets       []*ReleaseSetTargetExternal `json:"targets"`
+}
+
+// ReleaseSetTargetExternal represents the ReleaseSetTarget type that is sent
+// over REST
+type ReleaseSetTargetExternal struct {
+	ID          uint       `json:"id"`
+	ClusterID   uint       `json:"cluster_id"`
+	Namespace   string     `json:"namespace"`
+	ReleaseName string     `json:"release_name"`
+	Status      string     `json:"status,omitempty"`
+	Revision    int        `json:"revision,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	DeployedAt  *time.Time `json:"deployed_at,omitempty"`
+}
+
+// Externalize generates an external ReleaseSet to be shared over REST
+func (s *ReleaseSet) Externalize() *ReleaseSetExternal {
+	ext := &ReleaseSetExternal{
+		ID:            s.ID,
+		ProjectID:     s.ProjectID,
+		Name:          s.Name,
+		Strategy:      s.Strategy,
+		FailurePolicy: s.FailurePolicy,
+		WebhookToken:  s.WebhookToken,
+		Targets:       make([]*ReleaseSetTargetExternal, 0),
+	}
+
+	for i := range s.Targets {
+		ext.Targets = append(ext.Targets, s.Targets[i].Externalize())
+	}
+
+	return ext
+}
+
+// Externalize generates an external ReleaseSetTarget to be shared over REST
+func (t *ReleaseSetTarget) Externalize() *ReleaseSetTargetExternal {
+	ext := &ReleaseSetTargetExternal{
+		ID:          t.ID,
+		ClusterID:   t.ClusterID,
+		Namespace:   t.Namespace,
+		ReleaseName: t.ReleaseName,
+		Status:      t.Status,
+		Revision:    t.Revision,
+		Error:       t.Error,
+	}
+
+	if !t.DeployedAt.IsZero() {
+		deployedAt := t.DeployedAt
+		ext.DeployedAt = &deployedAt
+	}
+
+	return ext
+}
+
+// Target returns the target of the set with the given release, or nil if the
+// set doesn't deploy it
+func (s *ReleaseSet) Target(clusterID uint, namespace, name string) *ReleaseSetTarget {
+	for i, target := range s.Targets {
+		if target.ClusterID == clusterID && target.Namespace == namespace && target.ReleaseName == name {
+			return &s.Targets[i]
+		}
+	}
+
+	return nil
+}
@@ -6,10 +6,117 @@ import (
 
 // The roles available for a project
 const (
-	RoleAdmin  string = "admin"
-	RoleViewer string = "viewer"
+	RoleAdmin              string = "admin"
+	RoleDeveloper          string = "developer"
+	RoleDeployer           string = "deployer"
+	RoleIntegrationManager string = "integration_manager"
+	RoleViewer             string = "viewer"
 )
 
+// PermissionResource is a kind of project resource that access can be
+// granted to
+type PermissionResource string
+
+// The resources that permissions are granted on
+const (
+	ProjectResource     PermissionResource = "project"
+	ClusterResource     PermissionResource = "cluster"
+	RegistryResource    PermissionResource = "registry"
+	ReleaseResource     PermissionResource = "release"
+	IntegrationResource PermissionResource = "integration"
+	InfraResource       PermissionResource = "infra"
+
+	// AuditResource is the audit log of a project, which only admins can read
+	AuditResource PermissionResource = "audit"
+
+	// SecretResource is the secret store of a project. Its secrets can be
+	// referenced from any cluster and namespace, so only admins can write them.
+	SecretResource PermissionResource = "secret"
+)
+
+// PermissionVerb is an action that can be performed on a resource
+type PermissionVerb string
+
+// The verbs that can be granted on a resource
+const (
+	ReadVerb   PermissionVerb = "read"
+	WriteVerb  PermissionVerb = "write"
+	DeleteVerb PermissionVerb = "delete"
+)
+
+var (
+	readOnly  = []PermissionVerb{ReadVerb}
+	readWrite = []PermissionVerb{ReadVerb, WriteVerb}
+	allVerbs  = []PermissionVerb{ReadVerb, WriteVerb, DeleteVerb}
+)
+
+// RolePolicies maps each role to the verbs it is granted on each resource. A
+// resource that is missing from a role's policy cannot be accessed at all.
+var RolePolicies = map[string]map[PermissionResource][]PermissionVerb{
+	RoleAdmin: {
+		ProjectResource:     allVerbs,
+		ClusterResource:     allVerbs,
+		RegistryResource:    allVerbs,
+		ReleaseResource:     allVerbs,
+		IntegrationResource: allVerbs,
+		InfraResource:       allVerbs,
+		AuditResource:       readOnly,
+		SecretResource:      allVerbs,
+	},
+	RoleDeveloper: {
+		ProjectResource:     readOnly,
+		ClusterResource:     readWrite,
+		RegistryResource:    readWrite,
+		ReleaseResource:     allVerbs,
+		IntegrationResource: readOnly,
+		InfraResource:       readOnly,
+		SecretResource:      readOnly,
+	},
+	RoleDeployer: {
+		ProjectResource:  readOnly,
+		ClusterResource:  readOnly,
+		RegistryResource: readOnly,
+		ReleaseResource:  readWrite,
+		InfraResource:    readOnly,
+		SecretResource:   readOnly,
+	},
+	RoleIntegrationManager: {
+		ProjectResource:     readOnly,
+		RegistryResource:    allVerbs,
+		IntegrationResource: allVerbs,
+		InfraResource:       readOnly,
+	},
+	RoleViewer: {
+		ProjectResource:  readOnly,
+		ClusterResource:  readOnly,
+		RegistryResource: readOnly,
+		ReleaseResource:  readOnly,
+		InfraResource:    readOnly,
+		SecretResource:   readOnly,
+	},
+}
+
+// roleRanks orders the roles from least to most privileged
+var roleRanks = map[string]int{
+	RoleViewer:             1,
+	RoleIntegrationManager: 2,
+	RoleDeployer:           3,
+	RoleDeveloper:          4,
+	RoleAdmin:              5,
+}
+
+// IsRoleMorePrivileged returns true if the role kind is more privileged than
+// the other role kind
+func IsRoleMorePrivileged(kind, other string) bool {
+	return roleRanks[kind] > roleRanks[other]
+}
+
+// IsValidRole returns true if the role kind is one of the supported roles
+func IsValidRole(kind string) bool {
+	_, ok := RolePolicies[kind]
+	return ok
+}
+
 // Role type that extends gorm.Model
 type Role struct {
 	gorm.Model
@@ -17,6 +124,11 @@ type Role struct {
 	Kind      string `json:"kind"`
 	UserID    uint   `json:"user_id"`
 	ProjectID uint   `json:"project_id"`
+
+	// SSOManaged is set when the role was granted or raised by an SSO group
+	// mapping. Managed roles follow the user's SSO groups each time they log
+	// in, and are removed when no mapping grants them anymore.
+	SSOManaged bool `json:"sso_managed"`
 }
 
 // RoleExternal represents the Role type that is sent over REST
@@ -25,14 +137,37 @@ type RoleExternal struct {
 	Kind      string `json:"kind"`
 	UserID    uint   `json:"user_id"`
 	ProjectID uint   `json:"project_id"`
+
+	// SSOManaged is set when the role was granted or raised by an SSO group
+	// mapping. Managed roles follow the user's SSO groups each time they log
+	// in, and are removed when no mapping grants them anymore.
+	SSOManaged bool `json:"sso_managed"`
 }
 
 // Externalize generates an external Role to be shared over REST
 func (r *Role) Externalize() *RoleExternal {
 	return &RoleExternal{
-		ID:        r.ID,
-		Kind:      r.Kind,
-		UserID:    r.UserID,
-		ProjectID: r.ProjectID,
+		ID:         r.ID,
+		Kind:       r.Kind,
+		UserID:     r.UserID,
+		ProjectID:  r.ProjectID,
+		SSOManaged: r.SSOManaged,
 	}
 }
+
+// HasPermission returns true if the role grants the verb on the resource
+func (r *Role) HasPermission(resource PermissionResource, verb PermissionVerb) bool {
+	policy, ok := RolePolicies[r.Kind]
+
+	if !ok {
+		return false
+	}
+
+	for _, allowed := range policy[resource] {
+		if allowed == verb {
+			return true
+		}
+	}
+
+	return false
+}
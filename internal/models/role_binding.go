@@ -0,0 +1,117 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// RoleBinding scopes a project member or a stored API token to a single
+// cluster, and optionally to a single namespace within that cluster. A member
+// without any role bindings can access every cluster and namespace in the
+// project, and a token without any role bindings has the access of the member
+// that created it.
+type RoleBinding struct {
+	gorm.Model
+
+	ProjectID uint
+
+	// A binding applies to either a user or a stored API token, so exactly one
+	// of UserID and APITokenID is set
+	UserID     uint
+	APITokenID uint
+
+	ClusterID uint
+
+	// Namespace is the namespace that the binding grants access to. If empty,
+	// the binding grants access to every namespace in the cluster.
+	Namespace string
+}
+
+// RoleBindingExternal represents the RoleBinding type that is sent over REST
+type RoleBindingExternal struct {
+	ID         uint   `json:"id"`
+	ProjectID  uint   `json:"project_id"`
+	UserID     uint   `json:"user_id,omitempty"`
+	APITokenID uint   `json:"api_token_id,omitempty"`
+	ClusterID  uint   `json:"cluster_id"`
+	Namespace  string `json:"namespace"`
+}
+
+// Externalize generates an external RoleBinding to be shared over REST
+func (rb *RoleBinding) Externalize() *RoleBindingExternal {
+	return &RoleBindingExternal{
+		ID:         rb.ID,
+		ProjectID:  rb.ProjectID,
+		UserID:     rb.UserID,
+		APITokenID: rb.APITokenID,
+		ClusterID:  rb.ClusterID,
+		Namespace:  rb.Namespace,
+	}
+}
+
+// Allows returns true if the binding grants access to the namespace in the
+// cluster. An empty namespace is only allowed by a binding to the whole
+// cluster, so that requests that don't name a namespace fail closed.
+func (rb *RoleBinding) Allows(clusterID uint, namespace string) bool {
+	if rb.ClusterID != clusterID {
+		return false
+	}
+
+	return rb.Namespace == "" || rb.Namespace == namespace
+}
+
+// RoleBindings is a set of role bindings for a single project member or token
+type RoleBindings []*RoleBinding
+
+// Allows returns true if the set of bindings grants access to the namespace
+// in the cluster. An empty set of bindings places no restrictions on access.
+func (rbs RoleBindings) Allows(clusterID uint, namespace string) bool {
+	if len(rbs) == 0 {
+		return true
+	}
+
+	for _, rb := range rbs {
+		if rb.Allows(clusterID, namespace) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsCluster returns true if the set of bindings grants access to the
+// cluster or to any namespace in it. It should only be used by routes that
+// filter their results by namespace with Allows.
+func (rbs RoleBindings) AllowsCluster(clusterID uint) bool {
+	if len(rbs) == 0 {
+		return true
+	}
+
+	for _, rb := range rbs {
+		if rb.ClusterID == clusterID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SubjectRoleBindings are the role bindings that apply to a request: the
+// bindings of the user that made the request, and the bindings of the API
+// token that the request was made with, if any. Access must be allowed by
+// both, so that a token can't grant more than the user that created it.
+type SubjectRoleBindings struct {
+	User     RoleBindings
+	APIToken RoleBindings
+}
+
+// Allows returns true if the user's and the token's bindings both grant
+// access to the namespace in the cluster
+func (s *SubjectRoleBindings) Allows(clusterID uint, namespace string) bool {
+	return s.User.Allows(clusterID, namespace) && s.APIToken.Allows(clusterID, namespace)
+}
+
+// AllowsCluster returns true if the user's and the token's bindings both
+// grant access to the cluster or to a namespace in it
+func (s *SubjectRoleBindings) AllowsCluster(clusterID uint) bool {
+	return s.User.AllowsCluster(clusterID) && s.APIToken.AllowsCluster(clusterID)
+}
@@ -0,0 +1,113 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+type roleBindingsAllowTest struct {
+	description string
+	bindings    models.RoleBindings
+	clusterID   uint
+	namespace   string
+	expected    bool
+}
+
+var roleBindingsAllowTests = []roleBindingsAllowTest{
+	{
+		description: "no bindings",
+		bindings:    models.RoleBindings{},
+		clusterID:   1,
+		namespace:   "default",
+		expected:    true,
+	},
+	{
+		description: "cluster-wide binding",
+		bindings:    models.RoleBindings{{ClusterID: 1}},
+		clusterID:   1,
+		namespace:   "default",
+		expected:    true,
+	},
+	{
+		description: "binding on other cluster",
+		bindings:    models.RoleBindings{{ClusterID: 2}},
+		clusterID:   1,
+		namespace:   "",
+		expected:    false,
+	},
+	{
+		description: "cluster-wide binding, no namespace",
+		bindings:    models.RoleBindings{{ClusterID: 1}},
+		clusterID:   1,
+		namespace:   "",
+		expected:    true,
+	},
+	{
+		description: "namespace binding, no namespace",
+		bindings:    models.RoleBindings{{ClusterID: 1, Namespace: "staging"}},
+		clusterID:   1,
+		namespace:   "",
+		expected:    false,
+	},
+	{
+		description: "namespace binding, same namespace",
+		bindings:    models.RoleBindings{{ClusterID: 1, Namespace: "staging"}},
+		clusterID:   1,
+		namespace:   "staging",
+		expected:    true,
+	},
+	{
+		description: "namespace binding, other namespace",
+		bindings:    models.RoleBindings{{ClusterID: 1, Namespace: "staging"}},
+		clusterID:   1,
+		namespace:   "production",
+		expected:    false,
+	},
+}
+
+func TestRoleBindingsAllows(t *testing.T) {
+	for _, test := range roleBindingsAllowTests {
+		res := test.bindings.Allows(test.clusterID, test.namespace)
+
+		if res != test.expected {
+			t.Errorf("%s: expected %t, got %t\n", test.description, test.expected, res)
+		}
+	}
+}
+
+func TestRoleBindingsAllowsCluster(t *testing.T) {
+	bindings := models.RoleBindings{{ClusterID: 1, Namespace: "staging"}}
+
+	if !bindings.AllowsCluster(1) {
+		t.Errorf("expected namespace binding to allow listing its cluster\n")
+	}
+
+	if bindings.AllowsCluster(2) {
+		t.Errorf("expected namespace binding not to allow listing another cluster\n")
+	}
+}
+
+func TestSubjectRoleBindingsAllows(t *testing.T) {
+	bindings := &models.SubjectRoleBindings{
+		User:     models.RoleBindings{{ClusterID: 1}},
+		APIToken: models.RoleBindings{{ClusterID: 1, Namespace: "staging"}},
+	}
+
+	if !bindings.Allows(1, "staging") {
+		t.Errorf("expected token binding to allow its namespace\n")
+	}
+
+	if bindings.Allows(1, "production") {
+		t.Errorf("expected token binding to restrict the user's access\n")
+	}
+
+	bindings = &models.SubjectRoleBindings{
+		User:     models.RoleBindings{{ClusterID: 1, Namespace: "staging"}},
+		APIToken: models.RoleBindings{{ClusterID: 1}},
+	}
+
+	if bindings.Allows(1, "production") {
+		t.Errorf("expected token binding not to grant more than the user's bindings\n")
+	}
+}
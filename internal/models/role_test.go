@@ -0,0 +1,49 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+type rolePermissionTest struct {
+	kind     string
+	resource models.PermissionResource
+	verb     models.PermissionVerb
+	expected bool
+}
+
+var rolePermissionTests = []rolePermissionTest{
+	{models.RoleAdmin, models.ClusterResource, models.DeleteVerb, true},
+	{models.RoleAdmin, models.IntegrationResource, models.ReadVerb, true},
+	{models.RoleDeployer, models.ReleaseResource, models.WriteVerb, true},
+	{models.RoleDeployer, models.ReleaseResource, models.DeleteVerb, false},
+	{models.RoleDeployer, models.ClusterResource, models.DeleteVerb, false},
+	{models.RoleDeployer, models.IntegrationResource, models.ReadVerb, false},
+	{models.RoleDeveloper, models.ReleaseResource, models.DeleteVerb, true},
+	{models.RoleDeveloper, models.InfraResource, models.WriteVerb, false},
+	{models.RoleIntegrationManager, models.IntegrationResource, models.DeleteVerb, true},
+	{models.RoleIntegrationManager, models.ReleaseResource, models.ReadVerb, false},
+	{models.RoleViewer, models.ReleaseResource, models.ReadVerb, true},
+	{models.RoleViewer, models.ReleaseResource, models.WriteVerb, false},
+	{"unknown", models.ProjectResource, models.ReadVerb, false},
+}
+
+func TestRoleHasPermission(t *testing.T) {
+	for _, test := range rolePermissionTests {
+		role := &models.Role{
+			Kind: test.kind,
+		}
+
+		if res := role.HasPermission(test.resource, test.verb); res != test.expected {
+			t.Errorf(
+				"role %s, %s %s: expected %t, got %t\n",
+				test.kind,
+				test.verb,
+				test.resource,
+				test.expected,
+				res,
+			)
+		}
+	}
+}
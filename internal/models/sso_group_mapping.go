@@ -0,0 +1,41 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// SSOGroupMapping grants a role in a project to the members of an SSO group
+// when they log in
+type SSOGroupMapping struct {
+	gorm.Model
+
+	ProjectID uint
+
+	// Issuer is the SSO issuer that the group belongs to, which is the issuer
+	// of the admin that created the mapping. Groups of other issuers with the
+	// same name don't match.
+	Issuer string
+
+	Group string
+	Kind  string
+}
+
+// SSOGroupMappingExternal represents the SSOGroupMapping type that is sent over REST
+type SSOGroupMappingExternal struct {
+	ID        uint   `json:"id"`
+	ProjectID uint   `json:"project_id"`
+	Issuer    string `json:"issuer"`
+	Group     string `json:"group"`
+	Kind      string `json:"kind"`
+}
+
+// Externalize generates an external SSOGroupMapping to be shared over REST
+func (m *SSOGroupMapping) Externalize() *SSOGroupMappingExternal {
+	return &SSOGroupMappingExternal{
+		ID:        m.ID,
+		ProjectID: m.ProjectID,
+		Issuer:    m.Issuer,
+		Group:     m.Group,
+		Kind:      m.Kind,
+	}
+}
@@ -10,6 +10,18 @@ type PorterChartList struct {
 	Icon        string   `json:"icon"`
 }
 
+// PorterTemplateCatalog is a linked helm repo of a project whose charts can be
+// launched as templates
+type PorterTemplateCatalog struct {
+	HelmRepoID uint               `json:"helm_repo_id"`
+	Name       string             `json:"name"`
+	RepoURL    string             `json:"repo_url"`
+	Templates  []*PorterChartList `json:"templates"`
+
+	// Error is set if the charts of the helm repo could not be listed
+	Error string `json:"error,omitempty"`
+}
+
 // PorterChartRead is a chart with detailed information and a form for reading
 type PorterChartRead struct {
 	Markdown string                 `json:"markdown"`
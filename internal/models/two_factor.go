@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TwoFactor is the time-based one-time password configuration for a user
+type TwoFactor struct {
+	gorm.Model
+
+	UserID uint `gorm:"unique"`
+
+	// Enabled is set once the user has confirmed enrollment with a valid code.
+	// Until then, the secret is pending and is not checked at login.
+	Enabled bool
+
+	// LastUsedStep is the time step of the last accepted code, so that a
+	// code cannot be used twice
+	LastUsedStep int64
+
+	// RecoveryCodes is a comma-separated list of the hashes of the recovery
+	// codes that have not been used
+	RecoveryCodes string
+
+	// FailedAttempts is the number of invalid codes that were entered since
+	// the last valid code or lockout
+	FailedAttempts uint
+
+	// LockedUntil is set when too many invalid codes are entered, and no code
+	// is accepted until it passes
+	LockedUntil *time.Time
+
+	// ------------------------------------------------------------------
+	// All fields encrypted before storage.
+	// ------------------------------------------------------------------
+
+	// Secret is the base32-encoded shared secret
+	Secret []byte
+}
+
+// TwoFactorExternal represents the TwoFactor type that is sent over REST
+type TwoFactorExternal struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// Externalize generates an external TwoFactor to be shared over REST
+func (t *TwoFactor) Externalize() *TwoFactorExternal {
+	return &TwoFactorExternal{
+		Enabled:                t.Enabled,
+		RecoveryCodesRemaining: len(t.RecoveryCodeHashes()),
+	}
+}
+
+// RecoveryCodeHashes returns the hashes of the unused recovery codes
+func (t *TwoFactor) RecoveryCodeHashes() []string {
+	res := make([]string, 0)
+
+	for _, hash := range strings.Split(t.RecoveryCodes, ",") {
+		if hash != "" {
+			res = append(res, hash)
+		}
+	}
+
+	return res
+}
+
+// IsLocked returns true if codes are not accepted because too many invalid
+// codes were entered
+func (t *TwoFactor) IsLocked(now time.Time) bool {
+	return t.LockedUntil != nil && now.Before(*t.LockedUntil)
+}
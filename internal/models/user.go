@@ -14,6 +14,10 @@ type User struct {
 
 	// The github user id used for login (optional)
 	GithubUserID int64
+
+	// The SSO issuer and subject used for login (optional)
+	SSOIssuer  string
+	SSOSubject string
 }
 
 // UserExternal represents the User type that is sent over REST
@@ -0,0 +1,205 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// SignatureHeader is the header that generic webhook payloads are signed in
+const SignatureHeader = "X-Porter-Signature"
+
+// EventHeader is the header that names the event of a generic webhook payload
+const EventHeader = "X-Porter-Event"
+
+// Event is something that happened in a project that channels can subscribe to
+type Event struct {
+	Kind      string            `json:"event"`
+	ProjectID uint              `json:"project_id"`
+	Title     string            `json:"title"`
+	Message   string            `json:"message"`
+	URL       string            `json:"url,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	Time      time.Time         `json:"time"`
+}
+
+// EmailSender sends a notification to an email address
+type EmailSender interface {
+	SendNotificationEmail(email, subject, message, url string) error
+}
+
+// Notifier sends events to the notification channels of a project
+type Notifier struct {
+	Repo repository.NotificationChannelRepository
+
+	// Email sends notifications to email channels. Email channels are skipped
+	// if it is not set.
+	Email EmailSender
+
+	HTTPClient *http.Client
+}
+
+// New returns a Notifier that reads channels from the repository. Its HTTP
+// client doesn't use a proxy and refuses to connect to loopback, private and
+// link-local addresses.
+func New(repo repository.NotificationChannelRepository, email EmailSender) *Notifier {
+	return &Notifier{
+		Repo:  repo,
+		Email: email,
+		HTTPClient: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext:         newDialer().DialContext,
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+		},
+	}
+}
+
+// Notify sends the event to every channel in the event's project that is
+// subscribed to it. All channels are attempted even if some of them fail.
+func (n *Notifier) Notify(event *Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	channels, err := n.Repo.ListNotificationChannelsByProjectID(event.ProjectID)
+
+	if err != nil {
+		return err
+	}
+
+	errs := make([]string, 0)
+
+	for _, channel := range channels {
+		if !channel.IsSubscribed(event.Kind) {
+			continue
+		}
+
+		if err := n.Send(channel, event); err != nil {
+			errs = append(errs, fmt.Sprintf("channel %d: %v", channel.ID, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("could not send notifications: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// Send sends the event to a single channel, regardless of its subscriptions
+func (n *Notifier) Send(channel *models.NotificationChannel, event *Event) error {
+	switch channel.Kind {
+	case models.NotificationChannelSlack:
+		return n.sendSlack(channel, event)
+	case models.NotificationChannelWebhook:
+		return n.sendWebhook(channel, event)
+	case models.NotificationChannelEmail:
+		if n.Email == nil {
+			return fmt.Errorf("email notifications are not configured")
+		}
+
+		return n.Email.SendNotificationEmail(string(channel.Target), event.Title, formatText(event), event.URL)
+	}
+
+	return fmt.Errorf("unknown channel kind %s", channel.Kind)
+}
+
+// SignPayload returns the signature of a generic webhook payload, which is the
+// hex-encoded HMAC-SHA256 of the body keyed by the channel's signing secret
+func SignPayload(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (n *Notifier) sendSlack(channel *models.NotificationChannel, event *Event) error {
+	text := fmt.Sprintf("*%s*\n%s", event.Title, formatText(event))
+
+	if event.URL != "" {
+		text = fmt.Sprintf("%s\n<%s|View in Porter>", text, event.URL)
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"text": text,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return n.post(string(channel.Target), body, nil)
+}
+
+func (n *Notifier) sendWebhook(channel *models.NotificationChannel, event *Event) error {
+	body, err := json.Marshal(event)
+
+	if err != nil {
+		return err
+	}
+
+	return n.post(string(channel.Target), body, map[string]string{
+		EventHeader:     event.Kind,
+		SignatureHeader: SignPayload(channel.SigningSecret, body),
+	})
+}
+
+func (n *Notifier) post(url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for key, val := range headers {
+		req.Header.Set(key, val)
+	}
+
+	res, err := n.HTTPClient.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// formatText renders the message of an event followed by its fields, in a
+// stable order
+func formatText(event *Event) string {
+	lines := []string{event.Message}
+
+	keys := make([]string, 0)
+
+	for key := range event.Fields {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %s", key, event.Fields[key]))
+	}
+
+	return strings.Join(lines, "\n")
+}
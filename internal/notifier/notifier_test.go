@@ -0,0 +1,248 @@
+package notifier_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/notifier"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+)
+
+type request struct {
+	path    string
+	headers http.Header
+	body    []byte
+}
+
+type emailStub struct {
+	sent []string
+}
+
+func (e *emailStub) SendNotificationEmail(email, subject, message, url string) error {
+	e.sent = append(e.sent, email)
+	return nil
+}
+
+func TestNotify(t *testing.T) {
+	requests := make([]request, 0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, request{r.URL.Path, r.Header, body})
+	}))
+
+	defer server.Close()
+
+	repo := memory.NewNotificationChannelRepository(true)
+
+	channels := []*models.NotificationChannel{
+		{
+			ProjectID: 1,
+			Kind:      models.NotificationChannelSlack,
+			Events:    models.NotificationEventDeployFailed,
+			Target:    []byte(server.URL + "/slack"),
+		},
+		{
+			ProjectID:     1,
+			Kind:          models.NotificationChannelWebhook,
+			Events:        models.NotificationEventDeployFailed + "," + models.NotificationEventRollback,
+			Target:        []byte(server.URL + "/webhook"),
+			SigningSecret: []byte("secret"),
+		},
+		{
+			ProjectID: 1,
+			Kind:      models.NotificationChannelEmail,
+			Events:    models.NotificationEventRollback,
+			Target:    []byte("oncall@example.com"),
+		},
+		{
+			// channels in other projects are never notified
+			ProjectID: 2,
+			Kind:      models.NotificationChannelSlack,
+			Events:    models.NotificationEventDeployFailed,
+			Target:    []byte(server.URL + "/other"),
+		},
+	}
+
+	for _, channel := range channels {
+		if _, err := repo.CreateNotificationChannel(channel); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	email := &emailStub{}
+	n := notifier.New(repo, email)
+
+	// the test server listens on a loopback address, which the notifier's
+	// own client refuses to connect to
+	n.HTTPClient = server.Client()
+
+	err := n.Notify(&notifier.Event{
+		Kind:      models.NotificationEventDeployFailed,
+		ProjectID: 1,
+		Title:     "Deploy of web failed",
+		Message:   "Upgrade failed",
+		Fields:    map[string]string{"release": "web"},
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(requests) != 2 || len(email.sent) != 0 {
+		t.Fatalf("expected 2 webhook calls and 0 emails, got %d and %d\n", len(requests), len(email.sent))
+	}
+
+	byPath := make(map[string]request)
+
+	for _, req := range requests {
+		byPath[req.path] = req
+	}
+
+	slack, ok := byPath["/slack"]
+
+	if !ok {
+		t.Fatalf("slack channel was not notified")
+	}
+
+	slackBody := make(map[string]string)
+	json.Unmarshal(slack.body, &slackBody)
+
+	if !strings.Contains(slackBody["text"], "*Deploy of web failed*") || !strings.Contains(slackBody["text"], "release: web") {
+		t.Errorf("incorrect slack text: %s\n", slackBody["text"])
+	}
+
+	webhook, ok := byPath["/webhook"]
+
+	if !ok {
+		t.Fatalf("webhook channel was not notified")
+	}
+
+	if sig := webhook.headers.Get(notifier.SignatureHeader); sig != notifier.SignPayload([]byte("secret"), webhook.body) {
+		t.Errorf("incorrect webhook signature: %s\n", sig)
+	}
+
+	if event := webhook.headers.Get(notifier.EventHeader); event != models.NotificationEventDeployFailed {
+		t.Errorf("incorrect webhook event header: %s\n", event)
+	}
+
+	// a rollback only goes to the webhook and email channels
+	requests = requests[:0]
+
+	err = n.Notify(&notifier.Event{
+		Kind:      models.NotificationEventRollback,
+		ProjectID: 1,
+		Title:     "web rolled back",
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(requests) != 1 || requests[0].path != "/webhook" {
+		t.Errorf("expected only the webhook channel to be called, got %d calls\n", len(requests))
+	}
+
+	if len(email.sent) != 1 || email.sent[0] != "oncall@example.com" {
+		t.Errorf("expected an email to oncall@example.com, got %v\n", email.sent)
+	}
+}
+
+func TestNotifyFailingChannel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	defer server.Close()
+
+	repo := memory.NewNotificationChannelRepository(true)
+
+	repo.CreateNotificationChannel(&models.NotificationChannel{
+		ProjectID: 1,
+		Kind:      models.NotificationChannelSlack,
+		Events:    models.NotificationEventInfraError,
+		Target:    []byte(server.URL),
+	})
+
+	n := notifier.New(repo, nil)
+	n.HTTPClient = server.Client()
+
+	err := n.Notify(&notifier.Event{
+		Kind:      models.NotificationEventInfraError,
+		ProjectID: 1,
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("expected an error with status 500, got %v\n", err)
+	}
+}
+
+func TestNotifyBlockedAddress(t *testing.T) {
+	called := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	defer server.Close()
+
+	repo := memory.NewNotificationChannelRepository(true)
+
+	repo.CreateNotificationChannel(&models.NotificationChannel{
+		ProjectID: 1,
+		Kind:      models.NotificationChannelSlack,
+		Events:    models.NotificationEventInfraError,
+		Target:    []byte(server.URL),
+	})
+
+	err := notifier.New(repo, nil).Notify(&notifier.Event{
+		Kind:      models.NotificationEventInfraError,
+		ProjectID: 1,
+	})
+
+	if err == nil || called {
+		t.Errorf("expected the notification to a loopback address to be refused\n")
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	valid := []string{
+		"https://hooks.slack.com/services/T000/B000/XXXX",
+		"https://example.com:8443/hook",
+		"https://8.8.8.8/hook",
+	}
+
+	for _, target := range valid {
+		if err := notifier.ValidateWebhookURL(target); err != nil {
+			t.Errorf("expected %s to be valid, got %v\n", target, err)
+		}
+	}
+
+	invalid := []string{
+		"http://hooks.slack.com/services/T000/B000/XXXX",
+		"ftp://example.com",
+		"https:///hook",
+		"https://localhost/hook",
+		"https://api.localhost/hook",
+		"https://127.0.0.1/hook",
+		"https://10.0.0.5/hook",
+		"https://172.20.1.1/hook",
+		"https://192.168.1.1/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/hook",
+		"https://[fe80::1]/hook",
+		"https://[fd00::1]/hook",
+		"https://[::ffff:127.0.0.1]/hook",
+	}
+
+	for _, target := range invalid {
+		if err := notifier.ValidateWebhookURL(target); err == nil {
+			t.Errorf("expected %s to be invalid\n", target)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package notifier
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// blockedNetworks are the loopback, private, link-local and other
+// non-public networks that notifications are never sent to, so that
+// channels can't be used to reach the server's own network
+var blockedNetworks = parseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	res := make([]*net.IPNet, 0)
+
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+
+		if err != nil {
+			panic(err)
+		}
+
+		res = append(res, ipNet)
+	}
+
+	return res
+}
+
+// IsBlockedIP returns true if notifications may not be sent to the address
+func IsBlockedIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	for _, ipNet := range blockedNetworks {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateWebhookURL checks that a Slack or webhook target uses https, and
+// that its host is not a local name or a blocked address. Host names that
+// resolve to a blocked address are rejected when the notification is sent.
+func ValidateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("target must be an https URL")
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("target must be a public host")
+	}
+
+	if ip := net.ParseIP(host); ip != nil && IsBlockedIP(ip) {
+		return fmt.Errorf("target must be a public host")
+	}
+
+	return nil
+}
+
+// dialControl refuses connections to blocked addresses. It runs after the
+// host name is resolved, so it also covers names that resolve to a blocked
+// address and redirects to blocked hosts.
+func dialControl(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || IsBlockedIP(ip) {
+		return fmt.Errorf("notifications can't be sent to %s", host)
+	}
+
+	return nil
+}
+
+// newDialer returns a dialer for notification requests that refuses
+// connections to blocked addresses
+func newDialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: dialControl,
+	}
+}
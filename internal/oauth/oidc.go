@@ -0,0 +1,332 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/dgrijalva/jwt-go"
+	"golang.org/x/oauth2"
+)
+
+// SSOIdentity is a user identity that has been verified by an SSO provider
+type SSOIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Groups        []string
+}
+
+// SSOProvider is an identity provider that users can log in with. The login
+// flow is started by redirecting to AuthCodeURL, and the provider redirects
+// back with a code that is exchanged for a verified identity.
+type SSOProvider interface {
+	AuthCodeURL(state, nonce string) string
+	Exchange(ctx context.Context, code, nonce string) (*SSOIdentity, error)
+}
+
+// OIDCConfig is the configuration for an OpenID Connect provider
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	BaseURL      string
+
+	// GroupsClaim is the ID token claim that lists the user's groups
+	GroupsClaim string
+}
+
+// OIDCProvider is an SSOProvider that implements the OpenID Connect
+// authorization code flow. The issuer's discovery document and signing keys
+// are fetched when they are first needed.
+type OIDCProvider struct {
+	cfg        *OIDCConfig
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// NewOIDCProvider returns an OIDCProvider for the issuer in the config
+func NewOIDCProvider(cfg *OIDCConfig) *OIDCProvider {
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+
+	return &OIDCProvider{
+		cfg:        cfg,
+		httpClient: http.DefaultClient,
+		keys:       make(map[string]*rsa.PublicKey),
+	}
+}
+
+// RedirectURL is the URL that the issuer redirects to after login
+func (p *OIDCProvider) RedirectURL() string {
+	return p.cfg.BaseURL + "/api/oauth/oidc/callback"
+}
+
+// AuthCodeURL returns the issuer URL to redirect the user to for login
+func (p *OIDCProvider) AuthCodeURL(state, nonce string) string {
+	conf, err := p.oauthConfig()
+
+	if err != nil {
+		return ""
+	}
+
+	return conf.AuthCodeURL(state, oauth2.SetAuthURLParam("nonce", nonce))
+}
+
+// Exchange exchanges the authorization code for an ID token, and returns the
+// identity in the token after verifying it
+func (p *OIDCProvider) Exchange(ctx context.Context, code, nonce string) (*SSOIdentity, error) {
+	conf, err := p.oauthConfig()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.httpClient)
+
+	tok, err := conf.Exchange(ctx, code)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, ok := tok.Extra("id_token").(string)
+
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("token response did not contain an id_token")
+	}
+
+	return p.VerifyIDToken(rawIDToken, nonce)
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of
+// an ID token, and returns the identity that it contains
+func (p *OIDCProvider) VerifyIDToken(rawIDToken, nonce string) (*SSOIdentity, error) {
+	discovery, err := p.getDiscovery()
+
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		kid, _ := token.Header["kid"].(string)
+
+		return p.getKey(discovery.JWKSURI, kid)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("could not verify id token: %v", err)
+	}
+
+	if !claims.VerifyIssuer(discovery.Issuer, true) {
+		return nil, fmt.Errorf("id token has an invalid issuer")
+	}
+
+	if !claims.VerifyAudience(p.cfg.ClientID, true) && !audienceContains(claims["aud"], p.cfg.ClientID) {
+		return nil, fmt.Errorf("id token has an invalid audience")
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("id token does not expire")
+	}
+
+	if tokNonce, _ := claims["nonce"].(string); tokNonce != nonce {
+		return nil, fmt.Errorf("id token has an invalid nonce")
+	}
+
+	res := &SSOIdentity{
+		Issuer: discovery.Issuer,
+		Groups: make([]string, 0),
+	}
+
+	res.Subject, _ = claims["sub"].(string)
+	res.Email, _ = claims["email"].(string)
+	res.EmailVerified, _ = claims["email_verified"].(bool)
+
+	if res.Subject == "" {
+		return nil, fmt.Errorf("id token does not have a subject")
+	}
+
+	if groups, ok := claims[p.cfg.GroupsClaim].([]interface{}); ok {
+		for _, group := range groups {
+			if groupStr, ok := group.(string); ok {
+				res.Groups = append(res.Groups, groupStr)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (p *OIDCProvider) oauthConfig() (*oauth2.Config, error) {
+	discovery, err := p.getDiscovery()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+		RedirectURL: p.RedirectURL(),
+		Scopes:      []string{"openid", "email", "profile", p.cfg.GroupsClaim},
+	}, nil
+}
+
+func (p *OIDCProvider) getDiscovery() (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+
+	discovery := &oidcDiscovery{}
+
+	if err := p.getJSON(wellKnown, discovery); err != nil {
+		return nil, fmt.Errorf("could not read oidc discovery document: %v", err)
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(p.cfg.IssuerURL, "/") {
+		return nil, fmt.Errorf("oidc issuer %s does not match %s", discovery.Issuer, p.cfg.IssuerURL)
+	}
+
+	p.discovery = discovery
+
+	return discovery, nil
+}
+
+// getKey returns the signing key with the given ID, refreshing the issuer's
+// keys if the ID is not known, since the issuer may have rotated its keys
+func (p *OIDCProvider) getKey(jwksURI, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	jwks := &jsonWebKeySet{}
+
+	if err := p.getJSON(jwksURI, jwks); err != nil {
+		return nil, fmt.Errorf("could not read oidc signing keys: %v", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+
+		key, err := jwk.rsaPublicKey()
+
+		if err != nil {
+			return nil, err
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	p.keys = keys
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("signing key %s not found", kid)
+}
+
+func (p *OIDCProvider) getJSON(url string, v interface{}) error {
+	resp, err := p.httpClient.Get(url)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (jwk *jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus for key %s: %v", jwk.Kid, err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent for key %s: %v", jwk.Kid, err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+// audienceContains handles ID tokens with a list of audiences, which are
+// not supported by MapClaims.VerifyAudience in this version of jwt-go
+func audienceContains(aud interface{}, clientID string) bool {
+	auds, ok := aud.([]interface{})
+
+	if !ok {
+		return false
+	}
+
+	for _, a := range auds {
+		if a == clientID {
+			return true
+		}
+	}
+
+	return false
+}
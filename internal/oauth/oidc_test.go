@@ -0,0 +1,134 @@
+package oauth_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/oauth"
+	"github.com/porter-dev/porter/internal/oauth/oidctest"
+)
+
+func newTestIssuer(t *testing.T) (*oidctest.Issuer, *oauth.OIDCProvider) {
+	issuer, err := oidctest.NewIssuer("porter", &oidctest.User{
+		Subject:       "1234",
+		Email:         "sso@porter.run",
+		EmailVerified: true,
+		Groups:        []string{"engineering", "ops"},
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	provider := oauth.NewOIDCProvider(&oauth.OIDCConfig{
+		IssuerURL:    issuer.URL(),
+		ClientID:     "porter",
+		ClientSecret: "secret",
+		BaseURL:      "http://localhost:8080",
+	})
+
+	return issuer, provider
+}
+
+func TestOIDCLoginFlow(t *testing.T) {
+	issuer, provider := newTestIssuer(t)
+	defer issuer.Close()
+
+	authURL := provider.AuthCodeURL("state", "nonce")
+
+	if authURL == "" {
+		t.Fatalf("expected an auth code url")
+	}
+
+	// follow the issuer's redirect back to porter to get the code
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(authURL)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	redirect, err := url.Parse(resp.Header.Get("Location"))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if state := redirect.Query().Get("state"); state != "state" {
+		t.Fatalf("incorrect state: expected %s, got %s\n", "state", state)
+	}
+
+	identity, err := provider.Exchange(context.Background(), redirect.Query().Get("code"), "nonce")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	expIdentity := &oauth.SSOIdentity{
+		Issuer:        issuer.URL(),
+		Subject:       "1234",
+		Email:         "sso@porter.run",
+		EmailVerified: true,
+		Groups:        []string{"engineering", "ops"},
+	}
+
+	if diff := deep.Equal(expIdentity, identity); diff != nil {
+		t.Errorf("incorrect identity")
+		t.Error(diff)
+	}
+}
+
+type invalidIDTokenTest struct {
+	description string
+	nonce       string
+	claims      jwt.MapClaims
+}
+
+var invalidIDTokenTests = []invalidIDTokenTest{
+	{
+		description: "wrong nonce",
+		nonce:       "other",
+	},
+	{
+		description: "wrong audience",
+		nonce:       "nonce",
+		claims:      jwt.MapClaims{"aud": "other"},
+	},
+	{
+		description: "wrong issuer",
+		nonce:       "nonce",
+		claims:      jwt.MapClaims{"iss": "https://other.example.com"},
+	},
+	{
+		description: "expired",
+		nonce:       "nonce",
+		claims:      jwt.MapClaims{"exp": time.Now().Add(-1 * time.Hour).Unix()},
+	},
+}
+
+func TestOIDCVerifyInvalidIDToken(t *testing.T) {
+	issuer, provider := newTestIssuer(t)
+	defer issuer.Close()
+
+	for _, test := range invalidIDTokenTests {
+		idToken, err := issuer.SignIDToken("nonce", test.claims)
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		if _, err := provider.VerifyIDToken(idToken, test.nonce); err == nil {
+			t.Errorf("%s: expected id token to be rejected\n", test.description)
+		}
+	}
+}
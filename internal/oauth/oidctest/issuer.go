@@ -0,0 +1,202 @@
+// Package oidctest provides a local OpenID Connect issuer for testing SSO login
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const keyID = "oidctest"
+
+// User is the identity that the issuer logs users in as
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Groups        []string
+}
+
+// Issuer is an OpenID Connect issuer that logs every user in as the same user
+// without prompting. The issuer must be closed after use.
+type Issuer struct {
+	ClientID string
+	User     *User
+
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	nonces map[string]string
+}
+
+// NewIssuer starts a new Issuer for the client ID
+func NewIssuer(clientID string, user *User) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := &Issuer{
+		ClientID: clientID,
+		User:     user,
+		key:      key,
+		nonces:   make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.handleDiscovery)
+	mux.HandleFunc("/keys", issuer.handleKeys)
+	mux.HandleFunc("/authorize", issuer.handleAuthorize)
+	mux.HandleFunc("/token", issuer.handleToken)
+
+	issuer.server = httptest.NewServer(mux)
+
+	return issuer, nil
+}
+
+// URL is the issuer URL
+func (i *Issuer) URL() string {
+	return i.server.URL
+}
+
+// Close shuts down the issuer
+func (i *Issuer) Close() {
+	i.server.Close()
+}
+
+// SignIDToken signs an ID token for the user with extra claims, which
+// override the default claims
+func (i *Issuer) SignIDToken(nonce string, extra jwt.MapClaims) (string, error) {
+	claims := jwt.MapClaims{
+		"iss":            i.URL(),
+		"aud":            i.ClientID,
+		"sub":            i.User.Subject,
+		"email":          i.User.Email,
+		"email_verified": i.User.EmailVerified,
+		"groups":         i.User.Groups,
+		"nonce":          nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+
+	for key, val := range extra {
+		claims[key] = val
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+
+	return token.SignedString(i.key)
+}
+
+func (i *Issuer) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 i.URL(),
+		"authorization_endpoint": i.URL() + "/authorize",
+		"token_endpoint":         i.URL() + "/token",
+		"jwks_uri":               i.URL() + "/keys",
+	})
+}
+
+func (i *Issuer) handleKeys(w http.ResponseWriter, r *http.Request) {
+	pub := i.key.PublicKey
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"kid": keyID,
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			},
+		},
+	})
+}
+
+// handleAuthorize logs the user in immediately, and redirects back to the
+// client with a code
+func (i *Issuer) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("client_id") != i.ClientID {
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	}
+
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+
+	i.mu.Lock()
+	i.nonces[code] = query.Get("nonce")
+	i.mu.Unlock()
+
+	vals := redirect.Query()
+	vals.Set("code", code)
+	vals.Set("state", query.Get("state"))
+	redirect.RawQuery = vals.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (i *Issuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+
+	code := r.PostForm.Get("code")
+
+	i.mu.Lock()
+	nonce, ok := i.nonces[code]
+	delete(i.nonces, code)
+	i.mu.Unlock()
+
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	idToken, err := i.SignIDToken(nonce, nil)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -721,6 +721,40 @@ func (r *Registry) GetDockerConfigJSON(
 	repo repository.Repository,
 	doAuth *oauth2.Config, // only required if using DOCR
 ) ([]byte, error) {
+	conf, err := r.getDockerConfigFile(repo, doAuth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(conf)
+}
+
+// GetBasicAuth returns the username and password that the registry API of the
+// registry can be accessed with
+func (r *Registry) GetBasicAuth(
+	repo repository.Repository,
+	doAuth *oauth2.Config, // only required if using DOCR
+) (string, string, error) {
+	conf, err := r.getDockerConfigFile(repo, doAuth)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if conf != nil {
+		for _, authConfig := range conf.AuthConfigs {
+			return authConfig.Username, authConfig.Password, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("error getting registry credentials")
+}
+
+func (r *Registry) getDockerConfigFile(
+	repo repository.Repository,
+	doAuth *oauth2.Config,
+) (*configfile.ConfigFile, error) {
 	var conf *configfile.ConfigFile
 	var err error
 
@@ -741,11 +775,7 @@ func (r *Registry) GetDockerConfigJSON(
 		conf, err = r.getPrivateRegistryDockerConfigFile(repo)
 	}
 
-	if err != nil {
-		return nil, err
-	}
-
-	return json.Marshal(conf)
+	return conf, err
 }
 
 func (r *Registry) getECRDockerConfigFile(
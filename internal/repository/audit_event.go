@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// ListAuditEventOpts are the filters and pagination options for listing
+// audit events. Zero values do not filter.
+type ListAuditEventOpts struct {
+	UserID   uint
+	Resource string
+	Limit    int
+	Offset   int
+}
+
+// AuditEventRepository represents the set of queries on the AuditEvent model
+type AuditEventRepository interface {
+	CreateAuditEvent(event *models.AuditEvent) (*models.AuditEvent, error)
+	ListAuditEventsByProjectID(projectID uint, opts *ListAuditEventOpts) ([]*models.AuditEvent, int64, error)
+}
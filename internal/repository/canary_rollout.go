@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// CanaryRolloutRepository represents the set of queries on the CanaryRollout
+// model
+type CanaryRolloutRepository interface {
+	CreateCanaryRollout(rollout *models.CanaryRollout) (*models.CanaryRollout, error)
+	ReadCanaryRollout(id uint) (*models.CanaryRollout, error)
+	ListCanaryRolloutsByRelease(clusterID uint, namespace, name string) ([]*models.CanaryRollout, error)
+	ListCanaryRolloutsByStatus(status string) ([]*models.CanaryRollout, error)
+	UpdateCanaryRollout(rollout *models.CanaryRollout) (*models.CanaryRollout, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// DeployPolicyRepository represents the set of queries on the DeployPolicy
+// model
+type DeployPolicyRepository interface {
+	CreateDeployPolicy(policy *models.DeployPolicy) (*models.DeployPolicy, error)
+	ReadDeployPolicy(id uint) (*models.DeployPolicy, error)
+	ListDeployPoliciesByProjectID(projectID uint) ([]*models.DeployPolicy, error)
+	UpdateDeployPolicy(policy *models.DeployPolicy) (*models.DeployPolicy, error)
+	DeleteDeployPolicy(policy *models.DeployPolicy) error
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// ListDeploymentOpts are the filters and pagination options for listing
+// deployments. Zero values do not filter.
+type ListDeploymentOpts struct {
+	Source string
+	Status string
+	Limit  int
+	Offset int
+}
+
+// DeploymentRepository represents the set of queries on the Deployment model
+type DeploymentRepository interface {
+	CreateDeployment(deployment *models.Deployment) (*models.Deployment, error)
+	ListDeploymentsByRelease(
+		clusterID uint,
+		namespace, name string,
+		opts *ListDeploymentOpts,
+	) ([]*models.Deployment, int64, error)
+	ListDeploymentsByStatus(status string) ([]*models.Deployment, error)
+	UpdateDeployment(deployment *models.Deployment) (*models.Deployment, error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// EnvGroupRepository represents the set of queries on the EnvGroup model and
+// its versions and attachments
+type EnvGroupRepository interface {
+	CreateEnvGroup(group *models.EnvGroup) (*models.EnvGroup, error)
+	ReadEnvGroup(id uint) (*models.EnvGroup, error)
+	ListEnvGroupsByClusterID(clusterID uint) ([]*models.EnvGroup, error)
+	ListEnvGroupsByRelease(clusterID uint, namespace, name string) ([]*models.EnvGroup, error)
+	UpdateEnvGroup(group *models.EnvGroup) (*models.EnvGroup, error)
+	DeleteEnvGroup(group *models.EnvGroup) error
+	CreateEnvGroupVersion(version *models.EnvGroupVersion) (*models.EnvGroupVersion, error)
+	ReadEnvGroupVersion(envGroupID, version uint) (*models.EnvGroupVersion, error)
+	ListEnvGroupVersions(envGroupID uint) ([]*models.EnvGroupVersion, error)
+	CreateEnvGroupAttachment(attachment *models.EnvGroupAttachment) (*models.EnvGroupAttachment, error)
+	DeleteEnvGroupAttachment(attachment *models.EnvGroupAttachment) error
+}
@@ -0,0 +1,77 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// APITokenRepository uses gorm.DB for querying the database
+type APITokenRepository struct {
+	db *gorm.DB
+}
+
+// NewAPITokenRepository returns an APITokenRepository which uses
+// gorm.DB for querying the database
+func NewAPITokenRepository(db *gorm.DB) repository.APITokenRepository {
+	return &APITokenRepository{db}
+}
+
+// CreateAPIToken creates a new API token
+func (repo *APITokenRepository) CreateAPIToken(
+	token *models.APIToken,
+) (*models.APIToken, error) {
+	if err := repo.db.Create(token).Error; err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// ReadAPIToken gets an API token specified by a unique id
+func (repo *APITokenRepository) ReadAPIToken(id uint) (*models.APIToken, error) {
+	token := &models.APIToken{}
+
+	if err := repo.db.Where("id = ?", id).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// ReadAPITokenByUniqueID gets an API token specified by the unique id
+// that is embedded in the encoded token
+func (repo *APITokenRepository) ReadAPITokenByUniqueID(uid string) (*models.APIToken, error) {
+	token := &models.APIToken{}
+
+	if err := repo.db.Where("unique_id = ?", uid).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// ListAPITokensByProjectID finds all API tokens
+// for a given project id
+func (repo *APITokenRepository) ListAPITokensByProjectID(
+	projectID uint,
+) ([]*models.APIToken, error) {
+	tokens := []*models.APIToken{}
+
+	if err := repo.db.Where("project_id = ?", projectID).Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// UpdateAPIToken modifies an existing API token in the database
+func (repo *APITokenRepository) UpdateAPIToken(
+	token *models.APIToken,
+) (*models.APIToken, error) {
+	if err := repo.db.Save(token).Error; err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
@@ -0,0 +1,90 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestCreateAPIToken(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_create_api_token.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	apiToken := &models.APIToken{
+		UniqueID:        "abcd",
+		ProjectID:       tester.initProjects[0].ID,
+		CreatedByUserID: 1,
+		Name:            "ci",
+		Scopes:          "release:write",
+	}
+
+	apiToken, err := tester.repo.APIToken.CreateAPIToken(apiToken)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	apiToken, err = tester.repo.APIToken.ReadAPITokenByUniqueID("abcd")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// make sure id is 1 and name is "ci"
+	if apiToken.ID != 1 {
+		t.Errorf("incorrect token ID: expected %d, got %d\n", 1, apiToken.ID)
+	}
+
+	if apiToken.Name != "ci" {
+		t.Errorf("incorrect name: expected %s, got %s\n", "ci", apiToken.Name)
+	}
+
+	if apiToken.Scopes != "release:write" {
+		t.Errorf("incorrect scopes: expected %s, got %s\n", "release:write", apiToken.Scopes)
+	}
+}
+
+func TestRevokeAPIToken(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_revoke_api_token.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	apiToken, err := tester.repo.APIToken.CreateAPIToken(&models.APIToken{
+		UniqueID:  "abcd",
+		ProjectID: tester.initProjects[0].ID,
+		Name:      "ci",
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	apiToken.Revoked = true
+
+	if _, err := tester.repo.APIToken.UpdateAPIToken(apiToken); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	tokens, err := tester.repo.APIToken.ListAPITokensByProjectID(tester.initProjects[0].ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(tokens) != 1 {
+		t.Fatalf("length of tokens incorrect: expected %d, got %d\n", 1, len(tokens))
+	}
+
+	if tokens[0].IsValid() {
+		t.Errorf("expected revoked token to be invalid")
+	}
+}
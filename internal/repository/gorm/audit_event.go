@@ -0,0 +1,66 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// AuditEventRepository uses gorm.DB for querying the database
+type AuditEventRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditEventRepository returns an AuditEventRepository which uses
+// gorm.DB for querying the database
+func NewAuditEventRepository(db *gorm.DB) repository.AuditEventRepository {
+	return &AuditEventRepository{db}
+}
+
+// CreateAuditEvent creates a new audit event
+func (repo *AuditEventRepository) CreateAuditEvent(
+	event *models.AuditEvent,
+) (*models.AuditEvent, error) {
+	if err := repo.db.Create(event).Error; err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// ListAuditEventsByProjectID finds the audit events for a given project id,
+// newest first, along with the total number of events that match the filters
+func (repo *AuditEventRepository) ListAuditEventsByProjectID(
+	projectID uint,
+	opts *repository.ListAuditEventOpts,
+) ([]*models.AuditEvent, int64, error) {
+	events := []*models.AuditEvent{}
+
+	query := repo.db.Model(&models.AuditEvent{}).Where("project_id = ?", projectID)
+
+	if opts.UserID != 0 {
+		query = query.Where("user_id = ?", opts.UserID)
+	}
+
+	if opts.Resource != "" {
+		query = query.Where("resource = ?", opts.Resource)
+	}
+
+	var total int64
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("id desc").Offset(opts.Offset)
+
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+
+	if err := query.Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
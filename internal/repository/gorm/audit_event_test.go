@@ -0,0 +1,79 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+func TestListAuditEventsByProjectID(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_audit_events.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	for i, resource := range []string{"releases", "infra", "releases", "releases"} {
+		_, err := tester.repo.AuditEvent.CreateAuditEvent(&models.AuditEvent{
+			ProjectID:    tester.initProjects[0].ID,
+			UserID:       1,
+			Resource:     resource,
+			ResourceName: string(rune('a' + i)),
+			Action:       "update",
+			Status:       200,
+		})
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	events, total, err := tester.repo.AuditEvent.ListAuditEventsByProjectID(
+		tester.initProjects[0].ID,
+		&repository.ListAuditEventOpts{
+			Resource: "releases",
+			Limit:    2,
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if total != 3 {
+		t.Errorf("incorrect total: expected %d, got %d\n", 3, total)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("length of events incorrect: expected %d, got %d\n", 2, len(events))
+	}
+
+	// make sure the newest events are returned first
+	if events[0].ResourceName != "d" || events[1].ResourceName != "c" {
+		t.Errorf(
+			"incorrect order: expected [d c], got [%s %s]\n",
+			events[0].ResourceName,
+			events[1].ResourceName,
+		)
+	}
+
+	events, _, err = tester.repo.AuditEvent.ListAuditEventsByProjectID(
+		tester.initProjects[0].ID,
+		&repository.ListAuditEventOpts{
+			Resource: "releases",
+			Limit:    2,
+			Offset:   2,
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(events) != 1 || events[0].ResourceName != "a" {
+		t.Errorf("incorrect second page of events")
+	}
+}
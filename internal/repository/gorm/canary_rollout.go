@@ -0,0 +1,87 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// CanaryRolloutRepository uses gorm.DB for querying the database
+type CanaryRolloutRepository struct {
+	db *gorm.DB
+}
+
+// NewCanaryRolloutRepository returns a CanaryRolloutRepository which uses
+// gorm.DB for querying the database
+func NewCanaryRolloutRepository(db *gorm.DB) repository.CanaryRolloutRepository {
+	return &CanaryRolloutRepository{db}
+}
+
+// CreateCanaryRollout creates a new canary rollout
+func (repo *CanaryRolloutRepository) CreateCanaryRollout(
+	rollout *models.CanaryRollout,
+) (*models.CanaryRollout, error) {
+	if err := repo.db.Create(rollout).Error; err != nil {
+		return nil, err
+	}
+
+	return rollout, nil
+}
+
+// ReadCanaryRollout gets a canary rollout specified by a unique id
+func (repo *CanaryRolloutRepository) ReadCanaryRollout(
+	id uint,
+) (*models.CanaryRollout, error) {
+	rollout := &models.CanaryRollout{}
+
+	if err := repo.db.Where("id = ?", id).First(&rollout).Error; err != nil {
+		return nil, err
+	}
+
+	return rollout, nil
+}
+
+// ListCanaryRolloutsByRelease finds the canary rollouts of a release, newest
+// first
+func (repo *CanaryRolloutRepository) ListCanaryRolloutsByRelease(
+	clusterID uint,
+	namespace, name string,
+) ([]*models.CanaryRollout, error) {
+	rollouts := []*models.CanaryRollout{}
+
+	if err := repo.db.Where(
+		"cluster_id = ? AND namespace = ? AND release_name = ?",
+		clusterID,
+		namespace,
+		name,
+	).Order("id desc").Find(&rollouts).Error; err != nil {
+		return nil, err
+	}
+
+	return rollouts, nil
+}
+
+// ListCanaryRolloutsByStatus finds the canary rollouts of every project that
+// have a status
+func (repo *CanaryRolloutRepository) ListCanaryRolloutsByStatus(
+	status string,
+) ([]*models.CanaryRollout, error) {
+	rollouts := []*models.CanaryRollout{}
+
+	if err := repo.db.Where("status = ?", status).Find(&rollouts).Error; err != nil {
+		return nil, err
+	}
+
+	return rollouts, nil
+}
+
+// UpdateCanaryRollout modifies an existing canary rollout in the database
+func (repo *CanaryRolloutRepository) UpdateCanaryRollout(
+	rollout *models.CanaryRollout,
+) (*models.CanaryRollout, error) {
+	if err := repo.db.Save(rollout).Error; err != nil {
+		return nil, err
+	}
+
+	return rollout, nil
+}
@@ -0,0 +1,105 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestListCanaryRolloutsByRelease(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_canary_rollouts.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	for _, name := range []string{"api", "worker", "api"} {
+		_, err := tester.repo.CanaryRollout.CreateCanaryRollout(&models.CanaryRollout{
+			ProjectID:   tester.initProjects[0].ID,
+			ClusterID:   1,
+			Namespace:   "default",
+			ReleaseName: name,
+			CanaryName:  name + "-canary",
+			Steps:       "10,50",
+			Status:      models.CanaryStatusProgressing,
+		})
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	rollouts, err := tester.repo.CanaryRollout.ListCanaryRolloutsByRelease(1, "default", "api")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(rollouts) != 2 {
+		t.Fatalf("length of rollouts incorrect: expected %d, got %d\n", 2, len(rollouts))
+	}
+
+	// make sure the newest rollout is returned first
+	if rollouts[0].ID != 3 || rollouts[1].ID != 1 {
+		t.Errorf("incorrect order: expected [3 1], got [%d %d]\n", rollouts[0].ID, rollouts[1].ID)
+	}
+
+	rollout := rollouts[0]
+	rollout.Status = models.CanaryStatusAborted
+
+	if _, err := tester.repo.CanaryRollout.UpdateCanaryRollout(rollout); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	rollout, err = tester.repo.CanaryRollout.ReadCanaryRollout(3)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if !rollout.IsFinished() {
+		t.Errorf("expected rollout to be finished\n")
+	}
+
+	if steps := rollout.StepWeights(); len(steps) != 2 || steps[0] != 10 || steps[1] != 50 {
+		t.Errorf("incorrect steps: got %v\n", steps)
+	}
+}
+
+func TestListCanaryRolloutsByStatus(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_canary_rollouts_by_status.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	for _, status := range []string{models.CanaryStatusProgressing, models.CanaryStatusPromoted} {
+		_, err := tester.repo.CanaryRollout.CreateCanaryRollout(&models.CanaryRollout{
+			ProjectID:   tester.initProjects[0].ID,
+			ClusterID:   1,
+			Namespace:   "default",
+			ReleaseName: "api",
+			CanaryName:  "api-canary",
+			Steps:       "10,50",
+			Status:      status,
+		})
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	rollouts, err := tester.repo.CanaryRollout.ListCanaryRolloutsByStatus(models.CanaryStatusProgressing)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(rollouts) != 1 || rollouts[0].ID != 1 {
+		t.Fatalf("expected only rollout 1 to be progressing, got %d rollouts\n", len(rollouts))
+	}
+}
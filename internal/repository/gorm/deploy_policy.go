@@ -0,0 +1,78 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DeployPolicyRepository uses gorm.DB for querying the database
+type DeployPolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewDeployPolicyRepository returns a DeployPolicyRepository which uses
+// gorm.DB for querying the database
+func NewDeployPolicyRepository(db *gorm.DB) repository.DeployPolicyRepository {
+	return &DeployPolicyRepository{db}
+}
+
+// CreateDeployPolicy creates a new deploy policy
+func (repo *DeployPolicyRepository) CreateDeployPolicy(
+	policy *models.DeployPolicy,
+) (*models.DeployPolicy, error) {
+	if err := repo.db.Create(policy).Error; err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// ReadDeployPolicy gets a deploy policy specified by a unique id
+func (repo *DeployPolicyRepository) ReadDeployPolicy(
+	id uint,
+) (*models.DeployPolicy, error) {
+	policy := &models.DeployPolicy{}
+
+	if err := repo.db.Where("id = ?", id).First(&policy).Error; err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// ListDeployPoliciesByProjectID finds all deploy policies for a given project
+// id
+func (repo *DeployPolicyRepository) ListDeployPoliciesByProjectID(
+	projectID uint,
+) ([]*models.DeployPolicy, error) {
+	policies := []*models.DeployPolicy{}
+
+	if err := repo.db.Where("project_id = ?", projectID).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// UpdateDeployPolicy modifies an existing deploy policy in the database
+func (repo *DeployPolicyRepository) UpdateDeployPolicy(
+	policy *models.DeployPolicy,
+) (*models.DeployPolicy, error) {
+	if err := repo.db.Save(policy).Error; err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// DeleteDeployPolicy removes a deploy policy from the db
+func (repo *DeployPolicyRepository) DeleteDeployPolicy(
+	policy *models.DeployPolicy,
+) error {
+	if err := repo.db.Where("id = ?", policy.ID).Delete(&models.DeployPolicy{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DeploymentRepository uses gorm.DB for querying the database
+type DeploymentRepository struct {
+	db *gorm.DB
+}
+
+// NewDeploymentRepository returns a DeploymentRepository which uses
+// gorm.DB for querying the database
+func NewDeploymentRepository(db *gorm.DB) repository.DeploymentRepository {
+	return &DeploymentRepository{db}
+}
+
+// CreateDeployment creates a new deployment record
+func (repo *DeploymentRepository) CreateDeployment(
+	deployment *models.Deployment,
+) (*models.Deployment, error) {
+	if err := repo.db.Create(deployment).Error; err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+// ListDeploymentsByRelease finds the deployments of a release, newest first,
+// along with the total number of deployments that match the filters
+func (repo *DeploymentRepository) ListDeploymentsByRelease(
+	clusterID uint,
+	namespace, name string,
+	opts *repository.ListDeploymentOpts,
+) ([]*models.Deployment, int64, error) {
+	deployments := []*models.Deployment{}
+
+	query := repo.db.Model(&models.Deployment{}).Where(
+		"cluster_id = ? AND namespace = ? AND release_name = ?",
+		clusterID,
+		namespace,
+		name,
+	)
+
+	if opts.Source != "" {
+		query = query.Where("source = ?", opts.Source)
+	}
+
+	if opts.Status != "" {
+		query = query.Where("status = ?", opts.Status)
+	}
+
+	var total int64
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("id desc").Offset(opts.Offset)
+
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+
+	if err := query.Find(&deployments).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return deployments, total, nil
+}
+
+// ListDeploymentsByStatus finds the deployments of every project that have a
+// status
+func (repo *DeploymentRepository) ListDeploymentsByStatus(
+	status string,
+) ([]*models.Deployment, error) {
+	deployments := []*models.Deployment{}
+
+	if err := repo.db.Where("status = ?", status).Find(&deployments).Error; err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
+}
+
+// UpdateDeployment modifies an existing deployment record in the database
+func (repo *DeploymentRepository) UpdateDeployment(
+	deployment *models.Deployment,
+) (*models.Deployment, error) {
+	if err := repo.db.Save(deployment).Error; err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
@@ -0,0 +1,149 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+func TestListDeploymentsByRelease(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_deployments.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	deployments := []*models.Deployment{
+		{ReleaseName: "web", Namespace: "default", Revision: 1, Status: models.DeploymentStatusSucceeded},
+		{ReleaseName: "web", Namespace: "staging", Revision: 1, Status: models.DeploymentStatusSucceeded},
+		{ReleaseName: "api", Namespace: "default", Revision: 1, Status: models.DeploymentStatusSucceeded},
+		{ReleaseName: "web", Namespace: "default", Revision: 2, Status: models.DeploymentStatusFailed},
+		{ReleaseName: "web", Namespace: "default", Revision: 3, Status: models.DeploymentStatusSucceeded},
+	}
+
+	for _, deployment := range deployments {
+		deployment.ProjectID = tester.initProjects[0].ID
+		deployment.ClusterID = 1
+		deployment.Source = models.DeploymentSourceUI
+		deployment.Action = models.DeploymentActionUpgrade
+
+		if _, err := tester.repo.Deployment.CreateDeployment(deployment); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	res, total, err := tester.repo.Deployment.ListDeploymentsByRelease(
+		1,
+		"default",
+		"web",
+		&repository.ListDeploymentOpts{},
+	)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if total != 3 || len(res) != 3 {
+		t.Fatalf("incorrect number of deployments: expected %d, got %d\n", 3, len(res))
+	}
+
+	// make sure the newest deployments are returned first
+	if res[0].Revision != 3 || res[1].Revision != 2 || res[2].Revision != 1 {
+		t.Errorf(
+			"incorrect order: expected [3 2 1], got [%d %d %d]\n",
+			res[0].Revision,
+			res[1].Revision,
+			res[2].Revision,
+		)
+	}
+
+	res, total, err = tester.repo.Deployment.ListDeploymentsByRelease(
+		1,
+		"default",
+		"web",
+		&repository.ListDeploymentOpts{
+			Status: models.DeploymentStatusSucceeded,
+			Limit:  1,
+			Offset: 1,
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if total != 2 {
+		t.Errorf("incorrect total: expected %d, got %d\n", 2, total)
+	}
+
+	if len(res) != 1 || res[0].Revision != 1 {
+		t.Errorf("incorrect second page of deployments")
+	}
+}
+
+func TestUpdateDeployment(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_update_deployment.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	deployment, err := tester.repo.Deployment.CreateDeployment(&models.Deployment{
+		ProjectID:   tester.initProjects[0].ID,
+		ClusterID:   1,
+		ReleaseName: "web",
+		Namespace:   "default",
+		Status:      models.DeploymentStatusProgressing,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	progressing, err := tester.repo.Deployment.ListDeploymentsByStatus(models.DeploymentStatusProgressing)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(progressing) != 1 || progressing[0].ID != deployment.ID {
+		t.Fatalf("expected deployment to be progressing, got %d deployments\n", len(progressing))
+	}
+
+	deployment.Status = models.DeploymentStatusFailed
+	deployment.Error = "rollout did not finish"
+
+	if _, err := tester.repo.Deployment.UpdateDeployment(deployment); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	res, _, err := tester.repo.Deployment.ListDeploymentsByRelease(
+		1,
+		"default",
+		"web",
+		&repository.ListDeploymentOpts{},
+	)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(res) != 1 || res[0].Status != models.DeploymentStatusFailed || res[0].Error != deployment.Error {
+		t.Errorf("deployment was not updated: got %v\n", res)
+	}
+
+	progressing, err = tester.repo.Deployment.ListDeploymentsByStatus(models.DeploymentStatusProgressing)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(progressing) != 0 {
+		t.Errorf("expected no deployments to be progressing, got %d\n", len(progressing))
+	}
+}
@@ -25,3 +25,24 @@ func (repo *DNSRecordRepository) CreateDNSRecord(record *models.DNSRecord) (*mod
 
 	return record, nil
 }
+
+// ReadDNSRecord gets a DNS record specified by a unique id
+func (repo *DNSRecordRepository) ReadDNSRecord(id uint) (*models.DNSRecord, error) {
+	record := &models.DNSRecord{}
+
+	if err := repo.db.Where("id = ?", id).First(&record).Error; err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// DeleteDNSRecord removes a DNS record. The row is deleted permanently so
+// that the subdomain can be reused.
+func (repo *DNSRecordRepository) DeleteDNSRecord(record *models.DNSRecord) error {
+	if err := repo.db.Unscoped().Where("id = ?", record.ID).Delete(&models.DNSRecord{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
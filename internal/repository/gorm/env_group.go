@@ -0,0 +1,207 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// EnvGroupRepository uses gorm.DB for querying the database
+type EnvGroupRepository struct {
+	db  *gorm.DB
+	key *[32]byte
+}
+
+// NewEnvGroupRepository returns an EnvGroupRepository which uses gorm.DB for
+// querying the database. It accepts an encryption key to encrypt sensitive data
+func NewEnvGroupRepository(db *gorm.DB, key *[32]byte) repository.EnvGroupRepository {
+	return &EnvGroupRepository{db, key}
+}
+
+// CreateEnvGroup creates a new env group
+func (repo *EnvGroupRepository) CreateEnvGroup(
+	group *models.EnvGroup,
+) (*models.EnvGroup, error) {
+	if err := repo.db.Create(group).Error; err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// ReadEnvGroup gets an env group and its attachments specified by a unique id
+func (repo *EnvGroupRepository) ReadEnvGroup(id uint) (*models.EnvGroup, error) {
+	group := &models.EnvGroup{}
+
+	if err := repo.db.Preload("Attachments").Where("id = ?", id).First(&group).Error; err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// ListEnvGroupsByClusterID finds all env groups in a cluster
+func (repo *EnvGroupRepository) ListEnvGroupsByClusterID(
+	clusterID uint,
+) ([]*models.EnvGroup, error) {
+	groups := []*models.EnvGroup{}
+
+	if err := repo.db.Preload("Attachments").Where("cluster_id = ?", clusterID).Find(&groups).Error; err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// ListEnvGroupsByRelease finds the env groups that are attached to a release,
+// in the order that they were attached
+func (repo *EnvGroupRepository) ListEnvGroupsByRelease(
+	clusterID uint,
+	namespace, name string,
+) ([]*models.EnvGroup, error) {
+	groups := []*models.EnvGroup{}
+
+	query := repo.db.Preload("Attachments").
+		Joins("JOIN env_group_attachments ON env_group_attachments.env_group_id = env_groups.id").
+		Where("env_groups.cluster_id = ?", clusterID).
+		Where("env_group_attachments.namespace = ? AND env_group_attachments.release_name = ?", namespace, name).
+		Where("env_group_attachments.deleted_at IS NULL").
+		Order("env_group_attachments.id asc")
+
+	if err := query.Find(&groups).Error; err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// UpdateEnvGroup modifies an existing env group in the database
+func (repo *EnvGroupRepository) UpdateEnvGroup(
+	group *models.EnvGroup,
+) (*models.EnvGroup, error) {
+	if err := repo.db.Omit("Attachments").Save(group).Error; err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// DeleteEnvGroup removes an env group from the db, along with its versions
+func (repo *EnvGroupRepository) DeleteEnvGroup(group *models.EnvGroup) error {
+	if err := repo.db.Where("env_group_id = ?", group.ID).Delete(&models.EnvGroupVersion{}).Error; err != nil {
+		return err
+	}
+
+	return repo.db.Where("id = ?", group.ID).Delete(&models.EnvGroup{}).Error
+}
+
+// CreateEnvGroupVersion creates a new version of an env group
+func (repo *EnvGroupRepository) CreateEnvGroupVersion(
+	version *models.EnvGroupVersion,
+) (*models.EnvGroupVersion, error) {
+	if err := repo.EncryptEnvGroupVersionData(version, repo.key); err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Create(version).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.DecryptEnvGroupVersionData(version, repo.key); err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// ReadEnvGroupVersion gets a version of an env group
+func (repo *EnvGroupRepository) ReadEnvGroupVersion(
+	envGroupID, version uint,
+) (*models.EnvGroupVersion, error) {
+	res := &models.EnvGroupVersion{}
+
+	if err := repo.db.Where("env_group_id = ? AND version = ?", envGroupID, version).First(&res).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.DecryptEnvGroupVersionData(res, repo.key); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ListEnvGroupVersions finds all versions of an env group, newest first
+func (repo *EnvGroupRepository) ListEnvGroupVersions(
+	envGroupID uint,
+) ([]*models.EnvGroupVersion, error) {
+	versions := []*models.EnvGroupVersion{}
+
+	if err := repo.db.Where("env_group_id = ?", envGroupID).Order("version desc").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+
+	for _, version := range versions {
+		if err := repo.DecryptEnvGroupVersionData(version, repo.key); err != nil {
+			return nil, err
+		}
+	}
+
+	return versions, nil
+}
+
+// CreateEnvGroupAttachment attaches an env group to a release
+func (repo *EnvGroupRepository) CreateEnvGroupAttachment(
+	attachment *models.EnvGroupAttachment,
+) (*models.EnvGroupAttachment, error) {
+	if err := repo.db.Create(attachment).Error; err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// DeleteEnvGroupAttachment detaches an env group from a release
+func (repo *EnvGroupRepository) DeleteEnvGroupAttachment(
+	attachment *models.EnvGroupAttachment,
+) error {
+	return repo.db.Where("id = ?", attachment.ID).Delete(&models.EnvGroupAttachment{}).Error
+}
+
+// EncryptEnvGroupVersionData will encrypt the secret variables of an env group
+// version before writing to the DB
+func (repo *EnvGroupRepository) EncryptEnvGroupVersionData(
+	version *models.EnvGroupVersion,
+	key *[32]byte,
+) error {
+	if len(version.SecretVariables) > 0 {
+		cipherData, err := repository.Encrypt(version.SecretVariables, key)
+
+		if err != nil {
+			return err
+		}
+
+		version.SecretVariables = cipherData
+	}
+
+	return nil
+}
+
+// DecryptEnvGroupVersionData will decrypt the secret variables of an env group
+// version before returning it from the DB
+func (repo *EnvGroupRepository) DecryptEnvGroupVersionData(
+	version *models.EnvGroupVersion,
+	key *[32]byte,
+) error {
+	if len(version.SecretVariables) > 0 {
+		plaintext, err := repository.Decrypt(version.SecretVariables, key)
+
+		if err != nil {
+			return err
+		}
+
+		version.SecretVariables = plaintext
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestEnvGroupVersions(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_env_group_versions.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	group, err := tester.repo.EnvGroup.CreateEnvGroup(&models.EnvGroup{
+		ProjectID: tester.initProjects[0].ID,
+		ClusterID: 1,
+		Name:      "shared",
+		Version:   1,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	for i, secret := range []string{"first", "second"} {
+		version := &models.EnvGroupVersion{
+			EnvGroupID: group.ID,
+			Version:    uint(i + 1),
+		}
+
+		if err := version.SetVariables(
+			map[string]string{"LOG_LEVEL": "info"},
+			map[string]string{"DATABASE_URL": secret},
+		); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		if _, err := tester.repo.EnvGroup.CreateEnvGroupVersion(version); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	version, err := tester.repo.EnvGroup.ReadEnvGroupVersion(group.ID, 2)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	secrets, err := version.GetSecretVariables()
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if diff := deep.Equal(secrets, map[string]string{"DATABASE_URL": "second"}); diff != nil {
+		t.Errorf("incorrect secret variables: %v\n", diff)
+	}
+
+	versions, err := tester.repo.EnvGroup.ListEnvGroupVersions(group.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(versions) != 2 || versions[0].Version != 2 || versions[1].Version != 1 {
+		t.Errorf("incorrect versions: expected [2 1], got %d versions\n", len(versions))
+	}
+}
+
+func TestListEnvGroupsByRelease(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_env_groups_by_release.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	groups := make([]*models.EnvGroup, 0)
+
+	for _, name := range []string{"first", "second", "other"} {
+		group, err := tester.repo.EnvGroup.CreateEnvGroup(&models.EnvGroup{
+			ProjectID: tester.initProjects[0].ID,
+			ClusterID: 1,
+			Name:      name,
+			Version:   1,
+		})
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		groups = append(groups, group)
+	}
+
+	// attach the second group first, so that the attachment order is checked
+	attachments := []*models.EnvGroupAttachment{
+		{EnvGroupID: groups[1].ID, ReleaseName: "web", Namespace: "default"},
+		{EnvGroupID: groups[0].ID, ReleaseName: "web", Namespace: "default"},
+		{EnvGroupID: groups[2].ID, ReleaseName: "web", Namespace: "staging"},
+	}
+
+	for _, attachment := range attachments {
+		if _, err := tester.repo.EnvGroup.CreateEnvGroupAttachment(attachment); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	res, err := tester.repo.EnvGroup.ListEnvGroupsByRelease(1, "default", "web")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(res) != 2 || res[0].Name != "second" || res[1].Name != "first" {
+		t.Fatalf("incorrect env groups: got %d groups\n", len(res))
+	}
+
+	if err := tester.repo.EnvGroup.DeleteEnvGroupAttachment(attachments[1]); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	res, err = tester.repo.EnvGroup.ListEnvGroupsByRelease(1, "default", "web")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(res) != 1 || res[0].Name != "second" {
+		t.Errorf("env group was not detached: got %d groups\n", len(res))
+	}
+
+	group, err := tester.repo.EnvGroup.ReadEnvGroup(groups[1].ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(group.Attachments) != 1 || group.Attachment("default", "web") == nil {
+		t.Errorf("attachments were not read with the env group\n")
+	}
+}
@@ -51,6 +51,22 @@ func setupTestEnv(tester *tester, t *testing.T) {
 	err = db.AutoMigrate(
 		&models.Project{},
 		&models.Role{},
+		&models.RoleBinding{},
+		&models.APIToken{},
+		&models.AuditEvent{},
+		&models.SSOGroupMapping{},
+		&models.TwoFactor{},
+		&models.PreviewEnvironment{},
+		&models.Deployment{},
+		&models.NotificationChannel{},
+		&models.CanaryRollout{},
+		&models.EnvGroup{},
+		&models.EnvGroupVersion{},
+		&models.EnvGroupAttachment{},
+		&models.ProjectSecret{},
+		&models.DeployPolicy{},
+		&models.ReleaseSet{},
+		&models.ReleaseSetTarget{},
 		&models.User{},
 		&models.Session{},
 		&models.GitRepo{},
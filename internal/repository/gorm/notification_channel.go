@@ -0,0 +1,168 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// NotificationChannelRepository uses gorm.DB for querying the database
+type NotificationChannelRepository struct {
+	db  *gorm.DB
+	key *[32]byte
+}
+
+// NewNotificationChannelRepository returns a NotificationChannelRepository which
+// uses gorm.DB for querying the database. It accepts an encryption key to encrypt
+// sensitive data
+func NewNotificationChannelRepository(
+	db *gorm.DB,
+	key *[32]byte,
+) repository.NotificationChannelRepository {
+	return &NotificationChannelRepository{db, key}
+}
+
+// CreateNotificationChannel creates a new notification channel
+func (repo *NotificationChannelRepository) CreateNotificationChannel(
+	channel *models.NotificationChannel,
+) (*models.NotificationChannel, error) {
+	if err := repo.EncryptNotificationChannelData(channel, repo.key); err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Create(channel).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.DecryptNotificationChannelData(channel, repo.key); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// ReadNotificationChannel gets a notification channel specified by a unique id
+func (repo *NotificationChannelRepository) ReadNotificationChannel(
+	id uint,
+) (*models.NotificationChannel, error) {
+	channel := &models.NotificationChannel{}
+
+	if err := repo.db.Where("id = ?", id).First(&channel).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.DecryptNotificationChannelData(channel, repo.key); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// ListNotificationChannelsByProjectID finds all notification channels for a
+// given project id
+func (repo *NotificationChannelRepository) ListNotificationChannelsByProjectID(
+	projectID uint,
+) ([]*models.NotificationChannel, error) {
+	channels := []*models.NotificationChannel{}
+
+	if err := repo.db.Where("project_id = ?", projectID).Find(&channels).Error; err != nil {
+		return nil, err
+	}
+
+	for _, channel := range channels {
+		if err := repo.DecryptNotificationChannelData(channel, repo.key); err != nil {
+			return nil, err
+		}
+	}
+
+	return channels, nil
+}
+
+// UpdateNotificationChannel modifies an existing notification channel in the
+// database
+func (repo *NotificationChannelRepository) UpdateNotificationChannel(
+	channel *models.NotificationChannel,
+) (*models.NotificationChannel, error) {
+	if err := repo.EncryptNotificationChannelData(channel, repo.key); err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Save(channel).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.DecryptNotificationChannelData(channel, repo.key); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// DeleteNotificationChannel removes a notification channel from the db
+func (repo *NotificationChannelRepository) DeleteNotificationChannel(
+	channel *models.NotificationChannel,
+) error {
+	if err := repo.db.Where("id = ?", channel.ID).Delete(&models.NotificationChannel{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EncryptNotificationChannelData will encrypt the channel target and signing
+// secret before writing to the DB
+func (repo *NotificationChannelRepository) EncryptNotificationChannelData(
+	channel *models.NotificationChannel,
+	key *[32]byte,
+) error {
+	if len(channel.Target) > 0 {
+		cipherData, err := repository.Encrypt(channel.Target, key)
+
+		if err != nil {
+			return err
+		}
+
+		channel.Target = cipherData
+	}
+
+	if len(channel.SigningSecret) > 0 {
+		cipherData, err := repository.Encrypt(channel.SigningSecret, key)
+
+		if err != nil {
+			return err
+		}
+
+		channel.SigningSecret = cipherData
+	}
+
+	return nil
+}
+
+// DecryptNotificationChannelData will decrypt the channel target and signing
+// secret before returning it from the DB
+func (repo *NotificationChannelRepository) DecryptNotificationChannelData(
+	channel *models.NotificationChannel,
+	key *[32]byte,
+) error {
+	if len(channel.Target) > 0 {
+		plaintext, err := repository.Decrypt(channel.Target, key)
+
+		if err != nil {
+			return err
+		}
+
+		channel.Target = plaintext
+	}
+
+	if len(channel.SigningSecret) > 0 {
+		plaintext, err := repository.Decrypt(channel.SigningSecret, key)
+
+		if err != nil {
+			return err
+		}
+
+		channel.SigningSecret = plaintext
+	}
+
+	return nil
+}
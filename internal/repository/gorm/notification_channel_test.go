@@ -0,0 +1,80 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestCreateNotificationChannel(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_create_notification_channel.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	channel := &models.NotificationChannel{
+		ProjectID:     tester.initProjects[0].ID,
+		Name:          "on-call",
+		Kind:          models.NotificationChannelWebhook,
+		Events:        "deploy_failed,rollback",
+		Target:        []byte("https://example.com/hooks/porter"),
+		SigningSecret: []byte("secret"),
+	}
+
+	channel, err := tester.repo.NotificationChannel.CreateNotificationChannel(channel)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	channel, err = tester.repo.NotificationChannel.ReadNotificationChannel(channel.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// make sure the encrypted fields are decrypted when read
+	if string(channel.Target) != "https://example.com/hooks/porter" {
+		t.Errorf("incorrect target: expected %s, got %s\n", "https://example.com/hooks/porter", channel.Target)
+	}
+
+	if string(channel.SigningSecret) != "secret" {
+		t.Errorf("incorrect signing secret: expected %s, got %s\n", "secret", channel.SigningSecret)
+	}
+
+	if !channel.IsSubscribed(models.NotificationEventRollback) ||
+		channel.IsSubscribed(models.NotificationEventInfraError) {
+		t.Errorf("incorrect subscriptions: got %s\n", channel.Events)
+	}
+
+	channels, err := tester.repo.NotificationChannel.ListNotificationChannelsByProjectID(
+		tester.initProjects[0].ID,
+	)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(channels) != 1 {
+		t.Fatalf("length of channels incorrect: expected %d, got %d\n", 1, len(channels))
+	}
+
+	if err := tester.repo.NotificationChannel.DeleteNotificationChannel(channel); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	channels, err = tester.repo.NotificationChannel.ListNotificationChannelsByProjectID(
+		tester.initProjects[0].ID,
+	)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(channels) != 0 {
+		t.Errorf("length of channels incorrect: expected %d, got %d\n", 0, len(channels))
+	}
+}
@@ -0,0 +1,80 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PreviewEnvironmentRepository uses gorm.DB for querying the database
+type PreviewEnvironmentRepository struct {
+	db *gorm.DB
+}
+
+// NewPreviewEnvironmentRepository returns a PreviewEnvironmentRepository which
+// uses gorm.DB for querying the database
+func NewPreviewEnvironmentRepository(db *gorm.DB) repository.PreviewEnvironmentRepository {
+	return &PreviewEnvironmentRepository{db}
+}
+
+// CreatePreviewEnvironment creates a new preview environment
+func (repo *PreviewEnvironmentRepository) CreatePreviewEnvironment(
+	env *models.PreviewEnvironment,
+) (*models.PreviewEnvironment, error) {
+	if err := repo.db.Create(env).Error; err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// ReadPreviewEnvironmentByPR finds the preview environment of a release for
+// a given pull request number
+func (repo *PreviewEnvironmentRepository) ReadPreviewEnvironmentByPR(
+	releaseID, prNumber uint,
+) (*models.PreviewEnvironment, error) {
+	env := &models.PreviewEnvironment{}
+
+	if err := repo.db.Where("release_id = ? AND pr_number = ?", releaseID, prNumber).First(&env).Error; err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// ListPreviewEnvironmentsByReleaseID finds all preview environments for a
+// given release id
+func (repo *PreviewEnvironmentRepository) ListPreviewEnvironmentsByReleaseID(
+	releaseID uint,
+) ([]*models.PreviewEnvironment, error) {
+	envs := []*models.PreviewEnvironment{}
+
+	if err := repo.db.Where("release_id = ?", releaseID).Find(&envs).Error; err != nil {
+		return nil, err
+	}
+
+	return envs, nil
+}
+
+// UpdatePreviewEnvironment modifies an existing preview environment in the
+// database
+func (repo *PreviewEnvironmentRepository) UpdatePreviewEnvironment(
+	env *models.PreviewEnvironment,
+) (*models.PreviewEnvironment, error) {
+	if err := repo.db.Save(env).Error; err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// DeletePreviewEnvironment removes a preview environment from the db
+func (repo *PreviewEnvironmentRepository) DeletePreviewEnvironment(
+	env *models.PreviewEnvironment,
+) error {
+	if err := repo.db.Unscoped().Where("id = ?", env.ID).Delete(&models.PreviewEnvironment{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
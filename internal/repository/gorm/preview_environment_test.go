@@ -0,0 +1,69 @@
+package gorm_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestReadPreviewEnvironmentByPR(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_read_preview_environment.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	for _, pr := range []uint{1, 2} {
+		_, err := tester.repo.PreviewEnvironment.CreatePreviewEnvironment(&models.PreviewEnvironment{
+			ProjectID: tester.initProjects[0].ID,
+			ReleaseID: 1,
+			PRNumber:  pr,
+			Name:      fmt.Sprintf("web-pr-%d", pr),
+			Namespace: fmt.Sprintf("preview-web-pr-%d", pr),
+		})
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	env, err := tester.repo.PreviewEnvironment.ReadPreviewEnvironmentByPR(1, 2)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	expEnv := models.PreviewEnvironment{
+		ProjectID: tester.initProjects[0].ID,
+		ReleaseID: 1,
+		PRNumber:  2,
+		Name:      "web-pr-2",
+		Namespace: "preview-web-pr-2",
+	}
+
+	// reset fields for reflect.DeepEqual
+	env.Model = gorm.Model{}
+
+	if diff := deep.Equal(expEnv, *env); diff != nil {
+		t.Errorf("incorrect preview environment")
+		t.Error(diff)
+	}
+
+	// deleting the preview should make it unreadable
+	env, _ = tester.repo.PreviewEnvironment.ReadPreviewEnvironmentByPR(1, 2)
+
+	if err := tester.repo.PreviewEnvironment.DeletePreviewEnvironment(env); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	_, err = tester.repo.PreviewEnvironment.ReadPreviewEnvironmentByPR(1, 2)
+
+	if err != gorm.ErrRecordNotFound {
+		t.Fatalf("expected record not found, got %v\n", err)
+	}
+}
@@ -0,0 +1,148 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ProjectSecretRepository uses gorm.DB for querying the database
+type ProjectSecretRepository struct {
+	db  *gorm.DB
+	key *[32]byte
+}
+
+// NewProjectSecretRepository returns a ProjectSecretRepository which uses
+// gorm.DB for querying the database. It accepts an encryption key to encrypt
+// sensitive data
+func NewProjectSecretRepository(
+	db *gorm.DB,
+	key *[32]byte,
+) repository.ProjectSecretRepository {
+	return &ProjectSecretRepository{db, key}
+}
+
+// CreateProjectSecret creates a new project secret
+func (repo *ProjectSecretRepository) CreateProjectSecret(
+	secret *models.ProjectSecret,
+) (*models.ProjectSecret, error) {
+	if err := repo.EncryptProjectSecretData(secret, repo.key); err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Create(secret).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.DecryptProjectSecretData(secret, repo.key); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// ReadProjectSecretByName gets a project secret by its name in the project
+func (repo *ProjectSecretRepository) ReadProjectSecretByName(
+	projectID uint,
+	name string,
+) (*models.ProjectSecret, error) {
+	secret := &models.ProjectSecret{}
+
+	if err := repo.db.Where("project_id = ? AND name = ?", projectID, name).First(&secret).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.DecryptProjectSecretData(secret, repo.key); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// ListProjectSecretsByProjectID finds all secrets for a given project id,
+// ordered by name
+func (repo *ProjectSecretRepository) ListProjectSecretsByProjectID(
+	projectID uint,
+) ([]*models.ProjectSecret, error) {
+	secrets := []*models.ProjectSecret{}
+
+	if err := repo.db.Where("project_id = ?", projectID).Order("name asc").Find(&secrets).Error; err != nil {
+		return nil, err
+	}
+
+	for _, secret := range secrets {
+		if err := repo.DecryptProjectSecretData(secret, repo.key); err != nil {
+			return nil, err
+		}
+	}
+
+	return secrets, nil
+}
+
+// UpdateProjectSecret modifies an existing project secret in the database
+func (repo *ProjectSecretRepository) UpdateProjectSecret(
+	secret *models.ProjectSecret,
+) (*models.ProjectSecret, error) {
+	if err := repo.EncryptProjectSecretData(secret, repo.key); err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Save(secret).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.DecryptProjectSecretData(secret, repo.key); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// DeleteProjectSecret removes a project secret from the db
+func (repo *ProjectSecretRepository) DeleteProjectSecret(
+	secret *models.ProjectSecret,
+) error {
+	if err := repo.db.Where("id = ?", secret.ID).Delete(&models.ProjectSecret{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EncryptProjectSecretData will encrypt the secret value before writing to the
+// DB
+func (repo *ProjectSecretRepository) EncryptProjectSecretData(
+	secret *models.ProjectSecret,
+	key *[32]byte,
+) error {
+	if len(secret.Value) > 0 {
+		cipherData, err := repository.Encrypt(secret.Value, key)
+
+		if err != nil {
+			return err
+		}
+
+		secret.Value = cipherData
+	}
+
+	return nil
+}
+
+// DecryptProjectSecretData will decrypt the secret value before returning it
+// from the DB
+func (repo *ProjectSecretRepository) DecryptProjectSecretData(
+	secret *models.ProjectSecret,
+	key *[32]byte,
+) error {
+	if len(secret.Value) > 0 {
+		plaintext, err := repository.Decrypt(secret.Value, key)
+
+		if err != nil {
+			return err
+		}
+
+		secret.Value = plaintext
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestReadProjectSecretByName(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_project_secret.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	secret, err := tester.repo.ProjectSecret.CreateProjectSecret(&models.ProjectSecret{
+		ProjectID: tester.initProjects[0].ID,
+		Name:      "DATABASE_URL",
+		Value:     []byte("postgres://"),
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	secret, err = tester.repo.ProjectSecret.ReadProjectSecretByName(tester.initProjects[0].ID, "DATABASE_URL")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if string(secret.Value) != "postgres://" {
+		t.Errorf("incorrect secret value: expected postgres://, got %s\n", secret.Value)
+	}
+
+	if _, err := tester.repo.ProjectSecret.ReadProjectSecretByName(tester.initProjects[0].ID+1, "DATABASE_URL"); err == nil {
+		t.Errorf("secret was read from another project\n")
+	}
+}
@@ -170,6 +170,94 @@ func TestListProjectsByUserID(t *testing.T) {
 
 }
 
+func TestUpdateProjectRole(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_update_proj_role.db",
+	}
+
+	setupTestEnv(tester, t)
+	initUser(tester, t)
+	initProject(tester, t)
+	initProjectRole(tester, t)
+	defer cleanup(tester, t)
+
+	role, err := tester.repo.Project.ReadProjectRole(
+		tester.initProjects[0].Model.ID,
+		tester.initUsers[0].Model.ID,
+	)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	role.Kind = models.RoleDeployer
+
+	_, err = tester.repo.Project.UpdateProjectRole(tester.initProjects[0].Model.ID, role)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	role, err = tester.repo.Project.ReadProjectRole(
+		tester.initProjects[0].Model.ID,
+		tester.initUsers[0].Model.ID,
+	)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if role.Kind != models.RoleDeployer {
+		t.Errorf("incorrect role kind: expected %s, got %s\n", models.RoleDeployer, role.Kind)
+	}
+
+	// make sure a role cannot be updated through a different project
+	_, err = tester.repo.Project.UpdateProjectRole(2, role)
+
+	if err != gorm.ErrRecordNotFound {
+		t.Fatalf("update should have returned record not found: returned %v\n", err)
+	}
+}
+
+func TestDeleteProjectRole(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_delete_proj_role.db",
+	}
+
+	setupTestEnv(tester, t)
+	initUser(tester, t)
+	initProject(tester, t)
+	initProjectRole(tester, t)
+	defer cleanup(tester, t)
+
+	role, err := tester.repo.Project.ReadProjectRole(
+		tester.initProjects[0].Model.ID,
+		tester.initUsers[0].Model.ID,
+	)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// make sure a role cannot be deleted through a different project
+	if _, err := tester.repo.Project.DeleteProjectRole(2, role); err != gorm.ErrRecordNotFound {
+		t.Fatalf("delete should have returned record not found: returned %v\n", err)
+	}
+
+	if _, err := tester.repo.Project.DeleteProjectRole(tester.initProjects[0].Model.ID, role); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	_, err = tester.repo.Project.ReadProjectRole(
+		tester.initProjects[0].Model.ID,
+		tester.initUsers[0].Model.ID,
+	)
+
+	if err != gorm.ErrRecordNotFound {
+		t.Fatalf("read should have returned record not found: returned %v\n", err)
+	}
+}
+
 func TestDeleteProject(t *testing.T) {
 	tester := &tester{
 		dbFileName: "./porter_create_proj_role.db",
@@ -43,6 +43,15 @@ func (repo *ReleaseRepository) ReadReleaseByWebhookToken(token string) (*models.
 	return release, nil
 }
 
+// ListReleasesByClusterID finds all releases that are stored for a cluster
+func (repo *ReleaseRepository) ListReleasesByClusterID(clusterID uint) ([]*models.Release, error) {
+	releases := []*models.Release{}
+	if err := repo.db.Where("cluster_id = ?", clusterID).Find(&releases).Error; err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
 // UpdateRelease modifies an existing Release in the database
 func (repo *ReleaseRepository) UpdateRelease(release *models.Release) (*models.Release, error) {
 	if err := repo.db.Save(release).Error; err != nil {
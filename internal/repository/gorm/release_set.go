@@ -0,0 +1,130 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ReleaseSetRepository uses gorm.DB for querying the database
+type ReleaseSetRepository struct {
+	db *gorm.DB
+}
+
+// NewReleaseSetRepository returns a ReleaseSetRepository which uses gorm.DB
+// for querying the database
+func NewReleaseSetRepository(db *gorm.DB) repository.ReleaseSetRepository {
+	return &ReleaseSetRepository{db}
+}
+
+// CreateReleaseSet creates a new release set along with its targets
+func (repo *ReleaseSetRepository) CreateReleaseSet(
+	set *models.ReleaseSet,
+) (*models.ReleaseSet, error) {
+	if err := repo.db.Create(set).Error; err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// ReadReleaseSet gets a release set and its targets specified by a unique id
+func (repo *ReleaseSetRepository) ReadReleaseSet(
+	id uint,
+) (*models.ReleaseSet, error) {
+	set := &models.ReleaseSet{}
+
+	if err := repo.preloadTargets().Where("id = ?", id).First(&set).Error; err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// ReadReleaseSetByWebhookToken finds a release set and its targets by its
+// webhook token
+func (repo *ReleaseSetRepository) ReadReleaseSetByWebhookToken(
+	token string,
+) (*models.ReleaseSet, error) {
+	set := &models.ReleaseSet{}
+
+	if err := repo.preloadTargets().Where("webhook_token = ?", token).First(&set).Error; err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// ListReleaseSetsByProjectID finds all release sets for a given project id
+func (repo *ReleaseSetRepository) ListReleaseSetsByProjectID(
+	projectID uint,
+) ([]*models.ReleaseSet, error) {
+	sets := []*models.ReleaseSet{}
+
+	if err := repo.preloadTargets().Where("project_id = ?", projectID).Find(&sets).Error; err != nil {
+		return nil, err
+	}
+
+	return sets, nil
+}
+
+// UpdateReleaseSet modifies an existing release set in the database, without
+// modifying its targets
+func (repo *ReleaseSetRepository) UpdateReleaseSet(
+	set *models.ReleaseSet,
+) (*models.ReleaseSet, error) {
+	if err := repo.db.Omit("Targets").Save(set).Error; err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// DeleteReleaseSet removes a release set from the db, along with its targets
+func (repo *ReleaseSetRepository) DeleteReleaseSet(
+	set *models.ReleaseSet,
+) error {
+	if err := repo.db.Where("release_set_id = ?", set.ID).Delete(&models.ReleaseSetTarget{}).Error; err != nil {
+		return err
+	}
+
+	return repo.db.Where("id = ?", set.ID).Delete(&models.ReleaseSet{}).Error
+}
+
+// CreateReleaseSetTarget adds a target to a release set
+func (repo *ReleaseSetRepository) CreateReleaseSetTarget(
+	target *models.ReleaseSetTarget,
+) (*models.ReleaseSetTarget, error) {
+	if err := repo.db.Create(target).Error; err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// UpdateReleaseSetTarget modifies an existing target, such as the status of
+// its last deploy
+func (repo *ReleaseSetRepository) UpdateReleaseSetTarget(
+	target *models.ReleaseSetTarget,
+) (*models.ReleaseSetTarget, error) {
+	if err := repo.db.Save(target).Error; err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// DeleteReleaseSetTarget removes a target from a release set
+func (repo *ReleaseSetRepository) DeleteReleaseSetTarget(
+	target *models.ReleaseSetTarget,
+) error {
+	return repo.db.Where("id = ?", target.ID).Delete(&models.ReleaseSetTarget{}).Error
+}
+
+// preloadTargets loads the targets of release sets in the order that they
+// were added
+func (repo *ReleaseSetRepository) preloadTargets() *gorm.DB {
+	return repo.db.Preload("Targets", func(db *gorm.DB) *gorm.DB {
+		return db.Order("release_set_targets.id asc")
+	})
+}
@@ -0,0 +1,94 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestReleaseSetTargets(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_release_set_targets.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	set, err := tester.repo.ReleaseSet.CreateReleaseSet(&models.ReleaseSet{
+		ProjectID:     tester.initProjects[0].ID,
+		Name:          "api",
+		Strategy:      models.ReleaseSetStrategySequential,
+		FailurePolicy: models.ReleaseSetFailureStop,
+		WebhookToken:  "abcdefgh",
+		Targets: []models.ReleaseSetTarget{
+			{ClusterID: 2, Namespace: "default", ReleaseName: "api"},
+			{ClusterID: 1, Namespace: "default", ReleaseName: "api"},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if _, err := tester.repo.ReleaseSet.CreateReleaseSetTarget(&models.ReleaseSetTarget{
+		ReleaseSetID: set.ID,
+		ClusterID:    3,
+		Namespace:    "default",
+		ReleaseName:  "api",
+	}); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if err := tester.repo.ReleaseSet.DeleteReleaseSetTarget(&set.Targets[1]); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	target := set.Targets[0]
+	target.Status = models.ReleaseSetTargetStatusDeployed
+	target.Revision = 4
+
+	if _, err := tester.repo.ReleaseSet.UpdateReleaseSetTarget(&target); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// updating the set doesn't recreate the deleted target
+	set.Name = "api-global"
+
+	if _, err := tester.repo.ReleaseSet.UpdateReleaseSet(set); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	set, err = tester.repo.ReleaseSet.ReadReleaseSetByWebhookToken("abcdefgh")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if set.Name != "api-global" {
+		t.Errorf("incorrect name: expected api-global, got %s\n", set.Name)
+	}
+
+	// targets are returned in the order that they were added
+	if len(set.Targets) != 2 || set.Targets[0].ClusterID != 2 || set.Targets[1].ClusterID != 3 {
+		t.Fatalf("incorrect targets: expected clusters [2 3], got %v\n", set.Targets)
+	}
+
+	if set.Targets[0].Status != models.ReleaseSetTargetStatusDeployed || set.Targets[0].Revision != 4 {
+		t.Errorf("target status not updated: got %s revision %d\n", set.Targets[0].Status, set.Targets[0].Revision)
+	}
+
+	if err := tester.repo.ReleaseSet.DeleteReleaseSet(set); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	sets, err := tester.repo.ReleaseSet.ListReleaseSetsByProjectID(tester.initProjects[0].ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(sets) != 0 {
+		t.Errorf("incorrect number of release sets: expected 0, got %d\n", len(sets))
+	}
+}
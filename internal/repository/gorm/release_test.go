@@ -1,6 +1,7 @@
 package gorm_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/porter-dev/porter/internal/models"
@@ -95,3 +96,42 @@ func TestDeleteRelease(t *testing.T) {
 		t.Fatalf("incorrect error: expected %v, got %v\n", orm.ErrRecordNotFound, err)
 	}
 }
+
+func TestListReleasesByClusterID(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_releases.db",
+	}
+
+	setupTestEnv(tester, t)
+	defer cleanup(tester, t)
+
+	for i, clusterID := range []uint{1, 1, 2} {
+		_, err := tester.repo.Release.CreateRelease(&models.Release{
+			Name:         fmt.Sprintf("release-%d", i),
+			Namespace:    "default",
+			ProjectID:    1,
+			ClusterID:    clusterID,
+			WebhookToken: fmt.Sprintf("token-%d", i),
+		})
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	releases, err := tester.repo.Release.ListReleasesByClusterID(1)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(releases) != 2 {
+		t.Fatalf("incorrect number of releases: expected %d, got %d\n", 2, len(releases))
+	}
+
+	for _, release := range releases {
+		if release.ClusterID != 1 {
+			t.Errorf("release %s is in cluster %d\n", release.Name, release.ClusterID)
+		}
+	}
+}
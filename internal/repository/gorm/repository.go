@@ -9,25 +9,38 @@ import (
 // gorm.DB for querying the database
 func NewRepository(db *gorm.DB, key *[32]byte) *repository.Repository {
 	return &repository.Repository{
-		User:             NewUserRepository(db),
-		Session:          NewSessionRepository(db),
-		Project:          NewProjectRepository(db),
-		Release:          NewReleaseRepository(db),
-		GitRepo:          NewGitRepoRepository(db, key),
-		Cluster:          NewClusterRepository(db, key),
-		HelmRepo:         NewHelmRepoRepository(db, key),
-		Registry:         NewRegistryRepository(db, key),
-		Infra:            NewInfraRepository(db, key),
-		GitActionConfig:  NewGitActionConfigRepository(db),
-		Invite:           NewInviteRepository(db),
-		AuthCode:         NewAuthCodeRepository(db),
-		DNSRecord:        NewDNSRecordRepository(db),
-		PWResetToken:     NewPWResetTokenRepository(db),
-		KubeIntegration:  NewKubeIntegrationRepository(db, key),
-		BasicIntegration: NewBasicIntegrationRepository(db, key),
-		OIDCIntegration:  NewOIDCIntegrationRepository(db, key),
-		OAuthIntegration: NewOAuthIntegrationRepository(db, key),
-		GCPIntegration:   NewGCPIntegrationRepository(db, key),
-		AWSIntegration:   NewAWSIntegrationRepository(db, key),
+		User:                NewUserRepository(db),
+		Session:             NewSessionRepository(db),
+		Project:             NewProjectRepository(db),
+		Release:             NewReleaseRepository(db),
+		GitRepo:             NewGitRepoRepository(db, key),
+		Cluster:             NewClusterRepository(db, key),
+		HelmRepo:            NewHelmRepoRepository(db, key),
+		Registry:            NewRegistryRepository(db, key),
+		Infra:               NewInfraRepository(db, key),
+		GitActionConfig:     NewGitActionConfigRepository(db),
+		Invite:              NewInviteRepository(db),
+		RoleBinding:         NewRoleBindingRepository(db),
+		APIToken:            NewAPITokenRepository(db),
+		AuditEvent:          NewAuditEventRepository(db),
+		SSOGroupMapping:     NewSSOGroupMappingRepository(db),
+		TwoFactor:           NewTwoFactorRepository(db, key),
+		PreviewEnvironment:  NewPreviewEnvironmentRepository(db),
+		Deployment:          NewDeploymentRepository(db),
+		NotificationChannel: NewNotificationChannelRepository(db, key),
+		CanaryRollout:       NewCanaryRolloutRepository(db),
+		EnvGroup:            NewEnvGroupRepository(db, key),
+		ProjectSecret:       NewProjectSecretRepository(db, key),
+		DeployPolicy:        NewDeployPolicyRepository(db),
+		ReleaseSet:          NewReleaseSetRepository(db),
+		AuthCode:            NewAuthCodeRepository(db),
+		DNSRecord:           NewDNSRecordRepository(db),
+		PWResetToken:        NewPWResetTokenRepository(db),
+		KubeIntegration:     NewKubeIntegrationRepository(db, key),
+		BasicIntegration:    NewBasicIntegrationRepository(db, key),
+		OIDCIntegration:     NewOIDCIntegrationRepository(db, key),
+		OAuthIntegration:    NewOAuthIntegrationRepository(db, key),
+		GCPIntegration:      NewGCPIntegrationRepository(db, key),
+		AWSIntegration:      NewAWSIntegrationRepository(db, key),
 	}
 }
@@ -0,0 +1,97 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// RoleBindingRepository uses gorm.DB for querying the database
+type RoleBindingRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleBindingRepository returns a RoleBindingRepository which uses
+// gorm.DB for querying the database
+func NewRoleBindingRepository(db *gorm.DB) repository.RoleBindingRepository {
+	return &RoleBindingRepository{db}
+}
+
+// CreateRoleBinding creates a new role binding
+func (repo *RoleBindingRepository) CreateRoleBinding(
+	rb *models.RoleBinding,
+) (*models.RoleBinding, error) {
+	if err := repo.db.Create(rb).Error; err != nil {
+		return nil, err
+	}
+
+	return rb, nil
+}
+
+// ReadRoleBinding gets a role binding specified by a unique id
+func (repo *RoleBindingRepository) ReadRoleBinding(id uint) (*models.RoleBinding, error) {
+	rb := &models.RoleBinding{}
+
+	if err := repo.db.Where("id = ?", id).First(&rb).Error; err != nil {
+		return nil, err
+	}
+
+	return rb, nil
+}
+
+// ListRoleBindingsByProjectID finds all role bindings
+// for a given project id
+func (repo *RoleBindingRepository) ListRoleBindingsByProjectID(
+	projectID uint,
+) ([]*models.RoleBinding, error) {
+	rbs := []*models.RoleBinding{}
+
+	if err := repo.db.Where("project_id = ?", projectID).Find(&rbs).Error; err != nil {
+		return nil, err
+	}
+
+	return rbs, nil
+}
+
+// ListRoleBindingsByUserID finds all role bindings for a
+// given user in a given project
+func (repo *RoleBindingRepository) ListRoleBindingsByUserID(
+	projectID, userID uint,
+) ([]*models.RoleBinding, error) {
+	rbs := []*models.RoleBinding{}
+
+	query := repo.db.Where("project_id = ? AND user_id = ? AND (api_token_id = 0 OR api_token_id IS NULL)", projectID, userID)
+
+	if err := query.Find(&rbs).Error; err != nil {
+		return nil, err
+	}
+
+	return rbs, nil
+}
+
+// ListRoleBindingsByAPITokenID finds all role bindings for a
+// given API token in a given project
+func (repo *RoleBindingRepository) ListRoleBindingsByAPITokenID(
+	projectID, apiTokenID uint,
+) ([]*models.RoleBinding, error) {
+	rbs := []*models.RoleBinding{}
+
+	query := repo.db.Where("project_id = ? AND api_token_id = ?", projectID, apiTokenID)
+
+	if err := query.Find(&rbs).Error; err != nil {
+		return nil, err
+	}
+
+	return rbs, nil
+}
+
+// DeleteRoleBinding removes a role binding from the db
+func (repo *RoleBindingRepository) DeleteRoleBinding(
+	rb *models.RoleBinding,
+) error {
+	if err := repo.db.Where("id = ?", rb.ID).Delete(&models.RoleBinding{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestCreateRoleBinding(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_create_role_binding.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	initCluster(tester, t)
+	defer cleanup(tester, t)
+
+	rb := &models.RoleBinding{
+		ProjectID: tester.initProjects[0].ID,
+		UserID:    1,
+		ClusterID: tester.initClusters[0].ID,
+		Namespace: "default",
+	}
+
+	rb, err := tester.repo.RoleBinding.CreateRoleBinding(rb)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	rb, err = tester.repo.RoleBinding.ReadRoleBinding(rb.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// make sure id is 1 and namespace is "default"
+	if rb.ID != 1 {
+		t.Errorf("incorrect role binding ID: expected %d, got %d\n", 1, rb.ID)
+	}
+
+	if rb.Namespace != "default" {
+		t.Errorf("incorrect namespace: expected %s, got %s\n", "default", rb.Namespace)
+	}
+}
+
+func TestListRoleBindingsByUserID(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_role_bindings.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	initCluster(tester, t)
+	defer cleanup(tester, t)
+
+	for _, userID := range []uint{1, 2} {
+		_, err := tester.repo.RoleBinding.CreateRoleBinding(&models.RoleBinding{
+			ProjectID: tester.initProjects[0].ID,
+			UserID:    userID,
+			ClusterID: tester.initClusters[0].ID,
+		})
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	// bindings of an API token created by the user are not the user's own
+	_, err := tester.repo.RoleBinding.CreateRoleBinding(&models.RoleBinding{
+		ProjectID:  tester.initProjects[0].ID,
+		UserID:     2,
+		APITokenID: 1,
+		ClusterID:  tester.initClusters[0].ID,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	rbs, err := tester.repo.RoleBinding.ListRoleBindingsByUserID(tester.initProjects[0].ID, 2)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(rbs) != 1 {
+		t.Fatalf("length of role bindings incorrect: expected %d, got %d\n", 1, len(rbs))
+	}
+
+	expRB := models.RoleBinding{
+		ProjectID: tester.initProjects[0].ID,
+		UserID:    2,
+		ClusterID: tester.initClusters[0].ID,
+	}
+
+	rb := rbs[0]
+
+	// reset fields for reflect.DeepEqual
+	rb.Model = gorm.Model{}
+
+	if diff := deep.Equal(expRB, *rb); diff != nil {
+		t.Errorf("incorrect role binding")
+		t.Error(diff)
+	}
+}
+
+func TestListRoleBindingsByAPITokenID(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_token_role_bindings.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	initCluster(tester, t)
+	defer cleanup(tester, t)
+
+	for _, apiTokenID := range []uint{0, 1} {
+		_, err := tester.repo.RoleBinding.CreateRoleBinding(&models.RoleBinding{
+			ProjectID:  tester.initProjects[0].ID,
+			UserID:     1,
+			APITokenID: apiTokenID,
+			ClusterID:  tester.initClusters[0].ID,
+			Namespace:  "staging",
+		})
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	rbs, err := tester.repo.RoleBinding.ListRoleBindingsByAPITokenID(tester.initProjects[0].ID, 1)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(rbs) != 1 {
+		t.Fatalf("length of role bindings incorrect: expected %d, got %d\n", 1, len(rbs))
+	}
+
+	if rbs[0].APITokenID != 1 {
+		t.Errorf("incorrect api token id: expected %d, got %d\n", 1, rbs[0].APITokenID)
+	}
+}
+
+func TestDeleteRoleBinding(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_delete_role_binding.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	initCluster(tester, t)
+	defer cleanup(tester, t)
+
+	rb, err := tester.repo.RoleBinding.CreateRoleBinding(&models.RoleBinding{
+		ProjectID: tester.initProjects[0].ID,
+		UserID:    1,
+		ClusterID: tester.initClusters[0].ID,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if err := tester.repo.RoleBinding.DeleteRoleBinding(rb); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	_, err = tester.repo.RoleBinding.ReadRoleBinding(rb.ID)
+
+	if err != gorm.ErrRecordNotFound {
+		t.Fatalf("incorrect error: expected %v, got %v\n", gorm.ErrRecordNotFound, err)
+	}
+}
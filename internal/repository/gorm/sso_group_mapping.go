@@ -0,0 +1,86 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// SSOGroupMappingRepository uses gorm.DB for querying the database
+type SSOGroupMappingRepository struct {
+	db *gorm.DB
+}
+
+// NewSSOGroupMappingRepository returns an SSOGroupMappingRepository which uses
+// gorm.DB for querying the database
+func NewSSOGroupMappingRepository(db *gorm.DB) repository.SSOGroupMappingRepository {
+	return &SSOGroupMappingRepository{db}
+}
+
+// CreateSSOGroupMapping creates a new SSO group mapping
+func (repo *SSOGroupMappingRepository) CreateSSOGroupMapping(
+	mapping *models.SSOGroupMapping,
+) (*models.SSOGroupMapping, error) {
+	if err := repo.db.Create(mapping).Error; err != nil {
+		return nil, err
+	}
+
+	return mapping, nil
+}
+
+// ReadSSOGroupMapping gets an SSO group mapping specified by a unique id
+func (repo *SSOGroupMappingRepository) ReadSSOGroupMapping(
+	id uint,
+) (*models.SSOGroupMapping, error) {
+	mapping := &models.SSOGroupMapping{}
+
+	if err := repo.db.Where("id = ?", id).First(&mapping).Error; err != nil {
+		return nil, err
+	}
+
+	return mapping, nil
+}
+
+// ListSSOGroupMappingsByProjectID finds all SSO group mappings
+// for a given project id
+func (repo *SSOGroupMappingRepository) ListSSOGroupMappingsByProjectID(
+	projectID uint,
+) ([]*models.SSOGroupMapping, error) {
+	mappings := []*models.SSOGroupMapping{}
+
+	if err := repo.db.Where("project_id = ?", projectID).Find(&mappings).Error; err != nil {
+		return nil, err
+	}
+
+	return mappings, nil
+}
+
+// ListSSOGroupMappingsByGroups finds all SSO group mappings, across all
+// projects, for any of the given groups of an issuer
+func (repo *SSOGroupMappingRepository) ListSSOGroupMappingsByGroups(
+	issuer string,
+	groups []string,
+) ([]*models.SSOGroupMapping, error) {
+	mappings := []*models.SSOGroupMapping{}
+
+	if len(groups) == 0 {
+		return mappings, nil
+	}
+
+	if err := repo.db.Where(`issuer = ? AND "group" IN ?`, issuer, groups).Find(&mappings).Error; err != nil {
+		return nil, err
+	}
+
+	return mappings, nil
+}
+
+// DeleteSSOGroupMapping removes an SSO group mapping from the db
+func (repo *SSOGroupMappingRepository) DeleteSSOGroupMapping(
+	mapping *models.SSOGroupMapping,
+) error {
+	if err := repo.db.Where("id = ?", mapping.ID).Delete(&models.SSOGroupMapping{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestListSSOGroupMappingsByGroups(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_sso_group_mappings.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	for _, group := range []string{"engineering", "ops", "sales"} {
+		_, err := tester.repo.SSOGroupMapping.CreateSSOGroupMapping(&models.SSOGroupMapping{
+			ProjectID: tester.initProjects[0].ID,
+			Issuer:    "https://sso.example.com",
+			Group:     group,
+			Kind:      models.RoleDeveloper,
+		})
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	// groups with the same name from another issuer must not match
+	_, err := tester.repo.SSOGroupMapping.CreateSSOGroupMapping(&models.SSOGroupMapping{
+		ProjectID: tester.initProjects[0].ID,
+		Issuer:    "https://other.example.com",
+		Group:     "ops",
+		Kind:      models.RoleAdmin,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	mappings, err := tester.repo.SSOGroupMapping.ListSSOGroupMappingsByGroups(
+		"https://sso.example.com",
+		[]string{"ops", "marketing"},
+	)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(mappings) != 1 {
+		t.Fatalf("length of sso group mappings incorrect: expected %d, got %d\n", 1, len(mappings))
+	}
+
+	expMapping := models.SSOGroupMapping{
+		ProjectID: tester.initProjects[0].ID,
+		Issuer:    "https://sso.example.com",
+		Group:     "ops",
+		Kind:      models.RoleDeveloper,
+	}
+
+	mapping := mappings[0]
+
+	// reset fields for reflect.DeepEqual
+	mapping.Model = gorm.Model{}
+
+	if diff := deep.Equal(expMapping, *mapping); diff != nil {
+		t.Errorf("incorrect sso group mapping")
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,125 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// TwoFactorRepository uses gorm.DB for querying the database
+type TwoFactorRepository struct {
+	db  *gorm.DB
+	key *[32]byte
+}
+
+// NewTwoFactorRepository returns a TwoFactorRepository which uses
+// gorm.DB for querying the database. It accepts an encryption key to encrypt
+// sensitive data
+func NewTwoFactorRepository(db *gorm.DB, key *[32]byte) repository.TwoFactorRepository {
+	return &TwoFactorRepository{db, key}
+}
+
+// CreateTwoFactor creates a new two-factor configuration for a user
+func (repo *TwoFactorRepository) CreateTwoFactor(
+	tf *models.TwoFactor,
+) (*models.TwoFactor, error) {
+	if err := repo.EncryptTwoFactorData(tf, repo.key); err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Create(tf).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.DecryptTwoFactorData(tf, repo.key); err != nil {
+		return nil, err
+	}
+
+	return tf, nil
+}
+
+// ReadTwoFactorByUserID gets the two-factor configuration of a user
+func (repo *TwoFactorRepository) ReadTwoFactorByUserID(
+	userID uint,
+) (*models.TwoFactor, error) {
+	tf := &models.TwoFactor{}
+
+	if err := repo.db.Where("user_id = ?", userID).First(&tf).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.DecryptTwoFactorData(tf, repo.key); err != nil {
+		return nil, err
+	}
+
+	return tf, nil
+}
+
+// UpdateTwoFactor modifies an existing two-factor configuration in the database
+func (repo *TwoFactorRepository) UpdateTwoFactor(
+	tf *models.TwoFactor,
+) (*models.TwoFactor, error) {
+	if err := repo.EncryptTwoFactorData(tf, repo.key); err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Save(tf).Error; err != nil {
+		return nil, err
+	}
+
+	if err := repo.DecryptTwoFactorData(tf, repo.key); err != nil {
+		return nil, err
+	}
+
+	return tf, nil
+}
+
+// DeleteTwoFactor removes a two-factor configuration. The row is deleted
+// permanently so that the user can enroll again.
+func (repo *TwoFactorRepository) DeleteTwoFactor(
+	tf *models.TwoFactor,
+) error {
+	if err := repo.db.Unscoped().Where("id = ?", tf.ID).Delete(&models.TwoFactor{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EncryptTwoFactorData will encrypt the two-factor secret before
+// writing to the DB
+func (repo *TwoFactorRepository) EncryptTwoFactorData(
+	tf *models.TwoFactor,
+	key *[32]byte,
+) error {
+	if len(tf.Secret) > 0 {
+		cipherData, err := repository.Encrypt(tf.Secret, key)
+
+		if err != nil {
+			return err
+		}
+
+		tf.Secret = cipherData
+	}
+
+	return nil
+}
+
+// DecryptTwoFactorData will decrypt the two-factor secret before
+// returning it from the DB
+func (repo *TwoFactorRepository) DecryptTwoFactorData(
+	tf *models.TwoFactor,
+	key *[32]byte,
+) error {
+	if len(tf.Secret) > 0 {
+		plaintext, err := repository.Decrypt(tf.Secret, key)
+
+		if err != nil {
+			return err
+		}
+
+		tf.Secret = plaintext
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestCreateTwoFactor(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_create_two_factor.db",
+	}
+
+	setupTestEnv(tester, t)
+	initUser(tester, t)
+	defer cleanup(tester, t)
+
+	tf := &models.TwoFactor{
+		UserID: tester.initUsers[0].ID,
+		Secret: []byte("JBSWY3DPEHPK3PXP"),
+	}
+
+	tf, err := tester.repo.TwoFactor.CreateTwoFactor(tf)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// make sure the secret is decrypted after the write
+	if string(tf.Secret) != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("incorrect secret: expected %s, got %s\n", "JBSWY3DPEHPK3PXP", tf.Secret)
+	}
+
+	tf, err = tester.repo.TwoFactor.ReadTwoFactorByUserID(tester.initUsers[0].ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if string(tf.Secret) != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("incorrect secret: expected %s, got %s\n", "JBSWY3DPEHPK3PXP", tf.Secret)
+	}
+
+	if tf.Enabled {
+		t.Errorf("expected two-factor to not be enabled\n")
+	}
+}
+
+func TestDeleteTwoFactor(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_delete_two_factor.db",
+	}
+
+	setupTestEnv(tester, t)
+	initUser(tester, t)
+	defer cleanup(tester, t)
+
+	tf, err := tester.repo.TwoFactor.CreateTwoFactor(&models.TwoFactor{
+		UserID:  tester.initUsers[0].ID,
+		Secret:  []byte("JBSWY3DPEHPK3PXP"),
+		Enabled: true,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if err := tester.repo.TwoFactor.DeleteTwoFactor(tf); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	_, err = tester.repo.TwoFactor.ReadTwoFactorByUserID(tester.initUsers[0].ID)
+
+	if err != gorm.ErrRecordNotFound {
+		t.Fatalf("incorrect error: expected %v, got %v\n", gorm.ErrRecordNotFound, err)
+	}
+
+	// the user must be able to enroll again
+	_, err = tester.repo.TwoFactor.CreateTwoFactor(&models.TwoFactor{
+		UserID: tester.initUsers[0].ID,
+		Secret: []byte("JBSWY3DPEHPK3PXP"),
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+}
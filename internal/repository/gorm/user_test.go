@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-test/deep"
 	"github.com/porter-dev/porter/internal/models"
+	"gorm.io/gorm"
 )
 
 func TestReadUserByGithubUserID(t *testing.T) {
@@ -38,3 +39,38 @@ func TestReadUserByGithubUserID(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+func TestReadUserBySSOSubject(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_read_user_sso.db",
+	}
+
+	setupTestEnv(tester, t)
+	defer cleanup(tester, t)
+
+	user, err := tester.repo.User.CreateUser(&models.User{
+		Email:      "sso@example.com",
+		SSOIssuer:  "https://issuer.example.com",
+		SSOSubject: "1234",
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	res, err := tester.repo.User.ReadUserBySSOSubject("https://issuer.example.com", "1234")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if res.ID != user.ID {
+		t.Errorf("incorrect user ID: expected %d, got %d\n", user.ID, res.ID)
+	}
+
+	_, err = tester.repo.User.ReadUserBySSOSubject("https://other.example.com", "1234")
+
+	if err != gorm.ErrRecordNotFound {
+		t.Fatalf("incorrect error: expected %v, got %v\n", gorm.ErrRecordNotFound, err)
+	}
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// APITokenRepository uses gorm.DB for querying the database
+type APITokenRepository struct {
+	canQuery bool
+	tokens   []*models.APIToken
+}
+
+// NewAPITokenRepository returns an APITokenRepository which uses
+// gorm.DB for querying the database
+func NewAPITokenRepository(canQuery bool) repository.APITokenRepository {
+	return &APITokenRepository{canQuery, []*models.APIToken{}}
+}
+
+// CreateAPIToken creates a new API token
+func (repo *APITokenRepository) CreateAPIToken(
+	token *models.APIToken,
+) (*models.APIToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.tokens = append(repo.tokens, token)
+	token.ID = uint(len(repo.tokens))
+
+	return token, nil
+}
+
+// ReadAPIToken gets an API token specified by a unique id
+func (repo *APITokenRepository) ReadAPIToken(id uint) (*models.APIToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(id-1) >= len(repo.tokens) || repo.tokens[id-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(id - 1)
+	return repo.tokens[index], nil
+}
+
+// ReadAPITokenByUniqueID gets an API token specified by the unique id
+// that is embedded in the encoded token
+func (repo *APITokenRepository) ReadAPITokenByUniqueID(uid string) (*models.APIToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, token := range repo.tokens {
+		if token != nil && token.UniqueID == uid {
+			return token, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListAPITokensByProjectID finds all API tokens
+// for a given project id
+func (repo *APITokenRepository) ListAPITokensByProjectID(
+	projectID uint,
+) ([]*models.APIToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.APIToken, 0)
+
+	for _, token := range repo.tokens {
+		if token != nil && token.ProjectID == projectID {
+			res = append(res, token)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateAPIToken modifies an existing API token in the database
+func (repo *APITokenRepository) UpdateAPIToken(
+	token *models.APIToken,
+) (*models.APIToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(token.ID-1) >= len(repo.tokens) || repo.tokens[token.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(token.ID - 1)
+	repo.tokens[index] = token
+
+	return token, nil
+}
@@ -0,0 +1,73 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// AuditEventRepository uses gorm.DB for querying the database
+type AuditEventRepository struct {
+	canQuery bool
+	events   []*models.AuditEvent
+}
+
+// NewAuditEventRepository returns an AuditEventRepository which uses
+// gorm.DB for querying the database
+func NewAuditEventRepository(canQuery bool) repository.AuditEventRepository {
+	return &AuditEventRepository{canQuery, []*models.AuditEvent{}}
+}
+
+// CreateAuditEvent creates a new audit event
+func (repo *AuditEventRepository) CreateAuditEvent(
+	event *models.AuditEvent,
+) (*models.AuditEvent, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.events = append(repo.events, event)
+	event.ID = uint(len(repo.events))
+
+	return event, nil
+}
+
+// ListAuditEventsByProjectID finds the audit events for a given project id,
+// newest first, along with the total number of events that match the filters
+func (repo *AuditEventRepository) ListAuditEventsByProjectID(
+	projectID uint,
+	opts *repository.ListAuditEventOpts,
+) ([]*models.AuditEvent, int64, error) {
+	if !repo.canQuery {
+		return nil, 0, errors.New("Cannot read from database")
+	}
+
+	matches := make([]*models.AuditEvent, 0)
+
+	for i := len(repo.events) - 1; i >= 0; i-- {
+		event := repo.events[i]
+
+		if event.ProjectID != projectID ||
+			(opts.UserID != 0 && event.UserID != opts.UserID) ||
+			(opts.Resource != "" && event.Resource != opts.Resource) {
+			continue
+		}
+
+		matches = append(matches, event)
+	}
+
+	total := int64(len(matches))
+
+	if opts.Offset >= len(matches) {
+		return []*models.AuditEvent{}, total, nil
+	}
+
+	matches = matches[opts.Offset:]
+
+	if opts.Limit > 0 && opts.Limit < len(matches) {
+		matches = matches[:opts.Limit]
+	}
+
+	return matches, total, nil
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// CanaryRolloutRepository uses gorm.DB for querying the database
+type CanaryRolloutRepository struct {
+	canQuery bool
+	rollouts []*models.CanaryRollout
+}
+
+// NewCanaryRolloutRepository returns a CanaryRolloutRepository which uses
+// gorm.DB for querying the database
+func NewCanaryRolloutRepository(canQuery bool) repository.CanaryRolloutRepository {
+	return &CanaryRolloutRepository{canQuery, []*models.CanaryRollout{}}
+}
+
+// CreateCanaryRollout creates a new canary rollout
+func (repo *CanaryRolloutRepository) CreateCanaryRollout(
+	rollout *models.CanaryRollout,
+) (*models.CanaryRollout, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.rollouts = append(repo.rollouts, rollout)
+	rollout.ID = uint(len(repo.rollouts))
+
+	return rollout, nil
+}
+
+// ReadCanaryRollout gets a canary rollout specified by a unique id
+func (repo *CanaryRolloutRepository) ReadCanaryRollout(
+	id uint,
+) (*models.CanaryRollout, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(id-1) >= len(repo.rollouts) || repo.rollouts[id-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(id - 1)
+	return repo.rollouts[index], nil
+}
+
+// ListCanaryRolloutsByRelease finds the canary rollouts of a release, newest
+// first
+func (repo *CanaryRolloutRepository) ListCanaryRolloutsByRelease(
+	clusterID uint,
+	namespace, name string,
+) ([]*models.CanaryRollout, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.CanaryRollout, 0)
+
+	for i := len(repo.rollouts) - 1; i >= 0; i-- {
+		rollout := repo.rollouts[i]
+
+		if rollout != nil && rollout.ClusterID == clusterID &&
+			rollout.Namespace == namespace && rollout.ReleaseName == name {
+			res = append(res, rollout)
+		}
+	}
+
+	return res, nil
+}
+
+// ListCanaryRolloutsByStatus finds the canary rollouts of every project that
+// have a status
+func (repo *CanaryRolloutRepository) ListCanaryRolloutsByStatus(
+	status string,
+) ([]*models.CanaryRollout, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.CanaryRollout, 0)
+
+	for _, rollout := range repo.rollouts {
+		if rollout != nil && rollout.Status == status {
+			res = append(res, rollout)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateCanaryRollout modifies an existing canary rollout in the database
+func (repo *CanaryRolloutRepository) UpdateCanaryRollout(
+	rollout *models.CanaryRollout,
+) (*models.CanaryRollout, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(rollout.ID-1) >= len(repo.rollouts) || repo.rollouts[rollout.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(rollout.ID - 1)
+	repo.rollouts[index] = rollout
+
+	return rollout, nil
+}
@@ -0,0 +1,108 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DeployPolicyRepository uses gorm.DB for querying the database
+type DeployPolicyRepository struct {
+	canQuery bool
+	policies []*models.DeployPolicy
+}
+
+// NewDeployPolicyRepository returns a DeployPolicyRepository which
+// uses gorm.DB for querying the database
+func NewDeployPolicyRepository(canQuery bool) repository.DeployPolicyRepository {
+	return &DeployPolicyRepository{canQuery, []*models.DeployPolicy{}}
+}
+
+// CreateDeployPolicy creates a new deploy policy
+func (repo *DeployPolicyRepository) CreateDeployPolicy(
+	policy *models.DeployPolicy,
+) (*models.DeployPolicy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.policies = append(repo.policies, policy)
+	policy.ID = uint(len(repo.policies))
+
+	return policy, nil
+}
+
+// ReadDeployPolicy gets a deploy policy specified by a unique id
+func (repo *DeployPolicyRepository) ReadDeployPolicy(
+	id uint,
+) (*models.DeployPolicy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(id-1) >= len(repo.policies) || repo.policies[id-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(id - 1)
+	return repo.policies[index], nil
+}
+
+// ListDeployPoliciesByProjectID finds all deploy policies for a
+// given project id
+func (repo *DeployPolicyRepository) ListDeployPoliciesByProjectID(
+	projectID uint,
+) ([]*models.DeployPolicy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.DeployPolicy, 0)
+
+	for _, policy := range repo.policies {
+		if policy != nil && policy.ProjectID == projectID {
+			res = append(res, policy)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateDeployPolicy modifies an existing deploy policy in the
+// database
+func (repo *DeployPolicyRepository) UpdateDeployPolicy(
+	policy *models.DeployPolicy,
+) (*models.DeployPolicy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(policy.ID-1) >= len(repo.policies) || repo.policies[policy.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(policy.ID - 1)
+	repo.policies[index] = policy
+
+	return policy, nil
+}
+
+// DeleteDeployPolicy removes a deploy policy from the db
+func (repo *DeployPolicyRepository) DeleteDeployPolicy(
+	policy *models.DeployPolicy,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(policy.ID-1) >= len(repo.policies) || repo.policies[policy.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	index := int(policy.ID - 1)
+	repo.policies[index] = nil
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DeploymentRepository uses gorm.DB for querying the database
+type DeploymentRepository struct {
+	canQuery    bool
+	deployments []*models.Deployment
+}
+
+// NewDeploymentRepository returns a DeploymentRepository which uses
+// gorm.DB for querying the database
+func NewDeploymentRepository(canQuery bool) repository.DeploymentRepository {
+	return &DeploymentRepository{canQuery, []*models.Deployment{}}
+}
+
+// CreateDeployment creates a new deployment record
+func (repo *DeploymentRepository) CreateDeployment(
+	deployment *models.Deployment,
+) (*models.Deployment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.deployments = append(repo.deployments, deployment)
+	deployment.ID = uint(len(repo.deployments))
+
+	return deployment, nil
+}
+
+// ListDeploymentsByRelease finds the deployments of a release, newest first,
+// along with the total number of deployments that match the filters
+func (repo *DeploymentRepository) ListDeploymentsByRelease(
+	clusterID uint,
+	namespace, name string,
+	opts *repository.ListDeploymentOpts,
+) ([]*models.Deployment, int64, error) {
+	if !repo.canQuery {
+		return nil, 0, errors.New("Cannot read from database")
+	}
+
+	matches := make([]*models.Deployment, 0)
+
+	for i := len(repo.deployments) - 1; i >= 0; i-- {
+		deployment := repo.deployments[i]
+
+		if deployment.ClusterID != clusterID ||
+			deployment.Namespace != namespace ||
+			deployment.ReleaseName != name ||
+			(opts.Source != "" && deployment.Source != opts.Source) ||
+			(opts.Status != "" && deployment.Status != opts.Status) {
+			continue
+		}
+
+		matches = append(matches, deployment)
+	}
+
+	total := int64(len(matches))
+
+	if opts.Offset >= len(matches) {
+		return []*models.Deployment{}, total, nil
+	}
+
+	matches = matches[opts.Offset:]
+
+	if opts.Limit > 0 && opts.Limit < len(matches) {
+		matches = matches[:opts.Limit]
+	}
+
+	return matches, total, nil
+}
+
+// ListDeploymentsByStatus finds the deployments of every project that have a
+// status
+func (repo *DeploymentRepository) ListDeploymentsByStatus(
+	status string,
+) ([]*models.Deployment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.Deployment, 0)
+
+	for _, deployment := range repo.deployments {
+		if deployment != nil && deployment.Status == status {
+			res = append(res, deployment)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateDeployment modifies an existing deployment record in the database
+func (repo *DeploymentRepository) UpdateDeployment(
+	deployment *models.Deployment,
+) (*models.Deployment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(deployment.ID-1) >= len(repo.deployments) || repo.deployments[deployment.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(deployment.ID - 1)
+	repo.deployments[index] = deployment
+
+	return deployment, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
 )
 
 // DNSRecordRepository implements repository.DNSRecordRepository
@@ -34,3 +35,33 @@ func (repo *DNSRecordRepository) CreateDNSRecord(
 
 	return record, nil
 }
+
+// ReadDNSRecord gets a DNS record specified by a unique id
+func (repo *DNSRecordRepository) ReadDNSRecord(id uint) (*models.DNSRecord, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(id-1) >= len(repo.dnsRecords) || repo.dnsRecords[id-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(id - 1)
+	return repo.dnsRecords[index], nil
+}
+
+// DeleteDNSRecord removes a DNS record
+func (repo *DNSRecordRepository) DeleteDNSRecord(record *models.DNSRecord) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(record.ID-1) >= len(repo.dnsRecords) || repo.dnsRecords[record.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	index := int(record.ID - 1)
+	repo.dnsRecords[index] = nil
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// EnvGroupRepository uses gorm.DB for querying the database
+type EnvGroupRepository struct {
+	canQuery       bool
+	groups         []*models.EnvGroup
+	versions       []*models.EnvGroupVersion
+	numAttachments uint
+}
+
+// NewEnvGroupRepository returns an EnvGroupRepository which uses gorm.DB for
+// querying the database
+func NewEnvGroupRepository(canQuery bool) repository.EnvGroupRepository {
+	return &EnvGroupRepository{canQuery, []*models.EnvGroup{}, []*models.EnvGroupVersion{}, 0}
+}
+
+// CreateEnvGroup creates a new env group
+func (repo *EnvGroupRepository) CreateEnvGroup(
+	group *models.EnvGroup,
+) (*models.EnvGroup, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.groups = append(repo.groups, group)
+	group.ID = uint(len(repo.groups))
+
+	return group, nil
+}
+
+// ReadEnvGroup gets an env group and its attachments specified by a unique id
+func (repo *EnvGroupRepository) ReadEnvGroup(id uint) (*models.EnvGroup, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(id-1) >= len(repo.groups) || repo.groups[id-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(id - 1)
+	return repo.groups[index], nil
+}
+
+// ListEnvGroupsByClusterID finds all env groups in a cluster
+func (repo *EnvGroupRepository) ListEnvGroupsByClusterID(
+	clusterID uint,
+) ([]*models.EnvGroup, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.EnvGroup, 0)
+
+	for _, group := range repo.groups {
+		if group != nil && group.ClusterID == clusterID {
+			res = append(res, group)
+		}
+	}
+
+	return res, nil
+}
+
+// ListEnvGroupsByRelease finds the env groups that are attached to a release,
+// in the order that they were attached
+func (repo *EnvGroupRepository) ListEnvGroupsByRelease(
+	clusterID uint,
+	namespace, name string,
+) ([]*models.EnvGroup, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	type match struct {
+		group        *models.EnvGroup
+		attachmentID uint
+	}
+
+	matches := make([]match, 0)
+
+	for _, group := range repo.groups {
+		if group == nil || group.ClusterID != clusterID {
+			continue
+		}
+
+		if attachment := group.Attachment(namespace, name); attachment != nil {
+			matches = append(matches, match{group, attachment.ID})
+		}
+	}
+
+	// sort by the time of attachment
+	for i := 1; i < len(matches); i++ {
+		for j := i; j > 0 && matches[j].attachmentID < matches[j-1].attachmentID; j-- {
+			matches[j], matches[j-1] = matches[j-1], matches[j]
+		}
+	}
+
+	res := make([]*models.EnvGroup, 0)
+
+	for _, m := range matches {
+		res = append(res, m.group)
+	}
+
+	return res, nil
+}
+
+// UpdateEnvGroup modifies an existing env group in the database
+func (repo *EnvGroupRepository) UpdateEnvGroup(
+	group *models.EnvGroup,
+) (*models.EnvGroup, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(group.ID-1) >= len(repo.groups) || repo.groups[group.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(group.ID - 1)
+	repo.groups[index] = group
+
+	return group, nil
+}
+
+// DeleteEnvGroup removes an env group from the db, along with its versions
+func (repo *EnvGroupRepository) DeleteEnvGroup(group *models.EnvGroup) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(group.ID-1) >= len(repo.groups) || repo.groups[group.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	index := int(group.ID - 1)
+	repo.groups[index] = nil
+
+	for i, version := range repo.versions {
+		if version != nil && version.EnvGroupID == group.ID {
+			repo.versions[i] = nil
+		}
+	}
+
+	return nil
+}
+
+// CreateEnvGroupVersion creates a new version of an env group
+func (repo *EnvGroupRepository) CreateEnvGroupVersion(
+	version *models.EnvGroupVersion,
+) (*models.EnvGroupVersion, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.versions = append(repo.versions, version)
+	version.ID = uint(len(repo.versions))
+
+	return version, nil
+}
+
+// ReadEnvGroupVersion gets a version of an env group
+func (repo *EnvGroupRepository) ReadEnvGroupVersion(
+	envGroupID, version uint,
+) (*models.EnvGroupVersion, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, v := range repo.versions {
+		if v != nil && v.EnvGroupID == envGroupID && v.Version == version {
+			return v, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListEnvGroupVersions finds all versions of an env group, newest first
+func (repo *EnvGroupRepository) ListEnvGroupVersions(
+	envGroupID uint,
+) ([]*models.EnvGroupVersion, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.EnvGroupVersion, 0)
+
+	for i := len(repo.versions) - 1; i >= 0; i-- {
+		if v := repo.versions[i]; v != nil && v.EnvGroupID == envGroupID {
+			res = append(res, v)
+		}
+	}
+
+	return res, nil
+}
+
+// CreateEnvGroupAttachment attaches an env group to a release
+func (repo *EnvGroupRepository) CreateEnvGroupAttachment(
+	attachment *models.EnvGroupAttachment,
+) (*models.EnvGroupAttachment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	group, err := repo.ReadEnvGroup(attachment.EnvGroupID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	repo.numAttachments++
+	attachment.ID = repo.numAttachments
+
+	group.Attachments = append(group.Attachments, *attachment)
+
+	return attachment, nil
+}
+
+// DeleteEnvGroupAttachment detaches an env group from a release
+func (repo *EnvGroupRepository) DeleteEnvGroupAttachment(
+	attachment *models.EnvGroupAttachment,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	group, err := repo.ReadEnvGroup(attachment.EnvGroupID)
+
+	if err != nil {
+		return err
+	}
+
+	for i, a := range group.Attachments {
+		if a.ID == attachment.ID {
+			group.Attachments = append(group.Attachments[:i], group.Attachments[i+1:]...)
+			return nil
+		}
+	}
+
+	return gorm.ErrRecordNotFound
+}
@@ -0,0 +1,108 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// NotificationChannelRepository uses gorm.DB for querying the database
+type NotificationChannelRepository struct {
+	canQuery bool
+	channels []*models.NotificationChannel
+}
+
+// NewNotificationChannelRepository returns a NotificationChannelRepository which
+// uses gorm.DB for querying the database
+func NewNotificationChannelRepository(canQuery bool) repository.NotificationChannelRepository {
+	return &NotificationChannelRepository{canQuery, []*models.NotificationChannel{}}
+}
+
+// CreateNotificationChannel creates a new notification channel
+func (repo *NotificationChannelRepository) CreateNotificationChannel(
+	channel *models.NotificationChannel,
+) (*models.NotificationChannel, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.channels = append(repo.channels, channel)
+	channel.ID = uint(len(repo.channels))
+
+	return channel, nil
+}
+
+// ReadNotificationChannel gets a notification channel specified by a unique id
+func (repo *NotificationChannelRepository) ReadNotificationChannel(
+	id uint,
+) (*models.NotificationChannel, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(id-1) >= len(repo.channels) || repo.channels[id-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(id - 1)
+	return repo.channels[index], nil
+}
+
+// ListNotificationChannelsByProjectID finds all notification channels for a
+// given project id
+func (repo *NotificationChannelRepository) ListNotificationChannelsByProjectID(
+	projectID uint,
+) ([]*models.NotificationChannel, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.NotificationChannel, 0)
+
+	for _, channel := range repo.channels {
+		if channel != nil && channel.ProjectID == projectID {
+			res = append(res, channel)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateNotificationChannel modifies an existing notification channel in the
+// database
+func (repo *NotificationChannelRepository) UpdateNotificationChannel(
+	channel *models.NotificationChannel,
+) (*models.NotificationChannel, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(channel.ID-1) >= len(repo.channels) || repo.channels[channel.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(channel.ID - 1)
+	repo.channels[index] = channel
+
+	return channel, nil
+}
+
+// DeleteNotificationChannel removes a notification channel from the db
+func (repo *NotificationChannelRepository) DeleteNotificationChannel(
+	channel *models.NotificationChannel,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(channel.ID-1) >= len(repo.channels) || repo.channels[channel.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	index := int(channel.ID - 1)
+	repo.channels[index] = nil
+
+	return nil
+}
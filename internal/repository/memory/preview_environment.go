@@ -0,0 +1,110 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PreviewEnvironmentRepository uses gorm.DB for querying the database
+type PreviewEnvironmentRepository struct {
+	canQuery bool
+	envs     []*models.PreviewEnvironment
+}
+
+// NewPreviewEnvironmentRepository returns a PreviewEnvironmentRepository which
+// uses gorm.DB for querying the database
+func NewPreviewEnvironmentRepository(canQuery bool) repository.PreviewEnvironmentRepository {
+	return &PreviewEnvironmentRepository{canQuery, []*models.PreviewEnvironment{}}
+}
+
+// CreatePreviewEnvironment creates a new preview environment
+func (repo *PreviewEnvironmentRepository) CreatePreviewEnvironment(
+	env *models.PreviewEnvironment,
+) (*models.PreviewEnvironment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.envs = append(repo.envs, env)
+	env.ID = uint(len(repo.envs))
+
+	return env, nil
+}
+
+// ReadPreviewEnvironmentByPR finds the preview environment of a release for
+// a given pull request number
+func (repo *PreviewEnvironmentRepository) ReadPreviewEnvironmentByPR(
+	releaseID, prNumber uint,
+) (*models.PreviewEnvironment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, env := range repo.envs {
+		if env != nil && env.ReleaseID == releaseID && env.PRNumber == prNumber {
+			return env, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListPreviewEnvironmentsByReleaseID finds all preview environments for a
+// given release id
+func (repo *PreviewEnvironmentRepository) ListPreviewEnvironmentsByReleaseID(
+	releaseID uint,
+) ([]*models.PreviewEnvironment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.PreviewEnvironment, 0)
+
+	for _, env := range repo.envs {
+		if env != nil && env.ReleaseID == releaseID {
+			res = append(res, env)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdatePreviewEnvironment modifies an existing preview environment in the
+// database
+func (repo *PreviewEnvironmentRepository) UpdatePreviewEnvironment(
+	env *models.PreviewEnvironment,
+) (*models.PreviewEnvironment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(env.ID-1) >= len(repo.envs) || repo.envs[env.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(env.ID - 1)
+	repo.envs[index] = env
+
+	return env, nil
+}
+
+// DeletePreviewEnvironment removes a preview environment from the db
+func (repo *PreviewEnvironmentRepository) DeletePreviewEnvironment(
+	env *models.PreviewEnvironment,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(env.ID-1) >= len(repo.envs) || repo.envs[env.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	index := int(env.ID - 1)
+	repo.envs[index] = nil
+
+	return nil
+}
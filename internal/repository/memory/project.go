@@ -51,6 +51,70 @@ func (repo *ProjectRepository) CreateProjectRole(project *models.Project, role *
 	return role, nil
 }
 
+// ReadProjectRole gets the role of a user in a project
+func (repo *ProjectRepository) ReadProjectRole(projID, userID uint) (*models.Role, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(projID-1) >= len(repo.projects) || repo.projects[projID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	for _, role := range repo.projects[projID-1].Roles {
+		if role.UserID == userID {
+			res := role
+			return &res, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// UpdateProjectRole updates the kind of an existing project role
+func (repo *ProjectRepository) UpdateProjectRole(projID uint, role *models.Role) (*models.Role, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(projID-1) >= len(repo.projects) || repo.projects[projID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	project := repo.projects[projID-1]
+
+	for i, existing := range project.Roles {
+		if existing.UserID == role.UserID {
+			project.Roles[i] = *role
+			return role, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// DeleteProjectRole removes a user's role from a project
+func (repo *ProjectRepository) DeleteProjectRole(projID uint, role *models.Role) (*models.Role, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(projID-1) >= len(repo.projects) || repo.projects[projID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	project := repo.projects[projID-1]
+
+	for i, existing := range project.Roles {
+		if existing.UserID == role.UserID {
+			project.Roles = append(project.Roles[:i], project.Roles[i+1:]...)
+			return role, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
 // ReadProject gets a projects specified by a unique id
 func (repo *ProjectRepository) ReadProject(id uint) (*models.Project, error) {
 	if !repo.canQuery {
@@ -65,6 +129,22 @@ func (repo *ProjectRepository) ReadProject(id uint) (*models.Project, error) {
 	return repo.projects[index], nil
 }
 
+// UpdateProject modifies an existing Project in the database
+func (repo *ProjectRepository) UpdateProject(project *models.Project) (*models.Project, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(project.ID-1) >= len(repo.projects) || repo.projects[project.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(project.ID - 1)
+	repo.projects[index] = project
+
+	return project, nil
+}
+
 // ListProjectsByUserID lists projects where a user has an associated role
 func (repo *ProjectRepository) ListProjectsByUserID(userID uint) ([]*models.Project, error) {
 	if !repo.canQuery {
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ProjectSecretRepository uses gorm.DB for querying the database
+type ProjectSecretRepository struct {
+	canQuery bool
+	secrets  []*models.ProjectSecret
+}
+
+// NewProjectSecretRepository returns a ProjectSecretRepository which uses
+// gorm.DB for querying the database
+func NewProjectSecretRepository(canQuery bool) repository.ProjectSecretRepository {
+	return &ProjectSecretRepository{canQuery, []*models.ProjectSecret{}}
+}
+
+// CreateProjectSecret creates a new project secret
+func (repo *ProjectSecretRepository) CreateProjectSecret(
+	secret *models.ProjectSecret,
+) (*models.ProjectSecret, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.secrets = append(repo.secrets, secret)
+	secret.ID = uint(len(repo.secrets))
+
+	return secret, nil
+}
+
+// ReadProjectSecretByName gets a project secret by its name in the project
+func (repo *ProjectSecretRepository) ReadProjectSecretByName(
+	projectID uint,
+	name string,
+) (*models.ProjectSecret, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, secret := range repo.secrets {
+		if secret != nil && secret.ProjectID == projectID && secret.Name == name {
+			return secret, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListProjectSecretsByProjectID finds all secrets for a given project id,
+// ordered by name
+func (repo *ProjectSecretRepository) ListProjectSecretsByProjectID(
+	projectID uint,
+) ([]*models.ProjectSecret, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.ProjectSecret, 0)
+
+	for _, secret := range repo.secrets {
+		if secret != nil && secret.ProjectID == projectID {
+			res = append(res, secret)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res, nil
+}
+
+// UpdateProjectSecret modifies an existing project secret in the database
+func (repo *ProjectSecretRepository) UpdateProjectSecret(
+	secret *models.ProjectSecret,
+) (*models.ProjectSecret, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(secret.ID-1) >= len(repo.secrets) || repo.secrets[secret.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(secret.ID - 1)
+	repo.secrets[index] = secret
+
+	return secret, nil
+}
+
+// DeleteProjectSecret removes a project secret from the db
+func (repo *ProjectSecretRepository) DeleteProjectSecret(
+	secret *models.ProjectSecret,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(secret.ID-1) >= len(repo.secrets) || repo.secrets[secret.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	index := int(secret.ID - 1)
+	repo.secrets[index] = nil
+
+	return nil
+}
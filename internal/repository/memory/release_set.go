@@ -0,0 +1,204 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ReleaseSetRepository uses gorm.DB for querying the database
+type ReleaseSetRepository struct {
+	canQuery   bool
+	sets       []*models.ReleaseSet
+	numTargets uint
+}
+
+// NewReleaseSetRepository returns a ReleaseSetRepository which uses gorm.DB
+// for querying the database
+func NewReleaseSetRepository(canQuery bool) repository.ReleaseSetRepository {
+	return &ReleaseSetRepository{canQuery, []*models.ReleaseSet{}, 0}
+}
+
+// CreateReleaseSet creates a new release set along with its targets
+func (repo *ReleaseSetRepository) CreateReleaseSet(
+	set *models.ReleaseSet,
+) (*models.ReleaseSet, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.sets = append(repo.sets, set)
+	set.ID = uint(len(repo.sets))
+
+	for i := range set.Targets {
+		repo.numTargets++
+		set.Targets[i].ID = repo.numTargets
+		set.Targets[i].ReleaseSetID = set.ID
+	}
+
+	return set, nil
+}
+
+// ReadReleaseSet gets a release set and its targets specified by a unique id
+func (repo *ReleaseSetRepository) ReadReleaseSet(
+	id uint,
+) (*models.ReleaseSet, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(id-1) >= len(repo.sets) || repo.sets[id-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(id - 1)
+	return repo.sets[index], nil
+}
+
+// ReadReleaseSetByWebhookToken finds a release set and its targets by its
+// webhook token
+func (repo *ReleaseSetRepository) ReadReleaseSetByWebhookToken(
+	token string,
+) (*models.ReleaseSet, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, set := range repo.sets {
+		if set != nil && set.WebhookToken == token {
+			return set, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListReleaseSetsByProjectID finds all release sets for a given project id
+func (repo *ReleaseSetRepository) ListReleaseSetsByProjectID(
+	projectID uint,
+) ([]*models.ReleaseSet, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.ReleaseSet, 0)
+
+	for _, set := range repo.sets {
+		if set != nil && set.ProjectID == projectID {
+			res = append(res, set)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateReleaseSet modifies an existing release set in the database, without
+// modifying its targets
+func (repo *ReleaseSetRepository) UpdateReleaseSet(
+	set *models.ReleaseSet,
+) (*models.ReleaseSet, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(set.ID-1) >= len(repo.sets) || repo.sets[set.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(set.ID - 1)
+	set.Targets = repo.sets[index].Targets
+	repo.sets[index] = set
+
+	return set, nil
+}
+
+// DeleteReleaseSet removes a release set from the db, along with its targets
+func (repo *ReleaseSetRepository) DeleteReleaseSet(
+	set *models.ReleaseSet,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(set.ID-1) >= len(repo.sets) || repo.sets[set.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	index := int(set.ID - 1)
+	repo.sets[index] = nil
+
+	return nil
+}
+
+// CreateReleaseSetTarget adds a target to a release set
+func (repo *ReleaseSetRepository) CreateReleaseSetTarget(
+	target *models.ReleaseSetTarget,
+) (*models.ReleaseSetTarget, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	set, err := repo.ReadReleaseSet(target.ReleaseSetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	repo.numTargets++
+	target.ID = repo.numTargets
+
+	set.Targets = append(set.Targets, *target)
+
+	return target, nil
+}
+
+// UpdateReleaseSetTarget modifies an existing target, such as the status of
+// its last deploy
+func (repo *ReleaseSetRepository) UpdateReleaseSetTarget(
+	target *models.ReleaseSetTarget,
+) (*models.ReleaseSetTarget, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	set, err := repo.ReadReleaseSet(target.ReleaseSetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, t := range set.Targets {
+		if t.ID == target.ID {
+			set.Targets[i] = *target
+			return target, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// DeleteReleaseSetTarget removes a target from a release set
+func (repo *ReleaseSetRepository) DeleteReleaseSetTarget(
+	target *models.ReleaseSetTarget,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	set, err := repo.ReadReleaseSet(target.ReleaseSetID)
+
+	if err != nil {
+		return err
+	}
+
+	for i, t := range set.Targets {
+		if t.ID == target.ID {
+			set.Targets = append(set.Targets[:i], set.Targets[i+1:]...)
+			return nil
+		}
+	}
+
+	return gorm.ErrRecordNotFound
+}
@@ -8,22 +8,35 @@ import (
 // and accepts a parameter that can trigger read/write errors
 func NewRepository(canQuery bool) *repository.Repository {
 	return &repository.Repository{
-		User:             NewUserRepository(canQuery),
-		Session:          NewSessionRepository(canQuery),
-		Project:          NewProjectRepository(canQuery),
-		Cluster:          NewClusterRepository(canQuery),
-		HelmRepo:         NewHelmRepoRepository(canQuery),
-		Registry:         NewRegistryRepository(canQuery),
-		GitRepo:          NewGitRepoRepository(canQuery),
-		Invite:           NewInviteRepository(canQuery),
-		AuthCode:         NewAuthCodeRepository(canQuery),
-		DNSRecord:        NewDNSRecordRepository(canQuery),
-		PWResetToken:     NewPWResetTokenRepository(canQuery),
-		KubeIntegration:  NewKubeIntegrationRepository(canQuery),
-		BasicIntegration: NewBasicIntegrationRepository(canQuery),
-		OIDCIntegration:  NewOIDCIntegrationRepository(canQuery),
-		OAuthIntegration: NewOAuthIntegrationRepository(canQuery),
-		GCPIntegration:   NewGCPIntegrationRepository(canQuery),
-		AWSIntegration:   NewAWSIntegrationRepository(canQuery),
+		User:                NewUserRepository(canQuery),
+		Session:             NewSessionRepository(canQuery),
+		Project:             NewProjectRepository(canQuery),
+		Cluster:             NewClusterRepository(canQuery),
+		HelmRepo:            NewHelmRepoRepository(canQuery),
+		Registry:            NewRegistryRepository(canQuery),
+		GitRepo:             NewGitRepoRepository(canQuery),
+		Invite:              NewInviteRepository(canQuery),
+		RoleBinding:         NewRoleBindingRepository(canQuery),
+		APIToken:            NewAPITokenRepository(canQuery),
+		AuditEvent:          NewAuditEventRepository(canQuery),
+		SSOGroupMapping:     NewSSOGroupMappingRepository(canQuery),
+		TwoFactor:           NewTwoFactorRepository(canQuery),
+		PreviewEnvironment:  NewPreviewEnvironmentRepository(canQuery),
+		Deployment:          NewDeploymentRepository(canQuery),
+		NotificationChannel: NewNotificationChannelRepository(canQuery),
+		CanaryRollout:       NewCanaryRolloutRepository(canQuery),
+		EnvGroup:            NewEnvGroupRepository(canQuery),
+		ProjectSecret:       NewProjectSecretRepository(canQuery),
+		DeployPolicy:        NewDeployPolicyRepository(canQuery),
+		ReleaseSet:          NewReleaseSetRepository(canQuery),
+		AuthCode:            NewAuthCodeRepository(canQuery),
+		DNSRecord:           NewDNSRecordRepository(canQuery),
+		PWResetToken:        NewPWResetTokenRepository(canQuery),
+		KubeIntegration:     NewKubeIntegrationRepository(canQuery),
+		BasicIntegration:    NewBasicIntegrationRepository(canQuery),
+		OIDCIntegration:     NewOIDCIntegrationRepository(canQuery),
+		OAuthIntegration:    NewOAuthIntegrationRepository(canQuery),
+		GCPIntegration:      NewGCPIntegrationRepository(canQuery),
+		AWSIntegration:      NewAWSIntegrationRepository(canQuery),
 	}
 }
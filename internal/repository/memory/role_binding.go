@@ -0,0 +1,127 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// RoleBindingRepository uses gorm.DB for querying the database
+type RoleBindingRepository struct {
+	canQuery     bool
+	roleBindings []*models.RoleBinding
+}
+
+// NewRoleBindingRepository returns a RoleBindingRepository which uses
+// gorm.DB for querying the database
+func NewRoleBindingRepository(canQuery bool) repository.RoleBindingRepository {
+	return &RoleBindingRepository{canQuery, []*models.RoleBinding{}}
+}
+
+// CreateRoleBinding creates a new role binding
+func (repo *RoleBindingRepository) CreateRoleBinding(
+	rb *models.RoleBinding,
+) (*models.RoleBinding, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.roleBindings = append(repo.roleBindings, rb)
+	rb.ID = uint(len(repo.roleBindings))
+
+	return rb, nil
+}
+
+// ReadRoleBinding gets a role binding specified by a unique id
+func (repo *RoleBindingRepository) ReadRoleBinding(id uint) (*models.RoleBinding, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(id-1) >= len(repo.roleBindings) || repo.roleBindings[id-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(id - 1)
+	return repo.roleBindings[index], nil
+}
+
+// ListRoleBindingsByProjectID finds all role bindings
+// for a given project id
+func (repo *RoleBindingRepository) ListRoleBindingsByProjectID(
+	projectID uint,
+) ([]*models.RoleBinding, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.RoleBinding, 0)
+
+	for _, rb := range repo.roleBindings {
+		if rb != nil && rb.ProjectID == projectID {
+			res = append(res, rb)
+		}
+	}
+
+	return res, nil
+}
+
+// ListRoleBindingsByUserID finds all role bindings for a
+// given user in a given project
+func (repo *RoleBindingRepository) ListRoleBindingsByUserID(
+	projectID, userID uint,
+) ([]*models.RoleBinding, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.RoleBinding, 0)
+
+	for _, rb := range repo.roleBindings {
+		if rb != nil && rb.ProjectID == projectID && rb.UserID == userID && rb.APITokenID == 0 {
+			res = append(res, rb)
+		}
+	}
+
+	return res, nil
+}
+
+// ListRoleBindingsByAPITokenID finds all role bindings for a
+// given API token in a given project
+func (repo *RoleBindingRepository) ListRoleBindingsByAPITokenID(
+	projectID, apiTokenID uint,
+) ([]*models.RoleBinding, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.RoleBinding, 0)
+
+	for _, rb := range repo.roleBindings {
+		if rb != nil && rb.ProjectID == projectID && rb.APITokenID == apiTokenID {
+			res = append(res, rb)
+		}
+	}
+
+	return res, nil
+}
+
+// DeleteRoleBinding removes a role binding from the db
+func (repo *RoleBindingRepository) DeleteRoleBinding(
+	rb *models.RoleBinding,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(rb.ID-1) >= len(repo.roleBindings) || repo.roleBindings[rb.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	index := int(rb.ID - 1)
+	repo.roleBindings[index] = nil
+
+	return nil
+}
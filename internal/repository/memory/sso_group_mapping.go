@@ -0,0 +1,117 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// SSOGroupMappingRepository uses gorm.DB for querying the database
+type SSOGroupMappingRepository struct {
+	canQuery bool
+	mappings []*models.SSOGroupMapping
+}
+
+// NewSSOGroupMappingRepository returns an SSOGroupMappingRepository which uses
+// gorm.DB for querying the database
+func NewSSOGroupMappingRepository(canQuery bool) repository.SSOGroupMappingRepository {
+	return &SSOGroupMappingRepository{canQuery, []*models.SSOGroupMapping{}}
+}
+
+// CreateSSOGroupMapping creates a new SSO group mapping
+func (repo *SSOGroupMappingRepository) CreateSSOGroupMapping(
+	mapping *models.SSOGroupMapping,
+) (*models.SSOGroupMapping, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.mappings = append(repo.mappings, mapping)
+	mapping.ID = uint(len(repo.mappings))
+
+	return mapping, nil
+}
+
+// ReadSSOGroupMapping gets an SSO group mapping specified by a unique id
+func (repo *SSOGroupMappingRepository) ReadSSOGroupMapping(
+	id uint,
+) (*models.SSOGroupMapping, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(id-1) >= len(repo.mappings) || repo.mappings[id-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(id - 1)
+	return repo.mappings[index], nil
+}
+
+// ListSSOGroupMappingsByProjectID finds all SSO group mappings
+// for a given project id
+func (repo *SSOGroupMappingRepository) ListSSOGroupMappingsByProjectID(
+	projectID uint,
+) ([]*models.SSOGroupMapping, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.SSOGroupMapping, 0)
+
+	for _, mapping := range repo.mappings {
+		if mapping != nil && mapping.ProjectID == projectID {
+			res = append(res, mapping)
+		}
+	}
+
+	return res, nil
+}
+
+// ListSSOGroupMappingsByGroups finds all SSO group mappings, across all
+// projects, for any of the given groups of an issuer
+func (repo *SSOGroupMappingRepository) ListSSOGroupMappingsByGroups(
+	issuer string,
+	groups []string,
+) ([]*models.SSOGroupMapping, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.SSOGroupMapping, 0)
+
+	for _, mapping := range repo.mappings {
+		if mapping == nil || mapping.Issuer != issuer {
+			continue
+		}
+
+		for _, group := range groups {
+			if mapping.Group == group {
+				res = append(res, mapping)
+				break
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// DeleteSSOGroupMapping removes an SSO group mapping from the db
+func (repo *SSOGroupMappingRepository) DeleteSSOGroupMapping(
+	mapping *models.SSOGroupMapping,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(mapping.ID-1) >= len(repo.mappings) || repo.mappings[mapping.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	index := int(mapping.ID - 1)
+	repo.mappings[index] = nil
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// TwoFactorRepository uses gorm.DB for querying the database
+type TwoFactorRepository struct {
+	canQuery   bool
+	twoFactors []*models.TwoFactor
+}
+
+// NewTwoFactorRepository returns a TwoFactorRepository which uses
+// gorm.DB for querying the database
+func NewTwoFactorRepository(canQuery bool) repository.TwoFactorRepository {
+	return &TwoFactorRepository{canQuery, []*models.TwoFactor{}}
+}
+
+// CreateTwoFactor creates a new two-factor configuration for a user
+func (repo *TwoFactorRepository) CreateTwoFactor(
+	tf *models.TwoFactor,
+) (*models.TwoFactor, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	for _, existing := range repo.twoFactors {
+		if existing != nil && existing.UserID == tf.UserID {
+			return nil, errors.New("Cannot write database")
+		}
+	}
+
+	repo.twoFactors = append(repo.twoFactors, tf)
+	tf.ID = uint(len(repo.twoFactors))
+
+	return tf, nil
+}
+
+// ReadTwoFactorByUserID gets the two-factor configuration of a user
+func (repo *TwoFactorRepository) ReadTwoFactorByUserID(
+	userID uint,
+) (*models.TwoFactor, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, tf := range repo.twoFactors {
+		if tf != nil && tf.UserID == userID {
+			return tf, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// UpdateTwoFactor modifies an existing two-factor configuration in the database
+func (repo *TwoFactorRepository) UpdateTwoFactor(
+	tf *models.TwoFactor,
+) (*models.TwoFactor, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(tf.ID-1) >= len(repo.twoFactors) || repo.twoFactors[tf.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(tf.ID - 1)
+	repo.twoFactors[index] = tf
+
+	return tf, nil
+}
+
+// DeleteTwoFactor removes a two-factor configuration
+func (repo *TwoFactorRepository) DeleteTwoFactor(
+	tf *models.TwoFactor,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(tf.ID-1) >= len(repo.twoFactors) || repo.twoFactors[tf.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	index := int(tf.ID - 1)
+	repo.twoFactors[index] = nil
+
+	return nil
+}
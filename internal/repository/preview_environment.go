@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// PreviewEnvironmentRepository represents the set of queries on the
+// PreviewEnvironment model
+type PreviewEnvironmentRepository interface {
+	CreatePreviewEnvironment(env *models.PreviewEnvironment) (*models.PreviewEnvironment, error)
+	ReadPreviewEnvironmentByPR(releaseID, prNumber uint) (*models.PreviewEnvironment, error)
+	ListPreviewEnvironmentsByReleaseID(releaseID uint) ([]*models.PreviewEnvironment, error)
+	UpdatePreviewEnvironment(env *models.PreviewEnvironment) (*models.PreviewEnvironment, error)
+	DeletePreviewEnvironment(env *models.PreviewEnvironment) error
+}
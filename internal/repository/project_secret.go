@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// ProjectSecretRepository represents the set of queries on the ProjectSecret
+// model
+type ProjectSecretRepository interface {
+	CreateProjectSecret(secret *models.ProjectSecret) (*models.ProjectSecret, error)
+	ReadProjectSecretByName(projectID uint, name string) (*models.ProjectSecret, error)
+	ListProjectSecretsByProjectID(projectID uint) ([]*models.ProjectSecret, error)
+	UpdateProjectSecret(secret *models.ProjectSecret) (*models.ProjectSecret, error)
+	DeleteProjectSecret(secret *models.ProjectSecret) error
+}
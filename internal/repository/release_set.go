@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// ReleaseSetRepository represents the set of queries on the ReleaseSet model
+// and its targets
+type ReleaseSetRepository interface {
+	CreateReleaseSet(set *models.ReleaseSet) (*models.ReleaseSet, error)
+	ReadReleaseSet(id uint) (*models.ReleaseSet, error)
+	ReadReleaseSetByWebhookToken(token string) (*models.ReleaseSet, error)
+	ListReleaseSetsByProjectID(projectID uint) ([]*models.ReleaseSet, error)
+	UpdateReleaseSet(set *models.ReleaseSet) (*models.ReleaseSet, error)
+	DeleteReleaseSet(set *models.ReleaseSet) error
+	CreateReleaseSetTarget(target *models.ReleaseSetTarget) (*models.ReleaseSetTarget, error)
+	UpdateReleaseSetTarget(target *models.ReleaseSetTarget) (*models.ReleaseSetTarget, error)
+	DeleteReleaseSetTarget(target *models.ReleaseSetTarget) error
+}
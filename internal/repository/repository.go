@@ -2,24 +2,37 @@ package repository
 
 // Repository collects the repositories for each model
 type Repository struct {
-	User             UserRepository
-	Project          ProjectRepository
-	Release          ReleaseRepository
-	Session          SessionRepository
-	GitRepo          GitRepoRepository
-	Cluster          ClusterRepository
-	HelmRepo         HelmRepoRepository
-	Registry         RegistryRepository
-	Infra            InfraRepository
-	GitActionConfig  GitActionConfigRepository
-	Invite           InviteRepository
-	AuthCode         AuthCodeRepository
-	DNSRecord        DNSRecordRepository
-	PWResetToken     PWResetTokenRepository
-	KubeIntegration  KubeIntegrationRepository
-	BasicIntegration BasicIntegrationRepository
-	OIDCIntegration  OIDCIntegrationRepository
-	OAuthIntegration OAuthIntegrationRepository
-	GCPIntegration   GCPIntegrationRepository
-	AWSIntegration   AWSIntegrationRepository
+	User                UserRepository
+	Project             ProjectRepository
+	Release             ReleaseRepository
+	Session             SessionRepository
+	GitRepo             GitRepoRepository
+	Cluster             ClusterRepository
+	HelmRepo            HelmRepoRepository
+	Registry            RegistryRepository
+	Infra               InfraRepository
+	GitActionConfig     GitActionConfigRepository
+	Invite              InviteRepository
+	RoleBinding         RoleBindingRepository
+	APIToken            APITokenRepository
+	AuditEvent          AuditEventRepository
+	SSOGroupMapping     SSOGroupMappingRepository
+	TwoFactor           TwoFactorRepository
+	PreviewEnvironment  PreviewEnvironmentRepository
+	Deployment          DeploymentRepository
+	NotificationChannel NotificationChannelRepository
+	CanaryRollout       CanaryRolloutRepository
+	EnvGroup            EnvGroupRepository
+	ProjectSecret       ProjectSecretRepository
+	DeployPolicy        DeployPolicyRepository
+	ReleaseSet          ReleaseSetRepository
+	AuthCode            AuthCodeRepository
+	DNSRecord           DNSRecordRepository
+	PWResetToken        PWResetTokenRepository
+	KubeIntegration     KubeIntegrationRepository
+	BasicIntegration    BasicIntegrationRepository
+	OIDCIntegration     OIDCIntegrationRepository
+	OAuthIntegration    OAuthIntegrationRepository
+	GCPIntegration      GCPIntegrationRepository
+	AWSIntegration      AWSIntegrationRepository
 }
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// RoleBindingRepository represents the set of queries on the RoleBinding model
+type RoleBindingRepository interface {
+	CreateRoleBinding(rb *models.RoleBinding) (*models.RoleBinding, error)
+	ReadRoleBinding(id uint) (*models.RoleBinding, error)
+	ListRoleBindingsByProjectID(projectID uint) ([]*models.RoleBinding, error)
+	ListRoleBindingsByUserID(projectID, userID uint) ([]*models.RoleBinding, error)
+	ListRoleBindingsByAPITokenID(projectID, apiTokenID uint) ([]*models.RoleBinding, error)
+	DeleteRoleBinding(rb *models.RoleBinding) error
+}
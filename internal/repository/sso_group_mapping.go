@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// SSOGroupMappingRepository represents the set of queries on the
+// SSOGroupMapping model
+type SSOGroupMappingRepository interface {
+	CreateSSOGroupMapping(mapping *models.SSOGroupMapping) (*models.SSOGroupMapping, error)
+	ReadSSOGroupMapping(id uint) (*models.SSOGroupMapping, error)
+	ListSSOGroupMappingsByProjectID(projectID uint) ([]*models.SSOGroupMapping, error)
+	ListSSOGroupMappingsByGroups(issuer string, groups []string) ([]*models.SSOGroupMapping, error)
+	DeleteSSOGroupMapping(mapping *models.SSOGroupMapping) error
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// TwoFactorRepository represents the set of queries on the TwoFactor model
+type TwoFactorRepository interface {
+	CreateTwoFactor(tf *models.TwoFactor) (*models.TwoFactor, error)
+	ReadTwoFactorByUserID(userID uint) (*models.TwoFactor, error)
+	UpdateTwoFactor(tf *models.TwoFactor) (*models.TwoFactor, error)
+	DeleteTwoFactor(tf *models.TwoFactor) error
+}
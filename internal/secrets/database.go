@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DatabaseBackend stores secrets in the Porter database. Values are encrypted
+// by the repository with the server's encryption key.
+type DatabaseBackend struct {
+	Repo repository.ProjectSecretRepository
+}
+
+// NewDatabaseBackend returns a backend that stores secrets in the repository
+func NewDatabaseBackend(repo repository.ProjectSecretRepository) *DatabaseBackend {
+	return &DatabaseBackend{repo}
+}
+
+// Get returns the value of a secret, or ErrNotFound
+func (d *DatabaseBackend) Get(projectID uint, name string) ([]byte, error) {
+	secret, err := d.Repo.ReadProjectSecretByName(projectID, name)
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return secret.Value, nil
+}
+
+// Put creates or replaces the value of a secret
+func (d *DatabaseBackend) Put(projectID uint, name string, value []byte) error {
+	secret, err := d.Repo.ReadProjectSecretByName(projectID, name)
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_, err = d.Repo.CreateProjectSecret(&models.ProjectSecret{
+			ProjectID: projectID,
+			Name:      name,
+			Value:     value,
+		})
+
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	secret.Value = value
+
+	_, err = d.Repo.UpdateProjectSecret(secret)
+
+	return err
+}
+
+// Delete removes a secret, or returns ErrNotFound
+func (d *DatabaseBackend) Delete(projectID uint, name string) error {
+	secret, err := d.Repo.ReadProjectSecretByName(projectID, name)
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+
+	return d.Repo.DeleteProjectSecret(secret)
+}
+
+// List returns the names of the secrets of a project, sorted
+func (d *DatabaseBackend) List(projectID uint) ([]string, error) {
+	secrets, err := d.Repo.ListProjectSecretsByProjectID(projectID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, 0)
+
+	for _, secret := range secrets {
+		res = append(res, secret.Name)
+	}
+
+	return res, nil
+}
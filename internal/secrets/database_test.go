@@ -0,0 +1,57 @@
+package secrets_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+	"github.com/porter-dev/porter/internal/secrets"
+)
+
+func TestDatabaseBackend(t *testing.T) {
+	backend := secrets.NewDatabaseBackend(memory.NewProjectSecretRepository(true))
+
+	if err := backend.Put(1, "DATABASE_URL", []byte("first")); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if err := backend.Put(1, "DATABASE_URL", []byte("postgres://")); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if err := backend.Put(1, "API_KEY", []byte("key")); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	value, err := backend.Get(1, "DATABASE_URL")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if string(value) != "postgres://" {
+		t.Errorf("incorrect value: expected postgres://, got %s\n", value)
+	}
+
+	if _, err := backend.Get(2, "DATABASE_URL"); err != secrets.ErrNotFound {
+		t.Errorf("expected not found in another project, got %v\n", err)
+	}
+
+	names, err := backend.List(1)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if diff := deep.Equal(names, []string{"API_KEY", "DATABASE_URL"}); diff != nil {
+		t.Errorf("incorrect secret names: %v\n", diff)
+	}
+
+	if err := backend.Delete(1, "API_KEY"); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if err := backend.Delete(1, "API_KEY"); err != secrets.ErrNotFound {
+		t.Errorf("expected not found when deleting twice, got %v\n", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Package secrets is the secret store of a project. Releases reference
+// secrets in their values by name, and the values are only read when the
+// release is deployed, so they never end up in Helm values.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// RefPrefix is the prefix of env values that reference a secret in the store:
+// an env var with the value PORTERSTORE_${name} is read from the secret
+// ${name} when the release is deployed
+const RefPrefix = "PORTERSTORE_"
+
+// ErrNotFound is returned by a backend when a secret does not exist
+var ErrNotFound = errors.New("secret not found")
+
+// Backend stores the secret values of projects
+type Backend interface {
+	// Get returns the value of a secret, or ErrNotFound
+	Get(projectID uint, name string) ([]byte, error)
+
+	// Put creates or replaces the value of a secret
+	Put(projectID uint, name string, value []byte) error
+
+	// Delete removes a secret, or returns ErrNotFound
+	Delete(projectID uint, name string) error
+
+	// List returns the names of the secrets of a project, sorted
+	List(projectID uint) ([]string, error)
+}
+
+// ValidateName checks that a secret name can be used as the key of a
+// Kubernetes secret
+func ValidateName(name string) error {
+	if errs := validation.IsConfigMapKey(name); len(errs) > 0 {
+		return fmt.Errorf("invalid secret name %s: %s", name, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// RefName returns the name of the secret that an env value references, and
+// false if the value is not a reference
+func RefName(value string) (string, bool) {
+	if !strings.HasPrefix(value, RefPrefix) || len(value) == len(RefPrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(value, RefPrefix), true
+}
@@ -0,0 +1,186 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VaultBackend stores secrets in the KV version 2 secrets engine of a Vault
+// server. Each secret is stored at ${mount}/data/${prefix}/projects/${id}/${name},
+// with the value under the "value" key.
+type VaultBackend struct {
+	// Address is the URL of the Vault server
+	Address string
+
+	// Token is the token that requests are authenticated with
+	Token string
+
+	// MountPath is the path that the KV engine is mounted on
+	MountPath string
+
+	// PathPrefix is prepended to the path of every secret
+	PathPrefix string
+
+	HTTPClient *http.Client
+}
+
+// NewVaultBackend returns a backend that stores secrets in a Vault server
+func NewVaultBackend(address, token, mountPath, pathPrefix string) *VaultBackend {
+	return &VaultBackend{
+		Address:    strings.TrimSuffix(address, "/"),
+		Token:      token,
+		MountPath:  strings.Trim(mountPath, "/"),
+		PathPrefix: strings.Trim(pathPrefix, "/"),
+		HTTPClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+type vaultReadResponse struct {
+	Data struct {
+		Data map[string]string `json:"data"`
+	} `json:"data"`
+}
+
+type vaultListResponse struct {
+	Data struct {
+		Keys []string `json:"keys"`
+	} `json:"data"`
+}
+
+type vaultWriteRequest struct {
+	Data map[string]string `json:"data"`
+}
+
+// Get returns the value of a secret, or ErrNotFound
+func (v *VaultBackend) Get(projectID uint, name string) ([]byte, error) {
+	resp := &vaultReadResponse{}
+
+	if err := v.do("GET", v.url("data", projectID, name), nil, resp); err != nil {
+		return nil, err
+	}
+
+	value, ok := resp.Data.Data["value"]
+
+	// deleted versions of a secret are read with no data
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return []byte(value), nil
+}
+
+// Put creates or replaces the value of a secret
+func (v *VaultBackend) Put(projectID uint, name string, value []byte) error {
+	return v.do("POST", v.url("data", projectID, name), &vaultWriteRequest{
+		Data: map[string]string{
+			"value": string(value),
+		},
+	}, nil)
+}
+
+// Delete removes every version of a secret, or returns ErrNotFound
+func (v *VaultBackend) Delete(projectID uint, name string) error {
+	// deleting the metadata of a secret that does not exist succeeds, so the
+	// secret is read first
+	if _, err := v.Get(projectID, name); err != nil {
+		return err
+	}
+
+	return v.do("DELETE", v.url("metadata", projectID, name), nil, nil)
+}
+
+// List returns the names of the secrets of a project, sorted
+func (v *VaultBackend) List(projectID uint) ([]string, error) {
+	resp := &vaultListResponse{}
+
+	err := v.do("GET", v.url("metadata", projectID, "")+"?list=true", nil, resp)
+
+	if err == ErrNotFound {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, 0)
+
+	for _, key := range resp.Data.Keys {
+		// keys ending in a slash are folders, which porter does not create
+		if !strings.HasSuffix(key, "/") {
+			res = append(res, key)
+		}
+	}
+
+	sort.Strings(res)
+
+	return res, nil
+}
+
+func (v *VaultBackend) url(kind string, projectID uint, name string) string {
+	segments := []string{v.Address, "v1", v.MountPath, kind}
+
+	if v.PathPrefix != "" {
+		segments = append(segments, v.PathPrefix)
+	}
+
+	segments = append(segments, "projects", fmt.Sprintf("%d", projectID))
+
+	if name != "" {
+		segments = append(segments, url.PathEscape(name))
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func (v *VaultBackend) do(method, reqURL string, body interface{}, dst interface{}) error {
+	reqBody := bytes.NewBuffer([]byte{})
+
+	if body != nil {
+		data, err := json.Marshal(body)
+
+		if err != nil {
+			return err
+		}
+
+		reqBody = bytes.NewBuffer(data)
+	}
+
+	req, err := http.NewRequest(method, reqURL, reqBody)
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-Vault-Token", v.Token)
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := v.HTTPClient.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	} else if res.StatusCode >= 300 {
+		return fmt.Errorf("vault responded with status %d", res.StatusCode)
+	}
+
+	if dst == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(dst)
+}
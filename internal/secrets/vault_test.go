@@ -0,0 +1,158 @@
+package secrets_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/secrets"
+)
+
+// kvServer is a stand-in for the KV version 2 engine of a Vault server,
+// mounted at /v1/secret
+type kvServer struct {
+	mu      sync.Mutex
+	token   string
+	secrets map[string]map[string]string
+}
+
+func (s *kvServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("X-Vault-Token") != s.token {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/secret/")
+
+	switch {
+	case strings.HasPrefix(path, "data/") && r.Method == "GET":
+		data, ok := s.secrets[strings.TrimPrefix(path, "data/")]
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"data": data},
+		})
+	case strings.HasPrefix(path, "data/") && r.Method == "POST":
+		body := struct {
+			Data map[string]string `json:"data"`
+		}{}
+
+		json.NewDecoder(r.Body).Decode(&body)
+		s.secrets[strings.TrimPrefix(path, "data/")] = body.Data
+
+		w.Write([]byte(`{"data":{"version":1}}`))
+	case strings.HasPrefix(path, "metadata/") && r.Method == "DELETE":
+		delete(s.secrets, strings.TrimPrefix(path, "metadata/"))
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasPrefix(path, "metadata/") && r.URL.Query().Get("list") == "true":
+		prefix := strings.TrimPrefix(path, "metadata/") + "/"
+		keys := make([]string, 0)
+
+		for key := range s.secrets {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, strings.TrimPrefix(key, prefix))
+			}
+		}
+
+		if len(keys) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		sort.Strings(keys)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"keys": keys},
+		})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestVaultBackend(t *testing.T) {
+	kv := &kvServer{
+		token:   "root",
+		secrets: make(map[string]map[string]string),
+	}
+
+	server := httptest.NewServer(kv)
+	defer server.Close()
+
+	backend := secrets.NewVaultBackend(server.URL, "root", "secret", "porter")
+
+	if _, err := backend.Get(1, "DATABASE_URL"); err != secrets.ErrNotFound {
+		t.Fatalf("expected not found, got %v\n", err)
+	}
+
+	names, err := backend.List(1)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(names) != 0 {
+		t.Errorf("expected no secrets, got %v\n", names)
+	}
+
+	for _, name := range []string{"DATABASE_URL", "API_KEY"} {
+		if err := backend.Put(1, name, []byte("first")); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	if err := backend.Put(1, "DATABASE_URL", []byte("postgres://")); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if err := backend.Put(2, "OTHER", []byte("other")); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if _, ok := kv.secrets["porter/projects/1/DATABASE_URL"]; !ok {
+		t.Errorf("secret was not written under the path prefix\n")
+	}
+
+	value, err := backend.Get(1, "DATABASE_URL")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if string(value) != "postgres://" {
+		t.Errorf("incorrect value: expected postgres://, got %s\n", value)
+	}
+
+	names, err = backend.List(1)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if diff := deep.Equal(names, []string{"API_KEY", "DATABASE_URL"}); diff != nil {
+		t.Errorf("incorrect secret names: %v\n", diff)
+	}
+
+	if err := backend.Delete(1, "API_KEY"); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if err := backend.Delete(1, "API_KEY"); err != secrets.ErrNotFound {
+		t.Errorf("expected not found when deleting twice, got %v\n", err)
+	}
+
+	if _, err := secrets.NewVaultBackend(server.URL, "wrong", "secret", "").Get(1, "DATABASE_URL"); err == nil {
+		t.Errorf("expected an error with an invalid token\n")
+	}
+}
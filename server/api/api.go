@@ -8,14 +8,20 @@ import (
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
 	vr "github.com/go-playground/validator/v10"
+	"github.com/porter-dev/porter/internal/adapter"
 	"github.com/porter-dev/porter/internal/auth/sessionstore"
 	"github.com/porter-dev/porter/internal/auth/token"
+	"github.com/porter-dev/porter/internal/integrations/email"
+	"github.com/porter-dev/porter/internal/notifier"
 	"github.com/porter-dev/porter/internal/oauth"
+	"github.com/porter-dev/porter/internal/secrets"
 	"golang.org/x/oauth2"
 	"gorm.io/gorm"
 
 	"github.com/gorilla/sessions"
 	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/helm/loader"
+	"github.com/porter-dev/porter/internal/helm/repo"
 	"github.com/porter-dev/porter/internal/kubernetes"
 	lr "github.com/porter-dev/porter/internal/logger"
 	"github.com/porter-dev/porter/internal/repository"
@@ -80,6 +86,16 @@ type App struct {
 	GithubProjectConf *oauth2.Config
 	DOConf            *oauth2.Config
 
+	// SSOProvider is the identity provider that users can log in with, if
+	// single sign-on is configured
+	SSOProvider oauth.SSOProvider
+
+	// Notifier sends project events to notification channels
+	Notifier *notifier.Notifier
+
+	// SecretBackend stores the values of the projects' secret stores
+	SecretBackend secrets.Backend
+
 	db         *gorm.DB
 	validator  *vr.Validate
 	translator *ut.Translator
@@ -153,6 +169,16 @@ func New(conf *AppConfig) (*App, error) {
 		})
 	}
 
+	if sc := conf.ServerConf; sc.OIDCIssuerURL != "" && sc.OIDCClientID != "" {
+		app.SSOProvider = oauth.NewOIDCProvider(&oauth.OIDCConfig{
+			IssuerURL:    sc.OIDCIssuerURL,
+			ClientID:     sc.OIDCClientID,
+			ClientSecret: sc.OIDCClientSecret,
+			BaseURL:      sc.ServerURL,
+			GroupsClaim:  sc.OIDCGroupsClaim,
+		})
+	}
+
 	app.tokenConf = &token.TokenGeneratorConf{
 		TokenSecret: conf.ServerConf.TokenGeneratorSecret,
 	}
@@ -162,6 +188,67 @@ func New(conf *AppConfig) (*App, error) {
 		app.segmentClient = &client
 	}
 
+	// email channels are only notified if sendgrid is configured
+	var emailSender notifier.EmailSender
+
+	if sc := conf.ServerConf; sc.SendgridAPIKey != "" && sc.SendgridNotificationTemplateID != "" {
+		emailSender = &email.SendgridClient{
+			APIKey:                 sc.SendgridAPIKey,
+			NotificationTemplateID: sc.SendgridNotificationTemplateID,
+			SenderEmail:            sc.SendgridSenderEmail,
+		}
+	}
+
+	app.Notifier = notifier.New(app.Repo.NotificationChannel, emailSender)
+
+	switch sc := conf.ServerConf; sc.SecretBackend {
+	case "", "database":
+		app.SecretBackend = secrets.NewDatabaseBackend(app.Repo.ProjectSecret)
+	case "vault":
+		if sc.VaultAddress == "" {
+			return nil, fmt.Errorf("VAULT_ADDR must be set to use the vault secret backend")
+		}
+
+		app.SecretBackend = secrets.NewVaultBackend(
+			sc.VaultAddress,
+			sc.VaultToken,
+			sc.VaultMountPath,
+			sc.VaultPathPrefix,
+		)
+	default:
+		return nil, fmt.Errorf("unknown secret backend %s", sc.SecretBackend)
+	}
+
+	repo.Endpoints = &repo.BucketEndpoints{
+		S3:  conf.ServerConf.HelmRepoS3Endpoint,
+		GCS: conf.ServerConf.HelmRepoGCSEndpoint,
+	}
+
+	// read public chart repos through a cache, if it's enabled
+	loader.DefaultChartCache = nil
+
+	if sc := conf.ServerConf; sc.HelmChartCacheSize > 0 {
+		var store loader.ChartStore
+
+		if sc.HelmChartCacheDir != "" {
+			store = &loader.DiskChartStore{Dir: sc.HelmChartCacheDir}
+		} else if sc.HelmChartCacheRedis && conf.RedisConf != nil && conf.RedisConf.Enabled {
+			client, err := adapter.NewRedisClient(conf.RedisConf)
+
+			if err != nil {
+				return nil, err
+			}
+
+			store = &loader.RedisChartStore{Client: client}
+		}
+
+		loader.DefaultChartCache = loader.NewChartCache(
+			sc.HelmChartCacheSize,
+			sc.HelmIndexRefreshInterval,
+			store,
+		)
+	}
+
 	return app, nil
 }
 
@@ -176,7 +263,20 @@ func (app *App) getTokenFromRequest(r *http.Request) *token.Token {
 
 	reqToken = strings.TrimSpace(splitToken[1])
 
-	tok, _ := token.GetTokenFromEncoded(reqToken, app.tokenConf)
+	tok, err := token.GetTokenFromEncoded(reqToken, app.tokenConf)
+
+	if err != nil {
+		return nil
+	}
+
+	// tokens backed by a stored API token must not be revoked or expired
+	if tok.TokenID != "" {
+		apiToken, err := app.Repo.APIToken.ReadAPITokenByUniqueID(tok.TokenID)
+
+		if err != nil || !apiToken.IsValid() || apiToken.ProjectID != tok.ProjectID {
+			return nil
+		}
+	}
 
 	return tok
 }
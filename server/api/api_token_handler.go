@@ -0,0 +1,203 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/auth/token"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// CreateAPITokenResponse is a stored API token along with its encoded value,
+// which is only returned when the token is created
+type CreateAPITokenResponse struct {
+	*models.APITokenExternal
+	Token string `json:"token"`
+}
+
+// HandleCreateAPIToken creates a new stored API token for a project
+func (app *App) HandleCreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	userID, err := app.getUserIDFromRequest(r)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	form := &forms.CreateAPITokenForm{
+		ProjectID: uint(projID),
+		UserID:    userID,
+	}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	apiToken, err := form.ToAPIToken()
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	apiToken, err = app.Repo.APIToken.CreateAPIToken(apiToken)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	jwt, err := token.GetStoredTokenForAPI(userID, uint(projID), apiToken.UniqueID)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	encoded, err := jwt.EncodeToken(app.tokenConf)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	app.Logger.Info().Msgf("New API token created: %d", apiToken.ID)
+
+	w.WriteHeader(http.StatusCreated)
+
+	resp := &CreateAPITokenResponse{
+		APITokenExternal: apiToken.Externalize(),
+		Token:            encoded,
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleListAPITokens returns a list of stored API tokens for a project
+func (app *App) HandleListAPITokens(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	apiTokens, err := app.Repo.APIToken.ListAPITokensByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	extTokens := make([]*models.APITokenExternal, 0)
+
+	for _, apiToken := range apiTokens {
+		extTokens = append(extTokens, apiToken.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(extTokens); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleReadAPIToken returns a stored API token via the token ID
+func (app *App) HandleReadAPIToken(w http.ResponseWriter, r *http.Request) {
+	apiToken, ok := app.readProjectAPIToken(w, r)
+
+	if !ok {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(apiToken.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleRevokeAPIToken revokes a stored API token via the token ID. The token
+// is kept so that it is still listed for the project.
+func (app *App) HandleRevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	apiToken, ok := app.readProjectAPIToken(w, r)
+
+	if !ok {
+		return
+	}
+
+	apiToken.Revoked = true
+
+	apiToken, err := app.Repo.APIToken.UpdateAPIToken(apiToken)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(apiToken.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// readProjectAPIToken reads the API token in the URL, and writes an error if
+// it cannot be read or does not belong to the project in the URL
+func (app *App) readProjectAPIToken(w http.ResponseWriter, r *http.Request) (*models.APIToken, bool) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(chi.URLParam(r, "token_id"), 0, 64)
+
+	if err != nil || id == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	apiToken, err := app.Repo.APIToken.ReadAPIToken(uint(id))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return nil, false
+	}
+
+	if apiToken.ProjectID != uint(projID) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+
+	return apiToken, true
+}
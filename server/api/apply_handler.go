@@ -0,0 +1,741 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/apply"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/helm/loader"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// applyRun is a spec that is planned or applied to a cluster, along with the
+// helm agents for each of the spec's namespaces
+type applyRun struct {
+	r          *http.Request
+	projID     uint64
+	cluster    *models.Cluster
+	spec       *apply.Spec
+	registries []*models.Registry
+	agents     map[string]*helm.Agent
+
+	// override is the reason given for deploying releases that deploy policies
+	// block, which fail if it is empty
+	override string
+}
+
+// HandlePlanApply computes the actions that applying a porter.yaml spec to a
+// cluster would perform, without performing them
+func (app *App) HandlePlanApply(w http.ResponseWriter, r *http.Request) {
+	_, plan, ok := app.planApply(w, r)
+
+	if !ok {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(plan); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleApply brings a cluster in line with a porter.yaml spec. The plan is
+// computed again and executed in order, and the result of each action is
+// returned. Actions that fail don't stop the rest of the plan, since
+// resources that were applied successfully are skipped on the next apply.
+func (app *App) HandleApply(w http.ResponseWriter, r *http.Request) {
+	run, plan, ok := app.planApply(w, r)
+
+	if !ok {
+		return
+	}
+
+	// the route only requires write access to releases, so plans that prune
+	// releases or env groups are checked for delete access here
+	for _, action := range plan.Actions {
+		if action.Operation == apply.OperationDelete && !app.canDeleteReleases(r, uint(run.projID)) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+	}
+
+	override, err := app.getDeployOverride(r, uint(run.projID))
+
+	if err != nil {
+		app.sendDeployOverrideError(err, w)
+		return
+	}
+
+	run.override = override
+
+	for _, action := range plan.Actions {
+		if action.Operation == apply.OperationNone {
+			continue
+		}
+
+		action.Status = apply.ActionStatusApplied
+
+		if err := app.executeApplyAction(run, action); err != nil {
+			action.Status = apply.ActionStatusFailed
+			action.Error = err.Error()
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(plan); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// canDeleteReleases returns true if the role of the requesting user, and the
+// scopes of the stored API token that the request was made with, if any, allow
+// deleting releases in the project
+func (app *App) canDeleteReleases(r *http.Request, projID uint) bool {
+	userID, err := app.getUserIDFromRequest(r)
+
+	if err != nil {
+		return false
+	}
+
+	role, err := app.Repo.Project.ReadProjectRole(projID, userID)
+
+	if err != nil || !role.HasPermission(models.ReleaseResource, models.DeleteVerb) {
+		return false
+	}
+
+	if tok := app.getTokenFromRequest(r); tok != nil && tok.TokenID != "" {
+		apiToken, err := app.Repo.APIToken.ReadAPITokenByUniqueID(tok.TokenID)
+
+		if err != nil || !apiToken.HasScope(models.ReleaseResource, models.DeleteVerb) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// planApply parses the spec in the request and compares it to the state of
+// the cluster
+func (app *App) planApply(w http.ResponseWriter, r *http.Request) (*applyRun, *apply.Plan, bool) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, nil, false
+	}
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return nil, nil, false
+	}
+
+	clusterID, err := strconv.ParseUint(vals.Get("cluster_id"), 10, 64)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return nil, nil, false
+	}
+
+	form := &forms.ApplyForm{}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return nil, nil, false
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return nil, nil, false
+	}
+
+	spec, err := apply.ParseSpec([]byte(form.Spec))
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return nil, nil, false
+	}
+
+	cluster, err := app.Repo.Cluster.ReadCluster(uint(clusterID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return nil, nil, false
+	}
+
+	// the access middleware only checks the cluster, so every namespace that
+	// the spec deploys to or prunes is checked against the user's role
+	// bindings here
+	bindings, err := app.getRoleBindingsFromRequest(r, uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return nil, nil, false
+	}
+
+	for _, namespace := range spec.Namespaces() {
+		if !bindings.Allows(cluster.ID, namespace) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return nil, nil, false
+		}
+	}
+
+	registries, err := app.Repo.Registry.ListRegistriesByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return nil, nil, false
+	}
+
+	run := &applyRun{
+		r:          r,
+		projID:     projID,
+		cluster:    cluster,
+		spec:       spec,
+		registries: registries,
+		agents:     make(map[string]*helm.Agent),
+	}
+
+	state, err := app.readApplyState(run, form.Prune)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return nil, nil, false
+	}
+
+	plan, err := apply.ComputePlan(spec, state, form.Prune)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return nil, nil, false
+	}
+
+	return run, plan, true
+}
+
+// applyAgent returns the helm agent for a namespace, creating it the first time
+// that the namespace is used
+func (app *App) applyAgent(run *applyRun, namespace string) (*helm.Agent, error) {
+	if agent, ok := run.agents[namespace]; ok {
+		return agent, nil
+	}
+
+	var agent *helm.Agent
+	var err error
+
+	if app.ServerConf.IsTesting {
+		agent = app.TestAgents.HelmAgent
+	} else {
+		agent, err = helm.GetAgentOutOfClusterConfig(&helm.Form{
+			Cluster:           run.cluster,
+			Repo:              app.Repo,
+			DigitalOceanOAuth: app.DOConf,
+			Storage:           "secret",
+			Namespace:         namespace,
+		}, app.Logger)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	run.agents[namespace] = agent
+
+	return agent, nil
+}
+
+// readApplyState reads the releases and env groups that the spec defines. When
+// pruning, every release in the spec's namespaces that was deployed through
+// Porter, and every Porter env group in the spec's namespaces, is read as well.
+func (app *App) readApplyState(run *applyRun, prune bool) (*apply.State, error) {
+	state := &apply.State{
+		Releases:  make([]*apply.ReleaseState, 0),
+		EnvGroups: make([]*apply.EnvGroupState, 0),
+	}
+
+	for _, namespace := range run.spec.Namespaces() {
+		agent, err := app.applyAgent(run, namespace)
+
+		if err != nil {
+			return nil, err
+		}
+
+		groupNames := make([]string, 0)
+		releaseNames := make([]string, 0)
+
+		for _, group := range run.spec.EnvGroups {
+			if group.Namespace == namespace {
+				groupNames = append(groupNames, group.Name)
+			}
+		}
+
+		for _, rel := range run.spec.Releases {
+			if rel.Namespace == namespace {
+				releaseNames = append(releaseNames, rel.Name)
+			}
+		}
+
+		if prune {
+			configMaps, err := agent.K8sAgent.ListConfigMaps(namespace)
+
+			if err != nil {
+				return nil, err
+			}
+
+			for _, configMap := range configMaps.Items {
+				if isApplyEnvGroup(&configMap) && run.spec.EnvGroup(configMap.Name, namespace) == nil {
+					groupNames = append(groupNames, configMap.Name)
+				}
+			}
+
+			releases, err := agent.ListReleases(namespace, &helm.ListFilter{
+				Namespace:    namespace,
+				StatusFilter: []string{"deployed", "failed", "pending-install", "pending-upgrade", "pending-rollback"},
+			})
+
+			if err != nil {
+				return nil, err
+			}
+
+			for _, rel := range releases {
+				// only releases deployed through Porter are stored
+				if _, err := app.Repo.Release.ReadRelease(run.cluster.ID, rel.Name, namespace); err != nil {
+					continue
+				}
+
+				if !containsString(releaseNames, rel.Name) {
+					releaseNames = append(releaseNames, rel.Name)
+				}
+			}
+		}
+
+		for _, name := range groupNames {
+			group, err := app.readEnvGroupState(agent, name, namespace)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if group != nil {
+				state.EnvGroups = append(state.EnvGroups, group)
+			}
+		}
+
+		for _, name := range releaseNames {
+			rel, err := agent.GetRelease(name, 0)
+
+			if errors.Is(err, driver.ErrReleaseNotFound) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			relState := &apply.ReleaseState{
+				Name:      rel.Name,
+				Namespace: rel.Namespace,
+				Chart:     rel.Chart.Metadata.Name,
+				Version:   rel.Chart.Metadata.Version,
+				Values:    rel.Config,
+			}
+
+			if stored, err := app.Repo.Release.ReadRelease(run.cluster.ID, name, namespace); err == nil {
+				relState.HasBuild = stored.GitActionConfig.ID != 0
+			}
+
+			state.Releases = append(state.Releases, relState)
+		}
+	}
+
+	return state, nil
+}
+
+// readEnvGroupState reads the configmap and linked secret of an env group, or
+// returns nil if the env group does not exist
+func (app *App) readEnvGroupState(
+	agent *helm.Agent,
+	name, namespace string,
+) (*apply.EnvGroupState, error) {
+	configMap, err := agent.K8sAgent.GetConfigMap(name, namespace)
+
+	if k8sErrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	group := &apply.EnvGroupState{
+		Name:      name,
+		Namespace: namespace,
+		Variables: make(map[string]string),
+		Secrets:   make(map[string]string),
+		Prunable:  isApplyEnvGroup(configMap),
+	}
+
+	secretRef := fmt.Sprintf("PORTERSECRET_%s", name)
+
+	for key, val := range configMap.Data {
+		if val != secretRef {
+			group.Variables[key] = val
+		}
+	}
+
+	secret, err := agent.K8sAgent.GetSecret(name, namespace)
+
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if secret != nil {
+		for key, val := range secret.Data {
+			group.Secrets[key] = string(val)
+		}
+	}
+
+	return group, nil
+}
+
+// isApplyEnvGroup returns true if a configmap is an env group that was created
+// from the dashboard or by apply. Configmaps of shared env groups and
+// configmaps that belong to a chart are left out, so that they are never
+// pruned.
+func isApplyEnvGroup(configMap *v1.ConfigMap) bool {
+	if configMap.Labels["porter"] != "true" || configMap.Labels[kubernetes.EnvGroupLabel] != "" {
+		return false
+	}
+
+	if configMap.Labels["app.kubernetes.io/managed-by"] == "Helm" || len(configMap.OwnerReferences) > 0 {
+		return false
+	}
+
+	return true
+}
+
+func (app *App) executeApplyAction(run *applyRun, action *apply.Action) error {
+	agent, err := app.applyAgent(run, action.Namespace)
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case action.Kind == apply.KindEnvGroup && action.Operation == apply.OperationDelete:
+		return app.deleteSpecEnvGroup(agent, action)
+	case action.Kind == apply.KindEnvGroup:
+		return app.writeSpecEnvGroup(run, agent, action)
+	case action.Operation == apply.OperationDelete:
+		return app.deleteSpecRelease(run, agent, action)
+	case action.Operation == apply.OperationCreate:
+		return app.installSpecRelease(run, agent, action)
+	default:
+		return app.upgradeSpecRelease(run, agent, action)
+	}
+}
+
+// writeSpecEnvGroup creates or updates the configmap and linked secret of an
+// env group so that they contain exactly the variables in the spec
+func (app *App) writeSpecEnvGroup(run *applyRun, agent *helm.Agent, action *apply.Action) error {
+	group := run.spec.EnvGroup(action.Name, action.Namespace)
+
+	variables := make(map[string]string)
+	secretData := make(map[string][]byte)
+
+	for key, val := range group.Variables {
+		variables[key] = val
+	}
+
+	for key, val := range group.Secrets {
+		variables[key] = fmt.Sprintf("PORTERSECRET_%s", group.Name)
+		secretData[key] = []byte(val)
+	}
+
+	if action.Operation == apply.OperationCreate {
+		if err := app.ensureNamespace(agent, group.Namespace); err != nil {
+			return err
+		}
+
+		if _, err := agent.K8sAgent.CreateLinkedSecret(group.Name, group.Namespace, group.Name, secretData); err != nil {
+			return err
+		}
+
+		_, err := agent.K8sAgent.CreateConfigMap(group.Name, group.Namespace, variables)
+
+		return err
+	}
+
+	curr, err := app.readEnvGroupState(agent, group.Name, group.Namespace)
+
+	if err != nil {
+		return err
+	}
+
+	// empty values remove keys from the configmap and secret
+	for key := range curr.Variables {
+		if _, ok := variables[key]; !ok {
+			variables[key] = ""
+		}
+	}
+
+	for key := range curr.Secrets {
+		if _, ok := group.Secrets[key]; !ok {
+			secretData[key] = []byte{}
+
+			if _, ok := variables[key]; !ok {
+				variables[key] = ""
+			}
+		}
+	}
+
+	if err := agent.K8sAgent.UpdateLinkedSecret(group.Name, group.Namespace, group.Name, secretData); err != nil {
+		return err
+	}
+
+	return agent.K8sAgent.UpdateConfigMap(group.Name, group.Namespace, variables)
+}
+
+func (app *App) deleteSpecEnvGroup(agent *helm.Agent, action *apply.Action) error {
+	if err := agent.K8sAgent.DeleteConfigMap(action.Name, action.Namespace); err != nil {
+		return err
+	}
+
+	if err := agent.K8sAgent.DeleteLinkedSecret(action.Name, action.Namespace); err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// installSpecRelease deploys a release from its template, and stores it with a
+// webhook token the same way that HandleDeployTemplate does
+func (app *App) installSpecRelease(run *applyRun, agent *helm.Agent, action *apply.Action) error {
+	rel := run.spec.Release(action.Name, action.Namespace)
+
+	chart, err := app.loadSpecChart(rel)
+
+	if err != nil {
+		return err
+	}
+
+	values, err := run.spec.ReleaseValues(rel, nil)
+
+	if err != nil {
+		return err
+	}
+
+	deployment := app.newDeployment(run.r, run.cluster, rel.Namespace, rel.Name, models.DeploymentActionInstall)
+
+	if err := app.enforceDeployPolicies(deployment, run.override); err != nil {
+		return err
+	}
+
+	if err := app.ensureNamespace(agent, rel.Namespace); err != nil {
+		return err
+	}
+
+	helmRel, err := agent.InstallChart(&helm.InstallChartConfig{
+		Chart:         chart,
+		Name:          rel.Name,
+		Namespace:     rel.Namespace,
+		Values:        values,
+		Cluster:       run.cluster,
+		Repo:          *app.Repo,
+		Registries:    run.registries,
+		SecretBackend: app.SecretBackend,
+	}, app.DOConf)
+
+	if err != nil {
+		app.recordDeployment(deployment, nil, err)
+		return err
+	}
+
+	token, err := repository.GenerateRandomBytes(16)
+
+	if err != nil {
+		return err
+	}
+
+	stored, err := app.Repo.Release.CreateRelease(&models.Release{
+		ClusterID:    run.cluster.ID,
+		ProjectID:    run.cluster.ProjectID,
+		Namespace:    rel.Namespace,
+		Name:         rel.Name,
+		WebhookToken: token,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	app.recordDeployment(deployment, helmRel, nil)
+
+	if rel.Build != nil {
+		return app.setupSpecBuild(run, stored, rel)
+	}
+
+	return nil
+}
+
+// upgradeSpecRelease upgrades a release to the template version and values in
+// the spec. The deployed chart is kept unless the spec changes the template or
+// its version.
+func (app *App) upgradeSpecRelease(run *applyRun, agent *helm.Agent, action *apply.Action) error {
+	rel := run.spec.Release(action.Name, action.Namespace)
+
+	curr, err := agent.GetRelease(rel.Name, 0)
+
+	if err != nil {
+		return err
+	}
+
+	conf := &helm.UpgradeReleaseConfig{
+		Name:          rel.Name,
+		Cluster:       run.cluster,
+		Repo:          *app.Repo,
+		Registries:    run.registries,
+		SecretBackend: app.SecretBackend,
+	}
+
+	if containsString(action.Changes, "template") || containsString(action.Changes, "version") {
+		if conf.Chart, err = app.loadSpecChart(rel); err != nil {
+			return err
+		}
+	}
+
+	conf.Values, err = run.spec.ReleaseValues(rel, &apply.ReleaseState{
+		Values: curr.Config,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if containsString(action.Changes, "template") || containsString(action.Changes, "version") ||
+		containsString(action.Changes, "values") {
+		deployment := app.newDeployment(run.r, run.cluster, rel.Namespace, rel.Name, models.DeploymentActionUpgrade)
+
+		if err := app.enforceDeployPolicies(deployment, run.override); err != nil {
+			return err
+		}
+
+		helmRel, err := agent.UpgradeReleaseByValues(conf, app.DOConf)
+
+		app.recordDeployment(deployment, helmRel, err)
+
+		if err != nil {
+			return err
+		}
+
+		app.watchRollout(agent, deployment, helmRel)
+	}
+
+	if containsString(action.Changes, "build") {
+		stored, err := app.Repo.Release.ReadRelease(run.cluster.ID, rel.Name, rel.Namespace)
+
+		if err != nil {
+			return fmt.Errorf("release %s was not deployed through Porter, so it can't be built from source", rel.Name)
+		}
+
+		return app.setupSpecBuild(run, stored, rel)
+	}
+
+	return nil
+}
+
+// deleteSpecRelease uninstalls a release that was removed from the spec
+func (app *App) deleteSpecRelease(run *applyRun, agent *helm.Agent, action *apply.Action) error {
+	if _, err := agent.UninstallChart(action.Name); err != nil {
+		return err
+	}
+
+	stored, err := app.Repo.Release.ReadRelease(run.cluster.ID, action.Name, action.Namespace)
+
+	if err == nil {
+		_, err = app.Repo.Release.DeleteRelease(stored)
+	}
+
+	return err
+}
+
+func (app *App) setupSpecBuild(run *applyRun, stored *models.Release, rel *apply.ReleaseSpec) error {
+	userID, _ := app.getUserIDFromRequest(run.r)
+
+	_, err := app.setupGitAction(run.projID, userID, stored, rel.Name, &forms.CreateGitAction{
+		ReleaseID:      stored.ID,
+		GitRepo:        rel.Build.GitRepo,
+		GitBranch:      rel.Build.GitBranch,
+		ImageRepoURI:   rel.Build.ImageRepoURI,
+		DockerfilePath: rel.Build.DockerfilePath,
+		FolderPath:     rel.Build.FolderPath,
+		GitRepoID:      rel.Build.GitRepoID,
+		BuildEnv:       rel.Build.Env,
+		RegistryID:     rel.Build.RegistryID,
+	})
+
+	return err
+}
+
+func (app *App) loadSpecChart(rel *apply.ReleaseSpec) (*chart.Chart, error) {
+	repoURL := rel.RepoURL
+
+	if repoURL == "" {
+		repoURL = app.ServerConf.DefaultApplicationHelmRepoURL
+	}
+
+	return loader.LoadChartPublic(repoURL, rel.Template, rel.Version)
+}
+
+// ensureNamespace creates a namespace if it does not exist yet
+func (app *App) ensureNamespace(agent *helm.Agent, namespace string) error {
+	namespaces, err := agent.K8sAgent.ListNamespaces()
+
+	if err != nil {
+		return err
+	}
+
+	for _, ns := range namespaces.Items {
+		if ns.Name == namespace {
+			return nil
+		}
+	}
+
+	_, err = agent.K8sAgent.CreateNamespace(namespace)
+
+	return err
+}
+
+func containsString(list []string, str string) bool {
+	for _, item := range list {
+		if item == str {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+const (
+	defaultAuditEventPageSize = 50
+	maxAuditEventPageSize     = 500
+)
+
+// ListAuditEventsResponse is a page of audit events for a project
+type ListAuditEventsResponse struct {
+	Events   []*models.AuditEventExternal `json:"events"`
+	Page     int                          `json:"page"`
+	PageSize int                          `json:"page_size"`
+	Total    int64                        `json:"total"`
+}
+
+// HandleListAuditEvents returns a page of audit events for a project, newest
+// first. The page, page_size, user_id and resource query params are optional.
+func (app *App) HandleListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	page, pageSize := 1, defaultAuditEventPageSize
+
+	if p := vals.Get("page"); p != "" {
+		if page, err = strconv.Atoi(p); err != nil || page < 1 {
+			app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+			return
+		}
+	}
+
+	if ps := vals.Get("page_size"); ps != "" {
+		if pageSize, err = strconv.Atoi(ps); err != nil || pageSize < 1 || pageSize > maxAuditEventPageSize {
+			app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+			return
+		}
+	}
+
+	opts := &repository.ListAuditEventOpts{
+		Resource: vals.Get("resource"),
+		Limit:    pageSize,
+		Offset:   (page - 1) * pageSize,
+	}
+
+	if uid := vals.Get("user_id"); uid != "" {
+		userID, err := strconv.ParseUint(uid, 10, 64)
+
+		if err != nil {
+			app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+			return
+		}
+
+		opts.UserID = uint(userID)
+	}
+
+	events, total, err := app.Repo.AuditEvent.ListAuditEventsByProjectID(uint(projID), opts)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	resp := &ListAuditEventsResponse{
+		Events:   make([]*models.AuditEventExternal, 0),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}
+
+	for _, event := range events {
+		resp.Events = append(resp.Events, event.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
@@ -0,0 +1,627 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/kubernetes/prometheus"
+	"github.com/porter-dev/porter/internal/models"
+	"helm.sh/helm/v3/pkg/chartutil"
+	v1 "k8s.io/api/core/v1"
+)
+
+// canaryPollInterval is how often a canary rollout checks whether it has been
+// aborted while it waits for a step to finish
+const canaryPollInterval = 10 * time.Second
+
+var errCanaryAborted = errors.New("canary rollout aborted")
+
+// canaryRun is a canary rollout that is in progress, along with everything
+// that is needed to step it up, promote it or roll it back
+type canaryRun struct {
+	rolloutID  uint
+	agent      *helm.Agent
+	promSvc    *v1.Service
+	deployment *models.Deployment
+
+	// conf upgrades the stable release to the canary's chart and values
+	conf *helm.UpgradeReleaseConfig
+}
+
+// startCanaryRollout deploys the upgrade in the form as a canary of the web
+// release, and steps it up in the background. The rollout is returned
+// immediately, and its progress can be read from HandleListCanaryRollouts. The
+// deployment must already have been checked against the deploy policies, and
+// is recorded when the rollout finishes.
+func (app *App) startCanaryRollout(
+	w http.ResponseWriter,
+	r *http.Request,
+	agent *helm.Agent,
+	conf *helm.UpgradeReleaseConfig,
+	form *forms.UpgradeReleaseForm,
+	deployment *models.Deployment,
+) {
+	stable, err := agent.GetRelease(form.Name, 0)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return
+	}
+
+	values, err := chartutil.ReadValues([]byte(form.Values))
+
+	if err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return
+	}
+
+	// the canary runs with the same env groups that the release is promoted
+	// with
+	if conf.EnvGroups != nil {
+		values = helm.InjectEnvGroups(values, conf.EnvGroups)
+	}
+
+	if err := validateCanaryRelease(stable.Chart.Metadata.Name, values); err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	promSvc, found, err := prometheus.GetPrometheusService(agent.K8sAgent.Clientset)
+
+	if err != nil || !found {
+		app.sendExternalError(fmt.Errorf("prometheus not found"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"canary rollouts require prometheus to be installed in the cluster"},
+		}, w)
+
+		return
+	}
+
+	cluster := form.ReleaseForm.Cluster
+
+	rollout, err := form.Canary.ToCanaryRollout(deployment.ProjectID, cluster.ID, stable.Namespace, form.Name)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	// only one rollout of a release can be in progress at a time
+	rollouts, err := app.Repo.CanaryRollout.ListCanaryRolloutsByRelease(cluster.ID, stable.Namespace, form.Name)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	if len(rollouts) > 0 && !rollouts[0].IsFinished() {
+		app.sendExternalError(fmt.Errorf("rollout %d in progress", rollouts[0].ID), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"a canary rollout of this release is already in progress"},
+		}, w)
+
+		return
+	}
+
+	rollout.StableRevision = stable.Version
+	rollout.ChartVersion = form.ChartVersion
+	rollout.UserID, _ = app.getUserIDFromRequest(r)
+
+	ch := stable.Chart
+
+	if conf.Chart != nil {
+		ch = conf.Chart
+	}
+
+	_, err = agent.InstallChart(&helm.InstallChartConfig{
+		Chart:         ch,
+		Name:          rollout.CanaryName,
+		Namespace:     stable.Namespace,
+		Values:        helm.CanaryValues(values, rollout.CurrentWeight),
+		Cluster:       conf.Cluster,
+		Repo:          conf.Repo,
+		Registries:    conf.Registries,
+		SecretBackend: app.SecretBackend,
+	}, app.DOConf)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{"error deploying canary " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	rollout, err = app.Repo.CanaryRollout.CreateCanaryRollout(rollout)
+
+	if err != nil {
+		agent.UninstallChart(helm.CanaryReleaseName(form.Name))
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	conf.Chart = ch
+	conf.Values = values
+
+	go app.runCanaryRollout(&canaryRun{
+		rolloutID:  rollout.ID,
+		agent:      agent,
+		promSvc:    promSvc,
+		deployment: deployment,
+		conf:       conf,
+	})
+
+	w.WriteHeader(http.StatusAccepted)
+
+	if err := json.NewEncoder(w).Encode(rollout.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// validateCanaryRelease returns an error if a release can't be rolled out as
+// a canary, which requires traffic to reach it through an nginx ingress
+func validateCanaryRelease(chartName string, values map[string]interface{}) error {
+	if chartName != "web" {
+		return fmt.Errorf("canary rollouts are only supported for web releases")
+	}
+
+	if !helm.IsIngressEnabled(values) {
+		return fmt.Errorf("canary rollouts require the ingress to be enabled")
+	}
+
+	return nil
+}
+
+// HandleListCanaryRollouts returns the canary rollouts of a release, newest
+// first
+func (app *App) HandleListCanaryRollouts(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	clusterID, err := strconv.ParseUint(vals.Get("cluster_id"), 10, 64)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	rollouts, err := app.Repo.CanaryRollout.ListCanaryRolloutsByRelease(
+		uint(clusterID),
+		vals.Get("namespace"),
+		name,
+	)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	extRollouts := make([]*models.CanaryRolloutExternal, 0)
+
+	for _, rollout := range rollouts {
+		extRollouts = append(extRollouts, rollout.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(extRollouts); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleAbortCanaryRollout aborts the canary rollout of a release that is in
+// progress. The canary is removed, and the release is rolled back if it has
+// already been promoted.
+func (app *App) HandleAbortCanaryRollout(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	clusterID, err := strconv.ParseUint(vals.Get("cluster_id"), 10, 64)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	rollouts, err := app.Repo.CanaryRollout.ListCanaryRolloutsByRelease(
+		uint(clusterID),
+		vals.Get("namespace"),
+		name,
+	)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	if len(rollouts) == 0 || rollouts[0].IsFinished() {
+		app.sendExternalError(fmt.Errorf("no rollout in progress"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"no canary rollout of this release is in progress"},
+		}, w)
+
+		return
+	}
+
+	// the rollout is cleaned up by its runner, which checks for aborts
+	rollout := rollouts[0]
+	rollout.Status = models.CanaryStatusAborted
+	rollout.Reason = "aborted by user"
+
+	rollout, err = app.Repo.CanaryRollout.UpdateCanaryRollout(rollout)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(rollout.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// runCanaryRollout steps the traffic to the canary up, watching its error
+// rate after each step. If every step passes, the stable release is upgraded
+// to the canary's values and watched for one more step, after which it is
+// rolled back if its error rate crosses the threshold.
+func (app *App) runCanaryRollout(run *canaryRun) {
+	rollout, err := app.Repo.CanaryRollout.ReadCanaryRollout(run.rolloutID)
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msgf("could not read canary rollout %d", run.rolloutID)
+		return
+	}
+
+	for i, weight := range rollout.StepWeights() {
+		if i > 0 {
+			_, err := run.agent.UpgradeReleaseByValues(&helm.UpgradeReleaseConfig{
+				Name:          rollout.CanaryName,
+				Values:        helm.CanaryValues(run.conf.Values, weight),
+				Cluster:       run.conf.Cluster,
+				Repo:          run.conf.Repo,
+				Registries:    run.conf.Registries,
+				SecretBackend: run.conf.SecretBackend,
+			}, app.DOConf)
+
+			if err != nil {
+				app.failCanaryRollout(run, models.CanaryStatusFailed, fmt.Sprintf(
+					"could not shift %d%% of traffic to the canary: %v",
+					weight,
+					err,
+				))
+
+				return
+			}
+
+			app.updateCanaryRollout(run, func(rollout *models.CanaryRollout) {
+				rollout.CurrentWeight = weight
+			})
+		}
+
+		errorRate, err := app.watchCanaryStep(run, rollout.CanaryName)
+
+		if err == errCanaryAborted {
+			app.failCanaryRollout(run, models.CanaryStatusAborted, "aborted by user")
+			return
+		} else if err != nil {
+			app.failCanaryRollout(run, models.CanaryStatusFailed, fmt.Sprintf(
+				"could not read the error rate of the canary: %v",
+				err,
+			))
+
+			return
+		} else if errorRate > rollout.ErrorThreshold {
+			app.failCanaryRollout(run, models.CanaryStatusRolledBack, fmt.Sprintf(
+				"error rate of the canary was %.2f%% with %d%% of traffic, above the threshold of %.2f%%",
+				errorRate,
+				weight,
+				rollout.ErrorThreshold,
+			))
+
+			return
+		}
+	}
+
+	// the policies are checked again before promotion, since the rollout can
+	// run into a window in which deploys are blocked. An override that was
+	// accepted when the rollout started still applies.
+	if err := app.checkCanaryPromotion(run); err != nil {
+		app.failCanaryRollout(run, models.CanaryStatusFailed, err.Error())
+		return
+	}
+
+	// promote the canary by upgrading the stable release to its values
+	rel, err := run.agent.UpgradeReleaseByValues(run.conf, app.DOConf)
+
+	app.recordDeployment(run.deployment, rel, err)
+	app.uninstallCanary(run, rollout.CanaryName)
+
+	if err != nil {
+		app.finishCanaryRollout(run, models.CanaryStatusFailed, fmt.Sprintf("could not promote the canary: %v", err))
+		return
+	}
+
+	app.updateCanaryRollout(run, func(rollout *models.CanaryRollout) {
+		rollout.CurrentWeight = 100
+	})
+
+	errorRate, err := app.watchCanaryStep(run, rollout.ReleaseName)
+
+	var reason string
+
+	switch {
+	case err == errCanaryAborted:
+		reason = "aborted by user after promotion"
+	case err != nil:
+		// the promoted release is kept, since it passed every canary step
+		app.Logger.Warn().Err(err).Msgf("could not read error rate of promoted release %s", rollout.ReleaseName)
+	case errorRate > rollout.ErrorThreshold:
+		reason = fmt.Sprintf(
+			"error rate of the promoted release was %.2f%%, above the threshold of %.2f%%",
+			errorRate,
+			rollout.ErrorThreshold,
+		)
+	}
+
+	if reason == "" {
+		app.finishCanaryRollout(run, models.CanaryStatusPromoted, "")
+		return
+	}
+
+	if err := app.rollbackCanaryPromotion(run, rollout); err != nil {
+		app.finishCanaryRollout(run, models.CanaryStatusFailed, fmt.Sprintf("%s, and could not roll back: %v", reason, err))
+		return
+	}
+
+	app.finishCanaryRollout(run, models.CanaryStatusRolledBack, fmt.Sprintf(
+		"%s, rolled back to revision %d",
+		reason,
+		rollout.StableRevision,
+	))
+}
+
+// RecoverCanaryRollouts finishes the canary rollouts that were in progress
+// when the server stopped. Rollouts are run in memory and can't be resumed, so
+// a rollout that was not promoted is failed and its canary is uninstalled,
+// which sends all traffic back to the stable release.
+func (app *App) RecoverCanaryRollouts() {
+	rollouts, err := app.Repo.CanaryRollout.ListCanaryRolloutsByStatus(models.CanaryStatusProgressing)
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msg("could not list canary rollouts in progress")
+		return
+	}
+
+	for _, rollout := range rollouts {
+		app.recoverCanaryRollout(rollout)
+	}
+}
+
+func (app *App) recoverCanaryRollout(rollout *models.CanaryRollout) {
+	run := &canaryRun{
+		rolloutID: rollout.ID,
+	}
+
+	cluster, err := app.Repo.Cluster.ReadCluster(rollout.ClusterID)
+
+	if err == nil {
+		run.agent, err = app.getClusterAgent(cluster, rollout.Namespace)
+	}
+
+	if err != nil {
+		app.finishCanaryRollout(run, models.CanaryStatusFailed, fmt.Sprintf(
+			"the server restarted during the rollout, and the canary could not be removed: %v",
+			err,
+		))
+
+		return
+	}
+
+	// the canary is removed before the stable release is watched after
+	// promotion, so it may already be gone
+	if _, err := run.agent.GetRelease(rollout.CanaryName, 0); err == nil {
+		app.uninstallCanary(run, rollout.CanaryName)
+	}
+
+	// a stable release with a newer revision than the one the rollout started
+	// from was already promoted, and is kept
+	if stable, err := run.agent.GetRelease(rollout.ReleaseName, 0); err == nil &&
+		stable.Version > rollout.StableRevision {
+		app.finishCanaryRollout(run, models.CanaryStatusPromoted, fmt.Sprintf(
+			"the server restarted before the promoted release was watched, so revision %d was kept",
+			stable.Version,
+		))
+
+		return
+	}
+
+	app.finishCanaryRollout(run, models.CanaryStatusFailed, "the server restarted during the rollout, and the canary was removed")
+}
+
+// checkCanaryPromotion returns an error if the deploy policies block the
+// promotion of a canary at the current time
+func (app *App) checkCanaryPromotion(run *canaryRun) error {
+	if run.deployment.OverrideReason != "" {
+		return nil
+	}
+
+	promotion := *run.deployment
+	promotion.StartedAt = time.Now()
+
+	reasons, err := app.deployPolicyBlockReasons(&promotion)
+
+	if err != nil {
+		return fmt.Errorf("could not check deploy policies before promotion: %v", err)
+	}
+
+	if len(reasons) > 0 {
+		return fmt.Errorf("promotion blocked by deploy policies: %s", strings.Join(reasons, "; "))
+	}
+
+	return nil
+}
+
+// watchCanaryStep waits for a step of the rollout to finish, and returns the
+// highest error rate of the release's ingresses during the step
+func (app *App) watchCanaryStep(run *canaryRun, name string) (float64, error) {
+	rollout, err := app.Repo.CanaryRollout.ReadCanaryRollout(run.rolloutID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	interval := time.Duration(rollout.StepIntervalSeconds) * time.Second
+
+	for end := time.Now().Add(interval); time.Now().Before(end); {
+		wait := time.Until(end)
+
+		if wait > canaryPollInterval {
+			wait = canaryPollInterval
+		}
+
+		time.Sleep(wait)
+
+		rollout, err := app.Repo.CanaryRollout.ReadCanaryRollout(run.rolloutID)
+
+		if err == nil && rollout.Status == models.CanaryStatusAborted {
+			return 0, errCanaryAborted
+		}
+	}
+
+	rel, err := run.agent.GetRelease(name, 0)
+
+	if err != nil {
+		return 0, err
+	}
+
+	ingresses, err := helm.IngressNames(rel.Manifest)
+
+	if err != nil {
+		return 0, err
+	}
+
+	errorRate, err := prometheus.GetNGINXErrorRate(
+		run.agent.K8sAgent.Clientset,
+		run.promSvc,
+		rel.Namespace,
+		ingresses,
+		interval,
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	app.updateCanaryRollout(run, func(rollout *models.CanaryRollout) {
+		rollout.ErrorRate = errorRate
+	})
+
+	return errorRate, nil
+}
+
+// failCanaryRollout removes the canary before it is promoted, so that all
+// traffic goes back to the stable release, and records the failed deploy
+func (app *App) failCanaryRollout(run *canaryRun, status, reason string) {
+	rollout, err := app.Repo.CanaryRollout.ReadCanaryRollout(run.rolloutID)
+
+	if err == nil {
+		app.uninstallCanary(run, rollout.CanaryName)
+	}
+
+	app.recordDeployment(run.deployment, nil, errors.New(reason))
+	app.finishCanaryRollout(run, status, reason)
+}
+
+// rollbackCanaryPromotion rolls the stable release back to the revision that
+// it was at before the rollout, and records the rollback
+func (app *App) rollbackCanaryPromotion(run *canaryRun, rollout *models.CanaryRollout) error {
+	deployment := &models.Deployment{
+		ProjectID:   run.deployment.ProjectID,
+		ClusterID:   run.deployment.ClusterID,
+		ReleaseName: run.deployment.ReleaseName,
+		Namespace:   run.deployment.Namespace,
+		Source:      run.deployment.Source,
+		Action:      models.DeploymentActionRollback,
+		UserID:      run.deployment.UserID,
+		StartedAt:   time.Now(),
+	}
+
+	err := run.agent.RollbackRelease(rollout.ReleaseName, rollout.StableRevision)
+
+	rel, _ := run.agent.GetRelease(rollout.ReleaseName, 0)
+
+	app.recordDeployment(deployment, rel, err)
+
+	return err
+}
+
+func (app *App) uninstallCanary(run *canaryRun, name string) {
+	if _, err := run.agent.UninstallChart(name); err != nil {
+		app.Logger.Warn().Err(err).Msgf("could not uninstall canary release %s", name)
+	}
+}
+
+// finishCanaryRollout stores the final status of the rollout
+func (app *App) finishCanaryRollout(run *canaryRun, status, reason string) {
+	now := time.Now()
+
+	app.updateCanaryRollout(run, func(rollout *models.CanaryRollout) {
+		rollout.Status = status
+		rollout.Reason = reason
+		rollout.FinishedAt = &now
+	})
+}
+
+// updateCanaryRollout applies the update to the latest stored rollout, so that
+// an abort that is stored while the rollout is running is not overwritten
+func (app *App) updateCanaryRollout(run *canaryRun, update func(rollout *models.CanaryRollout)) {
+	rollout, err := app.Repo.CanaryRollout.ReadCanaryRollout(run.rolloutID)
+
+	if err == nil {
+		update(rollout)
+		_, err = app.Repo.CanaryRollout.UpdateCanaryRollout(rollout)
+	}
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msgf("could not update canary rollout %d", run.rolloutID)
+	}
+}
@@ -9,6 +9,7 @@ import (
 type CapabilitiesExternal struct {
 	Provisioner bool `json:"provisioner"`
 	GitHub bool	`json:"github"`
+	SSO bool `json:"sso"`
 }
 
 // HandleGetCapabilities gets the capabilities of the server
@@ -19,6 +20,7 @@ func (app *App) HandleGetCapabilities(w http.ResponseWriter, r *http.Request) {
 	capExternal := &CapabilitiesExternal{
 		Provisioner: cap.Provisioner,
 		GitHub: cap.Github,
+		SSO: app.SSOProvider != nil,
 	}
 
 	if err := json.NewEncoder(w).Encode(capExternal); err != nil {
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -11,10 +12,12 @@ import (
 	"github.com/porter-dev/porter/internal/forms"
 	"github.com/porter-dev/porter/internal/helm"
 	"github.com/porter-dev/porter/internal/helm/loader"
+	"github.com/porter-dev/porter/internal/helm/repo"
 	"github.com/porter-dev/porter/internal/integrations/ci/actions"
 	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/repository"
 	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/chart"
 )
 
 // HandleDeployTemplate triggers a chart deployment from a template
@@ -50,7 +53,15 @@ func (app *App) HandleDeployTemplate(w http.ResponseWriter, r *http.Request) {
 
 	getChartForm.PopulateRepoURLFromQueryParams(vals)
 
-	chart, err := loader.LoadChartPublic(getChartForm.RepoURL, getChartForm.Name, getChartForm.Version)
+	var chart *chart.Chart
+
+	// if a helm_repo_id is passed as query param, the chart is loaded from
+	// the project's helm repo with its credentials
+	if helmID := vals.Get("helm_repo_id"); helmID != "" {
+		chart, err = app.loadHelmRepoChart(uint(projID), helmID, getChartForm.Name, getChartForm.Version)
+	} else {
+		chart, err = app.loadProjectChart(uint(projID), getChartForm.RepoURL, getChartForm.Name, getChartForm.Version)
+	}
 
 	if err != nil {
 		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
@@ -96,18 +107,33 @@ func (app *App) HandleDeployTemplate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	conf := &helm.InstallChartConfig{
-		Chart:      chart,
-		Name:       form.ChartTemplateForm.Name,
-		Namespace:  form.ReleaseForm.Form.Namespace,
-		Values:     form.ChartTemplateForm.FormValues,
-		Cluster:    form.ReleaseForm.Cluster,
-		Repo:       *app.Repo,
-		Registries: registries,
+		Chart:         chart,
+		Name:          form.ChartTemplateForm.Name,
+		Namespace:     form.ReleaseForm.Form.Namespace,
+		Values:        form.ChartTemplateForm.FormValues,
+		Cluster:       form.ReleaseForm.Cluster,
+		Repo:          *app.Repo,
+		Registries:    registries,
+		SecretBackend: app.SecretBackend,
+	}
+
+	deployment := app.newDeployment(
+		r,
+		form.ReleaseForm.Cluster,
+		form.ReleaseForm.Form.Namespace,
+		form.ChartTemplateForm.Name,
+		models.DeploymentActionInstall,
+	)
+
+	if !app.checkDeployPolicies(w, r, deployment) {
+		return
 	}
 
-	_, err = agent.InstallChart(conf, app.DOConf)
+	rel, err := agent.InstallChart(conf, app.DOConf)
 
 	if err != nil {
+		app.recordDeployment(deployment, nil, err)
+
 		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
 			Code:   ErrReleaseDeploy,
 			Errors: []string{"error installing a new chart: " + err.Error()},
@@ -135,22 +161,31 @@ func (app *App) HandleDeployTemplate(w http.ResponseWriter, r *http.Request) {
 	_, err = app.Repo.Release.CreateRelease(release)
 
 	if err != nil {
+		// the chart was installed, so the deployment is still recorded
+		app.recordDeployment(deployment, rel, nil)
+
 		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
 			Code:   ErrReleaseDeploy,
 			Errors: []string{"error creating a webhook: " + err.Error()},
 		}, w)
+
+		return
 	}
 
+	// recorded once the release is stored, so that the deployment is linked to it
+	app.recordDeployment(deployment, rel, nil)
+
 	// if github action config is linked, call the github action config handler
 	if form.GithubActionConfig != nil {
 		gaForm := &forms.CreateGitAction{
-			ReleaseID:      release.ID,
-			GitRepo:        form.GithubActionConfig.GitRepo,
-			ImageRepoURI:   form.GithubActionConfig.ImageRepoURI,
-			DockerfilePath: form.GithubActionConfig.DockerfilePath,
-			GitRepoID:      form.GithubActionConfig.GitRepoID,
-			BuildEnv:       form.GithubActionConfig.BuildEnv,
-			RegistryID:     form.GithubActionConfig.RegistryID,
+			ReleaseID:       release.ID,
+			GitRepo:         form.GithubActionConfig.GitRepo,
+			ImageRepoURI:    form.GithubActionConfig.ImageRepoURI,
+			DockerfilePath:  form.GithubActionConfig.DockerfilePath,
+			GitRepoID:       form.GithubActionConfig.GitRepoID,
+			BuildEnv:        form.GithubActionConfig.BuildEnv,
+			RegistryID:      form.GithubActionConfig.RegistryID,
+			PreviewsEnabled: form.GithubActionConfig.PreviewsEnabled,
 		}
 
 		// validate the form
@@ -263,6 +298,7 @@ func (app *App) HandleUninstallTemplate(w http.ResponseWriter, r *http.Request)
 					DockerFilePath: gitAction.DockerfilePath,
 					FolderPath:     gitAction.FolderPath,
 					ImageRepoURL:   gitAction.ImageRepoURI,
+					Previews:       gitAction.PreviewsEnabled,
 					BuildEnv:       cEnv.Container.Env.Normal,
 				}
 
@@ -281,3 +317,37 @@ func (app *App) HandleUninstallTemplate(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 	return
 }
+
+// loadProjectChart loads a chart from a public repo, or from an OCI registry
+// with the credentials of the project's linked registry
+func (app *App) loadProjectChart(projID uint, repoURL, name, version string) (*chart.Chart, error) {
+	if !loader.IsOCIURL(repoURL) {
+		return loader.LoadChartPublic(repoURL, name, version)
+	}
+
+	hr := &repo.HelmRepo{
+		ProjectID: projID,
+		RepoURL:   repoURL,
+	}
+
+	return hr.GetChart(*app.Repo, app.DOConf, name, version)
+}
+
+// loadHelmRepoChart loads a chart from a linked helm repo of the project
+func (app *App) loadHelmRepoChart(projID uint, helmID, name, version string) (*chart.Chart, error) {
+	id, err := strconv.ParseUint(helmID, 0, 64)
+
+	if err != nil || id == 0 {
+		return nil, fmt.Errorf("invalid helm repo id %s", helmID)
+	}
+
+	hr, err := app.Repo.HelmRepo.ReadHelmRepo(uint(id))
+
+	if err != nil || hr.ProjectID != projID {
+		return nil, fmt.Errorf("helm repo %d not found in project", id)
+	}
+
+	hrAPI := repo.HelmRepo(*hr)
+
+	return hrAPI.GetChart(*app.Repo, app.DOConf, name, version)
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/models"
 )
 
 // ------------------------- TEST TYPES AND MAIN LOOP ------------------------- //
@@ -89,6 +90,54 @@ func testDeployRequests(t *testing.T, tests []*deployTest, canQuery bool) {
 // 	testDeployRequests(t, newDeployTests, true)
 // }
 
+func TestHandleDeployTemplateFromHelmRepo(t *testing.T) {
+	server := newTestChartRepo(t)
+	defer server.Close()
+
+	tester := newTester(true)
+	initRoleMember(tester, models.RoleDeveloper)
+	initProjectHelmRepos(tester, server.URL)
+
+	tester.repo.Cluster.CreateCluster(&models.Cluster{
+		ProjectID: 1,
+		Name:      "cluster-test",
+		Server:    "https://10.10.10.10",
+	})
+
+	tester.repo.RoleBinding.CreateRoleBinding(&models.RoleBinding{
+		ProjectID: 1,
+		UserID:    1,
+		ClusterID: 1,
+		Namespace: "default",
+	})
+
+	// the chart is loaded from the project's helm repo, so the deploy gets as
+	// far as the namespace check of the member's binding
+	endpoint := "/api/projects/1/deploy/web/latest?cluster_id=1&namespace=default&storage=memory&helm_repo_id=1"
+	body := `{"name":"web","namespace":"other"}`
+
+	if rr := tester.send("POST", endpoint, body); rr.Code != http.StatusForbidden {
+		t.Errorf("Deploy from helm repo, handler returned wrong status code: got %v want %v",
+			rr.Code, http.StatusForbidden)
+	}
+
+	if server.requests("/project-1/web-0.1.0.tgz") != 1 {
+		t.Errorf("Deploy from helm repo, expected the chart to be downloaded from the helm repo")
+	}
+
+	// helm repo 3 belongs to another project, so its credentials are not used
+	endpoint = "/api/projects/1/deploy/web/latest?cluster_id=1&namespace=default&storage=memory&helm_repo_id=3"
+
+	if rr := tester.send("POST", endpoint, body); rr.Code != http.StatusBadRequest {
+		t.Errorf("Deploy from helm repo of other project, handler returned wrong status code: got %v want %v",
+			rr.Code, http.StatusBadRequest)
+	}
+
+	if server.requests("/project-2/index.yaml") != 0 {
+		t.Errorf("Deploy from helm repo of other project, the helm repo was read")
+	}
+}
+
 // ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
 
 func initDefaultDeploy(tester *tester) {
@@ -0,0 +1,400 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/notifier"
+	"github.com/porter-dev/porter/server/requestlog"
+)
+
+// DeployOverrideQueryParam is the query param that deploys are passed an
+// emergency override reason in, which lets a deploy go ahead while a deploy
+// policy blocks it
+const DeployOverrideQueryParam = "override_reason"
+
+var errDeployOverrideForbidden = errors.New("only project admins can override deploy policies")
+
+// HandleCreateDeployPolicy creates a new deploy policy for a project
+func (app *App) HandleCreateDeployPolicy(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	policy, ok := app.readDeployPolicyForm(w, r, uint(projID))
+
+	if !ok {
+		return
+	}
+
+	policy, err = app.Repo.DeployPolicy.CreateDeployPolicy(policy)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	app.Logger.Info().Msgf("New deploy policy created: %d", policy.ID)
+
+	requestlog.RecordChange(r, nil, policy.Externalize())
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(policy.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleListDeployPolicies returns the deploy policies of a project
+func (app *App) HandleListDeployPolicies(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	policies, err := app.Repo.DeployPolicy.ListDeployPoliciesByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	extPolicies := make([]*models.DeployPolicyExternal, 0)
+
+	for _, policy := range policies {
+		extPolicies = append(extPolicies, policy.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(extPolicies); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleUpdateDeployPolicy replaces the rules of a deploy policy
+func (app *App) HandleUpdateDeployPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, ok := app.readProjectDeployPolicy(w, r)
+
+	if !ok {
+		return
+	}
+
+	newPolicy, ok := app.readDeployPolicyForm(w, r, policy.ProjectID)
+
+	if !ok {
+		return
+	}
+
+	newPolicy.Model = policy.Model
+
+	newPolicy, err := app.Repo.DeployPolicy.UpdateDeployPolicy(newPolicy)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	requestlog.RecordChange(r, policy.Externalize(), newPolicy.Externalize())
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(newPolicy.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleDeleteDeployPolicy deletes a deploy policy
+func (app *App) HandleDeleteDeployPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, ok := app.readProjectDeployPolicy(w, r)
+
+	if !ok {
+		return
+	}
+
+	if err := app.Repo.DeployPolicy.DeleteDeployPolicy(policy); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	requestlog.RecordChange(r, policy.Externalize(), nil)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// checkDeployPolicies checks the deploy policies of the project and cluster of
+// a deployment before it is started, and writes an error and returns false if
+// a policy blocks it. A blocked deployment goes ahead only if a project admin
+// passes an override reason, which is recorded on the deployment and sent to
+// the project's notification channels. Webhook deploys can't be overridden.
+func (app *App) checkDeployPolicies(
+	w http.ResponseWriter,
+	r *http.Request,
+	deployment *models.Deployment,
+) bool {
+	reasons, err := app.deployPolicyBlockReasons(deployment)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return false
+	}
+
+	if len(reasons) == 0 {
+		return true
+	}
+
+	override := ""
+
+	if deployment.Source != models.DeploymentSourceWebhook {
+		override, err = app.getDeployOverride(r, deployment.ProjectID)
+
+		if err != nil {
+			app.sendDeployOverrideError(err, w)
+			return false
+		}
+	}
+
+	if override == "" {
+		app.sendExternalError(
+			fmt.Errorf("deploy of %s blocked by deploy policies", deployment.ReleaseName),
+			http.StatusForbidden,
+			HTTPError{
+				Code:   ErrReleaseDeployBlocked,
+				Errors: reasons,
+			},
+			w,
+		)
+
+		return false
+	}
+
+	app.overrideDeployPolicies(deployment, reasons, override)
+
+	return true
+}
+
+// getDeployOverride returns the override reason that is passed in the request.
+// Only project admins can override deploy policies, so an error is returned if
+// anyone else passes one.
+func (app *App) getDeployOverride(r *http.Request, projID uint) (string, error) {
+	override := strings.TrimSpace(r.URL.Query().Get(DeployOverrideQueryParam))
+
+	if override == "" {
+		return "", nil
+	}
+
+	userID, err := app.getUserIDFromRequest(r)
+
+	if err != nil || userID == 0 {
+		return "", errDeployOverrideForbidden
+	}
+
+	role, err := app.Repo.Project.ReadProjectRole(projID, userID)
+
+	if err != nil || role.Kind != models.RoleAdmin {
+		return "", errDeployOverrideForbidden
+	}
+
+	return override, nil
+}
+
+// sendDeployOverrideError writes the error of an override reason that the
+// requester is not allowed to pass
+func (app *App) sendDeployOverrideError(err error, w http.ResponseWriter) {
+	app.sendExternalError(err, http.StatusForbidden, HTTPError{
+		Code:   ErrReleaseDeployBlocked,
+		Errors: []string{err.Error()},
+	}, w)
+}
+
+// enforceDeployPolicies returns an error if the deploy policies block a
+// deployment that is one of several started by a request, such as the
+// releases of an apply or an env group redeploy. The override reason must
+// already have been read with getDeployOverride.
+func (app *App) enforceDeployPolicies(deployment *models.Deployment, override string) error {
+	reasons, err := app.deployPolicyBlockReasons(deployment)
+
+	if err != nil {
+		return err
+	}
+
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	if override == "" {
+		return fmt.Errorf("blocked by deploy policies: %s", strings.Join(reasons, "; "))
+	}
+
+	app.overrideDeployPolicies(deployment, reasons, override)
+
+	return nil
+}
+
+// deployPolicyBlockReasons returns the reasons that the deploy policies of the
+// project and cluster of a deployment block it, which is empty if the
+// deployment is allowed
+func (app *App) deployPolicyBlockReasons(deployment *models.Deployment) ([]string, error) {
+	policies, err := app.Repo.DeployPolicy.ListDeployPoliciesByProjectID(deployment.ProjectID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reasons := make([]string, 0)
+
+	for _, policy := range policies {
+		if !policy.AppliesTo(deployment.ClusterID) {
+			continue
+		}
+
+		reason, err := policy.BlockedReason(deployment.StartedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if reason != "" {
+			reasons = append(reasons, fmt.Sprintf("deploy policy %s: %s", policy.Name, reason))
+		}
+	}
+
+	return reasons, nil
+}
+
+// overrideDeployPolicies records the override reason of a blocked deployment,
+// and sends it to the project's notification channels
+func (app *App) overrideDeployPolicies(
+	deployment *models.Deployment,
+	reasons []string,
+	override string,
+) {
+	deployment.OverrideReason = override
+
+	app.Logger.Warn().Msgf(
+		"deploy policies overridden for release %s in project %d: %s",
+		deployment.ReleaseName,
+		deployment.ProjectID,
+		override,
+	)
+
+	app.notify(&notifier.Event{
+		Kind:      models.NotificationEventDeployOverride,
+		ProjectID: deployment.ProjectID,
+		Title:     fmt.Sprintf("Deploy policies overridden for %s", deployment.ReleaseName),
+		Message: fmt.Sprintf(
+			"Release %s in namespace %s was deployed while deploys were blocked (%s). Override reason: %s",
+			deployment.ReleaseName,
+			deployment.Namespace,
+			strings.Join(reasons, "; "),
+			override,
+		),
+		URL: app.ServerConf.ServerURL,
+		Fields: map[string]string{
+			"release":   deployment.ReleaseName,
+			"namespace": deployment.Namespace,
+			"source":    deployment.Source,
+			"action":    deployment.Action,
+		},
+		Time: deployment.StartedAt,
+	})
+}
+
+// readDeployPolicyForm decodes and validates a deploy policy in the request
+// body, and writes an error if the policy is invalid or applies to a cluster
+// outside of the project
+func (app *App) readDeployPolicyForm(
+	w http.ResponseWriter,
+	r *http.Request,
+	projectID uint,
+) (*models.DeployPolicy, bool) {
+	form := &forms.DeployPolicyForm{}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return nil, false
+	}
+
+	policy, err := form.ToDeployPolicy(projectID)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return nil, false
+	}
+
+	if policy.ClusterID != 0 {
+		cluster, err := app.Repo.Cluster.ReadCluster(policy.ClusterID)
+
+		if err != nil || cluster.ProjectID != projectID {
+			app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+				Code:   ErrProjectValidateFields,
+				Errors: []string{"cluster not found in project"},
+			}, w)
+
+			return nil, false
+		}
+	}
+
+	return policy, true
+}
+
+// readProjectDeployPolicy reads the deploy policy in the URL, and writes an
+// error if it cannot be read or does not belong to the project in the URL
+func (app *App) readProjectDeployPolicy(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*models.DeployPolicy, bool) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(chi.URLParam(r, "policy_id"), 0, 64)
+
+	if err != nil || id == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	policy, err := app.Repo.DeployPolicy.ReadDeployPolicy(uint(id))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return nil, false
+	}
+
+	if policy.ProjectID != uint(projID) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+
+	return policy, true
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/notifier"
+	"github.com/porter-dev/porter/internal/repository"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const (
+	defaultDeploymentPageSize = 20
+	maxDeploymentPageSize     = 100
+)
+
+// ListDeploymentsResponse is a page of deployments for a release
+type ListDeploymentsResponse struct {
+	Deployments []*models.DeploymentExternal `json:"deployments"`
+	Page        int                          `json:"page"`
+	PageSize    int                          `json:"page_size"`
+	Total       int64                        `json:"total"`
+}
+
+// HandleListDeployments returns a page of the deployments of a release, newest
+// first. The page, page_size, source and status query params are optional.
+func (app *App) HandleListDeployments(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	clusterID, err := strconv.ParseUint(vals.Get("cluster_id"), 10, 64)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	page, pageSize := 1, defaultDeploymentPageSize
+
+	if p := vals.Get("page"); p != "" {
+		if page, err = strconv.Atoi(p); err != nil || page < 1 {
+			app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+			return
+		}
+	}
+
+	if ps := vals.Get("page_size"); ps != "" {
+		if pageSize, err = strconv.Atoi(ps); err != nil || pageSize < 1 || pageSize > maxDeploymentPageSize {
+			app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+			return
+		}
+	}
+
+	deployments, total, err := app.Repo.Deployment.ListDeploymentsByRelease(
+		uint(clusterID),
+		vals.Get("namespace"),
+		name,
+		&repository.ListDeploymentOpts{
+			Source: vals.Get("source"),
+			Status: vals.Get("status"),
+			Limit:  pageSize,
+			Offset: (page - 1) * pageSize,
+		},
+	)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	resp := &ListDeploymentsResponse{
+		Deployments: make([]*models.DeploymentExternal, 0),
+		Page:        page,
+		PageSize:    pageSize,
+		Total:       total,
+	}
+
+	for _, deployment := range deployments {
+		resp.Deployments = append(resp.Deployments, deployment.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// newDeployment starts a deployment record for a release that is changed by a
+// user through the dashboard or the CLI
+func (app *App) newDeployment(
+	r *http.Request,
+	cluster *models.Cluster,
+	namespace, name, action string,
+) *models.Deployment {
+	source := models.DeploymentSourceUI
+
+	// the CLI identifies itself, and API tokens are only used outside of the
+	// dashboard
+	if r.Header.Get("X-Porter-Client") == "cli" || app.getTokenFromRequest(r) != nil {
+		source = models.DeploymentSourceCLI
+	}
+
+	userID, _ := app.getUserIDFromRequest(r)
+
+	return &models.Deployment{
+		ProjectID:   cluster.ProjectID,
+		ClusterID:   cluster.ID,
+		ReleaseName: name,
+		Namespace:   namespace,
+		Source:      source,
+		Action:      action,
+		UserID:      userID,
+		StartedAt:   time.Now(),
+	}
+}
+
+// recordDeployment completes a deployment record with the result of the deploy
+// and stores it. A record that can't be stored is only logged, since the deploy
+// itself has already happened.
+func (app *App) recordDeployment(
+	deployment *models.Deployment,
+	rel *release.Release,
+	deployErr error,
+) {
+	deployment.FinishedAt = time.Now()
+	deployment.Status = models.DeploymentStatusSucceeded
+
+	if deployErr != nil {
+		deployment.Status = models.DeploymentStatusFailed
+		deployment.Error = deployErr.Error()
+	}
+
+	if rel != nil {
+		deployment.Revision = rel.Version
+
+		if image, ok := rel.Config["image"].(map[string]interface{}); ok {
+			if repo, ok := image["repository"]; ok && repo != nil {
+				deployment.ImageRepository = fmt.Sprint(repo)
+			}
+
+			if tag, ok := image["tag"]; ok && tag != nil {
+				deployment.ImageTag = fmt.Sprint(tag)
+			}
+		}
+	}
+
+	stored, err := app.Repo.Release.ReadRelease(
+		deployment.ClusterID,
+		deployment.ReleaseName,
+		deployment.Namespace,
+	)
+
+	if err == nil {
+		deployment.ReleaseID = stored.ID
+
+		// images built by the github action are tagged with the commit
+		gitAction := stored.GitActionConfig
+
+		if deployment.CommitSHA == "" && gitAction.ID != 0 &&
+			deployment.ImageRepository == gitAction.ImageRepoURI {
+			deployment.CommitSHA = deployment.ImageTag
+		}
+	}
+
+	if _, err := app.Repo.Deployment.CreateDeployment(deployment); err != nil {
+		app.Logger.Warn().Err(err).Msgf(
+			"could not record deployment of release %s",
+			deployment.ReleaseName,
+		)
+	}
+
+	app.notifyDeployment(deployment)
+}
+
+// notifyDeployment sends failed deployments and successful rollbacks to the
+// project's notification channels
+func (app *App) notifyDeployment(deployment *models.Deployment) {
+	fields := map[string]string{
+		"release":   deployment.ReleaseName,
+		"namespace": deployment.Namespace,
+		"source":    deployment.Source,
+	}
+
+	if deployment.Revision != 0 {
+		fields["revision"] = strconv.Itoa(deployment.Revision)
+	}
+
+	if deployment.ImageTag != "" {
+		fields["image_tag"] = deployment.ImageTag
+	}
+
+	event := &notifier.Event{
+		ProjectID: deployment.ProjectID,
+		URL:       app.ServerConf.ServerURL,
+		Fields:    fields,
+		Time:      deployment.FinishedAt,
+	}
+
+	switch {
+	case deployment.Status == models.DeploymentStatusFailed:
+		event.Kind = models.NotificationEventDeployFailed
+		event.Title = fmt.Sprintf("Deploy of %s failed", deployment.ReleaseName)
+		event.Message = deployment.Error
+	case deployment.Action == models.DeploymentActionRollback:
+		event.Kind = models.NotificationEventRollback
+		event.Title = fmt.Sprintf("%s was rolled back", deployment.ReleaseName)
+		event.Message = fmt.Sprintf(
+			"Release %s in namespace %s was rolled back to revision %d.",
+			deployment.ReleaseName,
+			deployment.Namespace,
+			deployment.Revision,
+		)
+	default:
+		return
+	}
+
+	app.notify(event)
+}
+
+// notify sends an event to the project's notification channels in the
+// background
+func (app *App) notify(event *notifier.Event) {
+	if app.Notifier == nil {
+		return
+	}
+
+	go func() {
+		if err := app.Notifier.Notify(event); err != nil {
+			app.Logger.Warn().Err(err).Msgf("could not send %s notification", event.Kind)
+		}
+	}()
+}
@@ -0,0 +1,880 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/server/requestlog"
+)
+
+// EnvGroupSyncResponse is an env group along with the result of writing it to
+// the namespaces of its releases, and of redeploying the releases
+type EnvGroupSyncResponse struct {
+	*models.EnvGroupExternal
+
+	Namespaces []*EnvGroupNamespaceResult `json:"namespaces"`
+	Releases   []*EnvGroupReleaseResult   `json:"releases"`
+}
+
+// EnvGroupNamespaceResult is the result of writing the configmap and secret of
+// an env group to a namespace
+type EnvGroupNamespaceResult struct {
+	Namespace string `json:"namespace"`
+	Error     string `json:"error,omitempty"`
+}
+
+// EnvGroupReleaseResult is the result of redeploying a release that an env
+// group is attached to
+type EnvGroupReleaseResult struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Revision  int    `json:"revision,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HandleCreateEnvGroup creates a shared env group in a cluster. The group is
+// only written to the cluster once it is attached to a release.
+func (app *App) HandleCreateEnvGroup(w http.ResponseWriter, r *http.Request) {
+	cluster, ok := app.readEnvGroupCluster(w, r)
+
+	if !ok {
+		return
+	}
+
+	form := &forms.CreateEnvGroupForm{
+		ProjectID: cluster.ProjectID,
+		ClusterID: cluster.ID,
+	}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrK8sValidate, w)
+		return
+	}
+
+	group, version, err := form.ToEnvGroup()
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrK8sValidate,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	groups, err := app.Repo.EnvGroup.ListEnvGroupsByClusterID(cluster.ID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	for _, existing := range groups {
+		if existing.Name == group.Name {
+			app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+				Code:   ErrK8sValidate,
+				Errors: []string{fmt.Sprintf("env group %s already exists", group.Name)},
+			}, w)
+
+			return
+		}
+	}
+
+	group, err = app.Repo.EnvGroup.CreateEnvGroup(group)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	version.EnvGroupID = group.ID
+
+	version, err = app.Repo.EnvGroup.CreateEnvGroupVersion(version)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	app.Logger.Info().Msgf("New env group created: %d", group.ID)
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(group.Externalize(version)); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// HandleListEnvGroups returns the shared env groups of a cluster, with the
+// variables of their latest versions
+func (app *App) HandleListEnvGroups(w http.ResponseWriter, r *http.Request) {
+	cluster, ok := app.readEnvGroupCluster(w, r)
+
+	if !ok {
+		return
+	}
+
+	groups, err := app.Repo.EnvGroup.ListEnvGroupsByClusterID(cluster.ID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	extGroups := make([]*models.EnvGroupExternal, 0)
+
+	for _, group := range groups {
+		version, err := app.Repo.EnvGroup.ReadEnvGroupVersion(group.ID, group.Version)
+
+		if err != nil {
+			app.handleErrorDataRead(err, w)
+			return
+		}
+
+		extGroups = append(extGroups, group.Externalize(version))
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(extGroups); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// HandleReadEnvGroup returns a shared env group with the variables of its
+// latest version
+func (app *App) HandleReadEnvGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := app.readClusterEnvGroup(w, r)
+
+	if !ok {
+		return
+	}
+
+	version, err := app.Repo.EnvGroup.ReadEnvGroupVersion(group.ID, group.Version)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(group.Externalize(version)); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// HandleListEnvGroupVersions returns every version of a shared env group,
+// newest first
+func (app *App) HandleListEnvGroupVersions(w http.ResponseWriter, r *http.Request) {
+	group, ok := app.readClusterEnvGroup(w, r)
+
+	if !ok {
+		return
+	}
+
+	versions, err := app.Repo.EnvGroup.ListEnvGroupVersions(group.ID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	extVersions := make([]*models.EnvGroupVersionExternal, 0)
+
+	for _, version := range versions {
+		extVersions = append(extVersions, version.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(extVersions); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// HandleUpdateEnvGroup creates a new version of a shared env group, writes it
+// to every namespace that the group is attached in, and optionally redeploys
+// every release that the group is attached to
+func (app *App) HandleUpdateEnvGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := app.readClusterEnvGroup(w, r)
+
+	if !ok {
+		return
+	}
+
+	form := &forms.UpdateEnvGroupForm{}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrK8sValidate, w)
+		return
+	}
+
+	prev, err := app.Repo.EnvGroup.ReadEnvGroupVersion(group.ID, group.Version)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	version, err := form.ToEnvGroupVersion(group, prev)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrK8sValidate,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	version, err = app.Repo.EnvGroup.CreateEnvGroupVersion(version)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	group.Version = version.Version
+
+	group, err = app.Repo.EnvGroup.UpdateEnvGroup(group)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	requestlog.RecordChange(r, envGroupAuditState(prev), envGroupAuditState(version))
+
+	resp := &EnvGroupSyncResponse{
+		EnvGroupExternal: group.Externalize(version),
+		Namespaces:       make([]*EnvGroupNamespaceResult, 0),
+		Releases:         make([]*EnvGroupReleaseResult, 0),
+	}
+
+	cluster, err := app.Repo.Cluster.ReadCluster(group.ClusterID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	agents := make(map[string]*helm.Agent)
+	synced := make(map[string]bool)
+
+	for _, namespace := range group.Namespaces() {
+		res := &EnvGroupNamespaceResult{
+			Namespace: namespace,
+		}
+
+		agent, err := app.envGroupAgent(agents, cluster, namespace)
+
+		if err == nil {
+			err = app.syncEnvGroup(agent, group, version, namespace)
+		}
+
+		if err != nil {
+			res.Error = err.Error()
+		} else {
+			synced[namespace] = true
+		}
+
+		resp.Namespaces = append(resp.Namespaces, res)
+	}
+
+	if form.Redeploy {
+		for _, attachment := range group.Attachments {
+			res := &EnvGroupReleaseResult{
+				Name:      attachment.ReleaseName,
+				Namespace: attachment.Namespace,
+			}
+
+			// releases are not redeployed against a configmap that could not be
+			// written, since they would reference secret keys that don't exist
+			if !synced[attachment.Namespace] {
+				res.Error = "env group could not be written to the namespace"
+			} else {
+				agent, _ := app.envGroupAgent(agents, cluster, attachment.Namespace)
+				res.Revision, err = app.redeployEnvGroupRelease(r, agent, cluster, attachment.Namespace, attachment.ReleaseName)
+
+				if err != nil {
+					res.Error = err.Error()
+				}
+			}
+
+			resp.Releases = append(resp.Releases, res)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// envGroupAuditState returns the variables of an env group version for the
+// audit log. Secret values are redacted from the log by their key.
+func envGroupAuditState(version *models.EnvGroupVersion) map[string]interface{} {
+	variables, _ := version.GetVariables()
+	secrets, _ := version.GetSecretVariables()
+
+	return map[string]interface{}{
+		"variables": variables,
+		"secrets":   secrets,
+	}
+}
+
+// HandleDeleteEnvGroup deletes a shared env group along with its versions. An
+// env group can only be deleted once it is detached from every release.
+func (app *App) HandleDeleteEnvGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := app.readClusterEnvGroup(w, r)
+
+	if !ok {
+		return
+	}
+
+	if len(group.Attachments) > 0 {
+		app.sendExternalError(fmt.Errorf("env group is attached"), http.StatusBadRequest, HTTPError{
+			Code:   ErrK8sValidate,
+			Errors: []string{"env group must be detached from every release before it is deleted"},
+		}, w)
+
+		return
+	}
+
+	if err := app.Repo.EnvGroup.DeleteEnvGroup(group); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleAttachEnvGroup attaches a shared env group to a release, writing the
+// group to the release's namespace, and optionally redeploys the release
+func (app *App) HandleAttachEnvGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := app.readClusterEnvGroup(w, r)
+
+	if !ok {
+		return
+	}
+
+	form := &forms.EnvGroupAttachmentForm{}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrK8sValidate, w)
+		return
+	}
+
+	if !app.checkEnvGroupNamespace(w, r, group, form.Namespace) {
+		return
+	}
+
+	if group.Attachment(form.Namespace, form.ReleaseName) != nil {
+		app.sendExternalError(fmt.Errorf("env group is already attached"), http.StatusBadRequest, HTTPError{
+			Code:   ErrK8sValidate,
+			Errors: []string{fmt.Sprintf("env group is already attached to release %s", form.ReleaseName)},
+		}, w)
+
+		return
+	}
+
+	version, err := app.Repo.EnvGroup.ReadEnvGroupVersion(group.ID, group.Version)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	cluster, err := app.Repo.Cluster.ReadCluster(group.ClusterID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	agent, err := app.envGroupAgent(make(map[string]*helm.Agent), cluster, form.Namespace)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	if _, err := agent.GetRelease(form.ReleaseName, 0); err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return
+	}
+
+	// the group is written before it is attached, so that a release is never
+	// attached to a group that does not exist in its namespace
+	if err := app.syncEnvGroup(agent, group, version, form.Namespace); err != nil {
+		app.sendEnvGroupSyncError(err, w)
+		return
+	}
+
+	_, err = app.Repo.EnvGroup.CreateEnvGroupAttachment(&models.EnvGroupAttachment{
+		EnvGroupID:  group.ID,
+		ReleaseName: form.ReleaseName,
+		Namespace:   form.Namespace,
+	})
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	group, err = app.Repo.EnvGroup.ReadEnvGroup(group.ID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	resp := &EnvGroupSyncResponse{
+		EnvGroupExternal: group.Externalize(version),
+		Namespaces: []*EnvGroupNamespaceResult{
+			{Namespace: form.Namespace},
+		},
+		Releases: make([]*EnvGroupReleaseResult, 0),
+	}
+
+	if form.Redeploy {
+		res := &EnvGroupReleaseResult{
+			Name:      form.ReleaseName,
+			Namespace: form.Namespace,
+		}
+
+		res.Revision, err = app.redeployEnvGroupRelease(r, agent, cluster, form.Namespace, form.ReleaseName)
+
+		if err != nil {
+			res.Error = err.Error()
+		}
+
+		resp.Releases = append(resp.Releases, res)
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// HandleDetachEnvGroup detaches a shared env group from a release, and
+// optionally redeploys the release without the group's variables. The group's
+// configmap and secret are removed from the namespace once no release in the
+// namespace uses them.
+func (app *App) HandleDetachEnvGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := app.readClusterEnvGroup(w, r)
+
+	if !ok {
+		return
+	}
+
+	form := &forms.EnvGroupAttachmentForm{}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrK8sValidate, w)
+		return
+	}
+
+	if !app.checkEnvGroupNamespace(w, r, group, form.Namespace) {
+		return
+	}
+
+	attachment := group.Attachment(form.Namespace, form.ReleaseName)
+
+	if attachment == nil {
+		app.sendExternalError(fmt.Errorf("env group is not attached"), http.StatusBadRequest, HTTPError{
+			Code:   ErrK8sValidate,
+			Errors: []string{fmt.Sprintf("env group is not attached to release %s", form.ReleaseName)},
+		}, w)
+
+		return
+	}
+
+	if err := app.Repo.EnvGroup.DeleteEnvGroupAttachment(attachment); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	group, err := app.Repo.EnvGroup.ReadEnvGroup(group.ID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	version, err := app.Repo.EnvGroup.ReadEnvGroupVersion(group.ID, group.Version)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	resp := &EnvGroupSyncResponse{
+		EnvGroupExternal: group.Externalize(version),
+		Namespaces:       make([]*EnvGroupNamespaceResult, 0),
+		Releases:         make([]*EnvGroupReleaseResult, 0),
+	}
+
+	if !form.Redeploy {
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		}
+
+		return
+	}
+
+	cluster, err := app.Repo.Cluster.ReadCluster(group.ClusterID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	agent, err := app.envGroupAgent(make(map[string]*helm.Agent), cluster, form.Namespace)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	res := &EnvGroupReleaseResult{
+		Name:      form.ReleaseName,
+		Namespace: form.Namespace,
+	}
+
+	res.Revision, err = app.redeployEnvGroupRelease(r, agent, cluster, form.Namespace, form.ReleaseName)
+
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	resp.Releases = append(resp.Releases, res)
+
+	// the configmap and secret are only removed once the release no longer
+	// references them
+	if err == nil && !containsString(group.Namespaces(), form.Namespace) && agent.K8sAgent != nil {
+		nsRes := &EnvGroupNamespaceResult{
+			Namespace: form.Namespace,
+		}
+
+		if err := agent.K8sAgent.DeleteEnvGroup(group.Name, form.Namespace); err != nil {
+			nsRes.Error = err.Error()
+		}
+
+		resp.Namespaces = append(resp.Namespaces, nsRes)
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// releaseEnvGroups returns the latest versions of the env groups attached to a
+// release, in the order they were attached in
+func (app *App) releaseEnvGroups(clusterID uint, namespace, name string) ([]*helm.EnvGroupValues, error) {
+	groups, err := app.Repo.EnvGroup.ListEnvGroupsByRelease(clusterID, namespace, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*helm.EnvGroupValues, 0)
+
+	for _, group := range groups {
+		version, err := app.Repo.EnvGroup.ReadEnvGroupVersion(group.ID, group.Version)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ext := version.Externalize()
+
+		res = append(res, &helm.EnvGroupValues{
+			Name:       group.Name,
+			Version:    version.Version,
+			Variables:  ext.Variables,
+			SecretKeys: ext.SecretKeys,
+		})
+	}
+
+	return res, nil
+}
+
+// syncEnvGroup writes a version of an env group to a namespace
+func (app *App) syncEnvGroup(
+	agent *helm.Agent,
+	group *models.EnvGroup,
+	version *models.EnvGroupVersion,
+	namespace string,
+) error {
+	if agent.K8sAgent == nil {
+		return fmt.Errorf("could not connect to cluster")
+	}
+
+	variables, err := version.GetVariables()
+
+	if err != nil {
+		return err
+	}
+
+	secrets, err := version.GetSecretVariables()
+
+	if err != nil {
+		return err
+	}
+
+	secretData := make(map[string][]byte)
+
+	for key, val := range secrets {
+		secretData[key] = []byte(val)
+	}
+
+	return agent.K8sAgent.SyncEnvGroup(group.Name, namespace, version.Version, variables, secretData)
+}
+
+// redeployEnvGroupRelease upgrades a release with its current values and the
+// latest versions of its env groups, and returns the new revision
+func (app *App) redeployEnvGroupRelease(
+	r *http.Request,
+	agent *helm.Agent,
+	cluster *models.Cluster,
+	namespace, name string,
+) (int, error) {
+	rel, err := agent.GetRelease(name, 0)
+
+	if err != nil {
+		return 0, err
+	}
+
+	registries, err := app.Repo.Registry.ListRegistriesByProjectID(cluster.ProjectID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	envGroups, err := app.releaseEnvGroups(cluster.ID, namespace, name)
+
+	if err != nil {
+		return 0, err
+	}
+
+	conf := &helm.UpgradeReleaseConfig{
+		Name:          name,
+		Cluster:       cluster,
+		Repo:          *app.Repo,
+		Registries:    registries,
+		Values:        rel.Config,
+		EnvGroups:     envGroups,
+		SecretBackend: app.SecretBackend,
+	}
+
+	deployment := app.newDeployment(r, cluster, namespace, name, models.DeploymentActionUpgrade)
+
+	override, err := app.getDeployOverride(r, cluster.ProjectID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if err := app.enforceDeployPolicies(deployment, override); err != nil {
+		return 0, err
+	}
+
+	newRel, err := agent.UpgradeReleaseByValues(conf, app.DOConf)
+
+	app.recordDeployment(deployment, newRel, err)
+
+	if err != nil {
+		return 0, err
+	}
+
+	app.watchRollout(agent, deployment, newRel)
+
+	return newRel.Version, nil
+}
+
+// envGroupAgent returns a helm agent for a namespace of the env group's
+// cluster, reusing the agents that were already created
+func (app *App) envGroupAgent(
+	agents map[string]*helm.Agent,
+	cluster *models.Cluster,
+	namespace string,
+) (*helm.Agent, error) {
+	if agent, ok := agents[namespace]; ok {
+		return agent, nil
+	}
+
+	agent, err := app.getClusterAgent(cluster, namespace)
+
+	if err != nil {
+		return nil, err
+	}
+
+	agents[namespace] = agent
+
+	return agent, nil
+}
+
+// sendEnvGroupSyncError writes the error of an env group that could not be
+// written to a namespace. Conflicts with existing configmaps are sent back to
+// the client.
+func (app *App) sendEnvGroupSyncError(err error, w http.ResponseWriter) {
+	if conflictErr, ok := err.(*kubernetes.EnvGroupConflictError); ok {
+		app.sendExternalError(err, http.StatusConflict, HTTPError{
+			Code:   ErrK8sValidate,
+			Errors: []string{conflictErr.Error()},
+		}, w)
+
+		return
+	}
+
+	app.handleErrorInternal(err, w)
+}
+
+// readEnvGroupCluster reads the cluster in the URL, and writes an error if it
+// cannot be read or does not belong to the project in the URL
+func (app *App) readEnvGroupCluster(w http.ResponseWriter, r *http.Request) (*models.Cluster, bool) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	clusterID, err := strconv.ParseUint(chi.URLParam(r, "cluster_id"), 0, 64)
+
+	if err != nil || clusterID == 0 {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return nil, false
+	}
+
+	cluster, err := app.Repo.Cluster.ReadCluster(uint(clusterID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return nil, false
+	}
+
+	if cluster.ProjectID != uint(projID) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+
+	return cluster, true
+}
+
+// readClusterEnvGroup reads the env group in the URL, and writes an error if
+// it cannot be read or does not belong to the cluster in the URL
+func (app *App) readClusterEnvGroup(w http.ResponseWriter, r *http.Request) (*models.EnvGroup, bool) {
+	cluster, ok := app.readEnvGroupCluster(w, r)
+
+	if !ok {
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(chi.URLParam(r, "env_group_id"), 0, 64)
+
+	if err != nil || id == 0 {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return nil, false
+	}
+
+	group, err := app.Repo.EnvGroup.ReadEnvGroup(uint(id))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return nil, false
+	}
+
+	if group.ClusterID != cluster.ID {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+
+	return group, true
+}
+
+// checkEnvGroupNamespace writes an error if the role bindings of the request
+// don't allow access to the namespace of an attachment. The namespace is read
+// from the body, so the access middleware may have checked another namespace.
+func (app *App) checkEnvGroupNamespace(
+	w http.ResponseWriter,
+	r *http.Request,
+	group *models.EnvGroup,
+	namespace string,
+) bool {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return false
+	}
+
+	bindings, err := app.getRoleBindingsFromRequest(r, uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return false
+	}
+
+	if !bindings.Allows(group.ClusterID, namespace) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
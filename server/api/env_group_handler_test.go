@@ -0,0 +1,58 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestEnvGroupAttachmentNamespace(t *testing.T) {
+	cases := []struct {
+		msg       string
+		endpoint  string
+		namespace string
+		expStatus int
+	}{
+		// the group is not attached to the release, so an allowed namespace
+		// gets as far as the attachment check
+		{"Detach in allowed namespace", "detach", "default", http.StatusBadRequest},
+		{"Detach in other namespace", "detach", "other", http.StatusForbidden},
+		{"Attach in other namespace", "attach", "other", http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		tester := newTester(true)
+		initRoleMember(tester, models.RoleDeveloper)
+
+		tester.repo.Cluster.CreateCluster(&models.Cluster{
+			ProjectID: 1,
+			Name:      "cluster-test",
+			Server:    "https://10.10.10.10",
+		})
+
+		tester.repo.EnvGroup.CreateEnvGroup(&models.EnvGroup{
+			ProjectID: 1,
+			ClusterID: 1,
+			Name:      "shared",
+		})
+
+		// the middleware checks the namespace in the query, which the
+		// member's binding allows
+		tester.repo.RoleBinding.CreateRoleBinding(&models.RoleBinding{
+			ProjectID: 1,
+			UserID:    1,
+			ClusterID: 1,
+			Namespace: "default",
+		})
+
+		endpoint := fmt.Sprintf("/api/projects/1/clusters/1/env_groups/1/%s?namespace=default", c.endpoint)
+		body := fmt.Sprintf(`{"release_name":"web","namespace":"%s"}`, c.namespace)
+
+		if rr := tester.send("POST", endpoint, body); rr.Code != c.expStatus {
+			t.Errorf("%s, handler returned wrong status code: got %v want %v",
+				c.msg, rr.Code, c.expStatus)
+		}
+	}
+}
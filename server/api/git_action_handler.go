@@ -82,14 +82,41 @@ func (app *App) createGitActionFromForm(
 		return nil
 	}
 
+	session, err := app.Store.Get(r, app.ServerConf.CookieName)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	userID, _ := session.Values["user_id"].(uint)
+
+	ga, err := app.setupGitAction(projID, userID, release, name, form)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return nil
+	}
+
+	return ga.Externalize()
+}
+
+// setupGitAction commits a github action that builds and deploys the release to
+// its repository, and stores the action's config
+func (app *App) setupGitAction(
+	projID uint64,
+	userID uint,
+	release *models.Release,
+	name string,
+	form *forms.CreateGitAction,
+) (*models.GitActionConfig, error) {
 	// if the registry was provisioned through Porter, create a repository if necessary
 	if form.RegistryID != 0 {
 		// read the registry
 		reg, err := app.Repo.Registry.ReadRegistry(form.RegistryID)
 
 		if err != nil {
-			app.handleErrorDataRead(err, w)
-			return nil
+			return nil, err
 		}
 
 		_reg := registry.Registry(*reg)
@@ -102,8 +129,7 @@ func (app *App) createGitActionFromForm(
 		err = regAPI.CreateRepository(*app.Repo, repoName)
 
 		if err != nil {
-			app.handleErrorInternal(err, w)
-			return nil
+			return nil, err
 		}
 	}
 
@@ -111,44 +137,52 @@ func (app *App) createGitActionFromForm(
 	gitAction, err := form.ToGitActionConfig()
 
 	if err != nil {
-		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
-		return nil
+		return nil, err
 	}
 
 	// read the git repo
 	gr, err := app.Repo.GitRepo.ReadGitRepo(gitAction.GitRepoID)
 
 	if err != nil {
-		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
-		return nil
+		return nil, err
 	}
 
 	repoSplit := strings.Split(gitAction.GitRepo, "/")
 
 	if len(repoSplit) != 2 {
-		app.handleErrorFormDecoding(fmt.Errorf("invalid formatting of repo name"), ErrProjectDecode, w)
-		return nil
+		return nil, fmt.Errorf("invalid formatting of repo name")
 	}
 
-	session, err := app.Store.Get(r, app.ServerConf.CookieName)
+	// store an API token for the action, so that it can be revoked
+	apiToken, err := (&forms.CreateAPITokenForm{
+		ProjectID: uint(projID),
+		UserID:    userID,
+		Name:      fmt.Sprintf("github-actions-%s-%s", gitAction.GitRepo, name),
+	}).ToAPIToken()
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return nil
+		return nil, err
 	}
 
-	userID, _ := session.Values["user_id"].(uint)
+	apiToken, err = app.Repo.APIToken.CreateAPIToken(apiToken)
+
+	if err != nil {
+		return nil, err
+	}
 
 	// generate porter jwt token
-	jwt, _ := token.GetTokenForAPI(userID, uint(projID))
+	jwt, err := token.GetStoredTokenForAPI(userID, uint(projID), apiToken.UniqueID)
+
+	if err != nil {
+		return nil, err
+	}
 
 	encoded, err := jwt.EncodeToken(&token.TokenGeneratorConf{
 		TokenSecret: app.ServerConf.TokenGeneratorSecret,
 	})
 
 	if err != nil {
-		app.handleErrorInternal(err, w)
-		return nil
+		return nil, err
 	}
 
 	// create the commit in the git repo
@@ -165,6 +199,7 @@ func (app *App) createGitActionFromForm(
 		DockerFilePath: gitAction.DockerfilePath,
 		FolderPath:     gitAction.FolderPath,
 		ImageRepoURL:   gitAction.ImageRepoURI,
+		Previews:       gitAction.PreviewsEnabled,
 		PorterToken:    encoded,
 		BuildEnv:       form.BuildEnv,
 	}
@@ -172,19 +207,17 @@ func (app *App) createGitActionFromForm(
 	_, err = gaRunner.Setup()
 
 	if err != nil {
-		app.handleErrorInternal(err, w)
-		return nil
+		return nil, err
 	}
 
 	// handle write to the database
 	ga, err := app.Repo.GitActionConfig.CreateGitActionConfig(gitAction)
 
 	if err != nil {
-		app.handleErrorDataWrite(err, w)
-		return nil
+		return nil, err
 	}
 
 	app.Logger.Info().Msgf("New git action created: %d", ga.ID)
 
-	return ga.Externalize()
+	return ga, nil
 }
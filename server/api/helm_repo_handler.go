@@ -97,25 +97,52 @@ func (app *App) HandleListProjectHelmRepos(w http.ResponseWriter, r *http.Reques
 
 // HandleListHelmRepoCharts lists the charts for a given linked helm repo
 func (app *App) HandleListHelmRepoCharts(w http.ResponseWriter, r *http.Request) {
-	helmID, err := strconv.ParseUint(chi.URLParam(r, "helm_id"), 0, 64)
+	hr, ok := app.readProjectHelmRepo(w, r)
 
-	if err != nil || helmID == 0 {
-		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+	if !ok {
 		return
 	}
 
-	hr, err := app.Repo.HelmRepo.ReadHelmRepo(uint(helmID))
+	// cast to a registry from registry package
+	_hr := repo.HelmRepo(*hr)
+	hrAPI := &_hr
+
+	charts, err := hrAPI.ListCharts(*app.Repo, app.DOConf)
 
 	if err != nil {
 		app.handleErrorRead(err, ErrProjectDataRead, w)
 		return
 	}
 
-	// cast to a registry from registry package
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(charts); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleReadHelmRepoChart reads a chart of a linked helm repo with its form,
+// so that it can be launched as a template
+func (app *App) HandleReadHelmRepoChart(w http.ResponseWriter, r *http.Request) {
+	hr, ok := app.readProjectHelmRepo(w, r)
+
+	if !ok {
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	version := chi.URLParam(r, "version")
+
+	// if version passed as latest, pass empty string to loader to get latest
+	if version == "latest" {
+		version = ""
+	}
+
 	_hr := repo.HelmRepo(*hr)
 	hrAPI := &_hr
 
-	charts, err := hrAPI.ListCharts(*app.Repo)
+	chart, err := hrAPI.GetChart(*app.Repo, app.DOConf, name, version)
 
 	if err != nil {
 		app.handleErrorRead(err, ErrProjectDataRead, w)
@@ -124,8 +151,91 @@ func (app *App) HandleListHelmRepoCharts(w http.ResponseWriter, r *http.Request)
 
 	w.WriteHeader(http.StatusOK)
 
-	if err := json.NewEncoder(w).Encode(charts); err != nil {
+	if err := json.NewEncoder(w).Encode(porterChartRead(chart)); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleListProjectTemplates lists the charts of every linked helm repo of a
+// project as template catalogs. A helm repo that can't be read is returned
+// with an error, so that the other catalogs can still be launched from.
+func (app *App) HandleListProjectTemplates(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	hrs, err := app.Repo.HelmRepo.ListHelmReposByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	catalogs := make([]*models.PorterTemplateCatalog, 0)
+
+	for _, hr := range hrs {
+		catalog := &models.PorterTemplateCatalog{
+			HelmRepoID: hr.ID,
+			Name:       hr.Name,
+			RepoURL:    hr.RepoURL,
+			Templates:  make([]*models.PorterChartList, 0),
+		}
+
+		_hr := repo.HelmRepo(*hr)
+		hrAPI := &_hr
+
+		if charts, err := hrAPI.ListCharts(*app.Repo, app.DOConf); err != nil {
+			catalog.Error = err.Error()
+		} else {
+			catalog.Templates = charts
+		}
+
+		catalogs = append(catalogs, catalog)
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(catalogs); err != nil {
 		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
 		return
 	}
 }
+
+// readProjectHelmRepo reads the helm repo of the request, and writes an error
+// if the helm repo doesn't belong to the project of the request
+func (app *App) readProjectHelmRepo(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*models.HelmRepo, bool) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	helmID, err := strconv.ParseUint(chi.URLParam(r, "helm_id"), 0, 64)
+
+	if err != nil || helmID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	hr, err := app.Repo.HelmRepo.ReadHelmRepo(uint(helmID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return nil, false
+	}
+
+	if hr.ProjectID != uint(projID) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+
+	return hr, true
+}
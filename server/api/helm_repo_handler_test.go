@@ -2,12 +2,20 @@ package api_test
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/go-test/deep"
 	"github.com/porter-dev/porter/internal/models"
+	ints "github.com/porter-dev/porter/internal/models/integrations"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
 )
 
 // ------------------------- TEST TYPES AND MAIN LOOP ------------------------- //
@@ -115,8 +123,198 @@ func TestHandleListHelmRepos(t *testing.T) {
 	testHelmRepoRequests(t, listHelmReposTest, true)
 }
 
+func TestHandleListProjectTemplates(t *testing.T) {
+	server := newTestChartRepo(t)
+	defer server.Close()
+
+	tester := newTester(true)
+	initRoleMember(tester, models.RoleDeveloper)
+	initProjectHelmRepos(tester, server.URL)
+
+	rr := tester.send("GET", "/api/projects/1/templates", "")
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("List templates, handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	gotBody := make([]*models.PorterTemplateCatalog, 0)
+	json.Unmarshal(rr.Body.Bytes(), &gotBody)
+
+	// the helm repo of the other project is not listed, and the helm repo
+	// that can't be read is listed with its error
+	if len(gotBody) != 2 {
+		t.Fatalf("List templates, expected 2 catalogs, got %s", rr.Body.String())
+	}
+
+	if gotBody[0].HelmRepoID != 1 || len(gotBody[0].Templates) != 1 || gotBody[0].Templates[0].Name != "web" {
+		t.Errorf("List templates, incorrect catalog: %s", rr.Body.String())
+	}
+
+	if gotBody[1].HelmRepoID != 2 || gotBody[1].Error == "" || len(gotBody[1].Templates) != 0 {
+		t.Errorf("List templates, expected an error for the unreadable helm repo: %s", rr.Body.String())
+	}
+
+	if server.requests("/project-2/index.yaml") != 0 {
+		t.Errorf("List templates, the helm repo of the other project was read")
+	}
+}
+
+func TestHandleReadHelmRepoChart(t *testing.T) {
+	server := newTestChartRepo(t)
+	defer server.Close()
+
+	tester := newTester(true)
+	initRoleMember(tester, models.RoleDeveloper)
+	initProjectHelmRepos(tester, server.URL)
+
+	rr := tester.send("GET", "/api/projects/1/helmrepos/1/charts/web/latest", "")
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Read chart, handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	gotBody := &models.PorterChartRead{}
+	json.Unmarshal(rr.Body.Bytes(), gotBody)
+
+	if gotBody.Metadata == nil || gotBody.Metadata.Name != "web" || gotBody.Metadata.Version != "0.1.0" {
+		t.Errorf("Read chart, incorrect chart: %s", rr.Body.String())
+	}
+
+	// helm repo 3 belongs to another project
+	rr = tester.send("GET", "/api/projects/1/helmrepos/3/charts/web/latest", "")
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Read chart of other project, handler returned wrong status code: got %v want %v",
+			rr.Code, http.StatusForbidden)
+	}
+
+	if server.requests("/project-2/index.yaml") != 0 {
+		t.Errorf("Read chart of other project, the helm repo was read")
+	}
+}
+
 // ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
 
+// testChartRepo is a helm repo that serves the web chart under any path, and
+// counts the requests to each path
+type testChartRepo struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newTestChartRepo(t *testing.T) *testChartRepo {
+	archive := testChartArchive(t)
+
+	index := []byte(`apiVersion: v1
+entries:
+  web:
+  - apiVersion: v2
+    name: web
+    version: 0.1.0
+    urls:
+    - web-0.1.0.tgz
+`)
+
+	res := &testChartRepo{counts: make(map[string]int)}
+
+	res.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res.mu.Lock()
+		res.counts[r.URL.Path]++
+		res.mu.Unlock()
+
+		if _, _, ok := r.BasicAuth(); !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch filepath.Base(r.URL.Path) {
+		case "index.yaml":
+			w.Write(index)
+		case "web-0.1.0.tgz":
+			w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return res
+}
+
+func (r *testChartRepo) requests(path string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.counts[path]
+}
+
+func testChartArchive(t *testing.T) []byte {
+	dir, err := ioutil.TempDir("", "porter-chart")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path, err := chartutil.Save(&chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "web",
+			Version:    "0.1.0",
+		},
+	}, dir)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	return data
+}
+
+// initProjectHelmRepos links a readable helm repo and a helm repo without
+// credentials to project 1, and a helm repo to project 2
+func initProjectHelmRepos(tester *tester, repoURL string) {
+	tester.repo.BasicIntegration.CreateBasicIntegration(&ints.BasicIntegration{
+		ProjectID: 1,
+		Username:  []byte("user-1"),
+		Password:  []byte("password"),
+	})
+
+	tester.repo.BasicIntegration.CreateBasicIntegration(&ints.BasicIntegration{
+		ProjectID: 2,
+		Username:  []byte("user-2"),
+		Password:  []byte("password"),
+	})
+
+	tester.repo.HelmRepo.CreateHelmRepo(&models.HelmRepo{
+		Name:                   "charts",
+		ProjectID:              1,
+		RepoURL:                repoURL + "/project-1",
+		BasicAuthIntegrationID: 1,
+	})
+
+	tester.repo.HelmRepo.CreateHelmRepo(&models.HelmRepo{
+		Name:      "unreadable",
+		ProjectID: 1,
+		RepoURL:   repoURL + "/unreadable",
+	})
+
+	tester.repo.HelmRepo.CreateHelmRepo(&models.HelmRepo{
+		Name:                   "other-charts",
+		ProjectID:              2,
+		RepoURL:                repoURL + "/project-2",
+		BasicAuthIntegrationID: 2,
+	})
+}
+
 func initHelmRepo(tester *tester) {
 	proj, _ := tester.repo.Project.ReadProject(1)
 
@@ -11,12 +11,14 @@ import (
 	"github.com/porter-dev/porter/internal/helm"
 	"github.com/porter-dev/porter/internal/kubernetes"
 	lr "github.com/porter-dev/porter/internal/logger"
+	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/repository"
 	memory "github.com/porter-dev/porter/internal/repository/memory"
 	"github.com/porter-dev/porter/server/api"
 	"github.com/porter-dev/porter/server/router"
 
 	"github.com/porter-dev/porter/internal/auth/sessionstore"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type tester struct {
@@ -55,6 +57,56 @@ func (t *tester) createUserSession(email string, pw string) {
 	t.reset()
 }
 
+// createUser stores a user with a hashed password directly in the repository,
+// without creating a session
+func (t *tester) createUser(email string, pw string) *models.User {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte(pw), 8)
+
+	user, _ := t.repo.User.CreateUser(&models.User{
+		Email:    email,
+		Password: string(hashed),
+	})
+
+	return user
+}
+
+// send executes a request with the tester's cookie, if any, and keeps the
+// cookie that the response sets
+func (t *tester) send(method, endpoint, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, endpoint, strings.NewReader(body))
+
+	if t.cookie != nil {
+		req.AddCookie(t.cookie)
+	}
+
+	t.req = req
+	t.execute()
+
+	rr := t.rr
+
+	if cookies := rr.Result().Cookies(); len(cookies) > 0 {
+		t.cookie = cookies[0]
+	}
+
+	t.reset()
+
+	return rr
+}
+
+// sendWithToken executes a request with a bearer token and without a cookie
+func (t *tester) sendWithToken(method, endpoint, body, tok string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, endpoint, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+tok)
+
+	t.req = req
+	t.execute()
+
+	rr := t.rr
+	t.reset()
+
+	return rr
+}
+
 func newTester(canQuery bool) *tester {
 	appConf := config.Conf{
 		Debug: true,
@@ -123,7 +123,7 @@ var listHelmRepoIntegrationsTest = []*publicIntTest{
 		endpoint:  "/api/integrations/helm",
 		body:      ``,
 		expStatus: http.StatusOK,
-		expBody:   `[{"auth_mechanism":"basic","category":"helm","service":"helm"},{"auth_mechanism":"gcp","category":"helm","service":"gcs"},{"auth_mechanism":"aws","category":"helm","service":"s3"}]`,
+		expBody:   `[{"auth_mechanism":"basic","category":"helm","service":"helm"},{"auth_mechanism":"gcp","category":"helm","service":"gcs"},{"auth_mechanism":"aws","category":"helm","service":"s3"},{"auth_mechanism":"registry","category":"helm","service":"oci"}]`,
 		useCookie: true,
 		validators: []func(c *publicIntTest, tester *tester, t *testing.T){
 			publicIntBodyValidator,
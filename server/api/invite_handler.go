@@ -6,11 +6,13 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/porter-dev/porter/internal/forms"
 	"github.com/porter-dev/porter/internal/integrations/email"
 	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/oauth"
 )
 
 // HandleCreateInvite creates a new invite for a project
@@ -22,8 +24,16 @@ func (app *App) HandleCreateInvite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, err := app.getUserIDFromRequest(r)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
 	form := &forms.CreateInvite{
 		ProjectID: uint(projID),
+		InviterID: userID,
 	}
 
 	// decode from JSON to form value
@@ -41,8 +51,16 @@ func (app *App) HandleCreateInvite(w http.ResponseWriter, r *http.Request) {
 	// convert the form to an invite
 	invite, err := form.ToInvite()
 
+	if err == nil {
+		err = app.validateInviteCluster(invite)
+	}
+
 	if err != nil {
-		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
 		return
 	}
 
@@ -65,20 +83,168 @@ func (app *App) HandleCreateInvite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// send invite email
-	project, err := app.Repo.Project.ReadProject(uint(projID))
+	app.sendInviteEmail(invite, userID)
+}
+
+// HandleCreateBulkInvite creates an invite for each of a list of emails, all
+// of which are granted the same role
+func (app *App) HandleCreateBulkInvite(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	userID, err := app.getUserIDFromRequest(r)
 
 	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	form := &forms.CreateBulkInvite{
+		ProjectID: uint(projID),
+		InviterID: userID,
+	}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	invites, err := form.ToInvites()
+
+	if err == nil && len(invites) > 0 {
+		err = app.validateInviteCluster(invites[0])
+	}
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	extInvites := make([]*models.InviteExternal, 0)
+
+	for i, invite := range invites {
+		invite, err = app.Repo.Invite.CreateInvite(invite)
+
+		if err != nil {
+			app.handleErrorDataWrite(err, w)
+			return
+		}
+
+		invites[i] = invite
+		extInvites = append(extInvites, invite.Externalize())
+	}
+
+	app.Logger.Info().Msgf("%d new invites created for project %d", len(invites), projID)
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(extInvites); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	for _, invite := range invites {
+		app.sendInviteEmail(invite, userID)
+	}
+}
+
+// HandleResendInvite generates a new token and expiry for an invite that has
+// not been accepted, and sends the invite email again
+func (app *App) HandleResendInvite(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "invite_id"), 0, 64)
+
+	if err != nil || id == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
 		return
 	}
 
 	userID, err := app.getUserIDFromRequest(r)
 
 	if err != nil {
+		app.handleErrorInternal(err, w)
 		return
 	}
 
-	user, err := app.Repo.User.ReadUser(userID)
+	invite, err := app.Repo.Invite.ReadInvite(uint(id))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	if invite.IsAccepted() {
+		app.sendExternalError(fmt.Errorf("invite %d already accepted", invite.ID), http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{"invite has already been accepted"},
+		}, w)
+
+		return
+	}
+
+	// previously sent links stop working once the invite is resent
+	expiry := time.Now().Add(models.InviteExpiry)
+
+	invite.Token = oauth.CreateRandomState()
+	invite.Expiry = &expiry
+
+	invite, err = app.Repo.Invite.UpdateInvite(invite)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(invite.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	app.sendInviteEmail(invite, userID)
+}
+
+// validateInviteCluster returns an error if the invite is scoped to a cluster
+// that does not belong to its project
+func (app *App) validateInviteCluster(invite *models.Invite) error {
+	if invite.ClusterID == 0 {
+		return nil
+	}
+
+	cluster, err := app.Repo.Cluster.ReadCluster(invite.ClusterID)
+
+	if err != nil || cluster.ProjectID != invite.ProjectID {
+		return fmt.Errorf("cluster does not belong to the project")
+	}
+
+	return nil
+}
+
+// sendInviteEmail sends the invite link to the invited email. Failures are
+// logged, since the link can also be shared from the dashboard.
+func (app *App) sendInviteEmail(invite *models.Invite, inviterID uint) {
+	project, err := app.Repo.Project.ReadProject(invite.ProjectID)
+
+	if err != nil {
+		return
+	}
+
+	user, err := app.Repo.User.ReadUser(inviterID)
 
 	if err != nil {
 		return
@@ -90,12 +256,16 @@ func (app *App) HandleCreateInvite(w http.ResponseWriter, r *http.Request) {
 		SenderEmail:             app.ServerConf.SendgridSenderEmail,
 	}
 
-	sgClient.SendProjectInviteEmail(
-		fmt.Sprintf("%s/api/projects/%d/invites/%s", app.ServerConf.ServerURL, projID, invite.Token),
+	err = sgClient.SendProjectInviteEmail(
+		fmt.Sprintf("%s/api/projects/%d/invites/%s", app.ServerConf.ServerURL, invite.ProjectID, invite.Token),
 		project.Name,
 		user.Email,
-		form.Email,
+		invite.Email,
 	)
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msgf("could not send email for invite %d", invite.ID)
+	}
 }
 
 // HandleAcceptInvite accepts an invite to a new project: if successful, a new role
@@ -167,14 +337,30 @@ func (app *App) HandleAcceptInvite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// create a new Role with the user as the admin
+	// scope the user to the cluster and namespace of the invite. The binding is
+	// created before the role, so that the user is never a member of the
+	// project without the scope of the invite.
+	rb := invite.RoleBinding(userID)
+
+	if rb != nil {
+		if rb, err = app.Repo.RoleBinding.CreateRoleBinding(rb); err != nil {
+			acceptInviteError(w, r)
+			return
+		}
+	}
+
+	// create a new Role with the role granted by the invite
 	_, err = app.Repo.Project.CreateProjectRole(projModel, &models.Role{
 		UserID:    userID,
 		ProjectID: uint(projID),
-		Kind:      models.RoleAdmin,
+		Kind:      invite.RoleKind(),
 	})
 
 	if err != nil {
+		if rb != nil {
+			app.Repo.RoleBinding.DeleteRoleBinding(rb)
+		}
+
 		acceptInviteError(w, r)
 		return
 	}
@@ -200,7 +386,8 @@ func acceptInviteError(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
-// HandleListProjectInvites returns a list of invites for a project
+// HandleListProjectInvites returns a list of invites for a project. The
+// "status" query parameter filters to pending, expired or accepted invites.
 func (app *App) HandleListProjectInvites(w http.ResponseWriter, r *http.Request) {
 	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
 
@@ -209,6 +396,8 @@ func (app *App) HandleListProjectInvites(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	status := r.URL.Query().Get("status")
+
 	invites, err := app.Repo.Invite.ListInvitesByProjectID(uint(projID))
 
 	if err != nil {
@@ -219,6 +408,10 @@ func (app *App) HandleListProjectInvites(w http.ResponseWriter, r *http.Request)
 	extInvites := make([]*models.InviteExternal, 0)
 
 	for _, invite := range invites {
+		if status != "" && invite.Status() != status {
+			continue
+		}
+
 		extInvites = append(extInvites, invite.Externalize())
 	}
 
@@ -80,7 +80,7 @@ var createInviteTests = []*inviteTest{
 		endpoint:  "/api/projects/1/invites",
 		body:      `{"email":"test@test.it"}`,
 		expStatus: http.StatusCreated,
-		expBody:   `{"expired":false,"email":"test@test.it","accepted":false}`,
+		expBody:   `{"expired":false,"email":"test@test.it","accepted":false,"status":"pending","kind":"admin","inviter_id":1}`,
 		useCookie: true,
 		validators: []func(c *inviteTest, tester *tester, t *testing.T){
 			func(c *inviteTest, tester *tester, t *testing.T) {
@@ -89,7 +89,8 @@ var createInviteTests = []*inviteTest{
 
 				gotBody := &models.InviteExternal{}
 				expBody := &models.InviteExternal{
-					Token: invite.Token,
+					Token:  invite.Token,
+					Expiry: invite.Expiry,
 				}
 
 				json.Unmarshal(tester.rr.Body.Bytes(), &gotBody)
@@ -120,7 +121,7 @@ var listInvitesTest = []*inviteTest{
 		endpoint:  "/api/projects/1/invites",
 		body:      ``,
 		expStatus: http.StatusOK,
-		expBody:   `[{"expired":false,"email":"test@test.it","accepted":false}]`,
+		expBody:   `[{"expired":false,"email":"test@test.it","accepted":false,"status":"pending","kind":"admin"}]`,
 		useCookie: true,
 		validators: []func(c *inviteTest, tester *tester, t *testing.T){
 			func(c *inviteTest, tester *tester, t *testing.T) {
@@ -134,6 +135,7 @@ var listInvitesTest = []*inviteTest{
 				json.Unmarshal([]byte(c.expBody), &expBody)
 
 				expBody[0].Token = invite.Token
+				expBody[0].Expiry = invite.Expiry
 
 				if diff := deep.Equal(gotBody, expBody); diff != nil {
 					t.Errorf("handler returned wrong body:\n")
@@ -271,3 +273,142 @@ func initInviteExpiredToken(tester *tester) {
 
 	tester.repo.Invite.CreateInvite(invite)
 }
+
+func TestHandleAcceptScopedInvite(t *testing.T) {
+	tester := newTester(true)
+	initRoleMember(tester, models.RoleAdmin)
+
+	tester.repo.Cluster.CreateCluster(&models.Cluster{
+		ProjectID: 1,
+		Name:      "cluster-test",
+		Server:    "https://10.10.10.10",
+	})
+
+	invitee := tester.createUser("invitee@getporter.dev", "hello")
+	expiry := time.Now().Add(24 * time.Hour)
+
+	tester.repo.Invite.CreateInvite(&models.Invite{
+		Token:     "abcd",
+		Expiry:    &expiry,
+		Email:     "invitee@getporter.dev",
+		ProjectID: 1,
+		Kind:      models.RoleDeveloper,
+		ClusterID: 1,
+		Namespace: "default",
+	})
+
+	tester.cookie = nil
+	tester.send("POST", "/api/login", `{"email":"invitee@getporter.dev","password":"hello"}`)
+
+	rr := tester.send("GET", "/api/projects/1/invites/abcd", "")
+
+	if rr.Code != http.StatusFound || rr.Header().Get("Location") != "/dashboard" {
+		t.Fatalf("Accept scoped invite, got status %v and location %s", rr.Code, rr.Header().Get("Location"))
+	}
+
+	role, err := tester.repo.Project.ReadProjectRole(1, invitee.ID)
+
+	if err != nil || role.Kind != models.RoleDeveloper {
+		t.Errorf("Accept scoped invite, expected a developer role, got %v %v", role, err)
+	}
+
+	bindings, _ := tester.repo.RoleBinding.ListRoleBindingsByUserID(1, invitee.ID)
+
+	if len(bindings) != 1 || bindings[0].ClusterID != 1 || bindings[0].Namespace != "default" {
+		t.Errorf("Accept scoped invite, expected a binding to the invite's namespace, got %v", bindings)
+	}
+
+	invite, _ := tester.repo.Invite.ReadInviteByToken("abcd")
+
+	if !invite.IsAccepted() {
+		t.Errorf("Accept scoped invite, expected the invite to be accepted")
+	}
+}
+
+func TestHandleResendInvite(t *testing.T) {
+	tester := newTester(true)
+	initRoleMember(tester, models.RoleAdmin)
+
+	expiry := time.Now().Add(time.Hour)
+
+	tester.repo.Invite.CreateInvite(&models.Invite{
+		Token:     "abcd",
+		Expiry:    &expiry,
+		Email:     "invitee@getporter.dev",
+		ProjectID: 1,
+	})
+
+	rr := tester.send("POST", "/api/projects/1/invites/1/resend", "")
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Resend invite, handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	invite, _ := tester.repo.Invite.ReadInvite(1)
+
+	if invite.Token == "abcd" || !invite.Expiry.After(expiry) {
+		t.Errorf("Resend invite, expected a new token and expiry")
+	}
+
+	if _, err := tester.repo.Invite.ReadInviteByToken("abcd"); err == nil {
+		t.Errorf("Resend invite, expected the previous token to stop working")
+	}
+
+	// accepted invites can't be resent
+	invite.UserID = 1
+	tester.repo.Invite.UpdateInvite(invite)
+
+	if rr := tester.send("POST", "/api/projects/1/invites/1/resend", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Resend accepted invite, handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleCreateBulkInvite(t *testing.T) {
+	tester := newTester(true)
+	initRoleMember(tester, models.RoleAdmin)
+
+	// a cluster in another project
+	otherProj, _ := tester.repo.Project.CreateProject(&models.Project{
+		Name: "project-other",
+	})
+
+	tester.repo.Cluster.CreateCluster(&models.Cluster{
+		ProjectID: otherProj.ID,
+		Name:      "cluster-other",
+		Server:    "https://10.10.10.10",
+	})
+
+	body := `{"emails":["a@getporter.dev","A@getporter.dev","b@getporter.dev"],"kind":"viewer"}`
+	rr := tester.send("POST", "/api/projects/1/invites/bulk", body)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Bulk invite, handler returned wrong status code: got %v want %v", rr.Code, http.StatusCreated)
+	}
+
+	gotBody := []*models.InviteExternal{}
+	json.Unmarshal(rr.Body.Bytes(), &gotBody)
+
+	// duplicate emails only get one invite
+	if len(gotBody) != 2 {
+		t.Fatalf("Bulk invite, expected 2 invites, got %d", len(gotBody))
+	}
+
+	for _, invite := range gotBody {
+		if invite.Kind != models.RoleViewer {
+			t.Errorf("Bulk invite, expected role %s, got %s", models.RoleViewer, invite.Kind)
+		}
+	}
+
+	body = `{"emails":["c@getporter.dev"],"kind":"viewer","cluster_id":1}`
+
+	if rr := tester.send("POST", "/api/projects/1/invites/bulk", body); rr.Code != http.StatusBadRequest {
+		t.Errorf("Bulk invite to other project's cluster, handler returned wrong status code: got %v want %v",
+			rr.Code, http.StatusBadRequest)
+	}
+
+	invites, _ := tester.repo.Invite.ListInvitesByProjectID(1)
+
+	if len(invites) != 2 {
+		t.Errorf("Bulk invite, expected 2 stored invites, got %d", len(invites))
+	}
+}
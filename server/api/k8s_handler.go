@@ -69,6 +69,24 @@ func (app *App) HandleListNamespaces(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// only return the namespaces that the user's role bindings allow
+	bindings, err := app.getRoleBindingsFromRequest(r, form.Cluster.ProjectID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	allowed := namespaces.Items[:0]
+
+	for _, namespace := range namespaces.Items {
+		if bindings.Allows(form.Cluster.ID, namespace.Name) {
+			allowed = append(allowed, namespace)
+		}
+	}
+
+	namespaces.Items = allowed
+
 	if err := json.NewEncoder(w).Encode(namespaces); err != nil {
 		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
 		return
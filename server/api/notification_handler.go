@@ -0,0 +1,237 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/notifier"
+)
+
+// CreateNotificationChannelResponse is a notification channel along with the
+// secret that generic webhook payloads are signed with, which is only returned
+// when the channel is created
+type CreateNotificationChannelResponse struct {
+	*models.NotificationChannelExternal
+	SigningSecret string `json:"signing_secret,omitempty"`
+}
+
+// HandleCreateNotificationChannel creates a new notification channel for a
+// project
+func (app *App) HandleCreateNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	form := &forms.CreateNotificationChannelForm{
+		ProjectID: uint(projID),
+	}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	channel, err := form.ToNotificationChannel()
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	channel, err = app.Repo.NotificationChannel.CreateNotificationChannel(channel)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	app.Logger.Info().Msgf("New notification channel created: %d", channel.ID)
+
+	w.WriteHeader(http.StatusCreated)
+
+	resp := &CreateNotificationChannelResponse{
+		NotificationChannelExternal: channel.Externalize(),
+		SigningSecret:               string(channel.SigningSecret),
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleListNotificationChannels returns the notification channels of a project
+func (app *App) HandleListNotificationChannels(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	channels, err := app.Repo.NotificationChannel.ListNotificationChannelsByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	extChannels := make([]*models.NotificationChannelExternal, 0)
+
+	for _, channel := range channels {
+		extChannels = append(extChannels, channel.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(extChannels); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleUpdateNotificationChannel updates the name and the subscribed events
+// of a notification channel
+func (app *App) HandleUpdateNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	channel, ok := app.readProjectNotificationChannel(w, r)
+
+	if !ok {
+		return
+	}
+
+	form := &forms.UpdateNotificationChannelForm{}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	if err := form.UpdateNotificationChannel(channel); err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	channel, err := app.Repo.NotificationChannel.UpdateNotificationChannel(channel)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(channel.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleDeleteNotificationChannel deletes a notification channel
+func (app *App) HandleDeleteNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	channel, ok := app.readProjectNotificationChannel(w, r)
+
+	if !ok {
+		return
+	}
+
+	if err := app.Repo.NotificationChannel.DeleteNotificationChannel(channel); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleTestNotificationChannel sends a test notification to a channel, and
+// returns the delivery error so that misconfigured channels can be fixed
+func (app *App) HandleTestNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	channel, ok := app.readProjectNotificationChannel(w, r)
+
+	if !ok {
+		return
+	}
+
+	err := app.Notifier.Send(channel, &notifier.Event{
+		Kind:      "test",
+		ProjectID: channel.ProjectID,
+		Title:     "Test notification from Porter",
+		Message:   "Notifications for this project will be sent to " + channel.Name + ".",
+		URL:       app.ServerConf.ServerURL,
+	})
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// readProjectNotificationChannel reads the notification channel in the URL,
+// and writes an error if it cannot be read or does not belong to the project
+// in the URL
+func (app *App) readProjectNotificationChannel(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*models.NotificationChannel, bool) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(chi.URLParam(r, "channel_id"), 0, 64)
+
+	if err != nil || id == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	channel, err := app.Repo.NotificationChannel.ReadNotificationChannel(uint(id))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return nil, false
+	}
+
+	if channel.ProjectID != uint(projID) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+
+	return channel, true
+}
@@ -150,11 +150,19 @@ func (app *App) HandleGithubOAuthCallback(w http.ResponseWriter, r *http.Request
 		// log the user in
 		app.Logger.Info().Msgf("New user created: %d", user.ID)
 
-		session.Values["authenticated"] = true
-		session.Values["user_id"] = user.ID
-		session.Values["email"] = user.Email
-		session.Values["redirect"] = ""
+		twoFactorRequired, err := app.loginOrRequireTwoFactor(session, user)
+
+		if err != nil {
+			app.handleErrorDataRead(err, w)
+			return
+		}
+
 		session.Save(r, w)
+
+		if twoFactorRequired {
+			http.Redirect(w, r, "/login?two_factor=true", 302)
+			return
+		}
 	}
 
 	if session.Values["query_params"] != "" {
@@ -0,0 +1,456 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/oauth"
+	"gorm.io/gorm"
+)
+
+// HandleSSOStartUser starts the single sign-on flow for a user login request
+func (app *App) HandleSSOStartUser(w http.ResponseWriter, r *http.Request) {
+	if app.SSOProvider == nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	state := oauth.CreateRandomState()
+
+	if err := app.populateOAuthSession(w, r, state, false); err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	// the nonce ties the ID token to this login attempt
+	nonce := oauth.CreateRandomState()
+
+	session, err := app.Store.Get(r, app.ServerConf.CookieName)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	session.Values["sso_nonce"] = nonce
+
+	if err := session.Save(r, w); err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	url := app.SSOProvider.AuthCodeURL(state, nonce)
+
+	if url == "" {
+		app.handleErrorInternal(fmt.Errorf("could not reach sso provider"), w)
+		return
+	}
+
+	http.Redirect(w, r, url, 302)
+}
+
+// HandleSSOCallback verifies the callback request by checking that the state
+// parameter has not been modified, and exchanges the code for a verified
+// identity. The user is created if they do not exist, is granted the project
+// roles mapped to their SSO groups, and is then logged in.
+func (app *App) HandleSSOCallback(w http.ResponseWriter, r *http.Request) {
+	if app.SSOProvider == nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	session, err := app.Store.Get(r, app.ServerConf.CookieName)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	if _, ok := session.Values["state"]; !ok {
+		app.sendExternalError(
+			err,
+			http.StatusForbidden,
+			HTTPError{
+				Code: http.StatusForbidden,
+				Errors: []string{
+					"Could not read cookie: are cookies enabled?",
+				},
+			},
+			w,
+		)
+
+		return
+	}
+
+	if r.URL.Query().Get("state") != session.Values["state"] {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	nonce, _ := session.Values["sso_nonce"].(string)
+
+	identity, err := app.SSOProvider.Exchange(r.Context(), r.URL.Query().Get("code"), nonce)
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msg("could not verify sso identity")
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	user, err := app.upsertUserFromSSOIdentity(identity)
+
+	if err != nil {
+		http.Redirect(w, r, "/login?error="+url.QueryEscape(err.Error()), 302)
+		return
+	}
+
+	if err := app.syncSSOGroupRoles(user, identity.Issuer, identity.Groups); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	// log the user in
+	delete(session.Values, "sso_nonce")
+
+	twoFactorRequired, err := app.loginOrRequireTwoFactor(session, user)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	session.Save(r, w)
+
+	if twoFactorRequired {
+		http.Redirect(w, r, "/login?two_factor=true", 302)
+		return
+	}
+
+	if session.Values["query_params"] != "" {
+		http.Redirect(w, r, fmt.Sprintf("/dashboard?%s", session.Values["query_params"]), 302)
+	} else {
+		http.Redirect(w, r, "/dashboard", 302)
+	}
+}
+
+// upsertUserFromSSOIdentity finds the user linked to the SSO identity. If
+// there is none, and the provider has verified the email, an existing user
+// with the same email is linked, and otherwise a new user is created.
+func (app *App) upsertUserFromSSOIdentity(identity *oauth.SSOIdentity) (*models.User, error) {
+	user, err := app.Repo.User.ReadUserBySSOSubject(identity.Issuer, identity.Subject)
+
+	if err == nil {
+		return user, nil
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if identity.Email == "" {
+		return nil, fmt.Errorf("sso user must have an email")
+	}
+
+	// users are only provisioned for emails that the provider has verified,
+	// so that an unverified email can't claim the invites sent to it
+	if !identity.EmailVerified {
+		return nil, fmt.Errorf("sso email must be verified")
+	}
+
+	user, err = app.Repo.User.ReadUserByEmail(identity.Email)
+
+	if err == gorm.ErrRecordNotFound {
+		user = &models.User{
+			Email:         identity.Email,
+			EmailVerified: true,
+			SSOIssuer:     identity.Issuer,
+			SSOSubject:    identity.Subject,
+		}
+
+		user, err = app.Repo.User.CreateUser(user)
+
+		if err != nil {
+			return nil, err
+		}
+
+		app.Logger.Info().Msgf("New user created: %d", user.ID)
+
+		return user, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	user.SSOIssuer = identity.Issuer
+	user.SSOSubject = identity.Subject
+	user.EmailVerified = true
+
+	return app.Repo.User.UpdateUser(user)
+}
+
+// syncSSOGroupRoles sets the user's role in each project to the most
+// privileged role that the project's mappings grant to their SSO groups.
+// Roles that were granted without SSO are only ever raised, after which they
+// are managed by the mappings. Managed roles are lowered when the user's
+// groups change, and removed when no mapping grants them anymore, except that
+// the last admin of a project is always kept.
+func (app *App) syncSSOGroupRoles(user *models.User, issuer string, groups []string) error {
+	mappings, err := app.Repo.SSOGroupMapping.ListSSOGroupMappingsByGroups(issuer, groups)
+
+	if err != nil {
+		return err
+	}
+
+	kinds := make(map[uint]string)
+
+	for _, mapping := range mappings {
+		if curr, ok := kinds[mapping.ProjectID]; !ok || models.IsRoleMorePrivileged(mapping.Kind, curr) {
+			kinds[mapping.ProjectID] = mapping.Kind
+		}
+	}
+
+	for projID, kind := range kinds {
+		role, err := app.Repo.Project.ReadProjectRole(projID, user.ID)
+
+		if err == gorm.ErrRecordNotFound {
+			project, err := app.Repo.Project.ReadProject(projID)
+
+			if err != nil {
+				return err
+			}
+
+			_, err = app.Repo.Project.CreateProjectRole(project, &models.Role{
+				Kind:       kind,
+				UserID:     user.ID,
+				ProjectID:  projID,
+				SSOManaged: true,
+			})
+
+			if err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		} else if kind != role.Kind && (role.SSOManaged || models.IsRoleMorePrivileged(kind, role.Kind)) {
+			if err := app.updateSSOManagedRole(role, kind); err != nil {
+				return err
+			}
+		}
+	}
+
+	// managed roles in projects where none of the user's groups are mapped
+	// anymore are removed
+	projects, err := app.Repo.Project.ListProjectsByUserID(user.ID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		if _, ok := kinds[project.ID]; ok {
+			continue
+		}
+
+		role, err := app.Repo.Project.ReadProjectRole(project.ID, user.ID)
+
+		if err != nil {
+			return err
+		}
+
+		if role.SSOManaged {
+			if err := app.updateSSOManagedRole(role, ""); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// updateSSOManagedRole sets the kind of a role that is managed by SSO group
+// mappings, or removes the role if the kind is empty. The role is left as it
+// is if it is the last admin role of the project.
+func (app *App) updateSSOManagedRole(role *models.Role, kind string) error {
+	if role.Kind == models.RoleAdmin && kind != models.RoleAdmin {
+		project, err := app.Repo.Project.ReadProject(role.ProjectID)
+
+		if err != nil {
+			return err
+		}
+
+		numAdmins := 0
+
+		for _, projRole := range project.Roles {
+			if projRole.Kind == models.RoleAdmin {
+				numAdmins++
+			}
+		}
+
+		if numAdmins <= 1 {
+			app.Logger.Warn().Msgf(
+				"not changing the sso role of user %d, the last admin of project %d",
+				role.UserID,
+				role.ProjectID,
+			)
+
+			return nil
+		}
+	}
+
+	if kind == "" {
+		_, err := app.Repo.Project.DeleteProjectRole(role.ProjectID, role)
+		return err
+	}
+
+	role.Kind = kind
+	role.SSOManaged = true
+
+	_, err := app.Repo.Project.UpdateProjectRole(role.ProjectID, role)
+
+	return err
+}
+
+// HandleCreateSSOGroupMapping grants a project role to the members of an SSO
+// group of the requesting admin's SSO issuer
+func (app *App) HandleCreateSSOGroupMapping(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// mappings apply to the groups of the admin's own SSO issuer, so that a
+	// project can't claim the groups of an issuer that it doesn't use
+	userID, err := app.getUserIDFromRequest(r)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	user, err := app.Repo.User.ReadUser(userID)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	if user.SSOIssuer == "" {
+		app.sendExternalError(fmt.Errorf("user %d has no sso issuer", userID), http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{"sso group mappings can only be created by users that log in with sso"},
+		}, w)
+
+		return
+	}
+
+	form := &forms.CreateSSOGroupMappingForm{
+		ProjectID: uint(projID),
+		Issuer:    user.SSOIssuer,
+	}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	mapping, err := form.ToSSOGroupMapping()
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	mapping, err = app.Repo.SSOGroupMapping.CreateSSOGroupMapping(mapping)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(mapping.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleListSSOGroupMappings returns the SSO group mappings for a project
+func (app *App) HandleListSSOGroupMappings(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	mappings, err := app.Repo.SSOGroupMapping.ListSSOGroupMappingsByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	extMappings := make([]*models.SSOGroupMappingExternal, 0)
+
+	for _, mapping := range mappings {
+		extMappings = append(extMappings, mapping.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(extMappings); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleDeleteSSOGroupMapping deletes an SSO group mapping via the mapping ID.
+// Roles that were granted through the mapping are lowered or removed the next
+// time their users log in, unless another mapping grants them.
+func (app *App) HandleDeleteSSOGroupMapping(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	id, err := strconv.ParseUint(chi.URLParam(r, "mapping_id"), 0, 64)
+
+	if err != nil || id == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	mapping, err := app.Repo.SSOGroupMapping.ReadSSOGroupMapping(uint(id))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	if mapping.ProjectID != uint(projID) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	if err := app.Repo.SSOGroupMapping.DeleteSSOGroupMapping(mapping); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
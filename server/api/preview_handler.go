@@ -0,0 +1,549 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/integrations/ci/actions"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/kubernetes/domain"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/server/requestlog"
+	"gorm.io/gorm"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// HandleDeployPreviewWebhook deploys a copy of a release for a pull request. The
+// copy is installed in its own namespace the first time, and later calls upgrade
+// it to the new commit.
+func (app *App) HandleDeployPreviewWebhook(w http.ResponseWriter, r *http.Request) {
+	release, prNumber, ok := app.readPreviewWebhookRelease(w, r)
+
+	if !ok {
+		return
+	}
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	commit := vals.Get("commit")
+
+	if commit == "" {
+		app.sendExternalError(fmt.Errorf("commit not specified"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"commit is required"},
+		}, w)
+
+		return
+	}
+
+	// read the values of the release that the preview is cloned from
+	sourceAgent, err := app.getPreviewAgent(w, release, release.Namespace)
+
+	if err != nil {
+		return
+	}
+
+	rel, err := sourceAgent.GetRelease(release.Name, 0)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return
+	}
+
+	values := rel.Config
+
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+
+	repository := release.GitActionConfig.ImageRepoURI
+
+	if repo := vals.Get("repository"); repo != "" {
+		repository = repo
+	}
+
+	values["image"] = map[string]interface{}{
+		"repository": repository,
+		"tag":        commit,
+	}
+
+	env, err := app.Repo.PreviewEnvironment.ReadPreviewEnvironmentByPR(release.ID, prNumber)
+	isNew := err == gorm.ErrRecordNotFound
+
+	if err != nil && !isNew {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	if isNew {
+		name := models.NewPreviewEnvironmentName(release.Name, prNumber)
+
+		env = &models.PreviewEnvironment{
+			ProjectID: release.ProjectID,
+			ClusterID: release.ClusterID,
+			ReleaseID: release.ID,
+			PRNumber:  prNumber,
+			Name:      name,
+			Namespace: models.PreviewEnvironmentNamespace(name),
+		}
+	}
+
+	// previews that were stored before names were shortened may still be
+	// invalid, so the name is checked before anything is installed
+	if err := env.Validate(); err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	deployment := &models.Deployment{
+		ProjectID:   release.ProjectID,
+		ClusterID:   release.ClusterID,
+		ReleaseName: env.Name,
+		Namespace:   env.Namespace,
+		CommitSHA:   commit,
+		Source:      models.DeploymentSourceWebhook,
+		Action:      models.DeploymentActionUpgrade,
+		StartedAt:   time.Now(),
+	}
+
+	if isNew {
+		deployment.Action = models.DeploymentActionInstall
+	}
+
+	// deploys from a webhook can't override the deploy policies
+	if !app.checkDeployPolicies(w, r, deployment) {
+		return
+	}
+
+	agent, err := app.getPreviewAgent(w, release, env.Namespace)
+
+	if err != nil {
+		return
+	}
+
+	// previews are exposed on a generated subdomain, since custom domains belong
+	// to the release that the preview was cloned from
+	if ingress, ok := values["ingress"].(map[string]interface{}); ok && ingress["enabled"] == true {
+		if env.Hostname == "" {
+			record, err := app.createPreviewDNSRecord(agent.K8sAgent, env.Name)
+
+			if err != nil {
+				app.handleErrorInternal(err, w)
+				return
+			}
+
+			env.Hostname = record.Hostname
+			env.DNSRecordID = record.ID
+		}
+
+		ingress["custom_domain"] = false
+		ingress["hosts"] = []interface{}{}
+		ingress["porter_hosts"] = []interface{}{env.Hostname}
+	}
+
+	registries, err := app.Repo.Registry.ListRegistriesByProjectID(release.ProjectID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	cluster, err := app.Repo.Cluster.ReadCluster(release.ClusterID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	// store the preview before deploying, so that a failed deploy can be retried
+	// or torn down
+	env.Commit = commit
+
+	if isNew {
+		env, err = app.Repo.PreviewEnvironment.CreatePreviewEnvironment(env)
+	} else {
+		env, err = app.Repo.PreviewEnvironment.UpdatePreviewEnvironment(env)
+	}
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	var newRel *helmrelease.Release
+
+	// a release may be missing even if the preview exists, if its first deploy failed
+	if _, getErr := agent.GetRelease(env.Name, 0); getErr != nil {
+		_, err = agent.K8sAgent.CreateNamespace(env.Namespace)
+
+		if err != nil && !k8serrors.IsAlreadyExists(err) {
+			app.handleErrorInternal(err, w)
+			return
+		}
+
+		deployment.Action = models.DeploymentActionInstall
+
+		newRel, err = agent.InstallChart(&helm.InstallChartConfig{
+			Chart:         rel.Chart,
+			Name:          env.Name,
+			Namespace:     env.Namespace,
+			Values:        values,
+			Cluster:       cluster,
+			Repo:          *app.Repo,
+			Registries:    registries,
+			SecretBackend: app.SecretBackend,
+		}, app.DOConf)
+	} else {
+		newRel, err = agent.UpgradeReleaseByValues(&helm.UpgradeReleaseConfig{
+			Name:          env.Name,
+			Values:        values,
+			Cluster:       cluster,
+			Repo:          *app.Repo,
+			Registries:    registries,
+			SecretBackend: app.SecretBackend,
+		}, app.DOConf)
+	}
+
+	app.recordDeployment(deployment, newRel, err)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{"error deploying preview " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	app.watchRollout(agent, deployment, newRel)
+
+	// only comment when the preview is first created, so that pushes to the pull
+	// request don't flood it with comments
+	if isNew {
+		err = app.commentPreviewURL(release, env)
+
+		if err != nil {
+			app.Logger.Warn().Err(err).Msgf("could not comment on pull request %d", prNumber)
+		}
+	}
+
+	app.Logger.Info().Msgf("Preview environment deployed: %s", env.Name)
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(env.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleDeletePreviewWebhook tears down the preview of a release for a closed
+// pull request
+func (app *App) HandleDeletePreviewWebhook(w http.ResponseWriter, r *http.Request) {
+	release, prNumber, ok := app.readPreviewWebhookRelease(w, r)
+
+	if !ok {
+		return
+	}
+
+	env, err := app.Repo.PreviewEnvironment.ReadPreviewEnvironmentByPR(release.ID, prNumber)
+
+	if err == gorm.ErrRecordNotFound {
+		// nothing was deployed for this pull request
+		w.WriteHeader(http.StatusOK)
+		return
+	} else if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	agent, err := app.getPreviewAgent(w, release, env.Namespace)
+
+	if err != nil {
+		return
+	}
+
+	_, err = agent.UninstallChart(env.Name)
+
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{"error deleting preview " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	err = agent.K8sAgent.DeleteNamespace(env.Namespace)
+
+	if err != nil && !k8serrors.IsNotFound(err) {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	if env.DNSRecordID != 0 {
+		err = app.deletePreviewDNSRecord(env.DNSRecordID)
+
+		if err != nil {
+			app.handleErrorInternal(err, w)
+			return
+		}
+	}
+
+	if err := app.Repo.PreviewEnvironment.DeletePreviewEnvironment(env); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	app.Logger.Info().Msgf("Preview environment deleted: %s", env.Name)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleListPreviews returns the preview environments of a release
+func (app *App) HandleListPreviews(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	clusterID, err := strconv.ParseUint(vals.Get("cluster_id"), 10, 64)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	release, err := app.Repo.Release.ReadRelease(uint(clusterID), name, vals.Get("namespace"))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	envs, err := app.Repo.PreviewEnvironment.ListPreviewEnvironmentsByReleaseID(release.ID)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	extEnvs := make([]*models.PreviewEnvironmentExternal, 0)
+
+	for _, env := range envs {
+		extEnvs = append(extEnvs, env.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(extEnvs); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// readPreviewWebhookRelease reads the release by its webhook token and parses the
+// pull request number. Previews must be enabled for the release's github action.
+func (app *App) readPreviewWebhookRelease(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*models.Release, uint, bool) {
+	release, err := app.Repo.Release.ReadReleaseByWebhookToken(chi.URLParam(r, "token"))
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found with given webhook"},
+		}, w)
+
+		return nil, 0, false
+	}
+
+	// the webhook routes have no project ID, so the project is read from the
+	// release for the audit log
+	action := "preview_deploy"
+
+	if r.Method == "DELETE" {
+		action = "preview_delete"
+	}
+
+	requestlog.SetAuditTarget(r, &requestlog.AuditTarget{
+		ProjectID:    release.ProjectID,
+		Resource:     "releases",
+		ResourceName: release.Name,
+		Action:       action,
+	})
+
+	if release.GitActionConfig.ID == 0 || !release.GitActionConfig.PreviewsEnabled {
+		app.sendExternalError(fmt.Errorf("previews disabled"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{"previews are not enabled for this release"},
+		}, w)
+
+		return nil, 0, false
+	}
+
+	prNumber, err := strconv.ParseUint(r.URL.Query().Get("pr_number"), 10, 64)
+
+	if err != nil || prNumber == 0 {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"pr_number must be a positive integer"},
+		}, w)
+
+		return nil, 0, false
+	}
+
+	return release, uint(prNumber), true
+}
+
+// getPreviewAgent returns a Helm agent for the release's cluster that stores
+// releases in the given namespace
+func (app *App) getPreviewAgent(
+	w http.ResponseWriter,
+	release *models.Release,
+	namespace string,
+) (*helm.Agent, error) {
+	params := map[string][]string{}
+	params["cluster_id"] = []string{fmt.Sprint(release.ClusterID)}
+	params["storage"] = []string{"secret"}
+	params["namespace"] = []string{namespace}
+
+	form := &forms.ReleaseForm{
+		Form: &helm.Form{
+			Repo:              app.Repo,
+			DigitalOceanOAuth: app.DOConf,
+		},
+	}
+
+	form.PopulateHelmOptionsFromQueryParams(params, app.Repo.Cluster)
+
+	return app.getWebhookAgentFromReleaseForm(w, form)
+}
+
+// createPreviewDNSRecord points a new subdomain at the nginx ingress of the
+// cluster
+func (app *App) createPreviewDNSRecord(
+	agent *kubernetes.Agent,
+	name string,
+) (*models.DNSRecord, error) {
+	endpoint, found, err := domain.GetNGINXIngressServiceIP(agent.Clientset)
+
+	if err != nil {
+		return nil, err
+	} else if !found {
+		return nil, fmt.Errorf("target cluster does not have nginx ingress")
+	}
+
+	createDomain := domain.CreateDNSRecordConfig{
+		ReleaseName: name,
+		RootDomain:  app.ServerConf.AppRootDomain,
+		Endpoint:    endpoint,
+	}
+
+	record, err := app.Repo.DNSRecord.CreateDNSRecord(createDomain.NewDNSRecordForEndpoint())
+
+	if err != nil {
+		return nil, err
+	}
+
+	inClusterAgent, err := kubernetes.GetAgentInClusterConfig()
+
+	if err != nil {
+		return nil, err
+	}
+
+	_record := domain.DNSRecord(*record)
+
+	if err := _record.CreateDomain(inClusterAgent.Clientset); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// deletePreviewDNSRecord removes the subdomain of a preview
+func (app *App) deletePreviewDNSRecord(id uint) error {
+	record, err := app.Repo.DNSRecord.ReadDNSRecord(id)
+
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	inClusterAgent, err := kubernetes.GetAgentInClusterConfig()
+
+	if err != nil {
+		return err
+	}
+
+	_record := domain.DNSRecord(*record)
+
+	if err := _record.DeleteDomain(inClusterAgent.Clientset); err != nil {
+		return err
+	}
+
+	return app.Repo.DNSRecord.DeleteDNSRecord(record)
+}
+
+// commentPreviewURL posts the address of a preview on its pull request
+func (app *App) commentPreviewURL(release *models.Release, env *models.PreviewEnvironment) error {
+	gitAction := release.GitActionConfig
+
+	gr, err := app.Repo.GitRepo.ReadGitRepo(gitAction.GitRepoID)
+
+	if err != nil {
+		return err
+	}
+
+	repoSplit := strings.Split(gitAction.GitRepo, "/")
+
+	if len(repoSplit) != 2 {
+		return fmt.Errorf("invalid formatting of repo name")
+	}
+
+	gaRunner := &actions.GithubActions{
+		GitIntegration: gr,
+		GitRepoName:    repoSplit[1],
+		GitRepoOwner:   repoSplit[0],
+		Repo:           *app.Repo,
+		GithubConf:     app.GithubProjectConf,
+		ReleaseName:    release.Name,
+	}
+
+	body := fmt.Sprintf("Porter deployed a preview of `%s` for this pull request.", release.Name)
+
+	if ext := env.Externalize(); ext.URL != "" {
+		body = fmt.Sprintf("%s\n\nPreview URL: %s", body, ext.URL)
+	}
+
+	return gaRunner.CommentOnPullRequest(int(env.PRNumber), body)
+}
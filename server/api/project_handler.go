@@ -2,12 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi"
 	"github.com/porter-dev/porter/internal/forms"
 	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/server/requestlog"
 )
 
 // Enumeration of user API error codes, represented as int64
@@ -143,3 +145,101 @@ func (app *App) HandleDeleteProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// HandleUpdateProjectRole changes the role of a project member, reading from the
+// project_id and user_id in the URL params
+func (app *App) HandleUpdateProjectRole(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	userID, err := strconv.ParseUint(chi.URLParam(r, "user_id"), 0, 64)
+
+	if err != nil || userID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	form := &forms.UpdateProjectRoleForm{
+		ProjectID: uint(projID),
+		UserID:    uint(userID),
+	}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	proj, err := app.Repo.Project.ReadProject(uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	role, err := app.Repo.Project.ReadProjectRole(uint(projID), uint(userID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	// a project must always keep at least one admin
+	if role.Kind == models.RoleAdmin && form.Kind != models.RoleAdmin {
+		numAdmins := 0
+
+		for _, projRole := range proj.Roles {
+			if projRole.Kind == models.RoleAdmin {
+				numAdmins++
+			}
+		}
+
+		if numAdmins <= 1 {
+			app.sendExternalError(fmt.Errorf("project must have at least one admin"), http.StatusBadRequest, HTTPError{
+				Code:   ErrProjectValidateFields,
+				Errors: []string{"project must have at least one admin"},
+			}, w)
+
+			return
+		}
+	}
+
+	before := role.Externalize()
+
+	role, err = form.ToRole(role)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	role, err = app.Repo.Project.UpdateProjectRole(uint(projID), role)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	requestlog.RecordChange(r, before, role.Externalize())
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(role.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
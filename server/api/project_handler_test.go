@@ -2,11 +2,13 @@ package api_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 	"testing"
 
 	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/auth/token"
 	"github.com/porter-dev/porter/internal/models"
 )
 
@@ -175,3 +177,224 @@ func projectModelBodyValidator(c *projTest, tester *tester, t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+// ------------------------- ROLE PERMISSIONS ------------------------- //
+
+type roleMatrixRequest struct {
+	msg      string
+	method   string
+	endpoint string
+	body     string
+
+	// allowed are the roles that may make the request, and every other role
+	// is expected to be forbidden
+	allowed []string
+}
+
+var roleMatrixRequests = []*roleMatrixRequest{
+	&roleMatrixRequest{
+		msg:      "Read project",
+		method:   "GET",
+		endpoint: "/api/projects/1",
+		allowed: []string{
+			models.RoleAdmin,
+			models.RoleDeveloper,
+			models.RoleDeployer,
+			models.RoleIntegrationManager,
+			models.RoleViewer,
+		},
+	},
+	&roleMatrixRequest{
+		msg:      "Update project role",
+		method:   "POST",
+		endpoint: "/api/projects/1/roles/2",
+		body:     `{"kind":"viewer"}`,
+		allowed:  []string{models.RoleAdmin},
+	},
+	&roleMatrixRequest{
+		msg:      "List audit events",
+		method:   "GET",
+		endpoint: "/api/projects/1/events",
+		allowed:  []string{models.RoleAdmin},
+	},
+	&roleMatrixRequest{
+		msg:      "List project secrets",
+		method:   "GET",
+		endpoint: "/api/projects/1/secrets",
+		allowed: []string{
+			models.RoleAdmin,
+			models.RoleDeveloper,
+			models.RoleDeployer,
+			models.RoleViewer,
+		},
+	},
+	&roleMatrixRequest{
+		msg:      "Write project secret",
+		method:   "POST",
+		endpoint: "/api/projects/1/secrets",
+		body:     `{"name":"db-password","value":"hello"}`,
+		allowed:  []string{models.RoleAdmin},
+	},
+	&roleMatrixRequest{
+		msg:      "List git repos",
+		method:   "GET",
+		endpoint: "/api/projects/1/gitrepos",
+		allowed: []string{
+			models.RoleAdmin,
+			models.RoleDeveloper,
+			models.RoleIntegrationManager,
+		},
+	},
+	&roleMatrixRequest{
+		msg:      "List release sets",
+		method:   "GET",
+		endpoint: "/api/projects/1/release_sets",
+		allowed: []string{
+			models.RoleAdmin,
+			models.RoleDeveloper,
+			models.RoleDeployer,
+			models.RoleViewer,
+		},
+	},
+	&roleMatrixRequest{
+		msg:      "Delete project",
+		method:   "DELETE",
+		endpoint: "/api/projects/1",
+		allowed:  []string{models.RoleAdmin},
+	},
+}
+
+func TestProjectRolePermissions(t *testing.T) {
+	for _, c := range roleMatrixRequests {
+		for kind := range models.RolePolicies {
+			tester := newTester(true)
+			initRoleMember(tester, kind)
+
+			expStatus := http.StatusForbidden
+
+			for _, allowed := range c.allowed {
+				if allowed == kind {
+					expStatus = http.StatusOK
+				}
+			}
+
+			rr := tester.send(c.method, c.endpoint, c.body)
+
+			if rr.Code != expStatus {
+				t.Errorf("%s as %s, handler returned wrong status code: got %v want %v",
+					c.msg, kind, rr.Code, expStatus)
+			}
+		}
+	}
+}
+
+var updateProjectRoleTests = []*projTest{
+	&projTest{
+		initializers: []func(t *tester){
+			func(t *tester) { initRoleMember(t, models.RoleAdmin) },
+			// the other admin leaves, so the member is the last admin
+			func(t *tester) {
+				role, _ := t.repo.Project.ReadProjectRole(1, 2)
+				role.Kind = models.RoleViewer
+				t.repo.Project.UpdateProjectRole(1, role)
+			},
+		},
+		msg:       "Demote last admin",
+		method:    "POST",
+		endpoint:  "/api/projects/1/roles/1",
+		body:      `{"kind":"developer"}`,
+		expStatus: http.StatusBadRequest,
+		expBody:   `{"code":601,"errors":["project must have at least one admin"]}`,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			projectBasicBodyValidator,
+		},
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			func(t *tester) { initRoleMember(t, models.RoleAdmin) },
+		},
+		msg:       "Invalid role",
+		method:    "POST",
+		endpoint:  "/api/projects/1/roles/2",
+		body:      `{"kind":"owner"}`,
+		expStatus: http.StatusBadRequest,
+		expBody:   `{"code":601,"errors":["owner is not a valid role"]}`,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			projectBasicBodyValidator,
+		},
+	},
+}
+
+func TestHandleUpdateProjectRole(t *testing.T) {
+	testProjRequests(t, updateProjectRoleTests, true)
+}
+
+func TestStoredTokenInOtherProject(t *testing.T) {
+	tester := newTester(true)
+	initRoleMember(tester, models.RoleAdmin)
+
+	// the member is also an admin of a second project
+	otherProj, _ := tester.repo.Project.CreateProject(&models.Project{
+		Name: "project-other",
+	})
+
+	tester.repo.Project.CreateProjectRole(otherProj, &models.Role{
+		UserID:    1,
+		ProjectID: otherProj.ID,
+		Kind:      models.RoleAdmin,
+	})
+
+	tester.repo.APIToken.CreateAPIToken(&models.APIToken{
+		UniqueID:        "abcdef",
+		ProjectID:       1,
+		CreatedByUserID: 1,
+		Name:            "ci",
+		Scopes:          models.GetScope(models.ProjectResource, models.ReadVerb),
+	})
+
+	storedTok, _ := token.GetStoredTokenForAPI(1, 1, "abcdef")
+	encoded, err := storedTok.EncodeToken(&token.TokenGeneratorConf{TokenSecret: "secret"})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if rr := tester.sendWithToken("GET", "/api/projects/1", "", encoded); rr.Code != http.StatusOK {
+		t.Errorf("Token in its project, handler returned wrong status code: got %v want %v",
+			rr.Code, http.StatusOK)
+	}
+
+	endpoint := fmt.Sprintf("/api/projects/%d", otherProj.ID)
+
+	if rr := tester.sendWithToken("GET", endpoint, "", encoded); rr.Code != http.StatusForbidden {
+		t.Errorf("Token in other project, handler returned wrong status code: got %v want %v",
+			rr.Code, http.StatusForbidden)
+	}
+}
+
+// initRoleMember creates a project with a member of the given kind (user 1)
+// and an admin (user 2), and logs the member in
+func initRoleMember(tester *tester, kind string) {
+	member := tester.createUser("member@getporter.dev", "hello")
+	admin := tester.createUser("admin@getporter.dev", "hello")
+
+	projModel, _ := tester.repo.Project.CreateProject(&models.Project{
+		Name: "project-test",
+	})
+
+	tester.repo.Project.CreateProjectRole(projModel, &models.Role{
+		UserID:    member.ID,
+		ProjectID: projModel.ID,
+		Kind:      kind,
+	})
+
+	tester.repo.Project.CreateProjectRole(projModel, &models.Role{
+		UserID:    admin.ID,
+		ProjectID: projModel.ID,
+		Kind:      models.RoleAdmin,
+	})
+
+	tester.send("POST", "/api/login", `{"email":"member@getporter.dev","password":"hello"}`)
+}
@@ -0,0 +1,315 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// AdoptableRelease is a Helm release in a cluster that Porter doesn't manage,
+// since it was not deployed through Porter
+type AdoptableRelease struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	Chart        string `json:"chart"`
+	ChartVersion string `json:"chart_version"`
+	Revision     int    `json:"revision"`
+	Status       string `json:"status"`
+}
+
+// AdoptReleaseResult is the result of adopting a single Helm release
+type AdoptReleaseResult struct {
+	Name      string                  `json:"name"`
+	Namespace string                  `json:"namespace"`
+	Release   *models.ReleaseExternal `json:"release,omitempty"`
+	Error     string                  `json:"error,omitempty"`
+}
+
+// HandleListAdoptableReleases returns the Helm releases in a cluster that
+// Porter doesn't manage, in every namespace or in the namespace that is passed
+func (app *App) HandleListAdoptableReleases(w http.ResponseWriter, r *http.Request) {
+	form := &forms.ReleaseForm{
+		Form: &helm.Form{
+			Repo:              app.Repo,
+			DigitalOceanOAuth: app.DOConf,
+		},
+	}
+
+	agent, err := app.getAgentFromQueryParams(
+		w,
+		r,
+		form,
+		form.PopulateHelmOptionsFromQueryParams,
+	)
+
+	// errors are handled in app.getAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	releases, err := app.listAdoptableReleases(r, agent, form.Cluster, form.Namespace)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	res := make([]*AdoptableRelease, 0)
+
+	for _, rel := range releases {
+		res = append(res, &AdoptableRelease{
+			Name:         rel.Name,
+			Namespace:    rel.Namespace,
+			Chart:        rel.Chart.Metadata.Name,
+			ChartVersion: rel.Chart.Metadata.Version,
+			Revision:     rel.Version,
+			Status:       rel.Info.Status.String(),
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleAdoptReleases brings Helm releases that were not deployed through
+// Porter under Porter's management. Each release is stored with a webhook
+// token, and is optionally linked to a GitHub action. Releases are adopted
+// independently, so the result of each release is returned.
+func (app *App) HandleAdoptReleases(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	form := &forms.AdoptReleasesForm{
+		ReleaseForm: &forms.ReleaseForm{
+			Form: &helm.Form{
+				Repo:              app.Repo,
+				DigitalOceanOAuth: app.DOConf,
+			},
+		},
+	}
+
+	form.ReleaseForm.PopulateHelmOptionsFromQueryParams(
+		vals,
+		app.Repo.Cluster,
+	)
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return
+	}
+
+	if form.All == (len(form.Releases) > 0) {
+		app.sendExternalError(nil, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"either all or a list of releases must be set"},
+		}, w)
+
+		return
+	}
+
+	agent, err := app.getAgentFromReleaseForm(
+		w,
+		r,
+		form.ReleaseForm,
+	)
+
+	// errors are handled in app.getAgentFromBodyParams
+	if err != nil {
+		return
+	}
+
+	adoptable, err := app.listAdoptableReleases(r, agent, form.Cluster, form.Namespace)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	adoptableKeys := make(map[string]bool)
+
+	for _, rel := range adoptable {
+		adoptableKeys[rel.Namespace+"/"+rel.Name] = true
+	}
+
+	if form.All {
+		for _, rel := range adoptable {
+			form.Releases = append(form.Releases, &forms.AdoptReleaseForm{
+				Name:      rel.Name,
+				Namespace: rel.Namespace,
+			})
+		}
+	}
+
+	userID, _ := app.getUserIDFromRequest(r)
+	results := make([]*AdoptReleaseResult, 0)
+
+	for _, relForm := range form.Releases {
+		result := &AdoptReleaseResult{
+			Name:      relForm.Name,
+			Namespace: relForm.Namespace,
+		}
+
+		results = append(results, result)
+
+		if !adoptableKeys[relForm.Namespace+"/"+relForm.Name] {
+			result.Error = "release not found, or already managed by Porter"
+			continue
+		}
+
+		stored, err := app.adoptRelease(uint(projID), userID, form.Cluster, relForm)
+
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		if stored != nil {
+			result.Release = stored.Externalize()
+		}
+
+		// a release can only be adopted once, even if it is listed twice
+		delete(adoptableKeys, relForm.Namespace+"/"+relForm.Name)
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// adoptRelease stores a Helm release with a new webhook token, and links it to
+// a GitHub action if one is passed. The stored release is returned even if the
+// GitHub action could not be set up, since the release is adopted by then.
+func (app *App) adoptRelease(
+	projID, userID uint,
+	cluster *models.Cluster,
+	form *forms.AdoptReleaseForm,
+) (*models.Release, error) {
+	token, err := repository.GenerateRandomBytes(16)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := app.Repo.Release.CreateRelease(&models.Release{
+		ClusterID:    cluster.ID,
+		ProjectID:    cluster.ProjectID,
+		Namespace:    form.Namespace,
+		Name:         form.Name,
+		WebhookToken: token,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	app.Logger.Info().Msgf("Release %s in namespace %s adopted: %d", stored.Name, stored.Namespace, stored.ID)
+
+	if form.GitAction == nil {
+		return stored, nil
+	}
+
+	form.GitAction.ReleaseID = stored.ID
+
+	if err := app.validator.Struct(form.GitAction); err != nil {
+		return stored, fmt.Errorf("release adopted, but the github action is invalid: %v", err)
+	}
+
+	ga, err := app.setupGitAction(uint64(projID), userID, stored, stored.Name, form.GitAction)
+
+	if err != nil {
+		return stored, fmt.Errorf("release adopted, but the github action could not be set up: %v", err)
+	}
+
+	stored.GitActionConfig = *ga
+
+	return stored, nil
+}
+
+// listAdoptableReleases returns the deployed and failed Helm releases in a
+// cluster that don't have a stored release, and that the user's role bindings
+// allow access to. The canaries of stored releases are managed through their
+// stable release, so they are never adopted.
+func (app *App) listAdoptableReleases(
+	r *http.Request,
+	agent *helm.Agent,
+	cluster *models.Cluster,
+	namespace string,
+) ([]*release.Release, error) {
+	releases, err := agent.ListReleases(namespace, &helm.ListFilter{
+		Namespace:    namespace,
+		StatusFilter: []string{"deployed", "failed"},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := app.Repo.Release.ListReleasesByClusterID(cluster.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	managed := make(map[string]bool)
+
+	for _, rel := range stored {
+		managed[rel.Namespace+"/"+rel.Name] = true
+	}
+
+	bindings, err := app.getRoleBindingsFromRequest(r, cluster.ProjectID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*release.Release, 0)
+
+	for _, rel := range releases {
+		if managed[rel.Namespace+"/"+rel.Name] {
+			continue
+		}
+
+		if stable := strings.TrimSuffix(rel.Name, helm.CanarySuffix); stable != rel.Name && managed[rel.Namespace+"/"+stable] {
+			continue
+		}
+
+		if bindings.Allows(cluster.ID, rel.Namespace) {
+			res = append(res, rel)
+		}
+	}
+
+	return res, nil
+}
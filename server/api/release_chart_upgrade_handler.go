@@ -0,0 +1,292 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/templater/parser"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// ReleaseChartUpgrade is the result of upgrading a release to another version
+// of its chart
+type ReleaseChartUpgrade struct {
+	Name           string `json:"name"`
+	Chart          string `json:"chart"`
+	CurrentVersion string `json:"current_version"`
+	TargetVersion  string `json:"target_version"`
+
+	Migration *helm.ValuesMigration `json:"migration"`
+
+	// Form is the form.yaml of the new chart version, populated with the
+	// migrated values
+	Form *models.FormYAML `json:"form"`
+
+	// Diff is the set of changes of the upgrade, and is only set for dry runs
+	Diff *helm.ReleaseDiff `json:"diff,omitempty"`
+
+	// Revision is the revision that the upgrade created, and is unset for dry
+	// runs
+	Revision int `json:"revision,omitempty"`
+}
+
+// HandleUpgradeReleaseChart upgrades a release to another version of its
+// chart. The values of the release are three-way merged with the defaults of
+// the current and new chart versions, and the keys that were removed or
+// renamed are reported.
+func (app *App) HandleUpgradeReleaseChart(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	form := &forms.UpgradeReleaseChartForm{
+		ReleaseForm: &forms.ReleaseForm{
+			Form: &helm.Form{
+				Repo:              app.Repo,
+				DigitalOceanOAuth: app.DOConf,
+			},
+		},
+		Name: name,
+	}
+
+	form.ReleaseForm.PopulateHelmOptionsFromQueryParams(
+		vals,
+		app.Repo.Cluster,
+	)
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	if form.Version == "" {
+		app.sendExternalError(fmt.Errorf("version is required"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"version is required"},
+		}, w)
+
+		return
+	}
+
+	agent, err := app.getAgentFromReleaseForm(
+		w,
+		r,
+		form.ReleaseForm,
+	)
+
+	// errors are handled in app.getAgentFromBodyParams
+	if err != nil {
+		return
+	}
+
+	current, err := agent.GetRelease(form.Name, 0)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return
+	}
+
+	newChart, err := app.loadReleaseUpgradeChart(uint(projID), current, form)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"chart not found: " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	if newChart.Metadata.Name != current.Chart.Metadata.Name {
+		app.sendExternalError(fmt.Errorf("chart mismatch"), http.StatusBadRequest, HTTPError{
+			Code: ErrReleaseValidateFields,
+			Errors: []string{fmt.Sprintf(
+				"release uses chart %s, not %s",
+				current.Chart.Metadata.Name,
+				newChart.Metadata.Name,
+			)},
+		}, w)
+
+		return
+	}
+
+	migration := helm.MigrateValues(current.Config, current.Chart.Values, newChart.Values)
+
+	res := &ReleaseChartUpgrade{
+		Name:           current.Name,
+		Chart:          current.Chart.Metadata.Name,
+		CurrentVersion: current.Chart.Metadata.Version,
+		TargetVersion:  newChart.Metadata.Version,
+		Migration:      migration,
+		Form:           releaseUpgradeForm(current, newChart, migration.Values),
+	}
+
+	registries, err := app.Repo.Registry.ListRegistriesByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	conf := &helm.UpgradeReleaseConfig{
+		Name:          form.Name,
+		Values:        migration.Values,
+		Cluster:       form.ReleaseForm.Cluster,
+		Repo:          *app.Repo,
+		Registries:    registries,
+		Chart:         newChart,
+		SecretBackend: app.SecretBackend,
+	}
+
+	if form.DryRun {
+		res.Diff, err = agent.DiffUpgradeRelease(conf, app.DOConf)
+
+		if err != nil {
+			app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+				Code:   ErrReleaseDeploy,
+				Errors: []string{"error rendering upgrade " + err.Error()},
+			}, w)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		}
+
+		return
+	}
+
+	conf.EnvGroups, err = app.releaseEnvGroups(
+		form.ReleaseForm.Cluster.ID,
+		form.ReleaseForm.Form.Namespace,
+		form.Name,
+	)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	deployment := app.newDeployment(
+		r,
+		form.ReleaseForm.Cluster,
+		form.ReleaseForm.Form.Namespace,
+		form.Name,
+		models.DeploymentActionUpgrade,
+	)
+
+	if !app.checkDeployPolicies(w, r, deployment) {
+		return
+	}
+
+	rel, err := agent.UpgradeReleaseByValues(conf, app.DOConf)
+
+	app.recordDeployment(deployment, rel, err)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{"error upgrading release " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	app.watchRollout(agent, deployment, rel)
+
+	res.Revision = rel.Version
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// loadReleaseUpgradeChart loads the version of a release's chart that the
+// release is upgraded to
+func (app *App) loadReleaseUpgradeChart(
+	projID uint,
+	current *release.Release,
+	form *forms.UpgradeReleaseChartForm,
+) (*chart.Chart, error) {
+	chartName := current.Chart.Metadata.Name
+
+	if form.HelmRepoID != 0 {
+		return app.loadHelmRepoChart(projID, strconv.FormatUint(uint64(form.HelmRepoID), 10), chartName, form.Version)
+	}
+
+	repoURL := form.RepoURL
+
+	if repoURL == "" {
+		if _, found := porterApplications[chartName]; found {
+			repoURL = app.ServerConf.DefaultApplicationHelmRepoURL
+		} else {
+			repoURL = app.ServerConf.DefaultAddonHelmRepoURL
+		}
+	}
+
+	return app.loadProjectChart(projID, repoURL, chartName, form.Version)
+}
+
+// releaseUpgradeForm parses the form.yaml of the new chart version, with the
+// values that the release will have after the upgrade. It is nil if the chart
+// has no form.
+func releaseUpgradeForm(
+	current *release.Release,
+	newChart *chart.Chart,
+	values map[string]interface{},
+) *models.FormYAML {
+	parserDef := &parser.ClientConfigDefault{
+		HelmChart: newChart,
+		HelmRelease: &release.Release{
+			Name:      current.Name,
+			Namespace: current.Namespace,
+			Chart:     newChart,
+			Config:    values,
+		},
+	}
+
+	for _, file := range newChart.Files {
+		if strings.Contains(file.Name, "form.yaml") {
+			formYAML, err := parser.FormYAMLFromBytes(parserDef, file.Data, "declared")
+
+			if err != nil {
+				return nil
+			}
+
+			return formYAML
+		}
+	}
+
+	return nil
+}
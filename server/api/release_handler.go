@@ -7,10 +7,12 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/porter-dev/porter/internal/kubernetes/prometheus"
 	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/templater/parser"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -23,6 +25,7 @@ import (
 	"github.com/porter-dev/porter/internal/integrations/ci/actions"
 	"github.com/porter-dev/porter/internal/kubernetes"
 	"github.com/porter-dev/porter/internal/repository"
+	"github.com/porter-dev/porter/server/requestlog"
 	segment "gopkg.in/segmentio/analytics-go.v3"
 	"gopkg.in/yaml.v2"
 )
@@ -33,6 +36,7 @@ const (
 	ErrReleaseValidateFields
 	ErrReleaseReadData
 	ErrReleaseDeploy
+	ErrReleaseDeployBlocked
 )
 
 // HandleListReleases retrieves a list of releases for a cluster
@@ -68,7 +72,23 @@ func (app *App) HandleListReleases(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(releases); err != nil {
+	// only return the releases in namespaces that the user's role bindings allow
+	bindings, err := app.getRoleBindingsFromRequest(r, form.Cluster.ProjectID)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	allowed := make([]*release.Release, 0)
+
+	for _, rel := range releases {
+		if bindings.Allows(form.Cluster.ID, rel.Namespace) {
+			allowed = append(allowed, rel)
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(allowed); err != nil {
 		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
 		return
 	}
@@ -706,47 +726,54 @@ func (app *App) HandleUpgradeRelease(w http.ResponseWriter, r *http.Request) {
 	}
 
 	conf := &helm.UpgradeReleaseConfig{
-		Name:       form.Name,
-		Cluster:    form.ReleaseForm.Cluster,
-		Repo:       *app.Repo,
-		Registries: registries,
+		Name:          form.Name,
+		Cluster:       form.ReleaseForm.Cluster,
+		Repo:          *app.Repo,
+		Registries:    registries,
+		SecretBackend: app.SecretBackend,
 	}
 
-	// if the chart version is set, load a chart from the repo
-	if form.ChartVersion != "" {
-		release, err := agent.GetRelease(form.Name, 0)
+	conf.Chart, err = app.getUpgradeChart(w, agent, form.Name, form.ChartVersion)
 
-		if err != nil {
-			app.sendExternalError(err, http.StatusNotFound, HTTPError{
-				Code:   ErrReleaseReadData,
-				Errors: []string{"release not found"},
-			}, w)
+	// errors are handled in app.getUpgradeChart
+	if err != nil {
+		return
+	}
 
-			return
-		}
+	conf.EnvGroups, err = app.releaseEnvGroups(
+		form.ReleaseForm.Cluster.ID,
+		form.ReleaseForm.Form.Namespace,
+		form.Name,
+	)
 
-		if _, found := porterApplications[release.Chart.Metadata.Name]; found {
-			chart, err := loader.LoadChartPublic(
-				app.ServerConf.DefaultApplicationHelmRepoURL,
-				release.Chart.Metadata.Name,
-				form.ChartVersion,
-			)
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
 
-			if err != nil {
-				app.sendExternalError(err, http.StatusNotFound, HTTPError{
-					Code:   ErrReleaseReadData,
-					Errors: []string{"chart not found"},
-				}, w)
+	deployment := app.newDeployment(
+		r,
+		form.ReleaseForm.Cluster,
+		form.ReleaseForm.Form.Namespace,
+		form.Name,
+		models.DeploymentActionUpgrade,
+	)
 
-				return
-			}
+	if !app.checkDeployPolicies(w, r, deployment) {
+		return
+	}
 
-			conf.Chart = chart
-		}
+	if form.Canary != nil {
+		app.startCanaryRollout(w, r, agent, conf, form, deployment)
+		return
 	}
 
+	prevRel, prevErr := agent.GetRelease(form.Name, 0)
+
 	rel, err := agent.UpgradeRelease(conf, form.Values, app.DOConf)
 
+	app.recordDeployment(deployment, rel, err)
+
 	if err != nil {
 		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
 			Code:   ErrReleaseDeploy,
@@ -756,6 +783,12 @@ func (app *App) HandleUpgradeRelease(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	app.watchRollout(agent, deployment, rel)
+
+	if prevErr == nil {
+		requestlog.RecordChange(r, prevRel.Config, rel.Config)
+	}
+
 	// update the github actions env if the release exists and is built from source
 	if cName := rel.Chart.Metadata.Name; cName == "job" || cName == "web" || cName == "worker" {
 		clusterID, err := strconv.ParseUint(vals["cluster_id"][0], 10, 64)
@@ -802,6 +835,7 @@ func (app *App) HandleUpgradeRelease(w http.ResponseWriter, r *http.Request) {
 					DockerFilePath: gitAction.DockerfilePath,
 					FolderPath:     gitAction.FolderPath,
 					ImageRepoURL:   gitAction.ImageRepoURI,
+					Previews:       gitAction.PreviewsEnabled,
 					BuildEnv:       cEnv.Container.Env.Normal,
 				}
 
@@ -836,6 +870,15 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// the webhook route has no project ID, so the project is read from the
+	// release for the audit log
+	requestlog.SetAuditTarget(r, &requestlog.AuditTarget{
+		ProjectID:    release.ProjectID,
+		Resource:     "releases",
+		ResourceName: release.Name,
+		Action:       "webhook_deploy",
+	})
+
 	params := map[string][]string{}
 	params["cluster_id"] = []string{fmt.Sprint(release.ClusterID)}
 	params["storage"] = []string{"secret"}
@@ -863,13 +906,9 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 		app.Repo.Cluster,
 	)
 
-	agent, err := app.getAgentFromReleaseForm(
-		w,
-		r,
-		form.ReleaseForm,
-	)
+	agent, err := app.getWebhookAgentFromReleaseForm(w, form.ReleaseForm)
 
-	// errors are handled in app.getAgentFromBodyParams
+	// errors are handled in app.getWebhookAgentFromReleaseForm
 	if err != nil {
 		return
 	}
@@ -888,6 +927,8 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 		repository = gitAction.ImageRepoURI
 	}
 
+	prevImage := rel.Config["image"]
+
 	image := map[string]interface{}{}
 	image["repository"] = repository
 	image["tag"] = commit
@@ -910,14 +951,39 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 	}
 
 	conf := &helm.UpgradeReleaseConfig{
-		Name:       form.Name,
-		Cluster:    form.ReleaseForm.Cluster,
-		Repo:       *app.Repo,
-		Registries: registries,
-		Values:     rel.Config,
+		Name:          form.Name,
+		Cluster:       form.ReleaseForm.Cluster,
+		Repo:          *app.Repo,
+		Registries:    registries,
+		Values:        rel.Config,
+		SecretBackend: app.SecretBackend,
+	}
+
+	conf.EnvGroups, err = app.releaseEnvGroups(release.ClusterID, release.Namespace, release.Name)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	deployment := &models.Deployment{
+		ProjectID:   release.ProjectID,
+		ClusterID:   release.ClusterID,
+		ReleaseName: release.Name,
+		Namespace:   release.Namespace,
+		CommitSHA:   commit,
+		Source:      models.DeploymentSourceWebhook,
+		Action:      models.DeploymentActionUpgrade,
+		StartedAt:   time.Now(),
 	}
 
-	_, err = agent.UpgradeReleaseByValues(conf, app.DOConf)
+	if !app.checkDeployPolicies(w, r, deployment) {
+		return
+	}
+
+	newRel, err := agent.UpgradeReleaseByValues(conf, app.DOConf)
+
+	app.recordDeployment(deployment, newRel, err)
 
 	if err != nil {
 		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
@@ -928,6 +994,14 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	app.watchRollout(agent, deployment, newRel)
+
+	requestlog.RecordChange(
+		r,
+		map[string]interface{}{"image": prevImage},
+		map[string]interface{}{"image": image},
+	)
+
 	client := *app.segmentClient
 	client.Enqueue(segment.Track{
 		UserId: "anonymous",
@@ -981,8 +1055,35 @@ func (app *App) HandleRollbackRelease(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	deployment := app.newDeployment(
+		r,
+		form.ReleaseForm.Cluster,
+		form.ReleaseForm.Form.Namespace,
+		form.Name,
+		models.DeploymentActionRollback,
+	)
+
+	if !app.checkDeployPolicies(w, r, deployment) {
+		return
+	}
+
+	prevRel, prevErr := agent.GetRelease(form.Name, 0)
+
 	err = agent.RollbackRelease(form.Name, form.Revision)
 
+	// the rollback creates a new revision, which is recorded as the deployment
+	var rolledBack *release.Release
+
+	if err == nil {
+		rolledBack, _ = agent.GetRelease(form.Name, 0)
+	}
+
+	if prevErr == nil && rolledBack != nil {
+		requestlog.RecordChange(r, prevRel.Config, rolledBack.Config)
+	}
+
+	app.recordDeployment(deployment, rolledBack, err)
+
 	if err != nil {
 		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
 			Code:   ErrReleaseDeploy,
@@ -1065,6 +1166,7 @@ func (app *App) HandleRollbackRelease(w http.ResponseWriter, r *http.Request) {
 					DockerFilePath: gitAction.DockerfilePath,
 					FolderPath:     gitAction.FolderPath,
 					ImageRepoURL:   gitAction.ImageRepoURI,
+					Previews:       gitAction.PreviewsEnabled,
 					BuildEnv:       cEnv.Container.Env.Normal,
 				}
 
@@ -1114,21 +1216,112 @@ func (app *App) getAgentFromQueryParams(
 	return app.getAgentFromReleaseForm(w, r, form)
 }
 
+// getUpgradeChart loads the chart at the given version if the release is a Porter
+// application. A nil chart is returned if the release chart should not change.
+func (app *App) getUpgradeChart(
+	w http.ResponseWriter,
+	agent *helm.Agent,
+	name, version string,
+) (*chart.Chart, error) {
+	if version == "" {
+		return nil, nil
+	}
+
+	release, err := agent.GetRelease(name, 0)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return nil, err
+	}
+
+	if _, found := porterApplications[release.Chart.Metadata.Name]; !found {
+		return nil, nil
+	}
+
+	ch, err := loader.LoadChartPublic(
+		app.ServerConf.DefaultApplicationHelmRepoURL,
+		release.Chart.Metadata.Name,
+		version,
+	)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"chart not found"},
+		}, w)
+
+		return nil, err
+	}
+
+	return ch, nil
+}
+
 // getAgentFromReleaseForm uses a non-validated form to construct a new Helm agent based on
 // the userID found in the session and the options required by the Helm agent.
+//
+// The namespace of the form may have been set from the request body after the
+// access middleware checked the namespace of the request, so it is checked
+// against the role bindings of the request again here.
 func (app *App) getAgentFromReleaseForm(
 	w http.ResponseWriter,
 	r *http.Request,
 	form *forms.ReleaseForm,
 ) (*helm.Agent, error) {
-	var err error
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return nil, err
+	}
+
+	bindings, err := app.getRoleBindingsFromRequest(r, form.Cluster.ProjectID)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return nil, err
+	}
+
+	allowed := bindings.Allows(form.Cluster.ID, form.Namespace)
+
+	// GET requests have no body, so an empty namespace is the one that the
+	// middleware allowed, which lists releases in every namespace for routes
+	// that filter their results
+	if form.Namespace == "" && r.Method == "GET" {
+		allowed = bindings.AllowsCluster(form.Cluster.ID)
+	}
+
+	if !allowed {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, fmt.Errorf("namespace %s is not allowed by the role bindings", form.Namespace)
+	}
+
+	return app.newAgentFromReleaseForm(w, form)
+}
 
+// getWebhookAgentFromReleaseForm constructs a new Helm agent from a form that
+// the server populated itself, for webhooks that aren't made by a user
+func (app *App) getWebhookAgentFromReleaseForm(
+	w http.ResponseWriter,
+	form *forms.ReleaseForm,
+) (*helm.Agent, error) {
 	// validate the form
 	if err := app.validator.Struct(form); err != nil {
 		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
 		return nil, err
 	}
 
+	return app.newAgentFromReleaseForm(w, form)
+}
+
+func (app *App) newAgentFromReleaseForm(
+	w http.ResponseWriter,
+	form *forms.ReleaseForm,
+) (*helm.Agent, error) {
+	var err error
+
 	// create a new agent
 	var agent *helm.Agent
 
@@ -1145,6 +1338,25 @@ func (app *App) getAgentFromReleaseForm(
 	return agent, err
 }
 
+// getClusterAgent constructs a Helm agent for a namespace of a cluster that is
+// not passed in the request, using secret storage for the releases
+func (app *App) getClusterAgent(
+	cluster *models.Cluster,
+	namespace string,
+) (*helm.Agent, error) {
+	if app.ServerConf.IsTesting {
+		return app.TestAgents.HelmAgent, nil
+	}
+
+	return helm.GetAgentOutOfClusterConfig(&helm.Form{
+		Cluster:           cluster,
+		Repo:              app.Repo,
+		DigitalOceanOAuth: app.DOConf,
+		Storage:           "secret",
+		Namespace:         namespace,
+	}, app.Logger)
+}
+
 const veleroForm string = `tags:
 - hello
 tabs:
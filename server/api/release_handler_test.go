@@ -2,6 +2,7 @@ package api_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -10,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/models"
 
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
@@ -322,7 +324,7 @@ var rollbackReleaseTests = []*releaseTest{
 		msg:       "Rollback release",
 		method:    "POST",
 		namespace: "default",
-		endpoint: "/api/projects/1/releases/wordpress/rollback?" + url.Values{
+		endpoint: "/api/projects/1/releases/wordpress/rollback/diff?" + url.Values{
 			"cluster_id": []string{"1"},
 		}.Encode(),
 		body: `
@@ -383,6 +385,52 @@ func TestRollbackRelease(t *testing.T) {
 	testReleaseRequests(t, rollbackReleaseTests, true)
 }
 
+func TestReleaseNamespaceFromBody(t *testing.T) {
+	cases := []struct {
+		msg       string
+		namespace string
+		expStatus int
+	}{
+		{"Body namespace allowed by binding", "default", http.StatusOK},
+		{"Body namespace not allowed by binding", "other", http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		tester := newTester(true)
+		initRoleMember(tester, models.RoleDeveloper)
+
+		tester.repo.Cluster.CreateCluster(&models.Cluster{
+			ProjectID: 1,
+			Name:      "cluster-test",
+			Server:    "https://10.10.10.10",
+		})
+
+		agent := tester.app.TestAgents.HelmAgent
+		makeReleases(agent, historyReleaseStubs)
+		agent.ActionConfig.Releases.Driver.(*driver.Memory).SetNamespace("default")
+
+		// the member can only access the namespace in the query
+		tester.repo.RoleBinding.CreateRoleBinding(&models.RoleBinding{
+			ProjectID: 1,
+			UserID:    1,
+			ClusterID: 1,
+			Namespace: "default",
+		})
+
+		endpoint := "/api/projects/1/releases/wordpress/rollback/diff?" + url.Values{
+			"cluster_id": []string{"1"},
+			"namespace":  []string{"default"},
+		}.Encode()
+
+		body := fmt.Sprintf(`{"namespace":"%s","storage":"memory","revision":1}`, c.namespace)
+
+		if rr := tester.send("POST", endpoint, body); rr.Code != c.expStatus {
+			t.Errorf("%s, handler returned wrong status code: got %v want %v",
+				c.msg, rr.Code, c.expStatus)
+		}
+	}
+}
+
 // ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
 
 func initDefaultReleases(tester *tester) {
@@ -0,0 +1,421 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// PromoteReleaseResponse is the result of promoting a release, or of rendering
+// the promotion without applying it
+type PromoteReleaseResponse struct {
+	TargetClusterID uint   `json:"target_cluster_id"`
+	TargetNamespace string `json:"target_namespace"`
+	TargetName      string `json:"target_name"`
+
+	// Created is true if the target release doesn't exist, and is installed by
+	// the promotion
+	Created bool `json:"created"`
+
+	// Diff is the change that the promotion makes to the target release, and
+	// is only set when the promotion is rendered
+	Diff *helm.ReleaseDiff `json:"diff,omitempty"`
+
+	// Revision is the revision of the target release that the promotion
+	// created, and is only set when the promotion is applied
+	Revision int `json:"revision,omitempty"`
+}
+
+// releasePromotion is a promotion of a source release to a target release
+// that has been read from a request
+type releasePromotion struct {
+	form       *forms.PromoteReleaseForm
+	source     *release.Release
+	cluster    *models.Cluster
+	agent      *helm.Agent
+	registries []*models.Registry
+
+	// target is nil if the target release doesn't exist
+	target *release.Release
+	name   string
+	values map[string]interface{}
+}
+
+func (p *releasePromotion) response() *PromoteReleaseResponse {
+	return &PromoteReleaseResponse{
+		TargetClusterID: p.cluster.ID,
+		TargetNamespace: p.form.TargetNamespace,
+		TargetName:      p.name,
+		Created:         p.target == nil,
+	}
+}
+
+// HandleDiffPromoteRelease renders the promotion of a release to another
+// cluster or namespace without applying it, and returns the changes to the
+// target release
+func (app *App) HandleDiffPromoteRelease(w http.ResponseWriter, r *http.Request) {
+	promotion, ok := app.readReleasePromotion(w, r)
+
+	if !ok {
+		return
+	}
+
+	var diff *helm.ReleaseDiff
+	var err error
+
+	if promotion.target == nil {
+		diff, err = promotion.agent.DiffInstallChart(&helm.InstallChartConfig{
+			Chart:      promotion.source.Chart,
+			Name:       promotion.name,
+			Namespace:  promotion.form.TargetNamespace,
+			Values:     promotion.values,
+			Cluster:    promotion.cluster,
+			Repo:       *app.Repo,
+			Registries: promotion.registries,
+		}, app.DOConf)
+	} else {
+		diff, err = promotion.agent.DiffUpgradeRelease(&helm.UpgradeReleaseConfig{
+			Name:       promotion.name,
+			Values:     promotion.values,
+			Cluster:    promotion.cluster,
+			Repo:       *app.Repo,
+			Registries: promotion.registries,
+			Chart:      promotion.source.Chart,
+		}, app.DOConf)
+	}
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{"error rendering promotion " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	resp := promotion.response()
+	resp.Diff = diff
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandlePromoteRelease applies the chart version, image tag and selected
+// values of a release to a release in another cluster or namespace, and
+// installs the target release if it doesn't exist
+func (app *App) HandlePromoteRelease(w http.ResponseWriter, r *http.Request) {
+	promotion, ok := app.readReleasePromotion(w, r)
+
+	if !ok {
+		return
+	}
+
+	action := models.DeploymentActionUpgrade
+
+	if promotion.target == nil {
+		action = models.DeploymentActionInstall
+	}
+
+	deployment := app.newDeployment(
+		r,
+		promotion.cluster,
+		promotion.form.TargetNamespace,
+		promotion.name,
+		action,
+	)
+
+	if !app.checkDeployPolicies(w, r, deployment) {
+		return
+	}
+
+	var rel *release.Release
+	var err error
+
+	if promotion.target == nil {
+		rel, err = app.installPromotedRelease(promotion, deployment)
+	} else {
+		rel, err = app.upgradePromotedRelease(promotion, deployment)
+	}
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{"error promoting release " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	app.watchRollout(promotion.agent, deployment, rel)
+
+	resp := promotion.response()
+	resp.Revision = rel.Version
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// installPromotedRelease installs a target release that doesn't exist, and
+// stores it with a webhook token the same way that HandleDeployTemplate does
+func (app *App) installPromotedRelease(
+	promotion *releasePromotion,
+	deployment *models.Deployment,
+) (*release.Release, error) {
+	if err := app.ensureNamespace(promotion.agent, promotion.form.TargetNamespace); err != nil {
+		app.recordDeployment(deployment, nil, err)
+		return nil, err
+	}
+
+	rel, err := promotion.agent.InstallChart(&helm.InstallChartConfig{
+		Chart:         promotion.source.Chart,
+		Name:          promotion.name,
+		Namespace:     promotion.form.TargetNamespace,
+		Values:        promotion.values,
+		Cluster:       promotion.cluster,
+		Repo:          *app.Repo,
+		Registries:    promotion.registries,
+		SecretBackend: app.SecretBackend,
+	}, app.DOConf)
+
+	if err != nil {
+		app.recordDeployment(deployment, nil, err)
+		return nil, err
+	}
+
+	token, err := repository.GenerateRandomBytes(16)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = app.Repo.Release.CreateRelease(&models.Release{
+		ClusterID:    promotion.cluster.ID,
+		ProjectID:    promotion.cluster.ProjectID,
+		Namespace:    promotion.form.TargetNamespace,
+		Name:         promotion.name,
+		WebhookToken: token,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// recorded once the release is stored, so that the deployment is linked to it
+	app.recordDeployment(deployment, rel, nil)
+
+	return rel, nil
+}
+
+// upgradePromotedRelease upgrades an existing target release to the chart and
+// the promoted values
+func (app *App) upgradePromotedRelease(
+	promotion *releasePromotion,
+	deployment *models.Deployment,
+) (*release.Release, error) {
+	envGroups, err := app.releaseEnvGroups(
+		promotion.cluster.ID,
+		promotion.form.TargetNamespace,
+		promotion.name,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rel, err := promotion.agent.UpgradeReleaseByValues(&helm.UpgradeReleaseConfig{
+		Name:          promotion.name,
+		Values:        promotion.values,
+		Cluster:       promotion.cluster,
+		Repo:          *app.Repo,
+		Registries:    promotion.registries,
+		Chart:         promotion.source.Chart,
+		EnvGroups:     envGroups,
+		SecretBackend: app.SecretBackend,
+	}, app.DOConf)
+
+	app.recordDeployment(deployment, rel, err)
+
+	return rel, err
+}
+
+// readReleasePromotion reads the source release of a promotion from the
+// release in the URL, and the target release from the request body, and writes
+// an error if either cannot be read. The target cluster must belong to the
+// project in the URL.
+func (app *App) readReleasePromotion(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*releasePromotion, bool) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return nil, false
+	}
+
+	form := &forms.PromoteReleaseForm{
+		ReleaseForm: &forms.ReleaseForm{
+			Form: &helm.Form{
+				Repo:              app.Repo,
+				DigitalOceanOAuth: app.DOConf,
+			},
+		},
+		Name: chi.URLParam(r, "name"),
+	}
+
+	form.ReleaseForm.PopulateHelmOptionsFromQueryParams(
+		vals,
+		app.Repo.Cluster,
+	)
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return nil, false
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return nil, false
+	}
+
+	sourceAgent, err := app.getAgentFromReleaseForm(
+		w,
+		r,
+		form.ReleaseForm,
+	)
+
+	// errors are handled in app.getAgentFromBodyParams
+	if err != nil {
+		return nil, false
+	}
+
+	promotion := &releasePromotion{
+		form: form,
+		name: form.TargetName,
+	}
+
+	if promotion.name == "" {
+		promotion.name = form.Name
+	}
+
+	promotion.source, err = sourceAgent.GetRelease(form.Name, 0)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return nil, false
+	}
+
+	promotion.cluster, err = app.Repo.Cluster.ReadCluster(form.TargetClusterID)
+
+	if err != nil || promotion.cluster.ProjectID != uint(projID) {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"target cluster not found in project"},
+		}, w)
+
+		return nil, false
+	}
+
+	// the access middleware only checks the source cluster, so the target is
+	// checked against the user's role bindings here
+	bindings, err := app.getRoleBindingsFromRequest(r, uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return nil, false
+	}
+
+	if !bindings.Allows(promotion.cluster.ID, form.TargetNamespace) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+
+	if promotion.cluster.ID == form.ReleaseForm.Cluster.ID &&
+		form.TargetNamespace == promotion.source.Namespace &&
+		promotion.name == promotion.source.Name {
+		app.sendExternalError(nil, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"a release cannot be promoted to itself"},
+		}, w)
+
+		return nil, false
+	}
+
+	promotion.agent, err = app.getClusterAgent(promotion.cluster, form.TargetNamespace)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return nil, false
+	}
+
+	promotion.target, err = promotion.agent.GetRelease(promotion.name, 0)
+
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		promotion.target = nil
+	} else if err != nil {
+		app.handleErrorInternal(err, w)
+		return nil, false
+	}
+
+	var targetValues map[string]interface{}
+
+	if promotion.target != nil {
+		targetValues = promotion.target.Config
+
+		// the target config is nil if the target was deployed with the
+		// chart defaults, which is not the same as a target that is created
+		if targetValues == nil {
+			targetValues = map[string]interface{}{}
+		}
+	}
+
+	promotion.values, err = helm.PromoteValues(promotion.source.Config, targetValues, form.Values)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return nil, false
+	}
+
+	promotion.registries, err = app.Repo.Registry.ListRegistriesByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return nil, false
+	}
+
+	return promotion, true
+}
@@ -0,0 +1,766 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/helm/loader"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"github.com/porter-dev/porter/server/requestlog"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// ReleaseSetDeployResponse is the result of deploying a release set, with the
+// status of the deploy on each of its targets
+type ReleaseSetDeployResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+
+	// Succeeded is true if no target failed
+	Succeeded bool                               `json:"succeeded"`
+	Targets   []*models.ReleaseSetTargetExternal `json:"targets"`
+}
+
+// HandleCreateReleaseSet creates a new release set for a project
+func (app *App) HandleCreateReleaseSet(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	set, ok := app.readReleaseSetForm(w, r, uint(projID))
+
+	if !ok {
+		return
+	}
+
+	set.WebhookToken, err = repository.GenerateRandomBytes(16)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	set, err = app.Repo.ReleaseSet.CreateReleaseSet(set)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	app.Logger.Info().Msgf("New release set created: %d", set.ID)
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(set.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleListReleaseSets returns the release sets of a project, along with the
+// status of the last deploy of each target
+func (app *App) HandleListReleaseSets(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	sets, err := app.Repo.ReleaseSet.ListReleaseSetsByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	extSets := make([]*models.ReleaseSetExternal, 0)
+
+	for _, set := range sets {
+		extSets = append(extSets, set.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(extSets); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleReadReleaseSet returns a release set, along with the status of the
+// last deploy of each target
+func (app *App) HandleReadReleaseSet(w http.ResponseWriter, r *http.Request) {
+	set, ok := app.readProjectReleaseSet(w, r)
+
+	if !ok {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(set.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleUpdateReleaseSet replaces the settings and targets of a release set.
+// Targets that the set already has keep the status of their last deploy.
+func (app *App) HandleUpdateReleaseSet(w http.ResponseWriter, r *http.Request) {
+	set, ok := app.readProjectReleaseSet(w, r)
+
+	if !ok {
+		return
+	}
+
+	newSet, ok := app.readReleaseSetForm(w, r, set.ProjectID)
+
+	if !ok {
+		return
+	}
+
+	for i := range set.Targets {
+		target := &set.Targets[i]
+
+		if newSet.Target(target.ClusterID, target.Namespace, target.ReleaseName) != nil {
+			continue
+		}
+
+		if err := app.Repo.ReleaseSet.DeleteReleaseSetTarget(target); err != nil {
+			app.handleErrorDataWrite(err, w)
+			return
+		}
+	}
+
+	targets := make([]models.ReleaseSetTarget, 0)
+
+	for _, newTarget := range newSet.Targets {
+		if target := set.Target(newTarget.ClusterID, newTarget.Namespace, newTarget.ReleaseName); target != nil {
+			targets = append(targets, *target)
+			continue
+		}
+
+		newTarget.ReleaseSetID = set.ID
+
+		target, err := app.Repo.ReleaseSet.CreateReleaseSetTarget(&newTarget)
+
+		if err != nil {
+			app.handleErrorDataWrite(err, w)
+			return
+		}
+
+		targets = append(targets, *target)
+	}
+
+	set.Name = newSet.Name
+	set.Strategy = newSet.Strategy
+	set.FailurePolicy = newSet.FailurePolicy
+
+	set, err := app.Repo.ReleaseSet.UpdateReleaseSet(set)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	set.Targets = targets
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(set.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleDeleteReleaseSet deletes a release set. The releases that it deploys
+// are not modified.
+func (app *App) HandleDeleteReleaseSet(w http.ResponseWriter, r *http.Request) {
+	set, ok := app.readProjectReleaseSet(w, r)
+
+	if !ok {
+		return
+	}
+
+	if err := app.Repo.ReleaseSet.DeleteReleaseSet(set); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleDeployReleaseSet deploys every target of a release set with the same
+// values, in sequence or in parallel depending on the set's strategy, and
+// returns the status of each target
+func (app *App) HandleDeployReleaseSet(w http.ResponseWriter, r *http.Request) {
+	set, ok := app.readProjectReleaseSet(w, r)
+
+	if !ok {
+		return
+	}
+
+	form := &forms.DeployReleaseSetForm{}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	if form.Values == "" && form.ImageTag == "" && form.ChartVersion == "" {
+		app.sendExternalError(nil, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"values, image tag or chart version must be set"},
+		}, w)
+
+		return
+	}
+
+	if _, err := chartutil.ReadValues([]byte(form.Values)); err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"values could not be parsed: " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	// the access middleware only checks the project, so every target is
+	// checked against the user's role bindings here
+	bindings, err := app.getRoleBindingsFromRequest(r, set.ProjectID)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	for _, target := range set.Targets {
+		if !bindings.Allows(target.ClusterID, target.Namespace) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+	}
+
+	override, err := app.getDeployOverride(r, set.ProjectID)
+
+	if err != nil {
+		app.sendDeployOverrideError(err, w)
+		return
+	}
+
+	succeeded, err := app.deployReleaseSet(&releaseSetDeploy{
+		set:      set,
+		form:     form,
+		override: override,
+		newDeployment: func(cluster *models.Cluster, target *models.ReleaseSetTarget, action string) *models.Deployment {
+			return app.newDeployment(r, cluster, target.Namespace, target.ReleaseName, action)
+		},
+	})
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(&ReleaseSetDeployResponse{
+		ID:        set.ID,
+		Name:      set.Name,
+		Succeeded: succeeded,
+		Targets:   set.Externalize().Targets,
+	}); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleReleaseSetDeployWebhook deploys every target of a release set with the
+// image tag of a commit when the set's webhook is called. Targets that have
+// deploys through webhooks disabled are skipped.
+func (app *App) HandleReleaseSetDeployWebhook(w http.ResponseWriter, r *http.Request) {
+	set, err := app.Repo.ReleaseSet.ReadReleaseSetByWebhookToken(chi.URLParam(r, "token"))
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release set not found with given webhook"},
+		}, w)
+
+		return
+	}
+
+	// the webhook route has no project ID, so the project is read from the
+	// release set for the audit log
+	requestlog.SetAuditTarget(r, &requestlog.AuditTarget{
+		ProjectID:    set.ProjectID,
+		Resource:     "release_sets",
+		ResourceName: set.Name,
+		Action:       "webhook_deploy",
+	})
+
+	commit := r.URL.Query().Get("commit")
+
+	if commit == "" {
+		app.sendExternalError(nil, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"commit must be set"},
+		}, w)
+
+		return
+	}
+
+	succeeded, err := app.deployReleaseSet(&releaseSetDeploy{
+		set:               set,
+		form:              &forms.DeployReleaseSetForm{ImageTag: commit},
+		requireAutoDeploy: true,
+		newDeployment: func(cluster *models.Cluster, target *models.ReleaseSetTarget, action string) *models.Deployment {
+			return &models.Deployment{
+				ProjectID:   cluster.ProjectID,
+				ClusterID:   cluster.ID,
+				ReleaseName: target.ReleaseName,
+				Namespace:   target.Namespace,
+				CommitSHA:   commit,
+				Source:      models.DeploymentSourceWebhook,
+				Action:      action,
+				StartedAt:   time.Now(),
+			}
+		},
+	})
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	if !succeeded {
+		errors := make([]string, 0)
+
+		for _, target := range set.Targets {
+			if target.Status != models.ReleaseSetTargetStatusDeployed {
+				errors = append(errors, fmt.Sprintf(
+					"%s/%s in cluster %d %s: %s",
+					target.Namespace,
+					target.ReleaseName,
+					target.ClusterID,
+					target.Status,
+					target.Error,
+				))
+			}
+		}
+
+		app.sendExternalError(
+			fmt.Errorf("release set %d failed to deploy", set.ID),
+			http.StatusInternalServerError,
+			HTTPError{
+				Code:   ErrReleaseDeploy,
+				Errors: errors,
+			},
+			w,
+		)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// releaseSetDeploy is a deploy of every target of a release set
+type releaseSetDeploy struct {
+	set  *models.ReleaseSet
+	form *forms.DeployReleaseSetForm
+
+	// override is the reason given for deploying targets that deploy policies
+	// block, which are failed if it is empty
+	override string
+
+	// requireAutoDeploy skips targets that have deploys through webhooks
+	// disabled
+	requireAutoDeploy bool
+
+	newDeployment func(cluster *models.Cluster, target *models.ReleaseSetTarget, action string) *models.Deployment
+	registries    []*models.Registry
+}
+
+// releaseSetTargetResult is the result of deploying a single target
+type releaseSetTargetResult struct {
+	status string
+	err    error
+
+	// agent, cluster and previous are set once the target has been deployed,
+	// so that it can be rolled back to the previous revision
+	agent    *helm.Agent
+	cluster  *models.Cluster
+	previous int
+	revision int
+}
+
+// deployReleaseSet deploys the targets of a release set according to its
+// strategy and failure policy, and stores the status of each target. It
+// returns false if any target failed.
+func (app *App) deployReleaseSet(d *releaseSetDeploy) (bool, error) {
+	var err error
+
+	d.registries, err = app.Repo.Registry.ListRegistriesByProjectID(d.set.ProjectID)
+
+	if err != nil {
+		return false, err
+	}
+
+	targets := d.set.Targets
+	results := make([]*releaseSetTargetResult, len(targets))
+
+	if d.set.Strategy == models.ReleaseSetStrategyParallel {
+		var wg sync.WaitGroup
+
+		for i := range targets {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+				results[i] = app.deployReleaseSetTarget(d, &targets[i])
+			}(i)
+		}
+
+		wg.Wait()
+	} else {
+		for i := range targets {
+			results[i] = app.deployReleaseSetTarget(d, &targets[i])
+
+			if results[i].status != models.ReleaseSetTargetStatusFailed ||
+				d.set.FailurePolicy == models.ReleaseSetFailureContinue {
+				continue
+			}
+
+			for j := i + 1; j < len(targets); j++ {
+				results[j] = &releaseSetTargetResult{
+					status: models.ReleaseSetTargetStatusSkipped,
+					err:    fmt.Errorf("skipped after %s failed", targets[i].ReleaseName),
+				}
+			}
+
+			break
+		}
+	}
+
+	succeeded := true
+
+	for _, result := range results {
+		if result.status == models.ReleaseSetTargetStatusFailed {
+			succeeded = false
+		}
+	}
+
+	if !succeeded && d.set.FailurePolicy == models.ReleaseSetFailureRollback {
+		for i, result := range results {
+			if result.status == models.ReleaseSetTargetStatusDeployed {
+				app.rollbackReleaseSetTarget(d, &targets[i], result)
+			}
+		}
+	}
+
+	now := time.Now()
+
+	for i, result := range results {
+		target := &targets[i]
+		target.Status = result.status
+		target.Error = ""
+
+		if result.err != nil {
+			target.Error = result.err.Error()
+		}
+
+		if result.revision != 0 {
+			target.Revision = result.revision
+		}
+
+		if result.status == models.ReleaseSetTargetStatusDeployed {
+			target.DeployedAt = now
+		}
+
+		// the deploy has already happened, so a status that can't be stored
+		// is only logged
+		if _, err := app.Repo.ReleaseSet.UpdateReleaseSetTarget(target); err != nil {
+			app.Logger.Warn().Err(err).Msgf(
+				"could not update status of release set %d target %d",
+				d.set.ID,
+				target.ID,
+			)
+		}
+	}
+
+	return succeeded, nil
+}
+
+// deployReleaseSetTarget upgrades the release of a single target with the
+// values of the deploy, and records the deployment
+func (app *App) deployReleaseSetTarget(
+	d *releaseSetDeploy,
+	target *models.ReleaseSetTarget,
+) *releaseSetTargetResult {
+	failed := func(err error) *releaseSetTargetResult {
+		return &releaseSetTargetResult{
+			status: models.ReleaseSetTargetStatusFailed,
+			err:    err,
+		}
+	}
+
+	cluster, err := app.Repo.Cluster.ReadCluster(target.ClusterID)
+
+	if err != nil || cluster.ProjectID != d.set.ProjectID {
+		return failed(fmt.Errorf("cluster not found in project"))
+	}
+
+	agent, err := app.getClusterAgent(cluster, target.Namespace)
+
+	if err != nil {
+		return failed(err)
+	}
+
+	current, err := agent.GetRelease(target.ReleaseName, 0)
+
+	if err != nil {
+		return failed(fmt.Errorf("release not found: %v", err))
+	}
+
+	if d.requireAutoDeploy && current.Config["auto_deploy"] == false {
+		return &releaseSetTargetResult{
+			status: models.ReleaseSetTargetStatusSkipped,
+			err:    fmt.Errorf("deploy webhook is disabled for this release"),
+		}
+	}
+
+	values, err := releaseSetTargetValues(d.form, current)
+
+	if err != nil {
+		return failed(err)
+	}
+
+	conf := &helm.UpgradeReleaseConfig{
+		Name:          target.ReleaseName,
+		Values:        values,
+		Cluster:       cluster,
+		Repo:          *app.Repo,
+		Registries:    d.registries,
+		SecretBackend: app.SecretBackend,
+	}
+
+	if _, found := porterApplications[current.Chart.Metadata.Name]; found && d.form.ChartVersion != "" {
+		conf.Chart, err = loader.LoadChartPublic(
+			app.ServerConf.DefaultApplicationHelmRepoURL,
+			current.Chart.Metadata.Name,
+			d.form.ChartVersion,
+		)
+
+		if err != nil {
+			return failed(fmt.Errorf("chart not found: %v", err))
+		}
+	}
+
+	conf.EnvGroups, err = app.releaseEnvGroups(cluster.ID, target.Namespace, target.ReleaseName)
+
+	if err != nil {
+		return failed(err)
+	}
+
+	deployment := d.newDeployment(cluster, target, models.DeploymentActionUpgrade)
+
+	if err := app.enforceDeployPolicies(deployment, d.override); err != nil {
+		return failed(err)
+	}
+
+	rel, err := agent.UpgradeReleaseByValues(conf, app.DOConf)
+
+	app.recordDeployment(deployment, rel, err)
+
+	if err != nil {
+		return failed(err)
+	}
+
+	app.watchRollout(agent, deployment, rel)
+
+	return &releaseSetTargetResult{
+		status:   models.ReleaseSetTargetStatusDeployed,
+		agent:    agent,
+		cluster:  cluster,
+		previous: current.Version,
+		revision: rel.Version,
+	}
+}
+
+// rollbackReleaseSetTarget rolls a deployed target back to the revision it had
+// before the deploy, and records the rollback as a deployment
+func (app *App) rollbackReleaseSetTarget(
+	d *releaseSetDeploy,
+	target *models.ReleaseSetTarget,
+	result *releaseSetTargetResult,
+) {
+	deployment := d.newDeployment(result.cluster, target, models.DeploymentActionRollback)
+
+	err := result.agent.RollbackRelease(target.ReleaseName, result.previous)
+
+	var rel *release.Release
+
+	if err == nil {
+		rel, err = result.agent.GetRelease(target.ReleaseName, 0)
+	}
+
+	app.recordDeployment(deployment, rel, err)
+
+	if err != nil {
+		result.err = fmt.Errorf("rollback to revision %d failed: %v", result.previous, err)
+		return
+	}
+
+	result.status = models.ReleaseSetTargetStatusRolledBack
+	result.err = fmt.Errorf("rolled back to revision %d after another target failed", result.previous)
+	result.revision = rel.Version
+}
+
+// releaseSetTargetValues returns the values that a target is deployed with:
+// the values of the deploy if they are set, or the current values of the
+// release otherwise, with the image tag of the deploy
+func releaseSetTargetValues(
+	form *forms.DeployReleaseSetForm,
+	current *release.Release,
+) (map[string]interface{}, error) {
+	values := current.Config
+
+	if form.Values != "" {
+		// the values are read for each target, since they are modified when
+		// they are deployed
+		parsed, err := chartutil.ReadValues([]byte(form.Values))
+
+		if err != nil {
+			return nil, err
+		}
+
+		values = parsed.AsMap()
+	}
+
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+
+	if form.ImageTag != "" {
+		image, ok := values["image"].(map[string]interface{})
+
+		if !ok {
+			image = map[string]interface{}{}
+			values["image"] = image
+		}
+
+		image["tag"] = form.ImageTag
+	}
+
+	return values, nil
+}
+
+// readReleaseSetForm decodes and validates a release set in the request body,
+// and writes an error if the set is invalid, deploys a release in a cluster
+// outside of the project, or deploys a release that the user can't access
+func (app *App) readReleaseSetForm(
+	w http.ResponseWriter,
+	r *http.Request,
+	projectID uint,
+) (*models.ReleaseSet, bool) {
+	form := &forms.ReleaseSetForm{}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return nil, false
+	}
+
+	set, err := form.ToReleaseSet(projectID)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return nil, false
+	}
+
+	bindings, err := app.getRoleBindingsFromRequest(r, projectID)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return nil, false
+	}
+
+	for _, target := range set.Targets {
+		cluster, err := app.Repo.Cluster.ReadCluster(target.ClusterID)
+
+		if err != nil || cluster.ProjectID != projectID {
+			app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+				Code:   ErrProjectValidateFields,
+				Errors: []string{fmt.Sprintf("cluster %d not found in project", target.ClusterID)},
+			}, w)
+
+			return nil, false
+		}
+
+		if !bindings.Allows(target.ClusterID, target.Namespace) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return nil, false
+		}
+	}
+
+	return set, true
+}
+
+// readProjectReleaseSet reads the release set in the URL, and writes an error
+// if it cannot be read or does not belong to the project in the URL
+func (app *App) readProjectReleaseSet(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*models.ReleaseSet, bool) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(chi.URLParam(r, "release_set_id"), 0, 64)
+
+	if err != nil || id == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	set, err := app.Repo.ReleaseSet.ReadReleaseSet(uint(id))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return nil, false
+	}
+
+	if set.ProjectID != uint(projID) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+
+	return set, true
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// HandleCreateRoleBinding scopes a project member to a cluster, and optionally
+// to a namespace within that cluster
+func (app *App) HandleCreateRoleBinding(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	form := &forms.CreateRoleBindingForm{
+		ProjectID: uint(projID),
+	}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	// a binding applies to exactly one user or API token
+	if (form.UserID == 0) == (form.APITokenID == 0) {
+		app.sendExternalError(fmt.Errorf("invalid binding subject"), http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{"exactly one of user_id and api_token_id must be set"},
+		}, w)
+
+		return
+	}
+
+	if form.UserID != 0 {
+		// the user must be a member of the project
+		if _, err := app.Repo.Project.ReadProjectRole(form.ProjectID, form.UserID); err != nil {
+			app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+				Code:   ErrProjectValidateFields,
+				Errors: []string{"user is not a member of the project"},
+			}, w)
+
+			return
+		}
+	} else {
+		// the token must belong to the project
+		apiToken, err := app.Repo.APIToken.ReadAPIToken(form.APITokenID)
+
+		if err != nil || apiToken.ProjectID != form.ProjectID {
+			app.sendExternalError(fmt.Errorf("api token %d not in project", form.APITokenID), http.StatusBadRequest, HTTPError{
+				Code:   ErrProjectValidateFields,
+				Errors: []string{"api token does not belong to the project"},
+			}, w)
+
+			return
+		}
+	}
+
+	// the cluster must belong to the project
+	cluster, err := app.Repo.Cluster.ReadCluster(form.ClusterID)
+
+	if err != nil || cluster.ProjectID != form.ProjectID {
+		app.sendExternalError(fmt.Errorf("cluster %d not in project", form.ClusterID), http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{"cluster does not belong to the project"},
+		}, w)
+
+		return
+	}
+
+	rb, err := form.ToRoleBinding()
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	rb, err = app.Repo.RoleBinding.CreateRoleBinding(rb)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	app.Logger.Info().Msgf("New role binding created: %d", rb.ID)
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(rb.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleListRoleBindings returns a list of role bindings for a project
+func (app *App) HandleListRoleBindings(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	rbs, err := app.Repo.RoleBinding.ListRoleBindingsByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	extRBs := make([]*models.RoleBindingExternal, 0)
+
+	for _, rb := range rbs {
+		extRBs = append(extRBs, rb.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(extRBs); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleDeleteRoleBinding deletes a role binding via the binding ID
+func (app *App) HandleDeleteRoleBinding(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	id, err := strconv.ParseUint(chi.URLParam(r, "binding_id"), 0, 64)
+
+	if err != nil || id == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	rb, err := app.Repo.RoleBinding.ReadRoleBinding(uint(id))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	if rb.ProjectID != uint(projID) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	if err := app.Repo.RoleBinding.DeleteRoleBinding(rb); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// getRoleBindingsFromRequest returns the role bindings of the requesting user
+// in the project, along with the bindings of the API token that the request
+// was made with
+func (app *App) getRoleBindingsFromRequest(
+	r *http.Request,
+	projID uint,
+) (*models.SubjectRoleBindings, error) {
+	userID, err := app.getUserIDFromRequest(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &models.SubjectRoleBindings{}
+
+	res.User, err = app.Repo.RoleBinding.ListRoleBindingsByUserID(projID, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := app.getTokenFromRequest(r); tok != nil && tok.TokenID != "" {
+		apiToken, err := app.Repo.APIToken.ReadAPITokenByUniqueID(tok.TokenID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res.APIToken, err = app.Repo.RoleBinding.ListRoleBindingsByAPITokenID(projID, apiToken.ID)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
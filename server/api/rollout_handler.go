@@ -0,0 +1,288 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/models"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// HandleUpdateRolloutPolicy sets how long the controllers of a release are
+// given to roll out after each deploy, and whether a failed rollout is rolled
+// back automatically
+func (app *App) HandleUpdateRolloutPolicy(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	clusterID, err := strconv.ParseUint(vals.Get("cluster_id"), 10, 64)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	form := &forms.RolloutPolicyForm{}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return
+	}
+
+	rel, err := app.Repo.Release.ReadRelease(uint(clusterID), name, vals.Get("namespace"))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	rel.RolloutDeadlineSeconds = form.RolloutDeadlineSeconds
+	rel.AutoRollback = form.AutoRollback
+
+	rel, err = app.Repo.Release.UpdateRelease(rel)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(rel.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// watchRollout watches the rollout of a release that was deployed, if the
+// release has a rollout deadline. The deployment is kept as progressing until
+// the controllers of the release have rolled out, and is marked as failed if
+// they don't before the deadline.
+func (app *App) watchRollout(agent *helm.Agent, deployment *models.Deployment, rel *release.Release) {
+	if deployment.ID == 0 || deployment.ReleaseID == 0 || agent.K8sAgent == nil {
+		return
+	}
+
+	stored, err := app.Repo.Release.ReadRelease(
+		deployment.ClusterID,
+		deployment.ReleaseName,
+		deployment.Namespace,
+	)
+
+	if err != nil || stored.RolloutDeadlineSeconds == 0 {
+		return
+	}
+
+	controllers, err := helm.ReleaseControllers(rel.Manifest)
+
+	if err != nil || len(controllers) == 0 {
+		return
+	}
+
+	deployment.Status = models.DeploymentStatusProgressing
+
+	if _, err := app.Repo.Deployment.UpdateDeployment(deployment); err != nil {
+		app.Logger.Warn().Err(err).Msgf(
+			"could not update deployment of release %s",
+			deployment.ReleaseName,
+		)
+
+		return
+	}
+
+	deadline := time.Duration(stored.RolloutDeadlineSeconds) * time.Second
+
+	go app.finishRollout(agent, deployment, stored, rel, controllers, deadline)
+}
+
+// finishRollout waits for the controllers of a progressing deployment to roll
+// out, and stores whether they did before the deadline
+func (app *App) finishRollout(
+	agent *helm.Agent,
+	deployment *models.Deployment,
+	stored *models.Release,
+	rel *release.Release,
+	controllers []kubernetes.ControllerRef,
+	deadline time.Duration,
+) {
+	rolloutErr := agent.K8sAgent.WatchRollout(rel.Namespace, controllers, deadline)
+
+	deployment.FinishedAt = time.Now()
+	deployment.Status = models.DeploymentStatusSucceeded
+
+	if rolloutErr != nil {
+		deployment.Status = models.DeploymentStatusFailed
+		deployment.Error = fmt.Sprintf("rollout failed: %s", rolloutErr.Error())
+	}
+
+	if _, err := app.Repo.Deployment.UpdateDeployment(deployment); err != nil {
+		app.Logger.Warn().Err(err).Msgf(
+			"could not update deployment of release %s",
+			deployment.ReleaseName,
+		)
+	}
+
+	if rolloutErr == nil {
+		return
+	}
+
+	app.notifyDeployment(deployment)
+
+	if stored.AutoRollback {
+		app.rollbackFailedRollout(agent, deployment, rel)
+	}
+}
+
+// RecoverRollouts finishes the deployments that were progressing when the
+// server stopped. Rollouts are watched in memory, so a deployment whose
+// deadline has passed is marked as failed, and the rest are watched again for
+// the time that is left before their deadline.
+func (app *App) RecoverRollouts() {
+	deployments, err := app.Repo.Deployment.ListDeploymentsByStatus(models.DeploymentStatusProgressing)
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msg("could not list progressing deployments")
+		return
+	}
+
+	for _, deployment := range deployments {
+		app.recoverRollout(deployment)
+	}
+}
+
+func (app *App) recoverRollout(deployment *models.Deployment) {
+	stored, err := app.Repo.Release.ReadRelease(
+		deployment.ClusterID,
+		deployment.ReleaseName,
+		deployment.Namespace,
+	)
+
+	if err != nil {
+		app.failUnwatchedRollout(deployment, time.Now())
+		return
+	}
+
+	// the rollout is watched from the time that the deploy finished
+	deadline := deployment.FinishedAt.Add(time.Duration(stored.RolloutDeadlineSeconds) * time.Second)
+
+	if time.Now().After(deadline) {
+		app.failUnwatchedRollout(deployment, deadline)
+		return
+	}
+
+	cluster, err := app.Repo.Cluster.ReadCluster(deployment.ClusterID)
+
+	if err != nil {
+		app.failUnwatchedRollout(deployment, time.Now())
+		return
+	}
+
+	agent, err := app.getClusterAgent(cluster, deployment.Namespace)
+
+	if err != nil || agent.K8sAgent == nil {
+		app.failUnwatchedRollout(deployment, time.Now())
+		return
+	}
+
+	rel, err := agent.GetRelease(deployment.ReleaseName, deployment.Revision)
+
+	if err != nil {
+		app.failUnwatchedRollout(deployment, time.Now())
+		return
+	}
+
+	controllers, err := helm.ReleaseControllers(rel.Manifest)
+
+	if err != nil || len(controllers) == 0 {
+		app.failUnwatchedRollout(deployment, time.Now())
+		return
+	}
+
+	go app.finishRollout(agent, deployment, stored, rel, controllers, time.Until(deadline))
+}
+
+// failUnwatchedRollout marks a progressing deployment as failed when its
+// rollout can't be watched to the end
+func (app *App) failUnwatchedRollout(deployment *models.Deployment, finishedAt time.Time) {
+	deployment.FinishedAt = finishedAt
+	deployment.Status = models.DeploymentStatusFailed
+	deployment.Error = "rollout failed: the server restarted before the rollout could be watched to the end"
+
+	if _, err := app.Repo.Deployment.UpdateDeployment(deployment); err != nil {
+		app.Logger.Warn().Err(err).Msgf(
+			"could not update deployment of release %s",
+			deployment.ReleaseName,
+		)
+
+		return
+	}
+
+	app.notifyDeployment(deployment)
+}
+
+// rollbackFailedRollout rolls a release back to the revision before the one
+// that failed to roll out, unless the release has been deployed again since
+func (app *App) rollbackFailedRollout(agent *helm.Agent, failed *models.Deployment, rel *release.Release) {
+	current, err := agent.GetRelease(rel.Name, 0)
+
+	if err != nil || current.Version != rel.Version {
+		app.Logger.Info().Msgf(
+			"not rolling back release %s, revision %d is no longer the latest",
+			rel.Name,
+			rel.Version,
+		)
+
+		return
+	}
+
+	history, err := agent.GetReleaseHistory(rel.Name)
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msgf("could not read history of release %s", rel.Name)
+		return
+	}
+
+	revision := helm.PreviousRevision(history, rel.Version)
+
+	if revision == 0 {
+		return
+	}
+
+	deployment := &models.Deployment{
+		ProjectID:   failed.ProjectID,
+		ClusterID:   failed.ClusterID,
+		ReleaseName: failed.ReleaseName,
+		Namespace:   failed.Namespace,
+		Source:      failed.Source,
+		Action:      models.DeploymentActionRollback,
+		UserID:      failed.UserID,
+		StartedAt:   time.Now(),
+	}
+
+	err = agent.RollbackRelease(rel.Name, revision)
+
+	rolledBack, _ := agent.GetRelease(rel.Name, 0)
+
+	app.recordDeployment(deployment, rolledBack, err)
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/secrets"
+)
+
+// HandleListProjectSecrets returns the names of the secrets in a project's
+// secret store. Secret values are never returned.
+func (app *App) HandleListProjectSecrets(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	names, err := app.SecretBackend.List(uint(projID))
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	extSecrets := make([]*models.ProjectSecretExternal, 0)
+
+	for _, name := range names {
+		extSecrets = append(extSecrets, &models.ProjectSecretExternal{
+			Name: name,
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(extSecrets); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleWriteProjectSecret creates a secret in a project's secret store, or
+// replaces its value. Releases that reference the secret read the new value
+// the next time they are deployed.
+func (app *App) HandleWriteProjectSecret(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	form := &forms.WriteProjectSecretForm{}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	if err := secrets.ValidateName(form.Name); err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	if err := app.SecretBackend.Put(uint(projID), form.Name, []byte(form.Value)); err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(&models.ProjectSecretExternal{Name: form.Name}); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleDeleteProjectSecret deletes a secret from a project's secret store.
+// Releases that still reference the secret fail to deploy until the reference
+// is removed.
+func (app *App) HandleDeleteProjectSecret(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	err = app.SecretBackend.Delete(uint(projID), chi.URLParam(r, "name"))
+
+	if err == secrets.ErrNotFound {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrProjectDataRead,
+			Errors: []string{"secret not found"},
+		}, w)
+
+		return
+	} else if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -2,14 +2,17 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi"
 	"github.com/porter-dev/porter/internal/forms"
 	"github.com/porter-dev/porter/internal/helm/loader"
 	"github.com/porter-dev/porter/internal/templater/parser"
+	"helm.sh/helm/v3/pkg/chart"
 
 	"github.com/porter-dev/porter/internal/models"
 )
@@ -44,6 +47,87 @@ func (app *App) HandleListTemplates(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(porterCharts)
 }
 
+// HandleRefreshTemplates drops the cached index file of a template repo, and
+// lists the templates of the repo's current index file. Only the default
+// template repos and the helm repos of the project can be refreshed.
+func (app *App) HandleRefreshTemplates(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	repoURL := app.ServerConf.DefaultApplicationHelmRepoURL
+
+	if inputRepoURL, ok := vals["repo_url"]; ok && len(inputRepoURL) == 1 {
+		repoURL = inputRepoURL[0]
+	}
+
+	if ok, err := app.isProjectTemplateRepo(uint(projID), repoURL); err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	} else if !ok {
+		app.sendExternalError(fmt.Errorf("repo %s is not a template repo of project %d", repoURL, projID), http.StatusForbidden, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{"only the default template repos and the project's helm repos can be refreshed"},
+		}, w)
+
+		return
+	}
+
+	if loader.DefaultChartCache != nil {
+		loader.DefaultChartCache.Refresh(repoURL)
+	}
+
+	repoIndex, err := loader.LoadRepoIndexPublic(repoURL)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	porterCharts := loader.RepoIndexToPorterChartList(repoIndex)
+
+	json.NewEncoder(w).Encode(porterCharts)
+}
+
+// isProjectTemplateRepo returns true if the repo URL is one of the default
+// template repos, or the URL of one of the project's helm repos
+func (app *App) isProjectTemplateRepo(projID uint, repoURL string) (bool, error) {
+	repoURL = strings.TrimSuffix(repoURL, "/")
+
+	for _, defaultURL := range []string{
+		app.ServerConf.DefaultApplicationHelmRepoURL,
+		app.ServerConf.DefaultAddonHelmRepoURL,
+	} {
+		if defaultURL != "" && repoURL == strings.TrimSuffix(defaultURL, "/") {
+			return true, nil
+		}
+	}
+
+	hrs, err := app.Repo.HelmRepo.ListHelmReposByProjectID(projID)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, hr := range hrs {
+		if repoURL == strings.TrimSuffix(hr.RepoURL, "/") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // HandleReadTemplate reads a given template with name and version field
 func (app *App) HandleReadTemplate(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
@@ -77,6 +161,11 @@ func (app *App) HandleReadTemplate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	json.NewEncoder(w).Encode(porterChartRead(chart))
+}
+
+// porterChartRead reads the metadata, values, form and README of a chart
+func porterChartRead(chart *chart.Chart) *models.PorterChartRead {
 	parserDef := &parser.ClientConfigDefault{
 		HelmChart: chart,
 	}
@@ -99,5 +188,5 @@ func (app *App) HandleReadTemplate(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	json.NewEncoder(w).Encode(res)
+	return res
 }
@@ -92,6 +92,43 @@ func testTemplatesRequests(t *testing.T, tests []*templateTest, canQuery bool) {
 
 // ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
 
+var refreshTemplatesTests = []*templateTest{
+	&templateTest{
+		initializers: []func(tester *tester){
+			func(tester *tester) { initRoleMember(tester, models.RoleAdmin) },
+		},
+		msg:       "Refresh repo of another project",
+		method:    "POST",
+		endpoint:  "/api/projects/1/templates/refresh?repo_url=https://charts.example.com",
+		body:      "",
+		expStatus: http.StatusForbidden,
+		expBody:   `{"code":601,"errors":["only the default template repos and the project's helm repos can be refreshed"]}`,
+		useCookie: true,
+		validators: []func(c *templateTest, tester *tester, t *testing.T){
+			func(c *templateTest, tester *tester, t *testing.T) {
+				if body := tester.rr.Body.String(); strings.TrimSpace(body) != c.expBody {
+					t.Errorf("%s, handler returned wrong body: got %v want %v", c.msg, body, c.expBody)
+				}
+			},
+		},
+	},
+	&templateTest{
+		initializers: []func(tester *tester){
+			func(tester *tester) { initRoleMember(tester, models.RoleViewer) },
+		},
+		msg:       "Refresh as viewer",
+		method:    "POST",
+		endpoint:  "/api/projects/1/templates/refresh",
+		body:      "",
+		expStatus: http.StatusForbidden,
+		useCookie: true,
+	},
+}
+
+func TestHandleRefreshTemplates(t *testing.T) {
+	testTemplatesRequests(t, refreshTemplatesTests, true)
+}
+
 func templatesListValidator(c *templateTest, tester *tester, t *testing.T) {
 	gotBody := make([]*models.PorterChartList, 0)
 	expBody := make([]*models.PorterChartList, 0)
@@ -0,0 +1,666 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/porter-dev/porter/internal/auth/totp"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// twoFactorIssuer is the issuer that is shown in authenticator apps
+const twoFactorIssuer = "Porter"
+
+// twoFactorLoginTimeout is how long a user has to enter their code after
+// entering their password
+const twoFactorLoginTimeout = 5 * time.Minute
+
+// numRecoveryCodes is the number of recovery codes that are generated
+const numRecoveryCodes = 10
+
+// maxTwoFactorAttempts is the number of invalid codes that can be entered
+// before codes are no longer accepted for twoFactorLockout
+const maxTwoFactorAttempts = 5
+
+// twoFactorLockout is how long codes are not accepted after too many invalid
+// codes were entered
+const twoFactorLockout = 15 * time.Minute
+
+// EnrollTwoFactorResponse is the secret that the user adds to their
+// authenticator app, either directly or via the otpauth URI
+type EnrollTwoFactorResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// RecoveryCodesResponse is the list of recovery codes for a user, which is
+// only returned when the codes are generated
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// HandleReadTwoFactor returns whether two-factor authentication is enabled
+// for a user
+func (app *App) HandleReadTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user, err := app.readUser(w, r)
+
+	// error already handled by helper
+	if err != nil {
+		return
+	}
+
+	tfExt := &models.TwoFactorExternal{}
+
+	tf, err := app.Repo.TwoFactor.ReadTwoFactorByUserID(user.ID)
+
+	if err == nil {
+		tfExt = tf.Externalize()
+	} else if err != gorm.ErrRecordNotFound {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(tfExt); err != nil {
+		app.handleErrorFormDecoding(err, ErrUserDecode, w)
+		return
+	}
+}
+
+// HandleEnrollTwoFactor generates a new secret for a user. Two-factor
+// authentication is not enabled until the user confirms the secret with
+// a valid code.
+func (app *App) HandleEnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user, err := app.readUser(w, r)
+
+	// error already handled by helper
+	if err != nil {
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	tf, err := app.Repo.TwoFactor.ReadTwoFactorByUserID(user.ID)
+
+	if err == gorm.ErrRecordNotFound {
+		_, err = app.Repo.TwoFactor.CreateTwoFactor(&models.TwoFactor{
+			UserID: user.ID,
+			Secret: []byte(secret),
+		})
+	} else if err == nil && tf.Enabled {
+		app.sendExternalError(fmt.Errorf("two-factor already enabled"), http.StatusBadRequest, HTTPError{
+			Code:   ErrUserValidateFields,
+			Errors: []string{"two-factor authentication is already enabled"},
+		}, w)
+
+		return
+	} else if err == nil {
+		// replace the pending secret
+		tf.Secret = []byte(secret)
+		_, err = app.Repo.TwoFactor.UpdateTwoFactor(tf)
+	}
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	resp := &EnrollTwoFactorResponse{
+		Secret: secret,
+		URI:    totp.KeyURI(twoFactorIssuer, user.Email, secret),
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		app.handleErrorFormDecoding(err, ErrUserDecode, w)
+		return
+	}
+}
+
+// HandleConfirmTwoFactor enables two-factor authentication for a user after
+// checking a code for the pending secret, and returns the recovery codes
+func (app *App) HandleConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user, err := app.readUser(w, r)
+
+	// error already handled by helper
+	if err != nil {
+		return
+	}
+
+	form := &forms.TwoFactorCodeForm{}
+
+	if !app.decodeTwoFactorForm(form, w, r) {
+		return
+	}
+
+	tf, err := app.Repo.TwoFactor.ReadTwoFactorByUserID(user.ID)
+
+	if err == gorm.ErrRecordNotFound || (err == nil && tf.Enabled) {
+		app.sendExternalError(fmt.Errorf("no pending enrollment"), http.StatusBadRequest, HTTPError{
+			Code:   ErrUserValidateFields,
+			Errors: []string{"no pending two-factor enrollment"},
+		}, w)
+
+		return
+	} else if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	step, ok := totp.Validate(string(tf.Secret), form.Code, time.Now(), tf.LastUsedStep)
+
+	if !ok {
+		app.sendInvalidTwoFactorCode(w)
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	tf.Enabled = true
+	tf.LastUsedStep = step
+	tf.RecoveryCodes = hashes
+
+	if _, err := app.Repo.TwoFactor.UpdateTwoFactor(tf); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	// the current session has now passed the second step
+	if session, err := app.Store.Get(r, app.ServerConf.CookieName); err == nil {
+		session.Values["two_factor_verified"] = true
+		session.Save(r, w)
+	}
+
+	app.Logger.Info().Msgf("Two-factor authentication enabled for user: %d", user.ID)
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(&RecoveryCodesResponse{codes}); err != nil {
+		app.handleErrorFormDecoding(err, ErrUserDecode, w)
+		return
+	}
+}
+
+// HandleRegenerateRecoveryCodes replaces a user's recovery codes after
+// checking a code from their authenticator app
+func (app *App) HandleRegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	user, err := app.readUser(w, r)
+
+	// error already handled by helper
+	if err != nil {
+		return
+	}
+
+	form := &forms.TwoFactorCodeForm{}
+
+	if !app.decodeTwoFactorForm(form, w, r) {
+		return
+	}
+
+	tf, ok := app.readEnabledTwoFactor(user.ID, w)
+
+	if !ok {
+		return
+	}
+
+	step, ok := totp.Validate(string(tf.Secret), form.Code, time.Now(), tf.LastUsedStep)
+
+	if !ok {
+		app.sendInvalidTwoFactorCode(w)
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	tf.LastUsedStep = step
+	tf.RecoveryCodes = hashes
+
+	if _, err := app.Repo.TwoFactor.UpdateTwoFactor(tf); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(&RecoveryCodesResponse{codes}); err != nil {
+		app.handleErrorFormDecoding(err, ErrUserDecode, w)
+		return
+	}
+}
+
+// HandleDisableTwoFactor disables two-factor authentication for a user after
+// checking a code or a recovery code. Users cannot disable two-factor
+// authentication while they are a member of a project that requires it.
+func (app *App) HandleDisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user, err := app.readUser(w, r)
+
+	// error already handled by helper
+	if err != nil {
+		return
+	}
+
+	form := &forms.VerifyTwoFactorForm{}
+
+	if !app.decodeTwoFactorForm(form, w, r) {
+		return
+	}
+
+	tf, ok := app.readEnabledTwoFactor(user.ID, w)
+
+	if !ok {
+		return
+	}
+
+	projects, err := app.Repo.Project.ListProjectsByUserID(user.ID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	for _, project := range projects {
+		if project.RequireTwoFactor {
+			app.sendExternalError(fmt.Errorf("project requires two-factor"), http.StatusBadRequest, HTTPError{
+				Code:   ErrUserValidateFields,
+				Errors: []string{fmt.Sprintf("project %s requires two-factor authentication", project.Name)},
+			}, w)
+
+			return
+		}
+	}
+
+	if ok, err := app.verifyTwoFactor(tf, form); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	} else if !ok {
+		app.sendInvalidTwoFactorCode(w)
+		return
+	}
+
+	if err := app.Repo.TwoFactor.DeleteTwoFactor(tf); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	app.Logger.Info().Msgf("Two-factor authentication disabled for user: %d", user.ID)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleLoginTwoFactor completes the second step of a login for a user that
+// has entered their password, and logs the user in
+func (app *App) HandleLoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	session, err := app.Store.Get(r, app.ServerConf.CookieName)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	userID, _ := session.Values["two_factor_user_id"].(uint)
+	expiry, _ := session.Values["two_factor_expiry"].(int64)
+
+	if userID == 0 || time.Now().Unix() > expiry {
+		app.sendExternalError(fmt.Errorf("no pending login"), http.StatusUnauthorized, HTTPError{
+			Code:   http.StatusUnauthorized,
+			Errors: []string{"login has expired, please log in again"},
+		}, w)
+
+		return
+	}
+
+	form := &forms.VerifyTwoFactorForm{}
+
+	if !app.decodeTwoFactorForm(form, w, r) {
+		return
+	}
+
+	user, err := app.Repo.User.ReadUser(userID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	tf, ok := app.readEnabledTwoFactor(user.ID, w)
+
+	if !ok {
+		return
+	}
+
+	if ok, err := app.verifyTwoFactor(tf, form); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	} else if !ok && tf.IsLocked(time.Now()) {
+		// the pending login is dropped, so that the password has to be
+		// entered again after the lockout
+		delete(session.Values, "two_factor_user_id")
+		delete(session.Values, "two_factor_expiry")
+
+		if err := session.Save(r, w); err != nil {
+			app.Logger.Warn().Err(err)
+		}
+
+		app.sendExternalError(fmt.Errorf("two-factor locked"), http.StatusTooManyRequests, HTTPError{
+			Code:   http.StatusTooManyRequests,
+			Errors: []string{"too many invalid two-factor codes, please try again later"},
+		}, w)
+
+		return
+	} else if !ok {
+		app.sendExternalError(fmt.Errorf("invalid code"), http.StatusUnauthorized, HTTPError{
+			Code:   http.StatusUnauthorized,
+			Errors: []string{"invalid two-factor code"},
+		}, w)
+
+		return
+	}
+
+	var redirect string
+
+	if valR := session.Values["redirect"]; valR != nil {
+		redirect = session.Values["redirect"].(string)
+	}
+
+	delete(session.Values, "two_factor_user_id")
+	delete(session.Values, "two_factor_expiry")
+
+	session.Values["authenticated"] = true
+	session.Values["user_id"] = user.ID
+	session.Values["email"] = user.Email
+	session.Values["redirect"] = ""
+	session.Values["two_factor_verified"] = true
+
+	if err := session.Save(r, w); err != nil {
+		app.Logger.Warn().Err(err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := app.sendUser(w, user.ID, user.Email, user.EmailVerified, redirect); err != nil {
+		app.handleErrorFormDecoding(err, ErrUserDecode, w)
+		return
+	}
+}
+
+// HandleUpdateProjectTwoFactor sets whether all members of a project must have
+// two-factor authentication enabled. The user making the request must have
+// two-factor authentication enabled to require it.
+func (app *App) HandleUpdateProjectTwoFactor(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	form := &forms.UpdateProjectTwoFactorForm{}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	proj, err := app.Repo.Project.ReadProject(uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	if form.Required {
+		userID, err := app.getUserIDFromRequest(r)
+
+		if err != nil {
+			app.handleErrorInternal(err, w)
+			return
+		}
+
+		if enabled, err := app.hasTwoFactor(userID); err != nil {
+			app.handleErrorDataRead(err, w)
+			return
+		} else if !enabled {
+			app.sendExternalError(fmt.Errorf("two-factor not enabled"), http.StatusBadRequest, HTTPError{
+				Code:   ErrProjectValidateFields,
+				Errors: []string{"you must enable two-factor authentication before requiring it"},
+			}, w)
+
+			return
+		}
+	}
+
+	proj.RequireTwoFactor = form.Required
+
+	proj, err = app.Repo.Project.UpdateProject(proj)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(proj.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// loginOrRequireTwoFactor logs the user in to the session, unless the user
+// has two-factor authentication enabled. In that case, the session waits for
+// the second step of the login, and true is returned. The session is not saved.
+func (app *App) loginOrRequireTwoFactor(session *sessions.Session, user *models.User) (bool, error) {
+	enabled, err := app.hasTwoFactor(user.ID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if enabled {
+		session.Values["authenticated"] = false
+		session.Values["user_id"] = nil
+		session.Values["email"] = nil
+		session.Values["two_factor_user_id"] = user.ID
+		session.Values["two_factor_expiry"] = time.Now().Add(twoFactorLoginTimeout).Unix()
+
+		return true, nil
+	}
+
+	session.Values["authenticated"] = true
+	session.Values["user_id"] = user.ID
+	session.Values["email"] = user.Email
+	session.Values["redirect"] = ""
+
+	return false, nil
+}
+
+// hasTwoFactor returns true if the user has two-factor authentication enabled
+func (app *App) hasTwoFactor(userID uint) (bool, error) {
+	tf, err := app.Repo.TwoFactor.ReadTwoFactorByUserID(userID)
+
+	if err == gorm.ErrRecordNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return tf.Enabled, nil
+}
+
+// verifyTwoFactor checks the code or recovery code in the form. Accepted
+// codes are recorded so that they cannot be used again. Invalid codes are
+// counted, and no code is accepted for twoFactorLockout after
+// maxTwoFactorAttempts invalid codes.
+func (app *App) verifyTwoFactor(tf *models.TwoFactor, form *forms.VerifyTwoFactorForm) (bool, error) {
+	now := time.Now()
+
+	if tf.IsLocked(now) {
+		return false, nil
+	}
+
+	ok, err := app.checkTwoFactorCode(tf, form, now)
+
+	if err != nil {
+		return false, err
+	}
+
+	if ok {
+		tf.FailedAttempts = 0
+	} else {
+		tf.FailedAttempts++
+
+		if tf.FailedAttempts >= maxTwoFactorAttempts {
+			lockedUntil := now.Add(twoFactorLockout)
+			tf.LockedUntil = &lockedUntil
+			tf.FailedAttempts = 0
+		}
+	}
+
+	if _, err := app.Repo.TwoFactor.UpdateTwoFactor(tf); err != nil {
+		return false, err
+	}
+
+	return ok, nil
+}
+
+// checkTwoFactorCode checks the code or recovery code in the form, and marks
+// an accepted code as used on tf
+func (app *App) checkTwoFactorCode(
+	tf *models.TwoFactor,
+	form *forms.VerifyTwoFactorForm,
+	now time.Time,
+) (bool, error) {
+	if form.Code != "" {
+		step, ok := totp.Validate(string(tf.Secret), form.Code, now, tf.LastUsedStep)
+
+		if !ok {
+			return false, nil
+		}
+
+		tf.LastUsedStep = step
+	} else {
+		hashes := tf.RecoveryCodeHashes()
+		remaining := make([]string, 0)
+		found := false
+
+		code := strings.ToLower(strings.TrimSpace(form.RecoveryCode))
+
+		for _, hash := range hashes {
+			if !found && bcrypt.CompareHashAndPassword([]byte(hash), []byte(code)) == nil {
+				found = true
+				continue
+			}
+
+			remaining = append(remaining, hash)
+		}
+
+		if !found {
+			return false, nil
+		}
+
+		tf.RecoveryCodes = strings.Join(remaining, ",")
+	}
+
+	return true, nil
+}
+
+// readEnabledTwoFactor reads the two-factor configuration of a user, and writes
+// an error if it cannot be read or is not enabled
+func (app *App) readEnabledTwoFactor(userID uint, w http.ResponseWriter) (*models.TwoFactor, bool) {
+	tf, err := app.Repo.TwoFactor.ReadTwoFactorByUserID(userID)
+
+	if err == gorm.ErrRecordNotFound || (err == nil && !tf.Enabled) {
+		app.sendExternalError(fmt.Errorf("two-factor not enabled"), http.StatusBadRequest, HTTPError{
+			Code:   ErrUserValidateFields,
+			Errors: []string{"two-factor authentication is not enabled"},
+		}, w)
+
+		return nil, false
+	} else if err != nil {
+		app.handleErrorDataRead(err, w)
+		return nil, false
+	}
+
+	return tf, true
+}
+
+// decodeTwoFactorForm decodes and validates a two-factor form, and writes an
+// error if the form is invalid
+func (app *App) decodeTwoFactorForm(form interface{}, w http.ResponseWriter, r *http.Request) bool {
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrUserDecode, w)
+		return false
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrUserValidateFields, w)
+		return false
+	}
+
+	return true
+}
+
+func (app *App) sendInvalidTwoFactorCode(w http.ResponseWriter) {
+	app.sendExternalError(fmt.Errorf("invalid code"), http.StatusBadRequest, HTTPError{
+		Code:   ErrUserValidateFields,
+		Errors: []string{"invalid two-factor code"},
+	}, w)
+}
+
+// generateRecoveryCodes returns new recovery codes, along with the
+// comma-separated hashes of the codes to store
+func generateRecoveryCodes() ([]string, string, error) {
+	codes := make([]string, 0)
+	hashes := make([]string, 0)
+
+	for i := 0; i < numRecoveryCodes; i++ {
+		raw, err := repository.GenerateRandomBytes(5)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		code := raw[:5] + "-" + raw[5:]
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, string(hash))
+	}
+
+	return codes, strings.Join(hashes, ","), nil
+}
@@ -0,0 +1,214 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/auth/token"
+	"github.com/porter-dev/porter/internal/auth/totp"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/server/api"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// initTwoFactorUser creates user 1 with two-factor authentication enabled, and
+// returns the secret
+func initTwoFactorUser(tester *tester, t *testing.T) string {
+	user := tester.createUser("belanger@getporter.dev", "hello")
+
+	secret, err := totp.GenerateSecret()
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	tester.repo.TwoFactor.CreateTwoFactor(&models.TwoFactor{
+		UserID:  user.ID,
+		Enabled: true,
+		Secret:  []byte(secret),
+	})
+
+	return secret
+}
+
+func currentTwoFactorCode(secret string, t *testing.T) string {
+	code, err := totp.GenerateCode(secret, totp.Step(time.Now()))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	return code
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	tester := newTester(true)
+	secret := initTwoFactorUser(tester, t)
+
+	rr := tester.send("POST", "/api/login", `{"email":"belanger@getporter.dev","password":"hello"}`)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Login, handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	loginResp := &api.SendUserExt{}
+	json.Unmarshal(rr.Body.Bytes(), loginResp)
+
+	if !loginResp.TwoFactorRequired || loginResp.ID != 0 {
+		t.Errorf("Login, expected only the second step to be required, got %s", rr.Body.String())
+	}
+
+	// the session is not logged in until the second step is completed
+	if rr := tester.send("GET", "/api/auth/check", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Auth check before code, got status %v want %v", rr.Code, http.StatusForbidden)
+	}
+
+	if rr := tester.send("GET", "/api/users/1", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Read user before code, got status %v want %v", rr.Code, http.StatusForbidden)
+	}
+
+	if rr := tester.send("POST", "/api/login/2fa", `{"code":"000000"}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Invalid code, got status %v want %v", rr.Code, http.StatusUnauthorized)
+	}
+
+	code := currentTwoFactorCode(secret, t)
+
+	if rr := tester.send("POST", "/api/login/2fa", fmt.Sprintf(`{"code":"%s"}`, code)); rr.Code != http.StatusOK {
+		t.Fatalf("Valid code, got status %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	if rr := tester.send("GET", "/api/auth/check", ""); rr.Code != http.StatusOK {
+		t.Errorf("Auth check after code, got status %v want %v", rr.Code, http.StatusOK)
+	}
+
+	// a code can only be used once, even in a new login
+	tester.cookie = nil
+	tester.send("POST", "/api/login", `{"email":"belanger@getporter.dev","password":"hello"}`)
+
+	if rr := tester.send("POST", "/api/login/2fa", fmt.Sprintf(`{"code":"%s"}`, code)); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Reused code, got status %v want %v", rr.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestTwoFactorLoginWithoutPassword(t *testing.T) {
+	tester := newTester(true)
+	secret := initTwoFactorUser(tester, t)
+
+	body := fmt.Sprintf(`{"code":"%s"}`, currentTwoFactorCode(secret, t))
+
+	if rr := tester.send("POST", "/api/login/2fa", body); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Code without password, got status %v want %v", rr.Code, http.StatusUnauthorized)
+	}
+
+	if rr := tester.send("GET", "/api/auth/check", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Auth check, got status %v want %v", rr.Code, http.StatusForbidden)
+	}
+}
+
+func TestTwoFactorLoginLockout(t *testing.T) {
+	tester := newTester(true)
+	secret := initTwoFactorUser(tester, t)
+
+	tester.send("POST", "/api/login", `{"email":"belanger@getporter.dev","password":"hello"}`)
+
+	var rr int
+
+	for i := 0; i < 5; i++ {
+		rr = tester.send("POST", "/api/login/2fa", `{"code":"000000"}`).Code
+	}
+
+	if rr != http.StatusTooManyRequests {
+		t.Errorf("Too many invalid codes, got status %v want %v", rr, http.StatusTooManyRequests)
+	}
+
+	// the valid code is not accepted during the lockout, even after the
+	// password is entered again
+	tester.send("POST", "/api/login", `{"email":"belanger@getporter.dev","password":"hello"}`)
+
+	body := fmt.Sprintf(`{"code":"%s"}`, currentTwoFactorCode(secret, t))
+
+	if rr := tester.send("POST", "/api/login/2fa", body); rr.Code == http.StatusOK {
+		t.Errorf("Valid code during lockout was accepted")
+	}
+}
+
+func TestTwoFactorLoginRecoveryCode(t *testing.T) {
+	tester := newTester(true)
+	initTwoFactorUser(tester, t)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("abcde-fghij"), bcrypt.MinCost)
+
+	tf, _ := tester.repo.TwoFactor.ReadTwoFactorByUserID(1)
+	tf.RecoveryCodes = string(hash)
+	tester.repo.TwoFactor.UpdateTwoFactor(tf)
+
+	tester.send("POST", "/api/login", `{"email":"belanger@getporter.dev","password":"hello"}`)
+
+	// recovery codes are not case-sensitive
+	body := `{"recovery_code":"ABCDE-FGHIJ"}`
+
+	if rr := tester.send("POST", "/api/login/2fa", body); rr.Code != http.StatusOK {
+		t.Errorf("Recovery code, got status %v want %v", rr.Code, http.StatusOK)
+	}
+
+	// recovery codes can only be used once
+	tester.cookie = nil
+	tester.send("POST", "/api/login", `{"email":"belanger@getporter.dev","password":"hello"}`)
+
+	if rr := tester.send("POST", "/api/login/2fa", body); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Reused recovery code, got status %v want %v", rr.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestTwoFactorCLIToken(t *testing.T) {
+	tester := newTester(true)
+	secret := initTwoFactorUser(tester, t)
+
+	proj, _ := tester.repo.Project.CreateProject(&models.Project{
+		Name:             "project-test",
+		RequireTwoFactor: true,
+	})
+
+	tester.repo.Project.CreateProjectRole(proj, &models.Role{
+		UserID:    1,
+		ProjectID: proj.ID,
+		Kind:      models.RoleAdmin,
+	})
+
+	tester.send("POST", "/api/login", `{"email":"belanger@getporter.dev","password":"hello"}`)
+	tester.send("POST", "/api/login/2fa", fmt.Sprintf(`{"code":"%s"}`, currentTwoFactorCode(secret, t)))
+
+	rr := tester.send("GET", "/api/cli/login?redirect=http://localhost:10000", "")
+
+	if rr.Code != http.StatusFound {
+		t.Fatalf("CLI login, handler returned wrong status code: got %v want %v", rr.Code, http.StatusFound)
+	}
+
+	location, err := url.Parse(rr.Header().Get("Location"))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	body := fmt.Sprintf(`{"authorization_code":"%s"}`, location.Query().Get("code"))
+	rr = tester.send("GET", "/api/cli/login/exchange", body)
+
+	exchangeResp := &api.ExchangeResponse{}
+	json.Unmarshal(rr.Body.Bytes(), exchangeResp)
+
+	if rr := tester.sendWithToken("GET", "/api/projects/1", "", exchangeResp.Token); rr.Code != http.StatusOK {
+		t.Errorf("CLI token after two-factor login, got status %v want %v", rr.Code, http.StatusOK)
+	}
+
+	// user tokens without the two-factor claim are rejected by the project
+	unverified, _ := token.GetTokenForUser(1)
+	encoded, _ := unverified.EncodeToken(&token.TokenGeneratorConf{TokenSecret: "secret"})
+
+	if rr := tester.sendWithToken("GET", "/api/projects/1", "", encoded); rr.Code != http.StatusForbidden {
+		t.Errorf("User token without two-factor claim, got status %v want %v", rr.Code, http.StatusForbidden)
+	}
+}
@@ -152,6 +152,34 @@ func (app *App) HandleCLILoginUser(w http.ResponseWriter, r *http.Request) {
 
 	userID, _ := session.Values["user_id"].(uint)
 
+	// sessions that were started before the user enabled two-factor
+	// authentication must complete the second step before a token is issued
+	user, err := app.Repo.User.ReadUser(userID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	verified, _ := session.Values["two_factor_verified"].(bool)
+
+	if !verified {
+		twoFactorRequired, err := app.loginOrRequireTwoFactor(session, user)
+
+		if err != nil {
+			app.handleErrorDataRead(err, w)
+			return
+		}
+
+		if twoFactorRequired {
+			session.Values["redirect"] = r.URL.Path + "?" + r.URL.RawQuery
+			session.Save(r, w)
+
+			http.Redirect(w, r, "/login?two_factor=true", 302)
+			return
+		}
+	}
+
 	// generate the token
 	jwt, err := token.GetTokenForUser(userID)
 
@@ -160,6 +188,10 @@ func (app *App) HandleCLILoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the token passes the projects that require two-factor authentication
+	// if the session that it is issued to has
+	jwt.TwoFactorVerified = verified
+
 	encoded, err := jwt.EncodeToken(&token.TokenGeneratorConf{
 		TokenSecret: app.ServerConf.TokenGeneratorSecret,
 	})
@@ -275,11 +307,14 @@ func (app *App) HandleLoginUser(w http.ResponseWriter, r *http.Request) {
 		redirect = session.Values["redirect"].(string)
 	}
 
-	// Set user as authenticated
-	session.Values["authenticated"] = true
-	session.Values["user_id"] = storedUser.ID
-	session.Values["email"] = storedUser.Email
-	session.Values["redirect"] = ""
+	// Set user as authenticated, or wait for the second step if the user
+	// has two-factor authentication enabled
+	twoFactorRequired, err := app.loginOrRequireTwoFactor(session, storedUser)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
 
 	if err := session.Save(r, w); err != nil {
 		app.Logger.Warn().Err(err)
@@ -287,6 +322,14 @@ func (app *App) HandleLoginUser(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 
+	if twoFactorRequired {
+		if err := json.NewEncoder(w).Encode(&SendUserExt{TwoFactorRequired: true}); err != nil {
+			app.handleErrorFormDecoding(err, ErrUserDecode, w)
+		}
+
+		return
+	}
+
 	if err := app.sendUser(w, storedUser.ID, storedUser.Email, storedUser.EmailVerified, redirect); err != nil {
 		app.handleErrorFormDecoding(err, ErrUserDecode, w)
 		return
@@ -867,6 +910,10 @@ type SendUserExt struct {
 	Email         string `json:"email"`
 	EmailVerified bool   `json:"email_verified"`
 	Redirect      string `json:"redirect,omitempty"`
+
+	// TwoFactorRequired is set when the user must complete the second step
+	// of the login, in which case the other fields are not set
+	TwoFactorRequired bool `json:"two_factor_required,omitempty"`
 }
 
 func (app *App) sendUser(w http.ResponseWriter, userID uint, email string, emailVerified bool, redirect string) error {
@@ -572,3 +572,68 @@ func userProjectsListValidator(c *userTest, tester *tester, t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+// ------------------------- API TOKENS ON USER ROUTES ------------------------- //
+
+func TestUserRoutesRejectProjectTokens(t *testing.T) {
+	tester := newTester(true)
+	initRoleMember(tester, models.RoleAdmin)
+
+	tester.repo.APIToken.CreateAPIToken(&models.APIToken{
+		UniqueID:        "abcdef",
+		ProjectID:       1,
+		CreatedByUserID: 1,
+		Name:            "ci",
+		Scopes:          models.GetScope(models.ProjectResource, models.ReadVerb),
+	})
+
+	encodeToken := func(tok *token.Token) string {
+		encoded, err := tok.EncodeToken(&token.TokenGeneratorConf{TokenSecret: "secret"})
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		return encoded
+	}
+
+	userTok, _ := token.GetTokenForUser(1)
+	projTok, _ := token.GetTokenForAPI(1, 1)
+	storedTok, _ := token.GetStoredTokenForAPI(1, 1, "abcdef")
+
+	tests := []struct {
+		msg       string
+		endpoint  string
+		tok       string
+		expStatus int
+	}{
+		{"User token", "/api/users/1", encodeToken(userTok), http.StatusOK},
+		{"User token for other user", "/api/users/2", encodeToken(userTok), http.StatusForbidden},
+		{"Stored API token", "/api/users/1", encodeToken(storedTok), http.StatusForbidden},
+		{"Stored API token on 2fa", "/api/users/1/2fa", encodeToken(storedTok), http.StatusForbidden},
+		{"Stored API token in scope", "/api/projects/1", encodeToken(storedTok), http.StatusOK},
+		{"Stored API token out of scope", "/api/projects/1/gitrepos", encodeToken(storedTok), http.StatusForbidden},
+		{"Project token", "/api/projects/1/gitrepos", encodeToken(projTok), http.StatusOK},
+	}
+
+	for _, c := range tests {
+		if rr := tester.sendWithToken("GET", c.endpoint, "", c.tok); rr.Code != c.expStatus {
+			t.Errorf("%s, handler returned wrong status code: got %v want %v",
+				c.msg, rr.Code, c.expStatus)
+		}
+	}
+
+	// a stored API token is rejected on user routes even if the request also
+	// has the user's session
+	req, _ := http.NewRequest("GET", "/api/users/1", nil)
+	req.Header.Set("Authorization", "Bearer "+encodeToken(storedTok))
+	req.AddCookie(tester.cookie)
+
+	tester.req = req
+	tester.execute()
+
+	if tester.rr.Code != http.StatusForbidden {
+		t.Errorf("Stored API token with session, handler returned wrong status code: got %v want %v",
+			tester.rr.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,401 @@
+package requestlog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	lr "github.com/porter-dev/porter/internal/logger"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// maxAuditChangesSize is the largest set of changes that is stored with the
+// values that were changed. Larger sets are stored with only the paths of the
+// values that were changed.
+const maxAuditChangesSize = 64 * 1024
+
+// redactedKeys are the substrings of JSON keys whose values are never stored
+// in the changes of an audit event
+var redactedKeys = []string{
+	"password",
+	"secret",
+	"token",
+	"key",
+	"cert",
+	"credential",
+	"kubeconfig",
+}
+
+// resourceAliases maps top-level project routes that act on another kind of
+// resource to that resource. The route name becomes part of the action.
+var resourceAliases = map[string]string{
+	"delete":    "releases",
+	"deploy":    "releases",
+	"provision": "infra",
+}
+
+// ActorFunc returns the user that made a request, and the ID of the stored
+// API token that the request was made with, if any
+type ActorFunc func(r *http.Request) (userID uint, apiTokenID uint)
+
+// AuditTarget identifies the resource that a request acted on, for routes
+// that don't have a project ID in their URL, such as webhooks
+type AuditTarget struct {
+	ProjectID    uint
+	Resource     string
+	ResourceName string
+	Action       string
+}
+
+type auditContextKey struct{}
+
+// auditRecord collects what the handler of an audited request reports about
+// the resource that it acted on
+type auditRecord struct {
+	target  *AuditTarget
+	changes []*auditChange
+}
+
+// auditChange is a value of a resource that a request changed. The path is
+// made from the keys of the value, joined by dots.
+type auditChange struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// SetAuditTarget sets the resource that a request acted on, so that requests
+// to routes without a project ID are audited. It does nothing if the request
+// is not audited.
+func SetAuditTarget(r *http.Request, target *AuditTarget) {
+	if record := getAuditRecord(r); record != nil {
+		record.target = target
+	}
+}
+
+// RecordChange compares the state of the resource that a request acted on
+// before and after the request, and stores the values that changed with the
+// audit event of the request. The values of sensitive keys are redacted. It
+// does nothing if the request is not audited.
+func RecordChange(r *http.Request, before, after interface{}) {
+	if record := getAuditRecord(r); record != nil {
+		record.changes = append(record.changes, diffValues(before, after)...)
+	}
+}
+
+func getAuditRecord(r *http.Request) *auditRecord {
+	record, _ := r.Context().Value(auditContextKey{}).(*auditRecord)
+	return record
+}
+
+// Auditor records an audit event for every mutating request against a project
+type Auditor struct {
+	repo   repository.AuditEventRepository
+	actor  ActorFunc
+	logger *lr.Logger
+}
+
+// NewAuditor creates a new Auditor that writes events to the repository
+func NewAuditor(
+	repo repository.AuditEventRepository,
+	actor ActorFunc,
+	l *lr.Logger,
+) *Auditor {
+	return &Auditor{repo, actor, l}
+}
+
+// Middleware serves the request, and then records an audit event if the
+// request was a mutating call on a project route, or on a route whose handler
+// set its target with SetAuditTarget. It must be registered on a chi router so
+// that the route pattern is known after the request is served.
+func (a *Auditor) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" || r.Method == "HEAD" || r.Method == "OPTIONS" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID, apiTokenID := a.actor(r)
+
+		record := &auditRecord{}
+		r = r.WithContext(context.WithValue(r.Context(), auditContextKey{}, record))
+
+		w2 := &responseStats{w: w}
+		next.ServeHTTP(w2, r)
+
+		rctx := chi.RouteContext(r.Context())
+
+		if rctx == nil {
+			return
+		}
+
+		status := w2.code
+
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		event := &models.AuditEvent{
+			UserID:     userID,
+			APITokenID: apiTokenID,
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Changes:    encodeChanges(record.changes),
+			Status:     status,
+		}
+
+		if target := record.target; target != nil {
+			event.ProjectID = target.ProjectID
+			event.Resource = target.Resource
+			event.ResourceName = target.ResourceName
+			event.Action = target.Action
+		} else {
+			projID, err := strconv.ParseUint(rctx.URLParam("project_id"), 10, 64)
+
+			if err != nil {
+				return
+			}
+
+			event.ProjectID = uint(projID)
+			event.Resource, event.Action = parseAuditRoute(rctx.RoutePattern(), r.Method)
+			event.ResourceName = getAuditResourceName(rctx)
+		}
+
+		if event.ProjectID == 0 {
+			return
+		}
+
+		if _, err := a.repo.CreateAuditEvent(event); err != nil {
+			a.logger.Error().Err(err).Msg("could not write audit event")
+		}
+	})
+}
+
+// parseAuditRoute gets the resource and action from a route pattern such as
+// /api/projects/{project_id}/releases/{name}/upgrade. The resource is the
+// segment after the project ID, and the action is made from the remaining
+// static segments, or from the method if there are none.
+func parseAuditRoute(pattern, method string) (resource, action string) {
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+	actionSegments := make([]string, 0)
+
+	for i, segment := range segments {
+		if segment != "{project_id}" {
+			continue
+		}
+
+		for _, rest := range segments[i+1:] {
+			if rest == "" || strings.HasPrefix(rest, "{") {
+				continue
+			}
+
+			if resource == "" {
+				resource = rest
+			} else {
+				actionSegments = append(actionSegments, rest)
+			}
+		}
+
+		break
+	}
+
+	if resource == "" {
+		resource = "projects"
+	}
+
+	if alias, ok := resourceAliases[resource]; ok {
+		actionSegments = append([]string{resource}, actionSegments...)
+		resource = alias
+	}
+
+	if len(actionSegments) > 0 {
+		return resource, strings.Join(actionSegments, "_")
+	}
+
+	switch method {
+	case "POST":
+		// posting to a route that ends with an ID updates that resource
+		if strings.HasSuffix(pattern, "}") {
+			return resource, "update"
+		}
+
+		return resource, "create"
+	case "DELETE":
+		return resource, "delete"
+	default:
+		return resource, "update"
+	}
+}
+
+// getAuditResourceName returns the name URL parameter if it is set, and
+// otherwise the last ID parameter that is not the project ID
+func getAuditResourceName(rctx *chi.Context) string {
+	if name := rctx.URLParam("name"); name != "" {
+		return name
+	}
+
+	res := ""
+
+	for i, key := range rctx.URLParams.Keys {
+		if key != "project_id" && strings.HasSuffix(key, "_id") {
+			res = rctx.URLParams.Values[i]
+		}
+	}
+
+	return res
+}
+
+// diffValues returns the values that differ between the JSON encodings of
+// before and after. Nested objects are compared key by key, and every other
+// value, including arrays, is compared as a whole.
+func diffValues(before, after interface{}) []*auditChange {
+	beforeVals := make(map[string]interface{})
+	afterVals := make(map[string]interface{})
+
+	flattenValue(toJSONValue(before), "", beforeVals)
+	flattenValue(toJSONValue(after), "", afterVals)
+
+	paths := make([]string, 0)
+
+	for path := range beforeVals {
+		paths = append(paths, path)
+	}
+
+	for path := range afterVals {
+		if _, ok := beforeVals[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+
+	sort.Strings(paths)
+
+	res := make([]*auditChange, 0)
+
+	for _, path := range paths {
+		beforeVal, inBefore := beforeVals[path]
+		afterVal, inAfter := afterVals[path]
+
+		if inBefore == inAfter && reflect.DeepEqual(beforeVal, afterVal) {
+			continue
+		}
+
+		change := &auditChange{
+			Path:   path,
+			Before: beforeVal,
+			After:  afterVal,
+		}
+
+		if isRedactedPath(path) {
+			if inBefore {
+				change.Before = "REDACTED"
+			}
+
+			if inAfter {
+				change.After = "REDACTED"
+			}
+		}
+
+		res = append(res, change)
+	}
+
+	return res
+}
+
+// toJSONValue converts a value to the generic form that it is decoded to from
+// JSON, so that values of different types can be compared
+func toJSONValue(val interface{}) interface{} {
+	if val == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(val)
+
+	if err != nil {
+		return nil
+	}
+
+	var res interface{}
+
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil
+	}
+
+	return res
+}
+
+func flattenValue(val interface{}, path string, res map[string]interface{}) {
+	if val == nil {
+		return
+	}
+
+	obj, ok := val.(map[string]interface{})
+
+	if !ok || len(obj) == 0 {
+		res[path] = val
+		return
+	}
+
+	for key, child := range obj {
+		childPath := key
+
+		if path != "" {
+			childPath = path + "." + key
+		}
+
+		flattenValue(child, childPath, res)
+	}
+}
+
+// encodeChanges returns the JSON encoding of the changes of a request
+func encodeChanges(changes []*auditChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	res, err := json.Marshal(changes)
+
+	if err == nil && len(res) <= maxAuditChangesSize {
+		return string(res)
+	}
+
+	for _, change := range changes {
+		change.Before = nil
+		change.After = nil
+	}
+
+	res, err = json.Marshal(changes)
+
+	if err != nil || len(res) > maxAuditChangesSize {
+		return ""
+	}
+
+	return string(res)
+}
+
+func isRedactedPath(path string) bool {
+	for _, key := range strings.Split(path, ".") {
+		if isRedactedKey(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isRedactedKey(key string) bool {
+	lower := strings.ToLower(key)
+
+	for _, redacted := range redactedKeys {
+		if strings.Contains(lower, redacted) {
+			return true
+		}
+	}
+
+	return false
+}
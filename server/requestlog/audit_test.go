@@ -0,0 +1,125 @@
+package requestlog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/chi"
+	lr "github.com/porter-dev/porter/internal/logger"
+	"github.com/porter-dev/porter/internal/repository"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+)
+
+type auditRouteTest struct {
+	pattern  string
+	method   string
+	resource string
+	action   string
+}
+
+var auditRouteTests = []auditRouteTest{
+	{"/api/projects/{project_id}/releases/{name}/upgrade", "POST", "releases", "upgrade"},
+	{"/api/projects/{project_id}/delete/{name}", "DELETE", "releases", "delete"},
+	{"/api/projects/{project_id}/deploy/{name}/{version}", "POST", "releases", "deploy"},
+	{"/api/projects/{project_id}/provision/ecr", "POST", "infra", "provision_ecr"},
+	{"/api/projects/{project_id}/clusters/{cluster_id}", "DELETE", "clusters", "delete"},
+	{"/api/projects/{project_id}/clusters/{cluster_id}", "POST", "clusters", "update"},
+	{"/api/projects/{project_id}/clusters", "POST", "clusters", "create"},
+	{"/api/projects/{project_id}", "DELETE", "projects", "delete"},
+}
+
+func TestParseAuditRoute(t *testing.T) {
+	for _, test := range auditRouteTests {
+		resource, action := parseAuditRoute(test.pattern, test.method)
+
+		if resource != test.resource || action != test.action {
+			t.Errorf(
+				"%s %s: expected %s/%s, got %s/%s\n",
+				test.method,
+				test.pattern,
+				test.resource,
+				test.action,
+				resource,
+				action,
+			)
+		}
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+	before := map[string]interface{}{
+		"replicaCount": 1,
+		"image":        map[string]interface{}{"repository": "web", "tag": "v1"},
+		"env":          map[string]interface{}{"API_KEY": "abcd", "LOG_LEVEL": "info"},
+		"hosts":        []string{"a.example.com"},
+	}
+
+	after := map[string]interface{}{
+		"replicaCount": 1,
+		"image":        map[string]interface{}{"repository": "web", "tag": "v2"},
+		"env":          map[string]interface{}{"API_KEY": "efgh"},
+		"hosts":        []string{"a.example.com", "b.example.com"},
+	}
+
+	expected := `[{"path":"env.API_KEY","before":"REDACTED","after":"REDACTED"},` +
+		`{"path":"env.LOG_LEVEL","before":"info","after":null},` +
+		`{"path":"hosts","before":["a.example.com"],"after":["a.example.com","b.example.com"]},` +
+		`{"path":"image.tag","before":"v1","after":"v2"}]`
+
+	if res := encodeChanges(diffValues(before, after)); res != expected {
+		t.Errorf("incorrect changes: expected %s, got %s\n", expected, res)
+	}
+
+	if res := encodeChanges(diffValues(before, before)); res != "" {
+		t.Errorf("expected no changes, got %s\n", res)
+	}
+}
+
+func TestAuditWebhookTarget(t *testing.T) {
+	repo := memory.NewAuditEventRepository(true)
+
+	auditor := NewAuditor(repo, func(r *http.Request) (uint, uint) {
+		return 0, 0
+	}, lr.New(false, os.Stdout))
+
+	r := chi.NewRouter()
+	r.Use(auditor.Middleware)
+
+	r.Post("/webhooks/deploy/{token}", func(w http.ResponseWriter, r *http.Request) {
+		SetAuditTarget(r, &AuditTarget{
+			ProjectID:    1,
+			Resource:     "releases",
+			ResourceName: "web",
+			Action:       "webhook_deploy",
+		})
+
+		RecordChange(
+			r,
+			map[string]interface{}{"image": map[string]interface{}{"tag": "v1"}},
+			map[string]interface{}{"image": map[string]interface{}{"tag": "v2"}},
+		)
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/webhooks/deploy/abcd", nil))
+
+	events, _, err := repo.ListAuditEventsByProjectID(1, &repository.ListAuditEventOpts{})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected webhook call to be audited, got %d events\n", len(events))
+	}
+
+	expected := `[{"path":"image.tag","before":"v1","after":"v2"}]`
+
+	if e := events[0]; e.Resource != "releases" || e.ResourceName != "web" ||
+		e.Action != "webhook_deploy" || e.Changes != expected {
+		t.Errorf("incorrect event: got %s %s %s %s\n", e.Resource, e.ResourceName, e.Action, e.Changes)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/gorilla/sessions"
@@ -131,8 +132,13 @@ type bodyDOIntegrationID struct {
 	DOIntegrationID uint64 `json:"do_integration_id"`
 }
 
+type bodyNamespace struct {
+	Namespace string `json:"namespace"`
+}
+
 // DoesUserIDMatch checks the id URL parameter and verifies that it matches
-// the one stored in the session
+// the one stored in the session. Project API tokens only grant access to a
+// project, so they are never accepted for user routes.
 func (auth *Auth) DoesUserIDMatch(next http.Handler, loc IDLocation) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var err error
@@ -141,7 +147,7 @@ func (auth *Auth) DoesUserIDMatch(next http.Handler, loc IDLocation) http.Handle
 		// first check for token
 		tok := auth.getTokenFromRequest(r)
 
-		if err != nil {
+		if err != nil || (tok != nil && tok.TokenID != "") {
 			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 			return
 		} else if tok != nil && tok.IBy == uint(id) {
@@ -158,24 +164,25 @@ func (auth *Auth) DoesUserIDMatch(next http.Handler, loc IDLocation) http.Handle
 	})
 }
 
-// AccessType represents the various access types for a project
-type AccessType string
+// AccessType represents the various access types for a project resource
+type AccessType = models.PermissionVerb
 
 // The various access types
 const (
-	ReadAccess  AccessType = "read"
-	WriteAccess AccessType = "write"
+	ReadAccess   AccessType = models.ReadVerb
+	WriteAccess  AccessType = models.WriteVerb
+	DeleteAccess AccessType = models.DeleteVerb
 )
 
 // DoesUserHaveProjectAccess looks for a project_id parameter and checks that the
-// user has access via the specified accessType
+// user's role in the project grants the specified accessType on the resource
 func (auth *Auth) DoesUserHaveProjectAccess(
 	next http.Handler,
 	projLoc IDLocation,
+	resource models.PermissionResource,
 	accessType AccessType,
 ) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var err error
 		projID, err := findProjIDInRequest(r, projLoc)
 
 		if err != nil {
@@ -183,69 +190,43 @@ func (auth *Auth) DoesUserHaveProjectAccess(
 			return
 		}
 
-		// first check for token
-		tok := auth.getTokenFromRequest(r)
-
-		var userID uint
-
-		if tok != nil && tok.ProjectID == uint(projID) {
-			next.ServeHTTP(w, r)
-			return
-		} else if tok != nil {
-			userID = tok.IBy
-		} else {
-			session, err := auth.store.Get(r, auth.cookieName)
-
-			if err != nil {
-				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
-				return
-			}
-
-			sessionUserID, ok := session.Values["user_id"]
-			userID = sessionUserID.(uint)
-
-			if !ok {
-				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
-				return
-			}
-		}
-
-		// get the project
-		proj, err := auth.repo.Project.ReadProject(uint(projID))
-
-		if err != nil {
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-			return
-		}
-
-		// look for the user role in the project
-		for _, role := range proj.Roles {
-			if role.UserID == userID {
-				if accessType == ReadAccess {
-					next.ServeHTTP(w, r)
-					return
-				} else if accessType == WriteAccess {
-					if role.Kind == models.RoleAdmin {
-						next.ServeHTTP(w, r)
-						return
-					}
-				}
-
-			}
-		}
-
-		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
-		return
+		auth.checkPermission(next, w, r, uint(projID), resource, accessType)
 	})
 }
 
 // DoesUserHaveClusterAccess looks for a project_id parameter and a
 // cluster_id parameter, and verifies that the cluster belongs
-// to the project
+// to the project and that the user can read resources of its kind.
+// If the user or the API token of the request has role bindings in the
+// project, they must also allow access to the cluster and to the requested
+// namespace. Requests without a namespace are only allowed by bindings to the
+// whole cluster.
 func (auth *Auth) DoesUserHaveClusterAccess(
 	next http.Handler,
 	projLoc IDLocation,
 	clusterLoc IDLocation,
+) http.Handler {
+	return auth.clusterAccess(next, projLoc, clusterLoc, false)
+}
+
+// DoesUserHaveFilteredClusterAccess is DoesUserHaveClusterAccess for routes
+// that return the cluster itself, or that check the namespace of every
+// resource that they return or change against the role bindings themselves.
+// Requests without a namespace are allowed by bindings to any namespace of
+// the cluster.
+func (auth *Auth) DoesUserHaveFilteredClusterAccess(
+	next http.Handler,
+	projLoc IDLocation,
+	clusterLoc IDLocation,
+) http.Handler {
+	return auth.clusterAccess(next, projLoc, clusterLoc, true)
+}
+
+func (auth *Auth) clusterAccess(
+	next http.Handler,
+	projLoc IDLocation,
+	clusterLoc IDLocation,
+	filtered bool,
 ) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		clusterID, err := findClusterIDInRequest(r, clusterLoc)
@@ -279,19 +260,45 @@ func (auth *Auth) DoesUserHaveClusterAccess(
 			}
 		}
 
-		if doesExist {
-			next.ServeHTTP(w, r)
+		if !doesExist {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 			return
 		}
 
-		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
-		return
+		// check that the role bindings of the user and of the token allow
+		// access to the cluster and the requested namespace
+		bindings, err := auth.getRoleBindings(r, uint(projID))
+
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		namespace, err := findNamespaceInRequest(r)
+
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		allowed := bindings.Allows(uint(clusterID), namespace)
+
+		if namespace == "" && filtered {
+			allowed = bindings.AllowsCluster(uint(clusterID))
+		}
+
+		if !allowed {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		auth.checkPermission(next, w, r, uint(projID), models.ClusterResource, ReadAccess)
 	})
 }
 
 // DoesUserHaveInviteAccess looks for a project_id parameter and a
 // invite_id parameter, and verifies that the invite belongs
-// to the project
+// to the project and that the user can read resources of its kind
 func (auth *Auth) DoesUserHaveInviteAccess(
 	next http.Handler,
 	projLoc IDLocation,
@@ -330,7 +337,7 @@ func (auth *Auth) DoesUserHaveInviteAccess(
 		}
 
 		if doesExist {
-			next.ServeHTTP(w, r)
+			auth.checkPermission(next, w, r, uint(projID), models.ProjectResource, ReadAccess)
 			return
 		}
 
@@ -341,7 +348,7 @@ func (auth *Auth) DoesUserHaveInviteAccess(
 
 // DoesUserHaveRegistryAccess looks for a project_id parameter and a
 // registry_id parameter, and verifies that the registry belongs
-// to the project
+// to the project and that the user can read resources of its kind
 func (auth *Auth) DoesUserHaveRegistryAccess(
 	next http.Handler,
 	projLoc IDLocation,
@@ -380,7 +387,7 @@ func (auth *Auth) DoesUserHaveRegistryAccess(
 		}
 
 		if doesExist {
-			next.ServeHTTP(w, r)
+			auth.checkPermission(next, w, r, uint(projID), models.RegistryResource, ReadAccess)
 			return
 		}
 
@@ -391,7 +398,7 @@ func (auth *Auth) DoesUserHaveRegistryAccess(
 
 // DoesUserHaveGitRepoAccess looks for a project_id parameter and a
 // git_repo_id parameter, and verifies that the git repo belongs
-// to the project
+// to the project and that the user can read resources of its kind
 func (auth *Auth) DoesUserHaveGitRepoAccess(
 	next http.Handler,
 	projLoc IDLocation,
@@ -430,7 +437,7 @@ func (auth *Auth) DoesUserHaveGitRepoAccess(
 		}
 
 		if doesExist {
-			next.ServeHTTP(w, r)
+			auth.checkPermission(next, w, r, uint(projID), models.IntegrationResource, ReadAccess)
 			return
 		}
 
@@ -441,7 +448,7 @@ func (auth *Auth) DoesUserHaveGitRepoAccess(
 
 // DoesUserHaveInfraAccess looks for a project_id parameter and an
 // infra_id parameter, and verifies that the infra belongs
-// to the project
+// to the project and that the user can read resources of its kind
 func (auth *Auth) DoesUserHaveInfraAccess(
 	next http.Handler,
 	projLoc IDLocation,
@@ -479,7 +486,7 @@ func (auth *Auth) DoesUserHaveInfraAccess(
 		}
 
 		if doesExist {
-			next.ServeHTTP(w, r)
+			auth.checkPermission(next, w, r, uint(projID), models.InfraResource, ReadAccess)
 			return
 		}
 
@@ -490,7 +497,7 @@ func (auth *Auth) DoesUserHaveInfraAccess(
 
 // DoesUserHaveAWSIntegrationAccess looks for a project_id parameter and an
 // aws_integration_id parameter, and verifies that the infra belongs
-// to the project
+// to the project and that the user can read resources of its kind
 func (auth *Auth) DoesUserHaveAWSIntegrationAccess(
 	next http.Handler,
 	projLoc IDLocation,
@@ -534,7 +541,7 @@ func (auth *Auth) DoesUserHaveAWSIntegrationAccess(
 		}
 
 		if doesExist {
-			next.ServeHTTP(w, r)
+			auth.checkPermission(next, w, r, uint(projID), models.IntegrationResource, ReadAccess)
 			return
 		}
 
@@ -545,7 +552,7 @@ func (auth *Auth) DoesUserHaveAWSIntegrationAccess(
 
 // DoesUserHaveGCPIntegrationAccess looks for a project_id parameter and an
 // gcp_integration_id parameter, and verifies that the infra belongs
-// to the project
+// to the project and that the user can read resources of its kind
 func (auth *Auth) DoesUserHaveGCPIntegrationAccess(
 	next http.Handler,
 	projLoc IDLocation,
@@ -589,7 +596,7 @@ func (auth *Auth) DoesUserHaveGCPIntegrationAccess(
 		}
 
 		if doesExist {
-			next.ServeHTTP(w, r)
+			auth.checkPermission(next, w, r, uint(projID), models.IntegrationResource, ReadAccess)
 			return
 		}
 
@@ -600,7 +607,7 @@ func (auth *Auth) DoesUserHaveGCPIntegrationAccess(
 
 // DoesUserHaveDOIntegrationAccess looks for a project_id parameter and an
 // do_integration_id parameter, and verifies that the infra belongs
-// to the project
+// to the project and that the user can read resources of its kind
 func (auth *Auth) DoesUserHaveDOIntegrationAccess(
 	next http.Handler,
 	projLoc IDLocation,
@@ -644,7 +651,7 @@ func (auth *Auth) DoesUserHaveDOIntegrationAccess(
 		}
 
 		if doesExist {
-			next.ServeHTTP(w, r)
+			auth.checkPermission(next, w, r, uint(projID), models.IntegrationResource, ReadAccess)
 			return
 		}
 
@@ -654,6 +661,219 @@ func (auth *Auth) DoesUserHaveDOIntegrationAccess(
 }
 
 // Helpers
+
+// checkPermission serves the request if the requesting user or token is allowed to
+// perform accessType on the resource in the project, and returns 403 otherwise
+func (auth *Auth) checkPermission(
+	next http.Handler,
+	w http.ResponseWriter,
+	r *http.Request,
+	projID uint,
+	resource models.PermissionResource,
+	accessType AccessType,
+) {
+	// first check for token
+	tok := auth.getTokenFromRequest(r)
+
+	// get the project
+	proj, err := auth.repo.Project.ReadProject(projID)
+
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// a stored API token only grants access to the project it was created in,
+	// and never falls back to the access of the user that created it
+	if tok != nil && tok.TokenID != "" && tok.ProjectID != projID {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	if tok != nil && tok.ProjectID == projID {
+		// a token never grants more than the current role of the user that
+		// issued it, so tokens stop working when the user is removed from the
+		// project or demoted
+		if !hasRolePermission(proj, tok.IBy, resource, accessType) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		if tok.TokenID != "" && !auth.useAPIToken(tok.TokenID, resource, accessType) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	userID, err := auth.getUserIDFromRequest(r)
+
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	if !hasRolePermission(proj, userID, resource, accessType) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	if proj.RequireTwoFactor && !auth.isTwoFactorVerified(r, userID) {
+		http.Error(w, "project requires two-factor authentication", http.StatusForbidden)
+		return
+	}
+
+	next.ServeHTTP(w, r)
+}
+
+// hasRolePermission returns true if the user's role in the project grants
+// accessType on the resource
+func hasRolePermission(
+	proj *models.Project,
+	userID uint,
+	resource models.PermissionResource,
+	accessType AccessType,
+) bool {
+	for _, role := range proj.Roles {
+		if role.UserID == userID && role.HasPermission(resource, accessType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getRoleBindings returns the role bindings of the requesting user in the
+// project, along with the bindings of the API token that the request was made
+// with
+func (auth *Auth) getRoleBindings(r *http.Request, projID uint) (*models.SubjectRoleBindings, error) {
+	userID, err := auth.getUserIDFromRequest(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &models.SubjectRoleBindings{}
+
+	res.User, err = auth.repo.RoleBinding.ListRoleBindingsByUserID(projID, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := auth.getTokenFromRequest(r); tok != nil && tok.TokenID != "" {
+		apiToken, err := auth.repo.APIToken.ReadAPITokenByUniqueID(tok.TokenID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res.APIToken, err = auth.repo.RoleBinding.ListRoleBindingsByAPITokenID(projID, apiToken.ID)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// isTwoFactorVerified returns true if the user has two-factor authentication
+// enabled, and the session has passed the second step of the login. Sessions
+// that were started before the user enabled two-factor authentication have
+// not passed it. Requests made with a user token, such as from the CLI, use
+// the two-factor claim of the token instead of the session.
+func (auth *Auth) isTwoFactorVerified(r *http.Request, userID uint) bool {
+	tf, err := auth.repo.TwoFactor.ReadTwoFactorByUserID(userID)
+
+	if err != nil || !tf.Enabled {
+		return false
+	}
+
+	if tok := auth.getTokenFromRequest(r); tok != nil {
+		return tok.ProjectID == 0 && tok.TwoFactorVerified
+	}
+
+	session, err := auth.store.Get(r, auth.cookieName)
+
+	if err != nil {
+		return false
+	}
+
+	verified, _ := session.Values["two_factor_verified"].(bool)
+
+	return verified
+}
+
+// GetRequestActor returns the user that made the request, and the ID of the
+// stored API token that the request was made with, if any
+func (auth *Auth) GetRequestActor(r *http.Request) (uint, uint) {
+	tok := auth.getTokenFromRequest(r)
+
+	if tok == nil {
+		userID, _ := auth.getUserIDFromRequest(r)
+		return userID, 0
+	}
+
+	if tok.TokenID == "" {
+		return tok.IBy, 0
+	}
+
+	apiToken, err := auth.repo.APIToken.ReadAPITokenByUniqueID(tok.TokenID)
+
+	if err != nil {
+		return tok.IBy, 0
+	}
+
+	return tok.IBy, apiToken.ID
+}
+
+// useAPIToken checks that the stored API token is scoped to the verb on the
+// resource, and records that the token was used
+func (auth *Auth) useAPIToken(
+	tokenID string,
+	resource models.PermissionResource,
+	accessType AccessType,
+) bool {
+	apiToken, err := auth.repo.APIToken.ReadAPITokenByUniqueID(tokenID)
+
+	if err != nil || !apiToken.HasScope(resource, accessType) {
+		return false
+	}
+
+	now := time.Now()
+	apiToken.LastUsedAt = &now
+
+	// failing to record the last use should not block the request
+	auth.repo.APIToken.UpdateAPIToken(apiToken)
+
+	return true
+}
+
+// getUserIDFromRequest returns the ID of the user that issued the token used in
+// the request, or the ID of the user stored in the session
+func (auth *Auth) getUserIDFromRequest(r *http.Request) (uint, error) {
+	if tok := auth.getTokenFromRequest(r); tok != nil {
+		return tok.IBy, nil
+	}
+
+	session, err := auth.store.Get(r, auth.cookieName)
+
+	if err != nil {
+		return 0, err
+	}
+
+	userID, ok := session.Values["user_id"].(uint)
+
+	if !ok {
+		return 0, errors.New("user id not found")
+	}
+
+	return userID, nil
+}
+
 func (auth *Auth) doesSessionMatchID(r *http.Request, id uint) bool {
 	session, _ := auth.store.Get(r, auth.cookieName)
 
@@ -705,6 +925,15 @@ func (auth *Auth) getTokenFromRequest(r *http.Request) *token.Token {
 		return nil
 	}
 
+	// tokens backed by a stored API token must not be revoked or expired
+	if tok.TokenID != "" {
+		apiToken, err := auth.repo.APIToken.ReadAPITokenByUniqueID(tok.TokenID)
+
+		if err != nil || !apiToken.IsValid() || apiToken.ProjectID != tok.ProjectID {
+			return nil
+		}
+	}
+
 	return tok
 }
 
@@ -843,6 +1072,46 @@ func findClusterIDInRequest(r *http.Request, clusterLoc IDLocation) (uint64, err
 	return clusterID, nil
 }
 
+// findNamespaceInRequest looks for a namespace in the URL, the query string, and
+// the body, in that order. An empty namespace is returned if none is found.
+func findNamespaceInRequest(r *http.Request) (string, error) {
+	if namespace := chi.URLParam(r, "namespace"); namespace != "" {
+		return namespace, nil
+	}
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		return "", err
+	}
+
+	if namespace := vals.Get("namespace"); namespace != "" {
+		return namespace, nil
+	}
+
+	if r.Body == nil || r.Method == "GET" {
+		return "", nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+
+	if err != nil {
+		return "", err
+	}
+
+	// need to create a new stream for the body
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	form := &bodyNamespace{}
+
+	// bodies that are not JSON objects cannot target a namespace
+	if err := json.Unmarshal(body, form); err != nil {
+		return "", nil
+	}
+
+	return form.Namespace, nil
+}
+
 func findInviteIDInRequest(r *http.Request, inviteLoc IDLocation) (uint64, error) {
 	var inviteID uint64
 	var err error
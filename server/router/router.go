@@ -10,6 +10,7 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/porter-dev/porter/internal/auth/token"
+	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/server/api"
 	"github.com/porter-dev/porter/server/requestlog"
 	mw "github.com/porter-dev/porter/server/router/middleware"
@@ -25,8 +26,11 @@ func New(a *api.App) *chi.Mux {
 		TokenSecret: a.ServerConf.TokenGeneratorSecret,
 	}, a.Repo)
 
+	auditor := requestlog.NewAuditor(a.Repo.AuditEvent, auth.GetRequestActor, l)
+
 	r.Route("/api", func(r chi.Router) {
 		r.Use(mw.ContentTypeJSON)
+		r.Use(auditor.Middleware)
 
 		// Group for default operations with 10s timeout
 		r.Group(func(r chi.Router) {
@@ -70,6 +74,51 @@ func New(a *api.App) *chi.Mux {
 				),
 			)
 
+			r.Method(
+				"GET",
+				"/users/{user_id}/2fa",
+				auth.DoesUserIDMatch(
+					requestlog.NewHandler(a.HandleReadTwoFactor, l),
+					mw.URLParam,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/users/{user_id}/2fa/enroll",
+				auth.DoesUserIDMatch(
+					requestlog.NewHandler(a.HandleEnrollTwoFactor, l),
+					mw.URLParam,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/users/{user_id}/2fa/confirm",
+				auth.DoesUserIDMatch(
+					requestlog.NewHandler(a.HandleConfirmTwoFactor, l),
+					mw.URLParam,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/users/{user_id}/2fa/recovery_codes",
+				auth.DoesUserIDMatch(
+					requestlog.NewHandler(a.HandleRegenerateRecoveryCodes, l),
+					mw.URLParam,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/users/{user_id}/2fa",
+				auth.DoesUserIDMatch(
+					requestlog.NewHandler(a.HandleDisableTwoFactor, l),
+					mw.URLParam,
+				),
+			)
+
 			r.Method(
 				"GET",
 				"/cli/login",
@@ -90,6 +139,12 @@ func New(a *api.App) *chi.Mux {
 				requestlog.NewHandler(a.HandleLoginUser, l),
 			)
 
+			r.Method(
+				"POST",
+				"/login/2fa",
+				requestlog.NewHandler(a.HandleLoginTwoFactor, l),
+			)
+
 			r.Method(
 				"GET",
 				"/auth/check",
@@ -197,6 +252,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleGithubOAuthStartProject, l),
 					mw.URLParam,
+					models.IntegrationResource,
 					mw.WriteAccess,
 				),
 			)
@@ -212,12 +268,25 @@ func New(a *api.App) *chi.Mux {
 				requestlog.NewHandler(a.HandleGithubOAuthCallback, l),
 			)
 
+			r.Method(
+				"GET",
+				"/oauth/login/oidc",
+				requestlog.NewHandler(a.HandleSSOStartUser, l),
+			)
+
+			r.Method(
+				"GET",
+				"/oauth/oidc/callback",
+				requestlog.NewHandler(a.HandleSSOCallback, l),
+			)
+
 			r.Method(
 				"GET",
 				"/oauth/projects/{project_id}/digitalocean",
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleDOOAuthStartProject, l),
 					mw.URLParam,
+					models.IntegrationResource,
 					mw.WriteAccess,
 				),
 			)
@@ -235,6 +304,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleReadProject, l),
 					mw.URLParam,
+					models.ProjectResource,
 					mw.ReadAccess,
 				),
 			)
@@ -253,6 +323,345 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleDeleteProject, l),
 					mw.URLParam,
+					models.ProjectResource,
+					mw.DeleteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/2fa",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleUpdateProjectTwoFactor, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/roles/{user_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleUpdateProjectRole, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/rolebindings routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/rolebindings",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListRoleBindings, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/rolebindings",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleCreateRoleBinding, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/projects/{project_id}/rolebindings/{binding_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleDeleteRoleBinding, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/tokens routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/tokens",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListAPITokens, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/tokens",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleCreateAPIToken, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/tokens/{token_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleReadAPIToken, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/projects/{project_id}/tokens/{token_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleRevokeAPIToken, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/notifications routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/notifications/channels",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListNotificationChannels, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/notifications/channels",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleCreateNotificationChannel, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/notifications/channels/{channel_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleUpdateNotificationChannel, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/projects/{project_id}/notifications/channels/{channel_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleDeleteNotificationChannel, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/notifications/channels/{channel_id}/test",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleTestNotificationChannel, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/deploy_policies routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/deploy_policies",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListDeployPolicies, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/deploy_policies",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleCreateDeployPolicy, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/deploy_policies/{policy_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleUpdateDeployPolicy, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/projects/{project_id}/deploy_policies/{policy_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleDeleteDeployPolicy, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/release_sets routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/release_sets",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListReleaseSets, l),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/release_sets",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleCreateReleaseSet, l),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/release_sets/{release_set_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleReadReleaseSet, l),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/release_sets/{release_set_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleUpdateReleaseSet, l),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/projects/{project_id}/release_sets/{release_set_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleDeleteReleaseSet, l),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.WriteAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/secrets routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/secrets",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListProjectSecrets, l),
+					mw.URLParam,
+					models.SecretResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/secrets",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleWriteProjectSecret, l),
+					mw.URLParam,
+					models.SecretResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/projects/{project_id}/secrets/{name}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleDeleteProjectSecret, l),
+					mw.URLParam,
+					models.SecretResource,
+					mw.DeleteAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/events routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/events",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListAuditEvents, l),
+					mw.URLParam,
+					models.AuditResource,
+					mw.ReadAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/sso routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/sso/groups",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListSSOGroupMappings, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/sso/groups",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleCreateSSOGroupMapping, l),
+					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/projects/{project_id}/sso/groups/{mapping_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleDeleteSSOGroupMapping, l),
+					mw.URLParam,
+					models.ProjectResource,
 					mw.WriteAccess,
 				),
 			)
@@ -268,7 +677,8 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.ReleaseResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -279,6 +689,18 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleCreateInvite, l),
 					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/invites/bulk",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleCreateBulkInvite, l),
+					mw.URLParam,
+					models.ProjectResource,
 					mw.WriteAccess,
 				),
 			)
@@ -289,6 +711,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleListProjectInvites, l),
 					mw.URLParam,
+					models.ProjectResource,
 					mw.ReadAccess,
 				),
 			)
@@ -311,6 +734,22 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
+					models.ProjectResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/invites/{invite_id}/resend",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveInviteAccess(
+						requestlog.NewHandler(a.HandleResendInvite, l),
+						mw.URLParam,
+						mw.URLParam,
+					),
+					mw.URLParam,
+					models.ProjectResource,
 					mw.WriteAccess,
 				),
 			)
@@ -322,6 +761,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleListProjectInfra, l),
 					mw.URLParam,
+					models.InfraResource,
 					mw.ReadAccess,
 				),
 			)
@@ -333,7 +773,8 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleProvisionTestInfra, l),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.InfraResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -348,7 +789,8 @@ func New(a *api.App) *chi.Mux {
 						false,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.InfraResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -363,7 +805,8 @@ func New(a *api.App) *chi.Mux {
 						false,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.InfraResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -378,7 +821,8 @@ func New(a *api.App) *chi.Mux {
 						false,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.InfraResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -393,7 +837,8 @@ func New(a *api.App) *chi.Mux {
 						false,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.InfraResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -408,7 +853,8 @@ func New(a *api.App) *chi.Mux {
 						false,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.InfraResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -423,7 +869,8 @@ func New(a *api.App) *chi.Mux {
 						false,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.InfraResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -437,6 +884,7 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
+					models.InfraResource,
 					mw.ReadAccess,
 				),
 			)
@@ -451,6 +899,7 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
+					models.InfraResource,
 					mw.ReadAccess,
 				),
 			)
@@ -465,7 +914,8 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.InfraResource,
+					mw.DeleteAccess,
 				),
 			)
 
@@ -479,7 +929,8 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.InfraResource,
+					mw.DeleteAccess,
 				),
 			)
 
@@ -493,7 +944,8 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.InfraResource,
+					mw.DeleteAccess,
 				),
 			)
 
@@ -507,7 +959,8 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.InfraResource,
+					mw.DeleteAccess,
 				),
 			)
 
@@ -521,7 +974,8 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.InfraResource,
+					mw.DeleteAccess,
 				),
 			)
 
@@ -535,7 +989,8 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.InfraResource,
+					mw.DeleteAccess,
 				),
 			)
 
@@ -546,6 +1001,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleListProjectClusters, l),
 					mw.URLParam,
+					models.ClusterResource,
 					mw.ReadAccess,
 				),
 			)
@@ -566,6 +1022,7 @@ func New(a *api.App) *chi.Mux {
 						true,
 					),
 					mw.URLParam,
+					models.ClusterResource,
 					mw.WriteAccess,
 				),
 			)
@@ -574,12 +1031,13 @@ func New(a *api.App) *chi.Mux {
 				"GET",
 				"/projects/{project_id}/clusters/{cluster_id}",
 				auth.DoesUserHaveProjectAccess(
-					auth.DoesUserHaveClusterAccess(
+					auth.DoesUserHaveFilteredClusterAccess(
 						requestlog.NewHandler(a.HandleReadProjectCluster, l),
 						mw.URLParam,
 						mw.URLParam,
 					),
 					mw.URLParam,
+					models.ClusterResource,
 					mw.ReadAccess,
 				),
 			)
@@ -594,6 +1052,7 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
+					models.ClusterResource,
 					mw.WriteAccess,
 				),
 			)
@@ -608,10 +1067,87 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
+					models.ClusterResource,
+					mw.DeleteAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/clusters/{cluster_id}/env_groups routes
+			r.Method(
+				"POST",
+				"/projects/{project_id}/clusters/{cluster_id}/env_groups",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleCreateEnvGroup, l),
+						mw.URLParam,
+						mw.URLParam,
+					),
+					mw.URLParam,
+					models.ClusterResource,
 					mw.WriteAccess,
 				),
 			)
 
+			r.Method(
+				"GET",
+				"/projects/{project_id}/clusters/{cluster_id}/env_groups",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleListEnvGroups, l),
+						mw.URLParam,
+						mw.URLParam,
+					),
+					mw.URLParam,
+					models.ClusterResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/clusters/{cluster_id}/env_groups/{env_group_id}",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleReadEnvGroup, l),
+						mw.URLParam,
+						mw.URLParam,
+					),
+					mw.URLParam,
+					models.ClusterResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/clusters/{cluster_id}/env_groups/{env_group_id}/versions",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleListEnvGroupVersions, l),
+						mw.URLParam,
+						mw.URLParam,
+					),
+					mw.URLParam,
+					models.ClusterResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/projects/{project_id}/clusters/{cluster_id}/env_groups/{env_group_id}",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleDeleteEnvGroup, l),
+						mw.URLParam,
+						mw.URLParam,
+					),
+					mw.URLParam,
+					models.ClusterResource,
+					mw.DeleteAccess,
+				),
+			)
+
 			// /api/projects/{project_id}/clusters/candidates routes
 			r.Method(
 				"POST",
@@ -619,6 +1155,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleCreateProjectClusterCandidates, l),
 					mw.URLParam,
+					models.ClusterResource,
 					mw.WriteAccess,
 				),
 			)
@@ -629,6 +1166,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleListProjectClusterCandidates, l),
 					mw.URLParam,
+					models.ClusterResource,
 					mw.WriteAccess,
 				),
 			)
@@ -639,6 +1177,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleResolveClusterCandidate, l),
 					mw.URLParam,
+					models.ClusterResource,
 					mw.WriteAccess,
 				),
 			)
@@ -650,6 +1189,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleCreateGCPIntegration, l),
 					mw.URLParam,
+					models.IntegrationResource,
 					mw.WriteAccess,
 				),
 			)
@@ -660,6 +1200,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleCreateAWSIntegration, l),
 					mw.URLParam,
+					models.IntegrationResource,
 					mw.WriteAccess,
 				),
 			)
@@ -670,6 +1211,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleCreateBasicAuthIntegration, l),
 					mw.URLParam,
+					models.IntegrationResource,
 					mw.WriteAccess,
 				),
 			)
@@ -680,7 +1222,8 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleListProjectOAuthIntegrations, l),
 					mw.URLParam,
-					mw.WriteAccess,
+					models.IntegrationResource,
+					mw.ReadAccess,
 				),
 			)
 
@@ -701,26 +1244,63 @@ func New(a *api.App) *chi.Mux {
 						true,
 					),
 					mw.URLParam,
+					models.RegistryResource,
 					mw.WriteAccess,
 				),
 			)
 
 			r.Method(
 				"GET",
-				"/projects/{project_id}/helmrepos",
+				"/projects/{project_id}/helmrepos",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListProjectHelmRepos, l),
+					mw.URLParam,
+					models.RegistryResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/helmrepos/{helm_id}/charts",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListHelmRepoCharts, l),
+					mw.URLParam,
+					models.RegistryResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/helmrepos/{helm_id}/charts/{name}/{version}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleReadHelmRepoChart, l),
+					mw.URLParam,
+					models.RegistryResource,
+					mw.ReadAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/templates routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/templates",
 				auth.DoesUserHaveProjectAccess(
-					requestlog.NewHandler(a.HandleListProjectHelmRepos, l),
+					requestlog.NewHandler(a.HandleListProjectTemplates, l),
 					mw.URLParam,
-					mw.WriteAccess,
+					models.RegistryResource,
+					mw.ReadAccess,
 				),
 			)
 
 			r.Method(
-				"GET",
-				"/projects/{project_id}/helmrepos/{helm_id}/charts",
+				"POST",
+				"/projects/{project_id}/templates/refresh",
 				auth.DoesUserHaveProjectAccess(
-					requestlog.NewHandler(a.HandleListHelmRepoCharts, l),
+					requestlog.NewHandler(a.HandleRefreshTemplates, l),
 					mw.URLParam,
+					models.RegistryResource,
 					mw.WriteAccess,
 				),
 			)
@@ -747,6 +1327,7 @@ func New(a *api.App) *chi.Mux {
 						true,
 					),
 					mw.URLParam,
+					models.RegistryResource,
 					mw.WriteAccess,
 				),
 			)
@@ -757,7 +1338,8 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleListProjectRegistries, l),
 					mw.URLParam,
-					mw.WriteAccess,
+					models.RegistryResource,
+					mw.ReadAccess,
 				),
 			)
 
@@ -771,6 +1353,7 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
+					models.RegistryResource,
 					mw.WriteAccess,
 				),
 			)
@@ -781,6 +1364,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleGetProjectRegistryECRToken, l),
 					mw.URLParam,
+					models.RegistryResource,
 					mw.WriteAccess,
 				),
 			)
@@ -791,6 +1375,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleGetProjectRegistryGCRToken, l),
 					mw.URLParam,
+					models.RegistryResource,
 					mw.WriteAccess,
 				),
 			)
@@ -801,6 +1386,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleGetProjectRegistryDockerhubToken, l),
 					mw.URLParam,
+					models.RegistryResource,
 					mw.WriteAccess,
 				),
 			)
@@ -811,6 +1397,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleGetProjectRegistryDOCRToken, l),
 					mw.URLParam,
+					models.RegistryResource,
 					mw.WriteAccess,
 				),
 			)
@@ -825,7 +1412,8 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
-					mw.WriteAccess,
+					models.RegistryResource,
+					mw.DeleteAccess,
 				),
 			)
 
@@ -840,7 +1428,8 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
-					mw.WriteAccess,
+					models.RegistryResource,
+					mw.ReadAccess,
 				),
 			)
 
@@ -857,7 +1446,8 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
-					mw.WriteAccess,
+					models.RegistryResource,
+					mw.ReadAccess,
 				),
 			)
 
@@ -866,12 +1456,28 @@ func New(a *api.App) *chi.Mux {
 				"GET",
 				"/projects/{project_id}/releases",
 				auth.DoesUserHaveProjectAccess(
-					auth.DoesUserHaveClusterAccess(
+					auth.DoesUserHaveFilteredClusterAccess(
 						requestlog.NewHandler(a.HandleListReleases, l),
 						mw.URLParam,
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/releases/adoptable",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveFilteredClusterAccess(
+						requestlog.NewHandler(a.HandleListAdoptableReleases, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
@@ -886,6 +1492,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
@@ -900,6 +1507,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
@@ -914,6 +1522,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
@@ -928,10 +1537,86 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/releases/{name}/canary",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleListCanaryRollouts, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/releases/{name}/canary/abort",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleAbortCanaryRollout, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/releases/{name}/deployments",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleListDeployments, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/releases/{name}/previews",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleListPreviews, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
 
+			r.Method(
+				"POST",
+				"/projects/{project_id}/releases/{name}/rollout_policy",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleUpdateRolloutPolicy, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.WriteAccess,
+				),
+			)
+
 			r.Method(
 				"GET",
 				"/projects/{project_id}/releases/{name}/webhook_token",
@@ -942,7 +1627,8 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.ReleaseResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -956,6 +1642,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
@@ -967,6 +1654,7 @@ func New(a *api.App) *chi.Mux {
 				auth.DoesUserHaveProjectAccess(
 					requestlog.NewHandler(a.HandleListProjectGitRepos, l),
 					mw.URLParam,
+					models.IntegrationResource,
 					mw.ReadAccess,
 				),
 			)
@@ -981,7 +1669,8 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
-					mw.WriteAccess,
+					models.IntegrationResource,
+					mw.DeleteAccess,
 				),
 			)
 
@@ -995,6 +1684,7 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
+					models.IntegrationResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1009,6 +1699,7 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
+					models.IntegrationResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1023,6 +1714,7 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
+					models.IntegrationResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1037,6 +1729,7 @@ func New(a *api.App) *chi.Mux {
 						mw.URLParam,
 					),
 					mw.URLParam,
+					models.IntegrationResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1046,12 +1739,13 @@ func New(a *api.App) *chi.Mux {
 				"GET",
 				"/projects/{project_id}/k8s/namespaces",
 				auth.DoesUserHaveProjectAccess(
-					auth.DoesUserHaveClusterAccess(
+					auth.DoesUserHaveFilteredClusterAccess(
 						requestlog.NewHandler(a.HandleListNamespaces, l),
 						mw.URLParam,
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ClusterResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1066,6 +1760,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ClusterResource,
 					mw.WriteAccess,
 				),
 			)
@@ -1080,6 +1775,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ClusterResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1094,6 +1790,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ClusterResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1108,6 +1805,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ClusterResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1122,6 +1820,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1136,6 +1835,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1150,6 +1850,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1164,6 +1865,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1178,6 +1880,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1192,6 +1895,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1206,7 +1910,8 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.ReleaseResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -1220,7 +1925,8 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.ReleaseResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -1234,7 +1940,8 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.ReleaseResource,
+					mw.DeleteAccess,
 				),
 			)
 
@@ -1248,6 +1955,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1262,6 +1970,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
@@ -1276,7 +1985,8 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.ReleaseResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -1290,7 +2000,8 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.ReleaseResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -1305,7 +2016,8 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.ReleaseResource,
+					mw.WriteAccess,
 				),
 			)
 
@@ -1327,7 +2039,8 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.ReleaseResource,
+					mw.WriteAccess,
 				),
 			)
 		})
@@ -1346,16 +2059,121 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
+					models.ReleaseResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/releases/{name}/diff",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleDiffUpgradeRelease, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/releases/{name}/rollback/diff",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleDiffRollbackRelease, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
 					mw.ReadAccess,
 				),
 			)
 
+			r.Method(
+				"POST",
+				"/projects/{project_id}/releases/{name}/promote/diff",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleDiffPromoteRelease, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/apply/plan",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveFilteredClusterAccess(
+						requestlog.NewHandler(a.HandlePlanApply, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/apply",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveFilteredClusterAccess(
+						requestlog.NewHandler(a.HandleApply, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/release_sets/{release_set_id}/deploy",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleDeployReleaseSet, l),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.WriteAccess,
+				),
+			)
+
 			r.Method(
 				"POST",
 				"/webhooks/deploy/{token}",
 				requestlog.NewHandler(a.HandleReleaseDeployWebhook, l),
 			)
 
+			r.Method(
+				"POST",
+				"/webhooks/release_sets/{token}",
+				requestlog.NewHandler(a.HandleReleaseSetDeployWebhook, l),
+			)
+
+			r.Method(
+				"POST",
+				"/webhooks/preview/{token}",
+				requestlog.NewHandler(a.HandleDeployPreviewWebhook, l),
+			)
+
+			r.Method(
+				"DELETE",
+				"/webhooks/preview/{token}",
+				requestlog.NewHandler(a.HandleDeletePreviewWebhook, l),
+			)
+
 			r.Method(
 				"POST",
 				"/projects/{project_id}/delete/{name}",
@@ -1366,7 +2184,8 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.ReleaseResource,
+					mw.DeleteAccess,
 				),
 			)
 
@@ -1380,7 +2199,98 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					models.ReleaseResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/releases/{name}/upgrade_chart",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleUpgradeReleaseChart, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/releases/{name}/promote",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandlePromoteRelease, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/releases/adopt",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveFilteredClusterAccess(
+						requestlog.NewHandler(a.HandleAdoptReleases, l),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					models.ReleaseResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/clusters/{cluster_id}/env_groups/{env_group_id}",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleUpdateEnvGroup, l),
+						mw.URLParam,
+						mw.URLParam,
+					),
+					mw.URLParam,
+					models.ClusterResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/clusters/{cluster_id}/env_groups/{env_group_id}/attach",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleAttachEnvGroup, l),
+						mw.URLParam,
+						mw.URLParam,
+					),
+					mw.URLParam,
+					models.ClusterResource,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/clusters/{cluster_id}/env_groups/{env_group_id}/detach",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						requestlog.NewHandler(a.HandleDetachEnvGroup, l),
+						mw.URLParam,
+						mw.URLParam,
+					),
+					mw.URLParam,
+					models.ClusterResource,
+					mw.WriteAccess,
 				),
 			)
 		})